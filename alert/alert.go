@@ -0,0 +1,253 @@
+// Package alert notifies on spikes of the error rate of the requests logged
+// by the logger package, through Slack or PagerDuty, see New.
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/logger"
+)
+
+// buckets is the number of buckets the window is divided into, the rate
+// is the one of the last window give or take a bucket
+const buckets = 60
+
+// Alert is a change of the state of the error rate
+type Alert struct {
+	// Resolved is false when the error rate crossed the threshold, true
+	// when it went back under it
+	Resolved bool
+	// Rate is the error rate over the window, Errors out of Requests
+	Rate      float64
+	Errors    int
+	Requests  int
+	Window    time.Duration
+	Threshold float64
+	Time      time.Time
+}
+
+// String returns the summary of the alert
+func (a Alert) String() string {
+	if a.Resolved {
+		return fmt.Sprintf("Error rate back to %.1f%% (%d/%d requests) over %s, under %g%%",
+			100*a.Rate, a.Errors, a.Requests, a.Window, 100*a.Threshold)
+	}
+
+	return fmt.Sprintf("Error rate at %.1f%% (%d/%d requests) over %s, above %g%%",
+		100*a.Rate, a.Errors, a.Requests, a.Window, 100*a.Threshold)
+}
+
+// Notifier sends the alerts, e.g. Slack or PagerDuty
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// NotifierFunc is a function used as a Notifier
+type NotifierFunc func(ctx context.Context, a Alert) error
+
+// Notify calls f(ctx, a)
+func (f NotifierFunc) Notify(ctx context.Context, a Alert) error {
+	return f(ctx, a)
+}
+
+// Option configures the Tracker returned by New
+type Option func(*Tracker)
+
+// WithMinRequests sets the number of requests over the window below which
+// the rate isn't considered, so a handful of errors at night doesn't page
+// anyone, default to 20
+func WithMinRequests(n int) Option {
+	return func(t *Tracker) {
+		t.minRequests = n
+	}
+}
+
+// WithErrorFunc sets the function reporting whether an entry is an error,
+// default to its status being 5xx
+func WithErrorFunc(f func(e *logger.Entry) bool) Option {
+	return func(t *Tracker) {
+		t.isError = f
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// notifier, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(t *Tracker) {
+		t.errors = f
+	}
+}
+
+// WithClock sets the clock the window is measured with, default to the
+// system clock
+func WithClock(clock logger.Clock) Option {
+	return func(t *Tracker) {
+		t.now = clock.Now
+	}
+}
+
+type bucket struct {
+	start    time.Time
+	requests int
+	errors   int
+}
+
+// Tracker counts the requests and errors of the window in buckets, see
+// New
+type Tracker struct {
+	threshold   float64
+	window      time.Duration
+	notifier    Notifier
+	minRequests int
+	isError     func(e *logger.Entry) bool
+	errors      func(error)
+	now         func() time.Time
+
+	mu      sync.Mutex
+	buckets [buckets]bucket
+	firing  bool
+	closed  bool
+
+	// alerts are sent one at a time, in order
+	alerts chan Alert
+	done   chan struct{}
+}
+
+// New returns a Tracker whose Hook, for logger.WithHook, tracks the rate of
+// errors of the requests over the sliding window and calls notifier once it
+// crosses threshold, e.g. 0.05 for 5%, then once it goes back under it:
+//
+//	alerts := alert.New(0.05, 5*time.Minute,
+//		alert.Slack("https://hooks.slack.com/services/T000/B000/XXXX"))
+//	defer alerts.Close()
+//
+//	logger.New(mux, logger.WithHook(alerts.Hook))
+//
+// The notifications are sent in the background, one at a time, until Close.
+// The rate is only evaluated as requests are logged, a service which stops
+// serving isn't resolved before it serves again. New panics when window is
+// shorter than 60ns, the window being divided into 60 buckets.
+func New(threshold float64, window time.Duration, notifier Notifier, opts ...Option) *Tracker {
+	if window < buckets {
+		panic("alert: window " + window.String() + " shorter than 60ns")
+	}
+
+	t := &Tracker{
+		threshold:   threshold,
+		window:      window,
+		notifier:    notifier,
+		minRequests: 20,
+		isError: func(e *logger.Entry) bool {
+			return e.Status >= http.StatusInternalServerError
+		},
+		errors: func(error) {},
+		now:    time.Now,
+		alerts: make(chan Alert, 16),
+		done:   make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	go t.run()
+
+	return t
+}
+
+// Hook counts the request of e, it's a hook for logger.WithHook
+func (t *Tracker) Hook(e *logger.Entry) {
+	now := t.now()
+	failed := t.isError(e)
+
+	t.mu.Lock()
+
+	if t.closed {
+		t.mu.Unlock()
+
+		return
+	}
+
+	width := t.window / buckets
+	start := now.Truncate(width)
+	b := &t.buckets[start.UnixNano()/int64(width)%buckets]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+
+	b.requests++
+	if failed {
+		b.errors++
+	}
+
+	a := Alert{Window: t.window, Threshold: t.threshold, Time: now}
+	for _, b := range t.buckets {
+		if now.Sub(b.start) < t.window {
+			a.Requests += b.requests
+			a.Errors += b.errors
+		}
+	}
+	a.Rate = float64(a.Errors) / float64(a.Requests)
+
+	switch {
+	case !t.firing && a.Requests >= t.minRequests && a.Rate >= t.threshold:
+		t.firing = true
+	case t.firing && a.Rate < t.threshold:
+		t.firing = false
+		a.Resolved = true
+	default:
+		t.mu.Unlock()
+
+		return
+	}
+
+	// sent under the lock so Close doesn't close alerts meanwhile
+	var dropped bool
+	select {
+	case t.alerts <- a:
+	default:
+		dropped = true
+	}
+
+	t.mu.Unlock()
+
+	if dropped {
+		t.errors(errDropped)
+	}
+}
+
+// errDropped is passed to the error handler for the alerts dropped while
+// the notifier is stuck
+var errDropped = errors.New("alert: notification dropped, too many pending")
+
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	for a := range t.alerts {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := t.notifier.Notify(ctx, a); err != nil {
+			t.errors(err)
+		}
+		cancel()
+	}
+}
+
+// Close sends the pending notifications and stops the background
+// goroutine, the requests logged later are no longer tracked
+func (t *Tracker) Close() error {
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.alerts)
+	}
+	t.mu.Unlock()
+
+	<-t.done
+
+	return nil
+}
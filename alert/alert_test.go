@@ -0,0 +1,162 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/go-http-utils/logger/fakeclock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AlertSuite struct {
+	suite.Suite
+
+	clock  *fakeclock.Clock
+	alerts chan Alert
+}
+
+func (s *AlertSuite) SetupTest() {
+	s.clock = fakeclock.New(time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC))
+	s.alerts = make(chan Alert, 10)
+}
+
+func (s *AlertSuite) notifier() Notifier {
+	return NotifierFunc(func(_ context.Context, a Alert) error {
+		s.alerts <- a
+
+		return nil
+	})
+}
+
+// serve logs n requests answered with status
+func (s *AlertSuite) serve(h http.Handler, status, n int) {
+	for i := 0; i < n; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/"+strconv.Itoa(status), nil))
+	}
+}
+
+func (s *AlertSuite) handler(opts ...Option) http.Handler {
+	opts = append(opts, WithClock(s.clock))
+
+	t := New(0.1, time.Minute, s.notifier(), opts...)
+	s.T().Cleanup(func() { t.Close() })
+
+	return logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/"))
+		res.WriteHeader(status)
+	}), logger.WithHook(t.Hook), logger.WithWriter(&testWriter{}))
+}
+
+// testWriter discards the log output
+type testWriter struct{}
+
+func (testWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (s *AlertSuite) alert() Alert {
+	select {
+	case a := <-s.alerts:
+		return a
+	case <-time.After(time.Second):
+		s.FailNow("no alert")
+	}
+
+	return Alert{}
+}
+
+func (s *AlertSuite) TestFireAndResolve() {
+	h := s.handler()
+
+	s.serve(h, http.StatusOK, 18)
+	s.serve(h, http.StatusBadGateway, 2)
+
+	a := s.alert()
+	s.False(a.Resolved)
+	s.Equal(2, a.Errors)
+	s.Equal(20, a.Requests)
+	s.InDelta(0.1, a.Rate, 1e-9)
+	s.Equal("Error rate at 10.0% (2/20 requests) over 1m0s, above 10%", a.String())
+
+	// still firing
+	s.serve(h, http.StatusBadGateway, 5)
+	s.Empty(s.alerts)
+
+	s.serve(h, http.StatusOK, 50)
+
+	a = s.alert()
+	s.True(a.Resolved)
+	s.Equal("Error rate back to 9.9% (7/71 requests) over 1m0s, under 10%", a.String())
+}
+
+func (s *AlertSuite) TestMinRequests() {
+	h := s.handler(WithMinRequests(5))
+
+	s.serve(h, http.StatusBadGateway, 4)
+	s.Empty(s.alerts)
+
+	s.serve(h, http.StatusBadGateway, 1)
+	s.Equal(5, s.alert().Requests)
+}
+
+func (s *AlertSuite) TestWindow() {
+	h := s.handler()
+
+	s.serve(h, http.StatusBadGateway, 10)
+	s.clock.Advance(time.Minute)
+
+	// the errors of the previous window are forgotten
+	s.serve(h, http.StatusOK, 20)
+	s.Empty(s.alerts)
+}
+
+func (s *AlertSuite) TestErrorFunc() {
+	h := s.handler(WithErrorFunc(func(e *logger.Entry) bool {
+		return e.Status == http.StatusOK
+	}))
+
+	s.serve(h, http.StatusOK, 20)
+	s.Equal(20, s.alert().Errors)
+}
+
+func (s *AlertSuite) TestClose() {
+	release := make(chan struct{})
+	t := New(0.1, time.Minute, NotifierFunc(func(_ context.Context, a Alert) error {
+		<-release
+		s.alerts <- a
+
+		return nil
+	}), WithMinRequests(1), WithClock(s.clock))
+
+	t.Hook(&logger.Entry{Status: http.StatusBadGateway})
+	close(release)
+	s.Nil(t.Close())
+
+	// the pending alert was sent before Close returned
+	s.Len(s.alerts, 1)
+
+	t.Hook(&logger.Entry{Status: http.StatusOK})
+	s.Nil(t.Close())
+}
+
+func (s *AlertSuite) TestShortWindow() {
+	s.Panics(func() {
+		New(0.1, 59*time.Nanosecond, s.notifier())
+	})
+
+	t := New(0.1, 60*time.Nanosecond, s.notifier(), WithMinRequests(1), WithClock(s.clock))
+	defer t.Close()
+
+	t.Hook(&logger.Entry{Status: http.StatusBadGateway})
+	s.Equal(1, s.alert().Errors)
+}
+
+func TestAlert(t *testing.T) {
+	suite.Run(t, new(AlertSuite))
+}
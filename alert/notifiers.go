@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// pagerDutyEvents is the endpoint of the PagerDuty Events API v2
+const pagerDutyEvents = "https://events.pagerduty.com/v2/enqueue"
+
+// client sends the notifications
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// slack posts the alerts to an incoming webhook
+type slack struct {
+	url string
+}
+
+// Slack returns a Notifier posting the alerts to the Slack incoming
+// webhook url
+func Slack(url string) Notifier {
+	return slack{url}
+}
+
+func (s slack) Notify(ctx context.Context, a Alert) error {
+	emoji := ":rotating_light:"
+	if a.Resolved {
+		emoji = ":white_check_mark:"
+	}
+
+	return post(ctx, s.url, map[string]string{"text": emoji + " " + a.String()})
+}
+
+// pagerDuty triggers and resolves an incident with the Events API v2
+type pagerDuty struct {
+	url        string
+	routingKey string
+	source     string
+}
+
+// PagerDuty returns a Notifier triggering an incident of the service of
+// the integration routingKey with the Events API v2, resolved once the
+// error rate is back under the threshold. The source of the incidents is
+// the host name.
+func PagerDuty(routingKey string) Notifier {
+	source, _ := os.Hostname()
+
+	return pagerDuty{url: pagerDutyEvents, routingKey: routingKey, source: source}
+}
+
+func (pd pagerDuty) Notify(ctx context.Context, a Alert) error {
+	event := map[string]interface{}{
+		"routing_key":  pd.routingKey,
+		"event_action": "trigger",
+		// the same for the trigger and the resolve of an incident
+		"dedup_key": "http-error-rate-" + pd.source,
+	}
+
+	if a.Resolved {
+		event["event_action"] = "resolve"
+	} else {
+		event["payload"] = map[string]interface{}{
+			"summary":  a.String(),
+			"source":   pd.source,
+			"severity": "error",
+			"custom_details": map[string]interface{}{
+				"rate":      a.Rate,
+				"errors":    a.Errors,
+				"requests":  a.Requests,
+				"window":    a.Window.String(),
+				"threshold": a.Threshold,
+			},
+		}
+	}
+
+	return post(ctx, pd.url, event)
+}
+
+// post posts v as JSON to endpoint
+func post(ctx context.Context, endpoint string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		// the url of Slack webhooks is a secret, it's left out
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			return fmt.Errorf("alert: notification failed: %w", uerr.Err)
+		}
+
+		return err
+	}
+	defer res.Body.Close()
+
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("alert: notification failed with status %d", res.StatusCode)
+	}
+
+	return nil
+}
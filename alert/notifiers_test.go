@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NotifiersSuite struct {
+	suite.Suite
+
+	bodies []map[string]interface{}
+	status int
+	server *httptest.Server
+}
+
+func (s *NotifiersSuite) SetupTest() {
+	s.bodies, s.status = nil, http.StatusAccepted
+	s.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		s.Equal("application/json", req.Header.Get("Content-Type"))
+
+		body := map[string]interface{}{}
+		json.NewDecoder(req.Body).Decode(&body)
+		s.bodies = append(s.bodies, body)
+
+		res.WriteHeader(s.status)
+	}))
+}
+
+func (s *NotifiersSuite) TearDownTest() {
+	s.server.Close()
+}
+
+var testAlert = Alert{Rate: 0.25, Errors: 5, Requests: 20, Window: time.Minute, Threshold: 0.1}
+
+func (s *NotifiersSuite) TestSlack() {
+	s.Nil(Slack(s.server.URL).Notify(context.Background(), testAlert))
+
+	resolved := testAlert
+	resolved.Resolved = true
+	s.Nil(Slack(s.server.URL).Notify(context.Background(), resolved))
+
+	s.Equal(":rotating_light: Error rate at 25.0% (5/20 requests) over 1m0s, above 10%", s.bodies[0]["text"])
+	s.Equal(":white_check_mark: Error rate back to 25.0% (5/20 requests) over 1m0s, under 10%", s.bodies[1]["text"])
+}
+
+func (s *NotifiersSuite) TestPagerDuty() {
+	pd := PagerDuty("routing-key").(pagerDuty)
+	pd.url, pd.source = s.server.URL, "web-1"
+
+	s.Nil(pd.Notify(context.Background(), testAlert))
+
+	resolved := testAlert
+	resolved.Resolved = true
+	s.Nil(pd.Notify(context.Background(), resolved))
+
+	s.Require().Len(s.bodies, 2)
+	s.Equal("routing-key", s.bodies[0]["routing_key"])
+	s.Equal("trigger", s.bodies[0]["event_action"])
+	s.Equal("http-error-rate-web-1", s.bodies[0]["dedup_key"])
+
+	payload := s.bodies[0]["payload"].(map[string]interface{})
+	s.Equal("web-1", payload["source"])
+	s.Equal("error", payload["severity"])
+	s.Equal(float64(5), payload["custom_details"].(map[string]interface{})["errors"])
+
+	s.Equal("resolve", s.bodies[1]["event_action"])
+	s.Equal("http-error-rate-web-1", s.bodies[1]["dedup_key"])
+	s.NotContains(s.bodies[1], "payload")
+}
+
+func (s *NotifiersSuite) TestError() {
+	s.status = http.StatusForbidden
+	s.EqualError(Slack(s.server.URL).Notify(context.Background(), testAlert),
+		"alert: notification failed with status 403")
+}
+
+func (s *NotifiersSuite) TestURLLeftOut() {
+	s.server.Close()
+
+	err := Slack(s.server.URL+"/services/T000/B000/secret").Notify(context.Background(), testAlert)
+	s.Require().NotNil(err)
+	s.NotContains(err.Error(), "secret")
+	s.NotContains(err.Error(), s.server.URL)
+}
+
+func TestNotifiers(t *testing.T) {
+	suite.Run(t, new(NotifiersSuite))
+}
@@ -0,0 +1,498 @@
+// Package appinsights ships the entries of the logger package to Azure
+// Monitor Application Insights as request telemetry, in the requests
+// table, see New.
+package appinsights
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/logger"
+)
+
+// defaultEndpoint is the ingestion endpoint of the connection strings
+// without one
+const defaultEndpoint = "https://dc.services.visualstudio.com"
+
+// sdkVersion identifies the exporter in the ai.internal.sdkVersion tag
+const sdkVersion = "go-http-utils-logger"
+
+// Option configures the Exporter returned by New
+type Option func(*Exporter)
+
+// WithFlushInterval sets how often the pending telemetry is sent, default
+// to 5 seconds, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(ex *Exporter) {
+		if d > 0 {
+			ex.interval = d
+		}
+	}
+}
+
+// WithBatchSize sets the number of items sent in one request, and past
+// which they're sent before the flush interval, default to 500, kept for
+// n <= 0
+func WithBatchSize(n int) Option {
+	return func(ex *Exporter) {
+		if n > 0 {
+			ex.batchSize = n
+		}
+	}
+}
+
+// WithRetries sets how many times a batch is sent before it's dropped when
+// the ingestion endpoint is throttling or unavailable, default to 3, kept
+// for n <= 0
+func WithRetries(n int) Option {
+	return func(ex *Exporter) {
+		if n > 0 {
+			ex.attempts = n
+		}
+	}
+}
+
+// WithRoleName sets the cloud role name of the service in the application
+// map, ai.cloud.role, default to the WEBSITE_SITE_NAME environment variable
+// of App Service
+func WithRoleName(name string) Option {
+	return func(ex *Exporter) {
+		ex.role = name
+	}
+}
+
+// WithHTTPClient sets the client sending the telemetry, default to
+// http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(ex *Exporter) {
+		ex.client = c
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches sent in the background, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(ex *Exporter) {
+		ex.errors = f
+	}
+}
+
+// Exporter is a logger.Formatter sending every entry as a request
+// telemetry item, e.g. logger.WithFormatter(exporter). Items are sent in
+// batches in the background every flush interval, or as soon as a batch is
+// full, and retried when throttled. Close sends the pending items, call it
+// on shutdown.
+type Exporter struct {
+	endpoint  string
+	ikey      string
+	role      string
+	instance  string
+	client    *http.Client
+	interval  time.Duration
+	batchSize int
+	attempts  int
+	backoff   time.Duration
+	errors    func(error)
+
+	mu      sync.Mutex
+	pending []envelope
+
+	// sending serializes the batches
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// New returns an Exporter sending to the Application Insights resource of
+// connectionString, e.g.
+// "InstrumentationKey=00000000-0000-0000-0000-000000000000;IngestionEndpoint=https://westeurope-5.in.applicationinsights.azure.com/",
+// as given by the APPLICATIONINSIGHTS_CONNECTION_STRING environment
+// variable of App Service
+func New(connectionString string, opts ...Option) (*Exporter, error) {
+	ikey, endpoint, err := parseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, _ := os.Hostname()
+
+	ex := &Exporter{
+		endpoint:  endpoint + "/v2/track",
+		ikey:      ikey,
+		role:      os.Getenv("WEBSITE_SITE_NAME"),
+		instance:  instance,
+		client:    http.DefaultClient,
+		interval:  5 * time.Second,
+		batchSize: 500,
+		attempts:  3,
+		backoff:   time.Second,
+		errors:    func(error) {},
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(ex)
+	}
+
+	ex.wg.Add(1)
+	go ex.run()
+
+	return ex, nil
+}
+
+// parseConnectionString returns the instrumentation key and the ingestion
+// endpoint of a connection string
+func parseConnectionString(s string) (ikey, endpoint string, err error) {
+	endpoint = defaultEndpoint
+
+	for _, part := range strings.Split(s, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch strings.ToLower(k) {
+		case "instrumentationkey":
+			ikey = v
+		case "ingestionendpoint":
+			endpoint = strings.TrimRight(v, "/")
+		}
+	}
+
+	if ikey == "" {
+		return "", "", errors.New("appinsights: no InstrumentationKey in the connection string")
+	}
+
+	return ikey, endpoint, nil
+}
+
+func (ex *Exporter) run() {
+	defer ex.wg.Done()
+
+	ticker := time.NewTicker(ex.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ex.wake:
+		case <-ex.done:
+			return
+		}
+
+		if err := ex.Flush(); err != nil {
+			ex.errors(err)
+		}
+	}
+}
+
+// Format queues e as a request telemetry item, the writer of the handler
+// is unused
+func (ex *Exporter) Format(_ io.Writer, e *logger.Entry) error {
+	item := ex.envelope(e)
+
+	ex.mu.Lock()
+	ex.pending = append(ex.pending, item)
+	full := len(ex.pending) >= ex.batchSize
+	ex.mu.Unlock()
+
+	if full {
+		select {
+		case ex.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Flush sends the pending items
+func (ex *Exporter) Flush() error {
+	ex.sending.Lock()
+	defer ex.sending.Unlock()
+
+	ex.mu.Lock()
+	items := ex.pending
+	ex.pending = nil
+	ex.mu.Unlock()
+
+	for len(items) > 0 {
+		n := min(len(items), ex.batchSize)
+		if err := ex.send(context.Background(), items[:n]); err != nil {
+			return err
+		}
+		items = items[n:]
+	}
+
+	return nil
+}
+
+// Close stops the background sends and sends the pending items
+func (ex *Exporter) Close() error {
+	var err error
+
+	ex.once.Do(func() {
+		close(ex.done)
+		ex.wg.Wait()
+
+		err = ex.Flush()
+	})
+
+	return err
+}
+
+// trackResponse is the response of the ingestion endpoint to a partially
+// accepted batch
+type trackResponse struct {
+	Errors []struct {
+		Index      int `json:"index"`
+		StatusCode int `json:"statusCode"`
+	} `json:"errors"`
+}
+
+// send sends batch, resending the items rejected with a transient status
+// with exponential backoff
+func (ex *Exporter) send(ctx context.Context, batch []envelope) error {
+	var err error
+
+	for attempt := 0; attempt < ex.attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(ex.backoff << (attempt - 1))
+		}
+
+		var retry []envelope
+		retry, err = ex.post(ctx, batch)
+		if len(retry) == 0 {
+			return err
+		}
+		batch = retry
+	}
+
+	return err
+}
+
+// post sends batch once and returns the items to send again
+func (ex *Exporter) post(ctx context.Context, batch []envelope) ([]envelope, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ex.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := ex.client.Do(req)
+	if err != nil {
+		return batch, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusOK:
+		return nil, nil
+	case res.StatusCode == http.StatusPartialContent:
+		tr := trackResponse{}
+		if err := json.NewDecoder(res.Body).Decode(&tr); err != nil {
+			return nil, err
+		}
+
+		var retry []envelope
+		for _, e := range tr.Errors {
+			if transient(e.StatusCode) && e.Index >= 0 && e.Index < len(batch) {
+				retry = append(retry, batch[e.Index])
+			}
+		}
+
+		return retry, fmt.Errorf("appinsights: %d of %d items rejected", len(tr.Errors), len(batch))
+	case transient(res.StatusCode):
+		return batch, fmt.Errorf("appinsights: %s", res.Status)
+	}
+
+	return nil, fmt.Errorf("appinsights: %s", res.Status)
+}
+
+// transient reports whether the items rejected with status can be sent
+// again
+func transient(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, 439,
+		http.StatusInternalServerError, http.StatusServiceUnavailable:
+		return true
+	}
+
+	return false
+}
+
+type envelope struct {
+	Name string            `json:"name"`
+	Time string            `json:"time"`
+	IKey string            `json:"iKey"`
+	Tags map[string]string `json:"tags"`
+	Data data              `json:"data"`
+}
+
+type data struct {
+	BaseType string      `json:"baseType"`
+	BaseData requestData `json:"baseData"`
+}
+
+type requestData struct {
+	Ver          int                `json:"ver"`
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Duration     string             `json:"duration"`
+	ResponseCode string             `json:"responseCode"`
+	Success      bool               `json:"success"`
+	URL          string             `json:"url"`
+	Properties   map[string]string  `json:"properties,omitempty"`
+	Measurements map[string]float64 `json:"measurements,omitempty"`
+}
+
+// envelope returns the request telemetry item of e
+func (ex *Exporter) envelope(e *logger.Entry) envelope {
+	path, _, _ := strings.Cut(e.URL, "?")
+	if e.Route != "" {
+		path = e.Route
+	}
+	name := e.Method + " " + path
+
+	scheme := "http"
+	if e.TLS != nil {
+		scheme = "https"
+	}
+
+	operationID, parentID := operation(e)
+
+	tags := map[string]string{
+		"ai.operation.id":        operationID,
+		"ai.operation.name":      name,
+		"ai.internal.sdkVersion": sdkVersion,
+	}
+
+	if parentID != "" {
+		tags["ai.operation.parentId"] = parentID
+	}
+
+	if host, _, err := net.SplitHostPort(e.RemoteAddr); err == nil {
+		tags["ai.location.ip"] = host
+	}
+
+	if ex.role != "" {
+		tags["ai.cloud.role"] = ex.role
+	}
+
+	if ex.instance != "" {
+		tags["ai.cloud.roleInstance"] = ex.instance
+	}
+
+	if e.RemoteUser != "" {
+		tags["ai.user.authUserId"] = e.RemoteUser
+	}
+
+	properties := map[string]string{}
+
+	if e.RequestID != "" {
+		properties["request.id"] = e.RequestID
+	}
+
+	if e.UserAgent != "" {
+		properties["request.user_agent"] = e.UserAgent
+	}
+
+	if e.Referer != "" {
+		properties["request.referer"] = e.Referer
+	}
+
+	if e.Panicked {
+		properties["panic"] = e.PanicValue
+	}
+
+	for k, v := range e.Fields {
+		if _, ok := properties[k]; !ok {
+			properties[k] = fmt.Sprint(v)
+		}
+	}
+
+	return envelope{
+		Name: "Microsoft.ApplicationInsights.Request",
+		Time: e.Start.UTC().Format(time.RFC3339Nano),
+		IKey: ex.ikey,
+		Tags: tags,
+		Data: data{
+			BaseType: "RequestData",
+			BaseData: requestData{
+				Ver:          2,
+				ID:           randomHex(8),
+				Name:         name,
+				Duration:     duration(e.Duration),
+				ResponseCode: strconv.Itoa(e.Status),
+				Success:      e.Status < http.StatusBadRequest,
+				URL:          scheme + "://" + e.Host + e.URL,
+				Properties:   properties,
+				Measurements: map[string]float64{
+					"request.size":  float64(e.RequestSize),
+					"response.size": float64(e.Size),
+				},
+			},
+		},
+	}
+}
+
+// operation returns the operation ID and parent ID of e: the trace and
+// span IDs of its trace headers, the root of a legacy |root.parent.
+// Request-Id header, or else its request ID
+func operation(e *logger.Entry) (operationID, parentID string) {
+	if e.TraceID != "" {
+		return e.TraceID, e.SpanID
+	}
+
+	if e.Request != nil {
+		if id := e.Request.Header.Get("Request-Id"); strings.HasPrefix(id, "|") {
+			root, _, _ := strings.Cut(id[1:], ".")
+			if root != "" {
+				return root, id
+			}
+		}
+	}
+
+	if e.RequestID != "" {
+		return e.RequestID, ""
+	}
+
+	return randomHex(16), ""
+}
+
+// duration formats d as Application Insights expects, d.hh:mm:ss.fffffff
+func duration(d time.Duration) string {
+	ticks := d.Nanoseconds() / 100
+	seconds := ticks / 1e7
+
+	return fmt.Sprintf("%d.%02d:%02d:%02d.%07d",
+		seconds/86400, seconds/3600%24, seconds/60%60, seconds%60, ticks%1e7)
+}
+
+// randomHex returns n random bytes in hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
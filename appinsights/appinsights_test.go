@@ -0,0 +1,199 @@
+package appinsights
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type testIngestion struct {
+	mu       sync.Mutex
+	batches  [][]envelope
+	statuses []int
+	response string
+}
+
+func (ti *testIngestion) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+
+	b, _ := io.ReadAll(req.Body)
+	batch := []envelope{}
+	json.Unmarshal(b, &batch)
+	ti.batches = append(ti.batches, batch)
+
+	status := http.StatusOK
+	if len(ti.statuses) > 0 {
+		status, ti.statuses = ti.statuses[0], ti.statuses[1:]
+	}
+
+	res.WriteHeader(status)
+	if status == http.StatusPartialContent {
+		io.WriteString(res, ti.response)
+	}
+}
+
+type AppInsightsSuite struct {
+	suite.Suite
+
+	ti     *testIngestion
+	server *httptest.Server
+	ex     *Exporter
+}
+
+func (s *AppInsightsSuite) SetupTest() {
+	s.ti = &testIngestion{}
+	s.server = httptest.NewServer(s.ti)
+
+	ex, err := New("InstrumentationKey=ikey;IngestionEndpoint="+s.server.URL+"/",
+		WithFlushInterval(time.Hour), WithRoleName("api"))
+	s.Nil(err)
+	ex.backoff = time.Millisecond
+	s.ex = ex
+}
+
+func (s *AppInsightsSuite) TearDownTest() {
+	s.Nil(s.ex.Close())
+	s.server.Close()
+}
+
+func (s *AppInsightsSuite) serve(req *http.Request) envelope {
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(s.ex)).ServeHTTP(httptest.NewRecorder(), req)
+	s.Nil(s.ex.Flush())
+
+	s.Require().Len(s.ti.batches, 1)
+	s.Require().Len(s.ti.batches[0], 1)
+
+	return s.ti.batches[0][0]
+}
+
+func (s *AppInsightsSuite) TestRequest() {
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+
+	item := s.serve(req)
+
+	s.Equal("Microsoft.ApplicationInsights.Request", item.Name)
+	s.Equal("ikey", item.IKey)
+	s.Equal("RequestData", item.Data.BaseType)
+	s.Equal("GET /users", item.Data.BaseData.Name)
+	s.Equal("404", item.Data.BaseData.ResponseCode)
+	s.False(item.Data.BaseData.Success)
+	s.Equal("http://example.com/users?id=1", item.Data.BaseData.URL)
+	s.Len(item.Data.BaseData.ID, 16)
+	s.Equal("test-agent", item.Data.BaseData.Properties["request.user_agent"])
+	s.Equal(float64(19), item.Data.BaseData.Measurements["response.size"])
+	s.Equal("GET /users", item.Tags["ai.operation.name"])
+	s.Equal("192.0.2.1", item.Tags["ai.location.ip"])
+	s.Equal("api", item.Tags["ai.cloud.role"])
+	s.Len(item.Tags["ai.operation.id"], 32)
+}
+
+func (s *AppInsightsSuite) TestTraceparent() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	item := s.serve(req)
+
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", item.Tags["ai.operation.id"])
+	s.Equal("00f067aa0ba902b7", item.Tags["ai.operation.parentId"])
+}
+
+func (s *AppInsightsSuite) TestLegacyRequestID() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Request-Id", "|root.1.")
+
+	item := s.serve(req)
+
+	s.Equal("root", item.Tags["ai.operation.id"])
+	s.Equal("|root.1.", item.Tags["ai.operation.parentId"])
+}
+
+func (s *AppInsightsSuite) TestRequestID() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logger.RequestIDHeader, "abc")
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(s.ex), logger.WithRequestID()).
+		ServeHTTP(httptest.NewRecorder(), req)
+	s.Nil(s.ex.Flush())
+
+	s.Equal("abc", s.ti.batches[0][0].Tags["ai.operation.id"])
+	s.NotContains(s.ti.batches[0][0].Tags, "ai.operation.parentId")
+}
+
+func (s *AppInsightsSuite) TestRetry() {
+	s.ti.statuses = []int{http.StatusServiceUnavailable, http.StatusOK}
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(s.ex)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(s.ex.Flush())
+
+	s.Len(s.ti.batches, 2)
+	s.Equal(s.ti.batches[0], s.ti.batches[1])
+}
+
+func (s *AppInsightsSuite) TestPartialRetry() {
+	s.ti.statuses = []int{http.StatusPartialContent}
+	s.ti.response = `{"itemsReceived":2,"itemsAccepted":1,"errors":[{"index":1,"statusCode":429}]}`
+
+	h := logger.New(http.NotFoundHandler(), logger.WithFormatter(s.ex))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(s.ex.Flush())
+
+	s.Len(s.ti.batches, 2)
+	s.Len(s.ti.batches[1], 1)
+	s.Equal("GET /b", s.ti.batches[1][0].Data.BaseData.Name)
+}
+
+func (s *AppInsightsSuite) TestDropped() {
+	s.ti.statuses = []int{http.StatusBadRequest}
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(s.ex)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.EqualError(s.ex.Flush(), "appinsights: 400 Bad Request")
+	s.Len(s.ti.batches, 1)
+}
+
+func (s *AppInsightsSuite) TestInvalidOptions() {
+	ex, err := New("InstrumentationKey=ikey;IngestionEndpoint="+s.server.URL+"/",
+		WithFlushInterval(0), WithBatchSize(0), WithRetries(-1))
+	s.Require().Nil(err)
+	defer ex.Close()
+
+	s.Equal(5*time.Second, ex.interval)
+	s.Equal(500, ex.batchSize)
+	s.Equal(3, ex.attempts)
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(ex)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(ex.Flush())
+	s.Len(s.ti.batches, 1)
+}
+
+func (s *AppInsightsSuite) TestConnectionString() {
+	ikey, endpoint, err := parseConnectionString("InstrumentationKey=abc")
+	s.Nil(err)
+	s.Equal("abc", ikey)
+	s.Equal(defaultEndpoint, endpoint)
+
+	_, _, err = parseConnectionString("IngestionEndpoint=https://example.com/")
+	s.NotNil(err)
+}
+
+func (s *AppInsightsSuite) TestDuration() {
+	s.Equal("0.00:00:00.1234567", duration(123456789*time.Nanosecond))
+	s.Equal("1.01:01:01.0000000", duration(25*time.Hour+time.Minute+time.Second))
+}
+
+func TestAppInsights(t *testing.T) {
+	suite.Run(t, new(AppInsightsSuite))
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy tells what to do with an entry when the WithAsync queue
+// is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the request wait until the queue has room
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop discards the entry, the request is never delayed
+	OverflowDrop
+)
+
+// ErrClosed is returned when writing after the handler has been closed
+var ErrClosed = errors.New("logger: closed")
+
+// asyncWriter queues writes to a bounded channel drained by background
+// workers, so the writer latency is kept out of the request path
+type asyncWriter struct {
+	w       io.Writer
+	entries chan []byte
+	policy  OverflowPolicy
+	failed  func(error)
+	workers sync.WaitGroup
+	dropped uint64
+	// written counts the entries written, when not nil
+	written *atomic.Uint64
+
+	mu      sync.RWMutex
+	closed  bool
+	flushed *sync.Cond
+	pending int
+}
+
+// newAsyncWriter returns an asyncWriter calling failed, if not nil, with
+// the errors of w and ErrQueueFull for the dropped entries
+func newAsyncWriter(w io.Writer, size, workers int, policy OverflowPolicy, failed func(error)) *asyncWriter {
+	if workers < 1 {
+		workers = 1
+	}
+
+	aw := &asyncWriter{
+		w:       w,
+		entries: make(chan []byte, size),
+		policy:  policy,
+		failed:  failed,
+	}
+	aw.flushed = sync.NewCond(&sync.Mutex{})
+
+	aw.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go aw.work()
+	}
+
+	return aw
+}
+
+func (aw *asyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return 0, ErrClosed
+	}
+
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	aw.add(1)
+
+	if aw.policy == OverflowDrop {
+		select {
+		case aw.entries <- entry:
+		default:
+			aw.add(-1)
+			atomic.AddUint64(&aw.dropped, 1)
+			if aw.failed != nil {
+				aw.failed(ErrQueueFull)
+			}
+		}
+	} else {
+		aw.entries <- entry
+	}
+
+	return len(p), nil
+}
+
+func (aw *asyncWriter) work() {
+	defer aw.workers.Done()
+
+	for entry := range aw.entries {
+		if _, err := aw.w.Write(entry); err != nil {
+			if aw.failed != nil {
+				aw.failed(err)
+			}
+		} else if aw.written != nil {
+			aw.written.Add(1)
+		}
+		aw.add(-1)
+	}
+}
+
+func (aw *asyncWriter) add(delta int) {
+	aw.flushed.L.Lock()
+	aw.pending += delta
+	if aw.pending == 0 {
+		aw.flushed.Broadcast()
+	}
+	aw.flushed.L.Unlock()
+}
+
+// queued returns the number of entries queued or being written
+func (aw *asyncWriter) queued() int {
+	aw.flushed.L.Lock()
+	defer aw.flushed.L.Unlock()
+
+	return aw.pending
+}
+
+// Flush blocks until every queued entry has been written
+func (aw *asyncWriter) Flush() {
+	aw.flushed.L.Lock()
+	for aw.pending > 0 {
+		aw.flushed.Wait()
+	}
+	aw.flushed.L.Unlock()
+}
+
+// Close writes the queued entries and stops the workers, later writes
+// fail with ErrClosed
+func (aw *asyncWriter) Close() error {
+	aw.mu.Lock()
+	if !aw.closed {
+		aw.closed = true
+		close(aw.entries)
+	}
+	aw.mu.Unlock()
+
+	aw.workers.Wait()
+
+	return nil
+}
+
+// Dropped returns the number of entries discarded by OverflowDrop
+func (aw *asyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AsyncSuite struct {
+	suite.Suite
+}
+
+func (s *AsyncSuite) TestFlush() {
+	sw := &syncWriter{}
+	aw := newAsyncWriter(sw, 10, 1, OverflowBlock, nil)
+
+	for i := 0; i < 5; i++ {
+		aw.Write([]byte("entry\n"))
+	}
+	aw.Flush()
+
+	s.Equal("entry\nentry\nentry\nentry\nentry\n", sw.String())
+	s.Nil(aw.Close())
+}
+
+func (s *AsyncSuite) TestClose() {
+	sw := &syncWriter{}
+	aw := newAsyncWriter(sw, 10, 2, OverflowBlock, nil)
+
+	aw.Write([]byte("a\n"))
+	aw.Write([]byte("b\n"))
+	s.Nil(aw.Close())
+	s.Nil(aw.Close())
+
+	s.Len(sw.String(), 4)
+
+	_, err := aw.Write([]byte("c\n"))
+	s.Equal(ErrClosed, err)
+}
+
+func (s *AsyncSuite) TestDrop() {
+	bw := &blockingWriter{release: make(chan struct{})}
+	aw := newAsyncWriter(bw, 1, 1, OverflowDrop, nil)
+
+	for i := 0; i < 10; i++ {
+		n, err := aw.Write([]byte("entry\n"))
+		s.Nil(err)
+		s.Equal(6, n)
+	}
+
+	// one entry is held by the worker, one is queued
+	s.True(aw.Dropped() >= 8)
+
+	close(bw.release)
+	s.Nil(aw.Close())
+}
+
+func (s *AsyncSuite) TestCopiesEntry() {
+	sw := &syncWriter{}
+	aw := newAsyncWriter(sw, 10, 1, OverflowBlock, nil)
+
+	b := []byte("before\n")
+	aw.Write(b)
+	copy(b, "after!\n")
+	aw.Close()
+
+	s.Equal("before\n", sw.String())
+}
+
+func (s *AsyncSuite) TestHandler() {
+	sw := &syncWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(sw), WithClock(testClock{}), WithFormat(TinyLoggerType),
+		WithAsync(10), WithAsyncWorkers(2), WithOverflowPolicy(OverflowDrop))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(h.(interface{ Flush() error }).Flush())
+
+	s.Equal("GET / 404 19 - 0.000 ms\n", sw.String())
+	s.Nil(h.(io.Closer).Close())
+}
+
+func (s *AsyncSuite) TestSyncHandler() {
+	h := New(http.NotFoundHandler())
+
+	s.Nil(h.(interface{ Flush() error }).Flush())
+	s.Nil(h.(io.Closer).Close())
+}
+
+func TestAsync(t *testing.T) {
+	suite.Run(t, new(AsyncSuite))
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	b  []byte
+}
+
+func (sw *syncWriter) Write(b []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.b = append(sw.b, b...)
+
+	return len(b), nil
+}
+
+func (sw *syncWriter) String() string {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	return string(sw.b)
+}
+
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (bw *blockingWriter) Write(b []byte) (int, error) {
+	<-bw.release
+
+	return len(b), nil
+}
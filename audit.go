@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// auditHashField is the field WithAuditChain appends to every line
+const auditHashField = "audit_hash"
+
+// auditWriter appends to every line the hash of the line chained with the
+// hash of the previous one. It's used behind a lockedWriter, which
+// serializes the writes.
+type auditWriter struct {
+	w    io.Writer
+	key  []byte
+	prev []byte
+	buf  bytes.Buffer
+}
+
+func newAuditWriter(w io.Writer, key []byte) *auditWriter {
+	return &auditWriter{w: w, key: key}
+}
+
+func (aw *auditWriter) Write(p []byte) (int, error) {
+	aw.buf.Reset()
+
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		content := bytes.TrimRight(line, "\n")
+		if len(content) == 0 {
+			aw.buf.Write(line)
+
+			continue
+		}
+
+		aw.prev = auditHash(aw.key, aw.prev, content)
+
+		if content[0] == '{' && content[len(content)-1] == '}' && len(content) > 2 {
+			// JSON documents get one more field
+			aw.buf.Write(content[:len(content)-1])
+			aw.buf.WriteString(`,"` + auditHashField + `":"`)
+			aw.buf.Write(aw.prev)
+			aw.buf.WriteString(`"}`)
+		} else {
+			aw.buf.Write(content)
+			aw.buf.WriteString(" " + auditHashField + "=")
+			aw.buf.Write(aw.prev)
+		}
+
+		aw.buf.Write(line[len(content):])
+	}
+
+	if _, err := aw.w.Write(aw.buf.Bytes()); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush flushes the underlying writer when it buffers the output
+func (aw *auditWriter) Flush() error {
+	if f, ok := aw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// auditHash returns the hex HMAC-SHA256 with key, or SHA-256 without, of
+// the previous hash followed by line
+func auditHash(key, prev, line []byte) []byte {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+
+	h.Write(prev)
+	h.Write(line)
+
+	return []byte(hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditSuite struct {
+	suite.Suite
+}
+
+func (s *AuditSuite) TestChain() {
+	var buf bytes.Buffer
+	aw := newAuditWriter(&buf, []byte("key"))
+
+	n, err := aw.Write([]byte("first\n"))
+	s.Nil(err)
+	s.Equal(6, n)
+	aw.Write([]byte(`{"a":1}` + "\n"))
+
+	first := auditHash([]byte("key"), nil, []byte("first"))
+	second := auditHash([]byte("key"), first, []byte(`{"a":1}`))
+
+	s.Equal("first audit_hash="+string(first)+"\n"+
+		`{"a":1,"audit_hash":"`+string(second)+`"}`+"\n", buf.String())
+}
+
+func (s *AuditSuite) TestMultiline() {
+	var buf bytes.Buffer
+	aw := newAuditWriter(&buf, nil)
+
+	aw.Write([]byte("entry\n\npanic: boom\n"))
+
+	lines := strings.Split(buf.String(), "\n")
+	s.Len(lines, 4)
+	s.True(strings.HasPrefix(lines[0], "entry audit_hash="))
+	s.Equal("", lines[1])
+	s.True(strings.HasPrefix(lines[2], "panic: boom audit_hash="))
+}
+
+func (s *AuditSuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&buf), WithFormat(JsonLoggerType), WithAuditChain(nil))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Len(entry[auditHashField], 64)
+	s.Equal("404", entry["response.status"])
+}
+
+func (s *AuditSuite) TestRouteOverride() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&buf), WithFormat(TinyLoggerType), WithAuditChain(nil),
+		WithRouteOverride("/payments", WithFormat(ShortLoggerType)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/payments", nil))
+
+	// the lines of the override are chained once, with the others
+	var prev []byte
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		content, hash, ok := strings.Cut(line, " audit_hash=")
+		s.True(ok)
+		s.Len(hash, 64)
+
+		prev = auditHash(nil, prev, []byte(content))
+		s.Equal(string(prev), hash)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	suite.Run(t, new(AuditSuite))
+}
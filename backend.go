@@ -0,0 +1,25 @@
+package logger
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Backend writes the entries of JsonLoggerType, see WithBackend. The zap
+// and zerolog subpackages provide adapters for those libraries.
+type Backend interface {
+	// Log writes an entry made of msg and fields at level
+	Log(level Level, msg string, fields map[string]interface{})
+}
+
+// LogrusBackend returns a Backend writing to the logrus logger l
+func LogrusBackend(l *log.Logger) Backend {
+	return logrusBackend{l}
+}
+
+type logrusBackend struct {
+	l *log.Logger
+}
+
+func (b logrusBackend) Log(level Level, msg string, fields map[string]interface{}) {
+	b.l.WithFields(fields).Log(level.logrus(), msg)
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BackendSuite struct {
+	suite.Suite
+}
+
+func (s *BackendSuite) TestWithBackend() {
+	b := &testBackend{}
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithBackend(b), WithFields(map[string]interface{}{"service": "api"}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(LevelWarn, b.level)
+	s.Equal("request processed", b.msg)
+	s.Equal("404", b.fields["response.status"])
+	s.Equal("api", b.fields["service"])
+}
+
+type testBackend struct {
+	level  Level
+	msg    string
+	fields map[string]interface{}
+}
+
+func (tb *testBackend) Log(level Level, msg string, fields map[string]interface{}) {
+	tb.level, tb.msg, tb.fields = level, msg, fields
+}
+
+func TestBackend(t *testing.T) {
+	suite.Run(t, new(BackendSuite))
+}
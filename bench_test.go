@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// discardResponseWriter is a http.ResponseWriter which never allocates
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (rw discardResponseWriter) Header() http.Header      { return rw.header }
+func (discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
+
+func benchmarkFormat(b *testing.B, t Type) {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		res.Write([]byte("ok"))
+	}), WithFormat(t), WithWriter(io.Discard), WithLogrusLogger(newTestLogrus(io.Discard)))
+
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	req.Header.Set("User-Agent", "bench")
+	res := discardResponseWriter{header: http.Header{}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(res, req)
+	}
+}
+
+func TestTextFormatAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items with the race detector")
+	}
+
+	h := New(noopHandler{}, WithWriter(io.Discard))
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	res := discardResponseWriter{header: http.Header{}}
+
+	// the request context holding the AddField store and the Entry
+	if allocs := testing.AllocsPerRun(100, func() { h.ServeHTTP(res, req) }); allocs > 3 {
+		t.Errorf("%v allocations per request, want at most 3", allocs)
+	}
+}
+
+func BenchmarkCombined(b *testing.B) {
+	benchmarkFormat(b, CombineLoggerType)
+}
+
+func BenchmarkCommon(b *testing.B) {
+	benchmarkFormat(b, CommonLoggerType)
+}
+
+func BenchmarkDev(b *testing.B) {
+	benchmarkFormat(b, DevLoggerType)
+}
+
+func BenchmarkTiny(b *testing.B) {
+	benchmarkFormat(b, TinyLoggerType)
+}
+
+func BenchmarkJSON(b *testing.B) {
+	benchmarkFormat(b, JsonLoggerType)
+}
+
+func BenchmarkParallel(b *testing.B) {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("ok"))
+	}), WithWriter(io.Discard))
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+		res := discardResponseWriter{header: http.Header{}}
+
+		for pb.Next() {
+			h.ServeHTTP(res, req)
+		}
+	})
+}
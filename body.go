@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// DefaultBodyTypes are the content types of the request bodies
+// DefaultBodyFilter captures
+var DefaultBodyTypes = []string{"application/json", "application/*+json", "text/*"}
+
+// DefaultBodyFilter is the filter of WithBodyCapture unless WithBodyFilter
+// sets another: it captures the POST, PUT and PATCH bodies of the
+// DefaultBodyTypes so binary uploads are never buffered
+func DefaultBodyFilter(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return matchContentType(req.Header.Get("Content-Type"), DefaultBodyTypes)
+	}
+
+	return false
+}
+
+// bodyCapture tees up to max bytes of what the downstream handler reads
+// from the request body, leaving the stream itself untouched
+type bodyCapture struct {
+	io.ReadCloser
+
+	max int
+	buf bytes.Buffer
+}
+
+func newBodyCapture(body io.ReadCloser, max int) *bodyCapture {
+	return &bodyCapture{ReadCloser: body, max: max}
+}
+
+func (bc *bodyCapture) Read(p []byte) (int, error) {
+	n, err := bc.ReadCloser.Read(p)
+
+	if rest := bc.max - bc.buf.Len(); rest > 0 && n > 0 {
+		if n < rest {
+			rest = n
+		}
+
+		bc.buf.Write(p[:rest])
+	}
+
+	return n, err
+}
+
+func (bc *bodyCapture) String() string {
+	return bc.buf.String()
+}
+
+// requestBody counts the bytes the downstream handler reads from the
+// request body
+type requestBody struct {
+	io.ReadCloser
+
+	n int64
+}
+
+func (rb *requestBody) Read(p []byte) (int, error) {
+	n, err := rb.ReadCloser.Read(p)
+	rb.n += int64(n)
+
+	return n, err
+}
+
+// requestSize returns the number of body bytes read from req, its
+// Content-Length when the handler read none
+func requestSize(read int64, req *http.Request) int64 {
+	if read > 0 {
+		return read
+	}
+
+	if req.ContentLength > 0 {
+		return req.ContentLength
+	}
+
+	return 0
+}
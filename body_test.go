@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BodySuite struct {
+	suite.Suite
+}
+
+func (s *BodySuite) TestCapture() {
+	bc := newBodyCapture(ioutil.NopCloser(strings.NewReader("hello world")), 5)
+
+	b, err := ioutil.ReadAll(bc)
+
+	s.Nil(err)
+	s.Equal("hello world", string(b))
+	s.Equal("hello", bc.String())
+}
+
+func (s *BodySuite) TestCaptureUnread() {
+	bc := newBodyCapture(ioutil.NopCloser(strings.NewReader("hello world")), 5)
+
+	s.Equal("", bc.String())
+}
+
+func (s *BodySuite) TestHandlerWithBody() {
+	var read string
+	h := HandlerWithBody(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		read = string(b)
+	}), &testWriter{}, TinyLoggerType, 4)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	body := req.Body
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal("payload", read)
+	s.Equal(body, req.Body)
+}
+
+func (s *BodySuite) TestBodyUntouchedByDefault() {
+	w := &testWriter{}
+	h := Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}), w, JsonLoggerType)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("payload"))
+	body := req.Body
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal(body, req.Body)
+	s.NotContains(string(w.Bytes), `"body"`)
+
+	b, _ := ioutil.ReadAll(req.Body)
+	s.Equal("payload", string(b))
+}
+
+func (s *BodySuite) TestRequestSize() {
+	w := &testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+	}), WithWriter(w), WithCustomFormat(":method :url :req[content-length]"))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("payload"))
+	req.ContentLength = -1
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal("POST /upload 7\n", string(w.Bytes))
+}
+
+func (s *BodySuite) TestRequestSizeUnread() {
+	w := &testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}),
+		WithWriter(w), WithCustomFormat(":method :url :req[content-length]"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("payload")))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("POST /upload 7\nGET / 0\n", string(w.Bytes))
+}
+
+func (s *BodySuite) TestRequestSizeJSON() {
+	w := &testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+	}), WithWriter(w), WithFormat(JsonLoggerType))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/", strings.NewReader("hello world")))
+
+	s.Contains(string(w.Bytes), `"request.size":"11"`)
+}
+
+func (s *BodySuite) TestDefaultFilter() {
+	for _, tc := range []struct {
+		method, contentType string
+		captured            bool
+	}{
+		{http.MethodPost, "application/json", true},
+		{http.MethodPut, "text/plain; charset=utf-8", true},
+		{http.MethodPatch, "application/merge-patch+json", true},
+		{http.MethodPost, "application/octet-stream", false},
+		{http.MethodPost, "multipart/form-data; boundary=x", false},
+		{http.MethodPost, "", false},
+		{http.MethodDelete, "application/json", false},
+	} {
+		req := httptest.NewRequest(tc.method, "/", strings.NewReader("payload"))
+		req.Header.Set("Content-Type", tc.contentType)
+
+		s.Equal(tc.captured, DefaultBodyFilter(req), "%s %s", tc.method, tc.contentType)
+	}
+}
+
+func (s *BodySuite) TestFilter() {
+	var captured []byte
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+	}), WithWriter(&testWriter{}), WithBodyCapture(64), WithHook(func(e *Entry) {
+		captured = e.Body
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("\x00\x01"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	s.Nil(captured)
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	s.Equal(`{"a":1}`, string(captured))
+
+	h = New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		ioutil.ReadAll(req.Body)
+	}), WithWriter(&testWriter{}), WithBodyCapture(64), WithHook(func(e *Entry) {
+		captured = e.Body
+	}), WithBodyFilter(func(*http.Request) bool { return true }))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", strings.NewReader("raw")))
+	s.Equal("raw", string(captured))
+}
+
+func TestBody(t *testing.T) {
+	suite.Run(t, new(BodySuite))
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"sync"
+	"time"
+)
+
+// bufferedWriter buffers writes in memory, flushed on a timer, when the
+// buffer is full and on Close
+type bufferedWriter struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+func newBufferedWriter(w io.Writer, size int, interval time.Duration) *bufferedWriter {
+	bw := &bufferedWriter{w: bufio.NewWriterSize(w, size), done: make(chan struct{})}
+
+	if interval > 0 {
+		bw.wg.Add(1)
+		go bw.run(interval)
+	}
+
+	return bw
+}
+
+func (bw *bufferedWriter) run(interval time.Duration) {
+	defer bw.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			bw.Flush()
+		case <-bw.done:
+			return
+		}
+	}
+}
+
+func (bw *bufferedWriter) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	return bw.w.Write(p)
+}
+
+// Flush writes the buffered entries to the underlying writer
+func (bw *bufferedWriter) Flush() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	return bw.w.Flush()
+}
+
+// Close stops the timer and flushes the buffer, the underlying writer is
+// left open
+func (bw *bufferedWriter) Close() error {
+	bw.once.Do(func() {
+		close(bw.done)
+		bw.wg.Wait()
+	})
+
+	return bw.Flush()
+}
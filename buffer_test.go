@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type BufferSuite struct {
+	suite.Suite
+}
+
+func (s *BufferSuite) TestBuffered() {
+	sw := &syncWriter{}
+	bw := newBufferedWriter(sw, 1024, 0)
+
+	bw.Write([]byte("a\n"))
+	bw.Write([]byte("b\n"))
+	s.Equal("", sw.String())
+
+	s.Nil(bw.Flush())
+	s.Equal("a\nb\n", sw.String())
+	s.Nil(bw.Close())
+}
+
+func (s *BufferSuite) TestFull() {
+	sw := &syncWriter{}
+	bw := newBufferedWriter(sw, 4, 0)
+	defer bw.Close()
+
+	bw.Write([]byte("abc\n"))
+	bw.Write([]byte("d"))
+
+	s.Equal("abc\n", sw.String())
+}
+
+func (s *BufferSuite) TestInterval() {
+	sw := &syncWriter{}
+	bw := newBufferedWriter(sw, 1024, time.Millisecond)
+	defer bw.Close()
+
+	bw.Write([]byte("a\n"))
+
+	s.Eventually(func() bool { return sw.String() == "a\n" }, time.Second, time.Millisecond)
+}
+
+func (s *BufferSuite) TestHandler() {
+	sw := &syncWriter{}
+	h := NewLogger(noopHandler{}, WithWriter(sw), WithCustomFormat(":method :url"), WithBuffering(4096, time.Hour))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	s.Equal("", sw.String())
+
+	s.Nil(h.Flush())
+	s.Equal("GET /a\n", sw.String())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(h.Close(context.Background()))
+	s.Equal("GET /a\nGET /b\n", sw.String())
+}
+
+func (s *BufferSuite) TestAsync() {
+	sw := &syncWriter{}
+	h := NewLogger(noopHandler{}, WithWriter(sw), WithCustomFormat(":url"), WithBuffering(4096, time.Hour),
+		WithAsync(10))
+
+	for i := 0; i < 3; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal(3, strings.Count(sw.String(), "/\n"))
+}
+
+func TestBuffer(t *testing.T) {
+	suite.Run(t, new(BufferSuite))
+}
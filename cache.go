@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Cache states of Entry.CacheState
+const (
+	CacheHit     = "hit"
+	CacheMiss    = "miss"
+	CacheStale   = "stale"
+	CacheExpired = "expired"
+	CacheBypass  = "bypass"
+)
+
+// cacheState returns the state of the cache in front of the handler, as
+// told by the X-Cache or X-Cache-Status header of the response h, or by
+// its Age header when there's neither. It's empty when they don't tell.
+func cacheState(h http.Header) string {
+	for _, name := range []string{"X-Cache", "X-Cache-Status"} {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		// layered caches append their state, the last one is the closest
+		// to the client
+		states := strings.Split(values[len(values)-1], ",")
+
+		return cacheStateText(states[len(states)-1])
+	}
+
+	// skipped without the header, Atoi allocates its error
+	age := strings.TrimSpace(h.Get("Age"))
+	if age == "" {
+		return ""
+	}
+
+	if n, err := strconv.Atoi(age); err == nil && n > 0 {
+		return CacheHit
+	}
+
+	return ""
+}
+
+// cacheStateText maps the state of a X-Cache header, e.g. "TCP_HIT" or
+// "Miss from cloudfront", to a cache state
+func cacheStateText(s string) string {
+	s = strings.ToLower(s)
+
+	switch {
+	case strings.Contains(s, "stale"), strings.Contains(s, "updating"):
+		return CacheStale
+	case strings.Contains(s, "expired"):
+		return CacheExpired
+	case strings.Contains(s, "pass"):
+		return CacheBypass
+	case strings.Contains(s, "miss"):
+		return CacheMiss
+	case strings.Contains(s, "hit"):
+		return CacheHit
+	}
+
+	return ""
+}
+
+// emptyResponse reports whether the response of e has no body where one
+// was expected, unlike 304 Not Modified, 204 No Content and 1xx responses
+// and the responses to HEAD requests. A handler writing nothing sends an
+// empty 200.
+func emptyResponse(e *Entry) bool {
+	if e.Size > 0 || e.Hijacked || e.Method == http.MethodHead {
+		return false
+	}
+
+	switch {
+	case e.Status >= 100 && e.Status < 200, e.Status == http.StatusNoContent, e.Status == http.StatusNotModified:
+		return false
+	}
+
+	return true
+}
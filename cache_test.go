@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func (s *CacheSuite) TestCacheState() {
+	for header, state := range map[string]string{
+		"HIT":                        CacheHit,
+		"Miss from cloudfront":       CacheMiss,
+		"RefreshHit from cloudfront": CacheHit,
+		"TCP_MISS":                   CacheMiss,
+		"STALE":                      CacheStale,
+		"UPDATING":                   CacheStale,
+		"EXPIRED":                    CacheExpired,
+		"BYPASS":                     CacheBypass,
+		"HIT, MISS":                  CacheMiss,
+		"Error from cloudfront":      "",
+	} {
+		s.Equal(state, cacheState(http.Header{"X-Cache": {header}}), header)
+	}
+
+	s.Equal(CacheHit, cacheState(http.Header{"X-Cache-Status": {"HIT"}}))
+	s.Equal(CacheHit, cacheState(http.Header{"Age": {"120"}}))
+	s.Equal("", cacheState(http.Header{"Age": {"0"}}))
+	s.Equal(CacheMiss, cacheState(http.Header{"X-Cache": {"MISS"}, "Age": {"120"}}))
+	s.Equal("", cacheState(http.Header{}))
+}
+
+func (s *CacheSuite) TestEmpty() {
+	s.True(emptyResponse(&Entry{Method: http.MethodGet, Status: http.StatusOK}))
+	s.True(emptyResponse(&Entry{Method: http.MethodGet}))
+	s.False(emptyResponse(&Entry{Method: http.MethodGet, Status: http.StatusOK, Size: 1}))
+	s.False(emptyResponse(&Entry{Method: http.MethodGet, Status: http.StatusNotModified}))
+	s.False(emptyResponse(&Entry{Method: http.MethodDelete, Status: http.StatusNoContent}))
+	s.False(emptyResponse(&Entry{Method: http.MethodHead, Status: http.StatusOK}))
+	s.False(emptyResponse(&Entry{Method: http.MethodGet, Hijacked: true}))
+}
+
+func (s *CacheSuite) TestFields() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.Header.Get("If-None-Match") {
+		case `"v1"`:
+			res.WriteHeader(http.StatusNotModified)
+		case "":
+			res.Header().Set("Content-Range", "bytes 0-3/10")
+			res.Header().Set("X-Cache", "MISS")
+			res.WriteHeader(http.StatusPartialContent)
+			res.Write([]byte("abcd"))
+		default:
+			res.WriteHeader(http.StatusOK)
+		}
+	}), WithWriter(&tw), WithFormat(NDJSONLoggerType))
+
+	partial := httptest.NewRequest(http.MethodGet, "/file", nil)
+	partial.Header.Set("Range", "bytes=0-3")
+	notModified := httptest.NewRequest(http.MethodGet, "/file", nil)
+	notModified.Header.Set("If-None-Match", `"v1"`)
+	empty := httptest.NewRequest(http.MethodGet, "/file", nil)
+	empty.Header.Set("If-None-Match", `"v0"`)
+
+	var records []map[string]interface{}
+	for _, req := range []*http.Request{partial, notModified, empty} {
+		tw.Bytes = nil
+		h.ServeHTTP(httptest.NewRecorder(), req)
+
+		record := map[string]interface{}{}
+		s.Require().Nil(json.Unmarshal(tw.Bytes, &record))
+		records = append(records, record)
+	}
+
+	s.Equal("bytes=0-3", records[0]["request.range"])
+	s.Equal("bytes 0-3/10", records[0]["response.content_range"])
+	s.Equal("miss", records[0]["response.cache_state"])
+	s.NotContains(records[0], "response.empty")
+
+	s.Equal(float64(304), records[1]["response.status"])
+	s.NotContains(records[1], "response.empty")
+	s.NotContains(records[1], "request.range")
+
+	s.Equal(float64(200), records[2]["response.status"])
+	s.Equal(true, records[2]["response.empty"])
+}
+
+func (s *CacheSuite) TestToken() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Age", "30")
+	}), WithWriter(&tw), WithCustomFormat(":cache-state :req[range]"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("hit -\n", string(tw.Bytes))
+}
+
+func TestCache(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
@@ -0,0 +1,24 @@
+// Package chiroute takes the route patterns of the requests from chi, e.g.
+//
+//	r := chi.NewRouter()
+//	r.Use(logger.Middleware(logger.WithRouteFunc(chiroute.Route)))
+//
+// chi only exposes the route to its own middlewares, mount the logger
+// with Use.
+package chiroute
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Route returns the pattern of the chi route req was matched against, e.g.
+// "/users/{id}", it's a logger.RouteFunc
+func Route(req *http.Request) string {
+	if rctx := chi.RouteContext(req.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+
+	return ""
+}
@@ -0,0 +1,46 @@
+package chiroute
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type ChiSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+}
+
+func (s *ChiSuite) SetupTest() {
+	s.buf.Reset()
+}
+
+func (s *ChiSuite) TestRoute() {
+	r := chi.NewRouter()
+	r.Use(logger.Middleware(logger.WithWriter(&s.buf), logger.WithCustomFormat(":method :route"),
+		logger.WithRouteFunc(Route)))
+	r.Get("/users/{id}", func(res http.ResponseWriter, req *http.Request) {})
+	r.Route("/admin", func(r chi.Router) {
+		r.Delete("/users/{id}", http.NotFound)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/admin/users/42", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	s.Equal("GET /users/{id}\nDELETE /admin/users/{id}\nGET -\n", s.buf.String())
+}
+
+func (s *ChiSuite) TestUnrouted() {
+	s.Equal("", Route(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestChi(t *testing.T) {
+	suite.Run(t, new(ChiSuite))
+}
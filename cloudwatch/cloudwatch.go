@@ -0,0 +1,278 @@
+// Package cloudwatch ships the log output of the logger package to AWS
+// CloudWatch Logs, see Writer.
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// PutLogEvents limits
+const (
+	maxBatchEvents = 10000
+	maxBatchSize   = 1048576
+	// eventOverhead is added to the size of every message
+	eventOverhead = 26
+	maxEventSize  = 262144 - eventOverhead
+)
+
+// Client is the part of *cloudwatchlogs.Client used by Writer
+type Client interface {
+	CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// Option configures the writer returned by Writer
+type Option func(*writer)
+
+// WithFlushInterval sets how often the pending events are sent, default to
+// 5 seconds, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *writer) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches sent in the background, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(w *writer) {
+		w.errors = f
+	}
+}
+
+// writer sends every Write as one log event, in batches
+type writer struct {
+	client   Client
+	group    string
+	stream   string
+	interval time.Duration
+	errors   func(error)
+
+	mu      sync.Mutex
+	pending []types.InputLogEvent
+	size    int
+
+	// sending serializes the batches, which share the sequence token
+	sending sync.Mutex
+	token   *string
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Writer returns an io.WriteCloser sending each write, i.e. each entry of
+// the log output, as an event of the log stream of the log group, both
+// created when missing. Events are sent in the background every flush
+// interval, or as soon as a batch reaches the 10000 events or 1MB limits
+// of PutLogEvents. Close sends the pending events, call it on shutdown,
+// e.g.:
+//
+//	cfg, _ := config.LoadDefaultConfig(ctx)
+//	w, err := cloudwatch.Writer(cloudwatchlogs.NewFromConfig(cfg), "/ecs/api", "access")
+//	defer w.Close()
+func Writer(client Client, group, stream string, opts ...Option) (io.WriteCloser, error) {
+	w := &writer{
+		client:   client,
+		group:    group,
+		stream:   stream,
+		interval: 5 * time.Second,
+		errors:   func(error) {},
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if err := w.create(context.Background()); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+// create creates the log group and stream unless they already exist
+func (w *writer) create(ctx context.Context) error {
+	var exists *types.ResourceAlreadyExistsException
+
+	_, err := w.client.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{LogGroupName: aws.String(w.group)})
+	if err != nil && !errors.As(err, &exists) {
+		return err
+	}
+
+	_, err = w.client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(w.group),
+		LogStreamName: aws.String(w.stream),
+	})
+	if err != nil && !errors.As(err, &exists) {
+		return err
+	}
+
+	return nil
+}
+
+func (w *writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			w.errors(err)
+		}
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	msg := bytes.TrimRight(p, "\n")
+	if len(msg) > maxEventSize {
+		msg = msg[:maxEventSize]
+	}
+
+	w.mu.Lock()
+	// timestamped under the lock so the events are in chronological order
+	w.pending = append(w.pending, types.InputLogEvent{
+		Message:   aws.String(string(msg)),
+		Timestamp: aws.Int64(time.Now().UnixMilli()),
+	})
+	w.size += len(msg) + eventOverhead
+	full := len(w.pending) >= maxBatchEvents || w.size >= maxBatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the pending events
+func (w *writer) Flush() error {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	events := w.pending
+	w.pending, w.size = nil, 0
+	w.mu.Unlock()
+
+	for _, batch := range batches(events) {
+		if err := w.put(context.Background(), batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// put sends batch with the current sequence token, taking the expected one
+// when it's rejected and recreating the log stream when it was deleted
+func (w *writer) put(ctx context.Context, batch []types.InputLogEvent) error {
+	var (
+		invalid  *types.InvalidSequenceTokenException
+		accepted *types.DataAlreadyAcceptedException
+		notFound *types.ResourceNotFoundException
+	)
+
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var out *cloudwatchlogs.PutLogEventsOutput
+		out, err = w.client.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(w.group),
+			LogStreamName: aws.String(w.stream),
+			LogEvents:     batch,
+			SequenceToken: w.token,
+		})
+
+		switch {
+		case err == nil:
+			w.token = out.NextSequenceToken
+
+			return nil
+		case errors.As(err, &accepted):
+			w.token = accepted.ExpectedSequenceToken
+
+			return nil
+		case errors.As(err, &invalid):
+			w.token = invalid.ExpectedSequenceToken
+		case errors.As(err, &notFound):
+			w.token = nil
+			if err := w.create(ctx); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+
+	return err
+}
+
+// batches splits events into batches within the limits of PutLogEvents
+func batches(events []types.InputLogEvent) [][]types.InputLogEvent {
+	var all [][]types.InputLogEvent
+
+	start, size := 0, 0
+	for i, e := range events {
+		n := len(*e.Message) + eventOverhead
+		if i-start == maxBatchEvents || size+n > maxBatchSize {
+			all = append(all, events[start:i])
+			start, size = i, 0
+		}
+
+		size += n
+	}
+
+	if start < len(events) {
+		all = append(all, events[start:])
+	}
+
+	return all
+}
+
+// Close stops the background sends and sends the pending events
+func (w *writer) Close() error {
+	var err error
+
+	w.once.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+
+		err = w.Flush()
+	})
+
+	return err
+}
@@ -0,0 +1,178 @@
+package cloudwatch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type testClient struct {
+	mu      sync.Mutex
+	groups  int
+	streams int
+	exists  bool
+	puts    []*cloudwatchlogs.PutLogEventsInput
+	errs    []error
+}
+
+func (tc *testClient) CreateLogGroup(_ context.Context, _ *cloudwatchlogs.CreateLogGroupInput,
+	_ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	tc.groups++
+	if tc.exists {
+		return nil, &types.ResourceAlreadyExistsException{}
+	}
+
+	return &cloudwatchlogs.CreateLogGroupOutput{}, nil
+}
+
+func (tc *testClient) CreateLogStream(_ context.Context, _ *cloudwatchlogs.CreateLogStreamInput,
+	_ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	tc.streams++
+	if tc.exists {
+		return nil, &types.ResourceAlreadyExistsException{}
+	}
+
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (tc *testClient) PutLogEvents(_ context.Context, params *cloudwatchlogs.PutLogEventsInput,
+	_ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.puts = append(tc.puts, params)
+	if len(tc.errs) > 0 {
+		err := tc.errs[0]
+		tc.errs = tc.errs[1:]
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: aws.String("next")}, nil
+}
+
+type CloudWatchSuite struct {
+	suite.Suite
+}
+
+func (s *CloudWatchSuite) TestWriter() {
+	tc := &testClient{}
+	w, err := Writer(tc, "/ecs/api", "access")
+	s.Nil(err)
+	s.Equal(1, tc.groups)
+	s.Equal(1, tc.streams)
+
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithFormat(logger.TinyLoggerType))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(w.Close())
+
+	s.Len(tc.puts, 1)
+	s.Equal("/ecs/api", *tc.puts[0].LogGroupName)
+	s.Equal("access", *tc.puts[0].LogStreamName)
+	s.Nil(tc.puts[0].SequenceToken)
+	s.Len(tc.puts[0].LogEvents, 2)
+	s.True(strings.HasPrefix(*tc.puts[0].LogEvents[0].Message, "GET /a 404"))
+	s.LessOrEqual(*tc.puts[0].LogEvents[0].Timestamp, *tc.puts[0].LogEvents[1].Timestamp)
+}
+
+func (s *CloudWatchSuite) TestInvalidFlushInterval() {
+	tc := &testClient{}
+	w, err := Writer(tc, "/ecs/api", "access", WithFlushInterval(0))
+	s.Require().Nil(err)
+
+	s.Equal(5*time.Second, w.(*writer).interval)
+
+	w.Write([]byte("GET /\n"))
+	s.Nil(w.Close())
+	s.Len(tc.puts, 1)
+}
+
+func (s *CloudWatchSuite) TestExisting() {
+	tc := &testClient{exists: true}
+	_, err := Writer(tc, "/ecs/api", "access")
+	s.Nil(err)
+}
+
+func (s *CloudWatchSuite) TestSequenceToken() {
+	tc := &testClient{}
+	w, err := Writer(tc, "/ecs/api", "access")
+	s.Nil(err)
+	defer w.Close()
+
+	fw := w.(*writer)
+	fw.Write([]byte("a\n"))
+	s.Nil(fw.Flush())
+
+	tc.errs = []error{&types.InvalidSequenceTokenException{ExpectedSequenceToken: aws.String("expected")}}
+	fw.Write([]byte("b\n"))
+	s.Nil(fw.Flush())
+
+	s.Len(tc.puts, 3)
+	s.Equal("next", *tc.puts[1].SequenceToken)
+	s.Equal("expected", *tc.puts[2].SequenceToken)
+	s.Equal("next", *fw.token)
+}
+
+func (s *CloudWatchSuite) TestStreamDeleted() {
+	tc := &testClient{}
+	w, err := Writer(tc, "/ecs/api", "access")
+	s.Nil(err)
+	defer w.Close()
+
+	tc.errs = []error{&types.ResourceNotFoundException{}}
+	w.Write([]byte("a\n"))
+	s.Nil(w.(*writer).Flush())
+
+	s.Equal(2, tc.streams)
+	s.Len(tc.puts, 2)
+}
+
+func (s *CloudWatchSuite) TestError() {
+	tc := &testClient{}
+	w, err := Writer(tc, "/ecs/api", "access")
+	s.Nil(err)
+	defer w.Close()
+
+	tc.errs = []error{errors.New("throttled")}
+	w.Write([]byte("a\n"))
+	s.EqualError(w.(*writer).Flush(), "throttled")
+}
+
+func (s *CloudWatchSuite) TestBatches() {
+	events := make([]types.InputLogEvent, maxBatchEvents+1)
+	for i := range events {
+		events[i].Message = aws.String("x")
+	}
+
+	all := batches(events)
+	s.Len(all, 2)
+	s.Len(all[0], maxBatchEvents)
+	s.Len(all[1], 1)
+
+	large := strings.Repeat("x", maxEventSize)
+	events = []types.InputLogEvent{{Message: &large}, {Message: &large}, {Message: &large}, {Message: &large}, {Message: &large}}
+
+	all = batches(events)
+	s.Len(all, 2)
+	s.Len(all[0], 4)
+
+	s.Nil(batches(nil))
+}
+
+func TestCloudWatch(t *testing.T) {
+	suite.Run(t, new(CloudWatchSuite))
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// ColorMode tells whether DevLoggerType output is colored
+type ColorMode int
+
+const (
+	// ColorAuto colors the output when the writer is a terminal and the
+	// NO_COLOR environment variable is not set
+	ColorAuto ColorMode = iota
+	// ColorAlways always colors the output
+	ColorAlways
+	// ColorNever never colors the output
+	ColorNever
+)
+
+// DefaultSlowThreshold is the response time above which DevLoggerType
+// highlights a request
+const DefaultSlowThreshold = 500 * time.Millisecond
+
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+func (mode ColorMode) enabled(w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+
+	return isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func statusColor(status int) string {
+	switch {
+	case status >= 500:
+		return colorRed
+	case status >= 400:
+		return colorYellow
+	case status >= 300:
+		return colorCyan
+	case status >= 200:
+		return colorGreen
+	}
+
+	return ""
+}
+
+// colorDevFormat is DevLoggerType with the status colored by class and the
+// response time highlighted above slow
+func colorDevFormat(slow time.Duration) []token {
+	status := newToken("status", "")
+	responseTime := compileFormat(":response-time :duration-unit")
+
+	tokens := compileFormat(":method :url ")
+	tokens = append(tokens, func(b []byte, e *Entry) []byte {
+		color := statusColor(e.Status)
+		if color == "" {
+			return status(b, e)
+		}
+
+		return append(status(append(b, color...), e), colorReset...)
+	}, literalToken(" "), func(b []byte, e *Entry) []byte {
+		if slow > 0 && e.Duration >= slow {
+			return append(appendTokens(append(b, colorMagenta...), responseTime, e), colorReset...)
+		}
+
+		return appendTokens(b, responseTime, e)
+	})
+
+	return append(tokens, compileFormat(" - :res[content-length]")...)
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ColorSuite struct {
+	suite.Suite
+}
+
+func (s *ColorSuite) TestStatusColor() {
+	s.Equal(colorGreen, statusColor(http.StatusOK))
+	s.Equal(colorCyan, statusColor(http.StatusFound))
+	s.Equal(colorYellow, statusColor(http.StatusNotFound))
+	s.Equal(colorRed, statusColor(http.StatusBadGateway))
+	s.Equal("", statusColor(0))
+}
+
+func (s *ColorSuite) TestAlways() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{}), WithFormat(DevLoggerType), WithColor(ColorAlways))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / "+colorYellow+"404"+colorReset+" 0.000 ms - 19\n", string(tw.Bytes))
+}
+
+func (s *ColorSuite) TestSlow() {
+	tokens := colorDevFormat(time.Second)
+	e := &Entry{Method: http.MethodGet, URL: "/", Status: http.StatusOK, Duration: 2 * time.Second}
+
+	s.Equal("GET / "+colorGreen+"200"+colorReset+" "+colorMagenta+"2000.000 ms"+colorReset+" - 0",
+		render(tokens, e))
+}
+
+func (s *ColorSuite) TestNever() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{}), WithFormat(DevLoggerType), WithColor(ColorNever))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 404 0.000 ms - 19\n", string(tw.Bytes))
+}
+
+func (s *ColorSuite) TestAuto() {
+	s.False(ColorAuto.enabled(&testWriter{}))
+
+	f, err := os.CreateTemp("", "logger")
+	s.Nil(err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	s.False(ColorAuto.enabled(f))
+}
+
+func TestColor(t *testing.T) {
+	suite.Run(t, new(ColorSuite))
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"strings"
+)
+
+// gzipMagic starts every gzip stream, gzipMinSize being the size of an
+// empty one
+const (
+	gzipMagic   = "\x1f\x8b"
+	gzipMinSize = 18
+)
+
+// AddBodySize reports n more bytes of the response body of the request ctx
+// belongs to, before they're compressed, so the entry of a compressed
+// response logs its uncompressed size as response.body_size. It's meant
+// for compressing middlewares wrapped by the logger, e.g. called for every
+// write to their gzip.Writer. It does nothing for contexts not coming from
+// the middleware.
+func AddBodySize(ctx context.Context, n int) {
+	rf, ok := ctx.Value(fieldsKey).(*requestFields)
+	if !ok {
+		return
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	rf.bodySize += int64(n)
+	rf.bodySizeSet = true
+}
+
+// reportedBodySize returns the body size reported with AddBodySize, if any
+func (rf *requestFields) reportedBodySize() (int64, bool) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.bodySize, rf.bodySizeSet
+}
+
+// trackBody keeps the first and last bytes of the response body, written
+// once size bytes were, for bodySize
+func (rl *responseLogger) trackBody(size int, b []byte) {
+	if size < len(rl.head) {
+		copy(rl.head[size:], b)
+	}
+
+	if len(b) >= len(rl.tail) {
+		copy(rl.tail[:], b[len(b)-len(rl.tail):])
+
+		return
+	}
+
+	copy(rl.tail[:], rl.tail[len(b):])
+	copy(rl.tail[len(rl.tail)-len(b):], b)
+}
+
+// bodySize returns the size of the response body of rl before its
+// Content-Encoding encoding, -1 when it can't be derived
+func (rl *responseLogger) bodySize(encoding string) int64 {
+	if rl.custom != nil {
+		if n, ok := rl.custom.reportedBodySize(); ok {
+			return n
+		}
+	}
+
+	if encoding == "" || strings.EqualFold(encoding, "identity") {
+		return int64(rl.size)
+	}
+
+	if !strings.EqualFold(encoding, "gzip") || rl.untracked {
+		return -1
+	}
+
+	if rl.size < len(gzipMagic) || string(rl.head[:]) != gzipMagic {
+		// compressed by a middleware wrapping the logger, which sees the
+		// body as is
+		return int64(rl.size)
+	}
+
+	if rl.size < gzipMinSize {
+		return -1
+	}
+
+	// the gzip trailer ends with the size of the input, modulo 2^32
+	return int64(binary.LittleEndian.Uint32(rl.tail[:]))
+}
+
+// contentEncoding returns the Content-Encoding of the response header h
+func contentEncoding(h http.Header) string {
+	return strings.TrimSpace(h.Get("Content-Encoding"))
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CompressionSuite struct {
+	suite.Suite
+
+	tw testWriter
+}
+
+func (s *CompressionSuite) SetupTest() {
+	s.tw = testWriter{}
+}
+
+func (s *CompressionSuite) serve(h http.Handler) map[string]interface{} {
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	record := map[string]interface{}{}
+	s.Require().Nil(json.Unmarshal(s.tw.Bytes, &record))
+
+	return record
+}
+
+func (s *CompressionSuite) TestGzipHandler() {
+	body := strings.Repeat("hello world ", 100)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(body))
+	gz.Close()
+
+	record := s.serve(New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Encoding", "gzip")
+
+		// a byte at a time, so the trailer is split across writes
+		for _, c := range compressed.Bytes() {
+			res.Write([]byte{c})
+		}
+	}), WithWriter(&s.tw), WithFormat(NDJSONLoggerType)))
+
+	s.Equal("gzip", record["response.content_encoding"])
+	s.Equal(float64(compressed.Len()), record["response.size"])
+	s.Equal(float64(len(body)), record["response.body_size"])
+}
+
+func (s *CompressionSuite) TestGzipMiddleware() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("hello"))
+	}), WithWriter(&s.tw), WithFormat(NDJSONLoggerType))
+
+	// a compressing middleware wrapping the logger, which sees the body as is
+	record := s.serve(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(res)
+		defer gz.Close()
+
+		h.ServeHTTP(gzipResponseWriter{res, gz}, req)
+	}))
+
+	s.Equal(float64(5), record["response.size"])
+	s.Equal(float64(5), record["response.body_size"])
+}
+
+func (s *CompressionSuite) TestAddBodySize() {
+	record := s.serve(New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Encoding", "br")
+		res.Write([]byte("abc"))
+		AddBodySize(req.Context(), 10)
+	}), WithWriter(&s.tw), WithFormat(NDJSONLoggerType)))
+
+	s.Equal("br", record["response.content_encoding"])
+	s.Equal(float64(3), record["response.size"])
+	s.Equal(float64(10), record["response.body_size"])
+}
+
+func (s *CompressionSuite) TestUnknownBodySize() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Encoding", "br")
+		res.Write([]byte("abc"))
+	}), WithWriter(&s.tw), WithCustomFormat(":res[content-encoding] :body-size"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("br -\n", string(s.tw.Bytes))
+}
+
+func (s *CompressionSuite) TestNotEncoded() {
+	record := s.serve(New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("abc"))
+	}), WithWriter(&s.tw), WithFormat(NDJSONLoggerType)))
+
+	s.NotContains(record, "response.content_encoding")
+	s.NotContains(record, "response.body_size")
+}
+
+// gzipResponseWriter writes the body through a gzip.Writer
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	gz *gzip.Writer
+}
+
+func (w gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+func TestCompression(t *testing.T) {
+	suite.Run(t, new(CompressionSuite))
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"net/http"
+	"time"
+)
+
+// Stats describes a processed request to the conditions of WithCondition
+type Stats struct {
+	Status   int
+	Size     int
+	Duration time.Duration
+	Start    time.Time
+}
+
+// SlowerThan returns a condition for WithCondition holding for requests
+// which took d or longer
+func SlowerThan(d time.Duration) func(*http.Request, Stats) bool {
+	return func(_ *http.Request, stats Stats) bool {
+		return stats.Duration >= d
+	}
+}
+
+// StatusAtLeast returns a condition for WithCondition holding for requests
+// with a status of status or above
+func StatusAtLeast(status int) func(*http.Request, Stats) bool {
+	return func(_ *http.Request, stats Stats) bool {
+		return stats.Status >= status
+	}
+}
+
+// Any returns a condition for WithCondition holding when one of conditions
+// does, e.g. Any(SlowerThan(500*time.Millisecond), StatusAtLeast(400))
+func Any(conditions ...func(*http.Request, Stats) bool) func(*http.Request, Stats) bool {
+	return func(req *http.Request, stats Stats) bool {
+		for _, condition := range conditions {
+			if condition(req, stats) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// meets reports whether req satisfies every condition of WithCondition
+func (rh loggerHanlder) meets(req *http.Request, rl *responseLogger) bool {
+	stats := Stats{Status: rl.status, Size: rl.size, Duration: rl.duration, Start: rl.start}
+
+	for _, condition := range rh.conditions {
+		if !condition(req, stats) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConditionSuite struct {
+	suite.Suite
+}
+
+func (s *ConditionSuite) TestConditions() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	slow := Stats{Status: http.StatusOK, Duration: time.Second}
+	failed := Stats{Status: http.StatusBadGateway, Duration: time.Millisecond}
+	fast := Stats{Status: http.StatusOK, Duration: time.Millisecond}
+
+	s.True(SlowerThan(time.Second)(req, slow))
+	s.False(SlowerThan(time.Second)(req, failed))
+	s.True(StatusAtLeast(http.StatusBadRequest)(req, failed))
+	s.False(StatusAtLeast(http.StatusBadRequest)(req, slow))
+
+	either := Any(SlowerThan(500*time.Millisecond), StatusAtLeast(http.StatusBadRequest))
+	s.True(either(req, slow))
+	s.True(either(req, failed))
+	s.False(either(req, fast))
+	s.False(Any()(req, slow))
+}
+
+func (s *ConditionSuite) TestHandler() {
+	tw := testWriter{}
+	var got Stats
+	start := time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{start}), WithFormat(TinyLoggerType),
+		WithCondition(func(req *http.Request, stats Stats) bool {
+			got = stats
+
+			return true
+		}),
+		WithCondition(StatusAtLeast(http.StatusInternalServerError)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(Stats{Status: http.StatusNotFound, Size: 19, Start: start}, got)
+	s.Empty(tw.Bytes)
+}
+
+func (s *ConditionSuite) TestPanic() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}), WithWriter(&tw), WithRecovery(true), WithCondition(StatusAtLeast(600)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.NotEmpty(tw.Bytes)
+}
+
+func TestCondition(t *testing.T) {
+	suite.Run(t, new(ConditionSuite))
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connections are the connections tracked by the ConnStateHook hooks, by
+// connKey
+var connections sync.Map
+
+var (
+	// connTracking is set once a ConnStateHook is made, so the requests
+	// don't look their connection up for nothing
+	connTracking atomic.Bool
+	lastConnID   atomic.Uint64
+)
+
+// connInfo is a connection tracked by a ConnStateHook
+type connInfo struct {
+	id       string
+	start    time.Time
+	requests atomic.Int64
+}
+
+// ConnStateHook returns a function for http.Server.ConnState writing a
+// line to w when a connection is opened, becomes idle, is closed or
+// hijacked, e.g.
+//
+//	2017-01-02T15:04:05Z conn=1 remote=192.0.2.1:1234 state=new
+//	2017-01-02T15:04:06Z conn=1 remote=192.0.2.1:1234 state=idle requests=1
+//	2017-01-02T15:04:09Z conn=1 remote=192.0.2.1:1234 state=closed requests=2 duration=4.000s
+//
+// The ID of the connection of a request is printed by the :conn-id token
+// and logged as the connection.id structured field, so the request
+// entries can be correlated with their connection.
+func ConnStateHook(w io.Writer) func(net.Conn, http.ConnState) {
+	connTracking.Store(true)
+
+	var mu sync.Mutex
+
+	return func(c net.Conn, state http.ConnState) {
+		key := connKey(c.LocalAddr(), c.RemoteAddr().String())
+
+		var info *connInfo
+
+		switch state {
+		case http.StateNew:
+			info = &connInfo{
+				id:    strconv.FormatUint(lastConnID.Add(1), 10),
+				start: time.Now(),
+			}
+			connections.Store(key, info)
+		case http.StateActive:
+			if v, ok := connections.Load(key); ok {
+				v.(*connInfo).requests.Add(1)
+			}
+
+			return
+		case http.StateClosed, http.StateHijacked:
+			v, ok := connections.LoadAndDelete(key)
+			if !ok {
+				return
+			}
+			info = v.(*connInfo)
+		default:
+			v, ok := connections.Load(key)
+			if !ok {
+				return
+			}
+			info = v.(*connInfo)
+		}
+
+		now := time.Now()
+
+		b := now.UTC().AppendFormat(nil, time.RFC3339)
+		b = append(b, " conn="...)
+		b = append(b, info.id...)
+		b = append(b, " remote="...)
+		b = append(b, c.RemoteAddr().String()...)
+		b = append(b, " state="...)
+		b = append(b, state.String()...)
+
+		if state != http.StateNew {
+			b = append(b, " requests="...)
+			b = strconv.AppendInt(b, info.requests.Load(), 10)
+		}
+
+		if state == http.StateClosed || state == http.StateHijacked {
+			b = append(b, " duration="...)
+			b = strconv.AppendFloat(b, now.Sub(info.start).Seconds(), 'f', 3, 64)
+			b = append(b, 's')
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Write(append(b, '\n'))
+	}
+}
+
+// connKey identifies a connection by its local and remote addresses
+func connKey(local net.Addr, remote string) string {
+	if local == nil {
+		return remote
+	}
+
+	return local.String() + " " + remote
+}
+
+// connID returns the ID given by a ConnStateHook to the connection of req,
+// empty when it isn't tracked
+func connID(req *http.Request) string {
+	if !connTracking.Load() {
+		return ""
+	}
+
+	local, _ := req.Context().Value(http.LocalAddrContextKey).(net.Addr)
+
+	if v, ok := connections.Load(connKey(local, req.RemoteAddr)); ok {
+		return v.(*connInfo).id
+	}
+
+	return ""
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConnSuite struct {
+	suite.Suite
+}
+
+func (s *ConnSuite) TestHook() {
+	conns, requests := &syncWriter{}, &syncWriter{}
+
+	srv := httptest.NewUnstartedServer(New(http.NotFoundHandler(), WithWriter(requests),
+		WithCustomFormat(":conn-id :method :url")))
+	srv.Config.ConnState = ConnStateHook(conns)
+	srv.Start()
+	defer srv.Close()
+
+	client := srv.Client()
+	for _, path := range []string{"/a", "/b"} {
+		res, err := client.Get(srv.URL + path)
+		s.Require().Nil(err)
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+	}
+
+	client.CloseIdleConnections()
+	s.Eventually(func() bool {
+		return strings.Contains(conns.String(), "state=closed")
+	}, time.Second, 10*time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(requests.String()), "\n")
+	s.Require().Len(lines, 2)
+
+	id, _, _ := strings.Cut(lines[0], " ")
+	s.Equal(id+" GET /a", lines[0])
+	s.Equal(id+" GET /b", lines[1])
+
+	prefix := `\S+ conn=` + id + ` remote=127\.0\.0\.1:\d+ state=`
+	s.Regexp(regexp.MustCompile(`^`+prefix+`new\n`+
+		prefix+`idle requests=1\n`+
+		prefix+`idle requests=2\n`+
+		prefix+`closed requests=2 duration=\d+\.\d{3}s\n$`), conns.String())
+}
+
+func (s *ConnSuite) TestUntracked() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":conn-id :method"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("- GET\n", string(tw.Bytes))
+}
+
+func TestConn(t *testing.T) {
+	suite.Run(t, new(ConnSuite))
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+)
+
+// Correlation headers set by reverse proxies and load balancers, read by
+// WithCorrelationIDs
+const (
+	CorrelationIDHeader = "X-Correlation-ID"
+	AmznTraceIDHeader   = "X-Amzn-Trace-Id"
+)
+
+// correlationIDs are the correlation headers of a request
+type correlationIDs struct {
+	id          string
+	amznTraceID string
+}
+
+func (ids correlationIDs) empty() bool {
+	return ids.id == "" && ids.amznTraceID == ""
+}
+
+// incomingCorrelationIDs returns the correlation headers of req which are
+// safe to log
+func incomingCorrelationIDs(req *http.Request) correlationIDs {
+	var ids correlationIDs
+
+	if id := req.Header.Get(CorrelationIDHeader); validRequestID(id) {
+		ids.id = id
+	}
+
+	if id := req.Header.Get(AmznTraceIDHeader); validRequestID(id) {
+		ids.amznTraceID = id
+	}
+
+	return ids
+}
+
+// CorrelationIDFromContext returns the X-Correlation-ID of the request,
+// empty unless WithCorrelationIDs is used
+func CorrelationIDFromContext(ctx context.Context) string {
+	ids, _ := ctx.Value(correlationKey).(correlationIDs)
+
+	return ids.id
+}
+
+// AmznTraceIDFromContext returns the X-Amzn-Trace-Id of the request, empty
+// unless WithCorrelationIDs is used
+func AmznTraceIDFromContext(ctx context.Context) string {
+	ids, _ := ctx.Value(correlationKey).(correlationIDs)
+
+	return ids.amznTraceID
+}
+
+func (rh loggerHanlder) withCorrelationIDs(res http.ResponseWriter, req *http.Request, rl *responseLogger) *http.Request {
+	ids := incomingCorrelationIDs(req)
+	if ids.empty() {
+		return req
+	}
+
+	rl.correlationID, rl.amznTraceID = ids.id, ids.amznTraceID
+
+	if rh.echoCorrelation {
+		if ids.id != "" {
+			res.Header().Set(CorrelationIDHeader, ids.id)
+		}
+
+		if ids.amznTraceID != "" {
+			res.Header().Set(AmznTraceIDHeader, ids.amznTraceID)
+		}
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), correlationKey, ids))
+}
+
+// propagateCorrelationIDs returns req with the correlation headers of its
+// context, for the outgoing requests of the Transport
+func propagateCorrelationIDs(req *http.Request) *http.Request {
+	ids, _ := req.Context().Value(correlationKey).(correlationIDs)
+	if ids.empty() {
+		return req
+	}
+
+	propagated := req.Clone(req.Context())
+	if ids.id != "" && propagated.Header.Get(CorrelationIDHeader) == "" {
+		propagated.Header.Set(CorrelationIDHeader, ids.id)
+	}
+
+	if ids.amznTraceID != "" && propagated.Header.Get(AmznTraceIDHeader) == "" {
+		propagated.Header.Set(AmznTraceIDHeader, ids.amznTraceID)
+	}
+
+	return propagated
+}
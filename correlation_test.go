@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const testAmznTraceID = "Root=1-67891233-abcdef012345678912345678;Sampled=1"
+
+type CorrelationSuite struct {
+	suite.Suite
+
+	req *http.Request
+}
+
+func (s *CorrelationSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/", nil)
+	s.req.Header.Set(CorrelationIDHeader, "corr-1")
+	s.req.Header.Set(AmznTraceIDHeader, testAmznTraceID)
+}
+
+func (s *CorrelationSuite) TestText() {
+	tw := testWriter{}
+	var id, amzn string
+	h := New(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		id = CorrelationIDFromContext(req.Context())
+		amzn = AmznTraceIDFromContext(req.Context())
+	}), WithWriter(&tw), WithCustomFormat(":correlation-id :amzn-trace-id"), WithCorrelationIDs(true))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, s.req)
+
+	s.Equal("corr-1", id)
+	s.Equal(testAmznTraceID, amzn)
+	s.Equal("corr-1", rec.Header().Get(CorrelationIDHeader))
+	s.Equal(testAmznTraceID, rec.Header().Get(AmznTraceIDHeader))
+	s.Equal("corr-1 "+testAmznTraceID+"\n", string(tw.Bytes))
+}
+
+func (s *CorrelationSuite) TestNoEcho() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":correlation-id"), WithCorrelationIDs(false))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, s.req)
+
+	s.Empty(rec.Header().Get(CorrelationIDHeader))
+	s.Equal("corr-1\n", string(tw.Bytes))
+}
+
+func (s *CorrelationSuite) TestDisabled() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":correlation-id :amzn-trace-id"))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("- -\n", string(tw.Bytes))
+	s.Equal("", CorrelationIDFromContext(context.Background()))
+}
+
+func (s *CorrelationSuite) TestInvalid() {
+	s.req.Header.Set(CorrelationIDHeader, "has space")
+
+	ids := incomingCorrelationIDs(s.req)
+	s.Equal("", ids.id)
+	s.Equal(testAmznTraceID, ids.amznTraceID)
+}
+
+func (s *CorrelationSuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)),
+		WithCorrelationIDs(false))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("corr-1", entry["request.correlation_id"])
+	s.Equal(testAmznTraceID, entry["request.amzn_trace_id"])
+}
+
+func (s *CorrelationSuite) TestTransport() {
+	var got http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		got = req.Header
+	}))
+	defer ts.Close()
+
+	tw := testWriter{}
+	client := &http.Client{Transport: NewTransport(nil, WithWriter(&tw), WithCustomFormat(":correlation-id"),
+		WithCorrelationIDs(false))}
+
+	h := New(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		out, _ := http.NewRequestWithContext(req.Context(), http.MethodGet, ts.URL, nil)
+		res, err := client.Do(out)
+		s.Nil(err)
+		io.ReadAll(res.Body)
+		res.Body.Close()
+
+		s.Empty(out.Header.Get(CorrelationIDHeader))
+	}), WithWriter(&testWriter{}), WithCorrelationIDs(false))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("corr-1", got.Get(CorrelationIDHeader))
+	s.Equal(testAmznTraceID, got.Get(AmznTraceIDHeader))
+	s.Equal("corr-1\n", string(tw.Bytes))
+}
+
+func TestCorrelation(t *testing.T) {
+	suite.Run(t, new(CorrelationSuite))
+}
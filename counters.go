@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+)
+
+// Counters is a snapshot of the internal counters of a handler, to verify
+// no entry is silently lost
+type Counters struct {
+	// Written is the number of entries written, to the writer of WithAsync
+	// by its workers
+	Written uint64 `json:"written"`
+	// Dropped is the number of entries discarded by OverflowDrop as the
+	// WithAsync queue was full
+	Dropped uint64 `json:"dropped"`
+	// WriteErrors is the number of entries lost to errors of the writer
+	WriteErrors uint64 `json:"write_errors"`
+	// QueueDepth is the number of entries queued by WithAsync and not yet
+	// written, those of every Target included
+	QueueDepth int `json:"queue_depth"`
+}
+
+// Counters returns the internal counters of the handler, see WithExpvar.
+// The http.Handler returned by New implements it.
+func (rh loggerHanlder) Counters() Counters {
+	return Counters{
+		Written:     rh.counters.written.Load(),
+		Dropped:     rh.counters.dropped.Load(),
+		WriteErrors: rh.counters.writeErrors.Load(),
+		QueueDepth:  rh.queueDepth(),
+	}
+}
+
+// queueDepth returns the number of entries queued by the WithAsync writers
+// of rh and its outputs
+func (rh loggerHanlder) queueDepth() int {
+	var n int
+	if rh.async != nil {
+		n = rh.async.queued()
+	}
+
+	for _, o := range rh.outputs {
+		n += o.queueDepth()
+	}
+
+	return n
+}
+
+// publish publishes the Counters of rh with expvar under name
+func (rh loggerHanlder) publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return rh.Counters()
+	}))
+}
+
+// CountersHandler returns a http.Handler rendering the Counters of h as
+// JSON, h being returned by New or NewLogger, e.g.
+//
+//	h := logger.NewLogger(mux, logger.WithAsync(1024), logger.WithOverflowPolicy(logger.OverflowDrop))
+//	mux.Handle("/debug/loggerstats", logger.CountersHandler(h))
+func CountersHandler(h http.Handler) http.Handler {
+	c, ok := h.(interface{ Counters() Counters })
+	if !ok {
+		panic("logger: CountersHandler of a handler not returned by New or NewLogger")
+	}
+
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "application/json")
+
+		json.NewEncoder(res).Encode(c.Counters())
+	})
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CountersSuite struct {
+	suite.Suite
+}
+
+func (s *CountersSuite) TestWritten() {
+	w := &failingWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(w))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	w.failing = true
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(Counters{Written: 1, WriteErrors: 1}, h.(loggerHanlder).Counters())
+	s.Equal(uint64(1), h.(loggerHanlder).Dropped())
+}
+
+func (s *CountersSuite) TestAsync() {
+	bw := &blockingWriter{release: make(chan struct{})}
+	l := NewLogger(http.NotFoundHandler(), WithWriter(bw), WithAsync(1),
+		WithOverflowPolicy(OverflowDrop))
+
+	for i := 0; i < 10; i++ {
+		l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	// one entry is held by the worker, one may be queued
+	c := l.Counters()
+	s.Equal(uint64(0), c.Written)
+	s.True(c.QueueDepth >= 1 && c.QueueDepth <= 2)
+	s.Equal(uint64(10-c.QueueDepth), c.Dropped)
+
+	close(bw.release)
+	s.Nil(l.Flush())
+
+	s.Equal(Counters{Written: uint64(c.QueueDepth), Dropped: c.Dropped}, l.Counters())
+	s.Equal(c.Dropped, l.Dropped())
+}
+
+func (s *CountersSuite) TestTargets() {
+	bw := &blockingWriter{release: make(chan struct{})}
+	h := New(http.NotFoundHandler(),
+		WithTargets(Target{Writer: bw, Type: CombineLoggerType, Options: []Option{WithAsync(10)}}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Equal(1, h.(loggerHanlder).Counters().QueueDepth)
+
+	close(bw.release)
+	s.Nil(h.(loggerHanlder).Flush())
+
+	s.Equal(Counters{Written: 1}, h.(loggerHanlder).Counters())
+}
+
+func (s *CountersSuite) TestExpvar() {
+	h := New(http.NotFoundHandler(), WithWriter(&testWriter{}), WithExpvar("logger_test"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var c Counters
+	s.Nil(json.Unmarshal([]byte(expvar.Get("logger_test").String()), &c))
+	s.Equal(Counters{Written: 1}, c)
+
+	s.Panics(func() {
+		New(http.NotFoundHandler(), WithExpvar("logger_test"))
+	})
+}
+
+func (s *CountersSuite) TestHandler() {
+	h := New(http.NotFoundHandler(), WithWriter(&failingWriter{failing: true}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	rec := httptest.NewRecorder()
+	CountersHandler(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/loggerstats", nil))
+
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+	s.JSONEq(`{"written":0,"dropped":0,"write_errors":1,"queue_depth":0}`, rec.Body.String())
+
+	s.Panics(func() {
+		CountersHandler(http.NotFoundHandler())
+	})
+}
+
+func TestCounters(t *testing.T) {
+	suite.Run(t, new(CountersSuite))
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"sync"
+)
+
+// DefaultColumns are the columns of CSVLoggerType and TSVLoggerType unless
+// WithColumns sets others
+var DefaultColumns = []string{":date[iso]", ":remote-addr", ":remote-user", ":method", ":url", ":http-version",
+	":status", ":res[content-length]", ":response-time", ":referrer", ":user-agent"}
+
+// csvColumn is a column of CSVLoggerType and TSVLoggerType, named after its
+// token without the colon
+type csvColumn struct {
+	name  string
+	token token
+}
+
+// compileColumns compiles the tokens of columns, it panics for anything
+// else
+func compileColumns(columns []string) []csvColumn {
+	compiled := make([]csvColumn, 0, len(columns))
+
+	for _, column := range columns {
+		m := tokenRegexp.FindStringSubmatch(column)
+		if m == nil || m[0] != column {
+			panic("logger: invalid column " + column + ", want a token such as :method")
+		}
+
+		t := newToken(m[1], m[2])
+		if t == nil {
+			panic("logger: unknown column " + column)
+		}
+
+		compiled = append(compiled, csvColumn{strings.TrimPrefix(column, ":"), t})
+	}
+
+	return compiled
+}
+
+// csvFormatter prints entries as comma or tab separated values, quoted when
+// needed, with a header row of the column names before the first one when
+// enabled
+type csvFormatter struct {
+	columns []csvColumn
+	comma   rune
+	header  bool
+	once    *sync.Once
+}
+
+func (cf csvFormatter) Format(w io.Writer, e *Entry) error {
+	var b bytes.Buffer
+
+	cw := csv.NewWriter(&b)
+	cw.Comma = cf.comma
+
+	record := make([]string, len(cf.columns))
+
+	if cf.header {
+		cf.once.Do(func() {
+			for i, c := range cf.columns {
+				record[i] = c.name
+			}
+			cw.Write(record)
+		})
+	}
+
+	for i, c := range cf.columns {
+		record[i] = string(c.token(nil, e))
+	}
+	cw.Write(record)
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
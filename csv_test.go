@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CSVSuite struct {
+	suite.Suite
+}
+
+func (s *CSVSuite) TestCSV() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(CSVLoggerType), WithCSVHeader(true),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}),
+		WithColumns(":date[iso]", ":method", ":url", ":status", ":user-agent"))
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=a,b", nil)
+	req.Header.Set("User-Agent", `say "hi"`)
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodHead, "/", nil))
+
+	s.Equal("date[iso],method,url,status,user-agent\n"+
+		`2017-01-02T15:04:05.000Z,GET,"/search?q=a,b",404,"say ""hi"""`+"\n"+
+		"2017-01-02T15:04:05.000Z,HEAD,/,404,\n", string(tw.Bytes))
+}
+
+func (s *CSVSuite) TestTSV() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(TSVLoggerType), WithClock(testClock{}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "a\tb")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Equal("0001-01-01T00:00:00.000Z\t192.0.2.1:1234\t-\tGET\t/\t1.1\t404\t19\t0.000\t\t\"a\tb\"\n", string(tw.Bytes))
+}
+
+func (s *CSVSuite) TestColumns() {
+	columns := compileColumns([]string{":method", ":res[content-length]"})
+	s.Equal("method", columns[0].name)
+	s.Equal("res[content-length]", columns[1].name)
+
+	s.Panics(func() { WithColumns("method") })
+	s.Panics(func() { WithColumns(":method :url") })
+	s.Panics(func() { WithColumns(":unknown") })
+}
+
+func TestCSV(t *testing.T) {
+	suite.Run(t, new(CSVSuite))
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// dedupKey identifies the entries WithDeduplication collapses
+type dedupKey struct {
+	method string
+	path   string
+	status int
+}
+
+// dedupWindow is the window opened by the first entry of a key
+type dedupWindow struct {
+	start      time.Time
+	duplicates int
+}
+
+// duplicates is the number of entries of a key collapsed in a window
+type duplicates struct {
+	key   dedupKey
+	count int
+}
+
+// deduplicator suppresses the entries repeating the method, path and
+// status of an entry logged less than a window ago, see WithDeduplication
+type deduplicator struct {
+	mu sync.Mutex
+
+	window  time.Duration
+	windows map[dedupKey]dedupWindow
+	sweep   time.Time
+}
+
+func newDeduplicator(window time.Duration) *deduplicator {
+	return &deduplicator{window: window, windows: map[dedupKey]dedupWindow{}}
+}
+
+// allow reports whether the entry of key can be logged at now, along with
+// the duplicates of the windows over by now
+func (d *deduplicator) allow(key dedupKey, now time.Time) (bool, []duplicates) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var over []duplicates
+
+	// the windows of the keys which stopped repeating are closed once
+	// per window
+	if !now.Before(d.sweep) {
+		for k, w := range d.windows {
+			if now.Sub(w.start) >= d.window {
+				if w.duplicates > 0 {
+					over = append(over, duplicates{k, w.duplicates})
+				}
+				delete(d.windows, k)
+			}
+		}
+
+		d.sweep = now.Add(d.window)
+	}
+
+	if w, ok := d.windows[key]; ok {
+		w.duplicates++
+		d.windows[key] = w
+
+		return false, over
+	}
+
+	d.windows[key] = dedupWindow{start: now}
+
+	return true, over
+}
+
+// reset returns the duplicates of the open windows and closes them
+func (d *deduplicator) reset() []duplicates {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var over []duplicates
+	for k, w := range d.windows {
+		if w.duplicates > 0 {
+			over = append(over, duplicates{k, w.duplicates})
+		}
+	}
+
+	d.windows = map[dedupKey]dedupWindow{}
+
+	return over
+}
+
+// dedup reports whether the entry of req answered with status at now is
+// logged under WithDeduplication, writing the collapsed lines of the
+// windows over first
+func (rh loggerHanlder) dedup(req *http.Request, status int, now time.Time) bool {
+	if rh.repeats == nil {
+		return true
+	}
+
+	ok, over := rh.repeats.allow(dedupKey{req.Method, req.URL.Path, status}, now)
+	for _, d := range over {
+		rh.repeated(d)
+	}
+
+	return ok
+}
+
+// repeated writes the line collapsing the duplicates of d
+func (rh loggerHanlder) repeated(d duplicates) {
+	rh.notice(LevelInfo, fmt.Sprintf("repeated %s %s %d count=%d", d.key.method, d.key.path, d.key.status, d.count),
+		map[string]interface{}{
+			"request.method":  d.key.method,
+			"request.path":    d.key.path,
+			"response.status": d.key.status,
+			"count":           d.count,
+		})
+}
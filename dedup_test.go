@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DedupSuite struct {
+	suite.Suite
+
+	tw    testWriter
+	clock *stepClock
+}
+
+func (s *DedupSuite) SetupTest() {
+	s.tw = testWriter{}
+	s.clock = &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}
+}
+
+func (s *DedupSuite) serve(h http.Handler, method, path string, n int) {
+	for i := 0; i < n; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+}
+
+func (s *DedupSuite) TestCollapse() {
+	h := New(http.NotFoundHandler(), WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":method :url :status"),
+		WithDeduplication(time.Second))
+
+	s.serve(h, http.MethodGet, "/retry", 5)
+	s.serve(h, http.MethodPost, "/retry", 1)
+	s.serve(h, http.MethodGet, "/retry?attempt=6", 1)
+	s.Equal("GET /retry 404\nPOST /retry 404\n", string(s.tw.Bytes))
+
+	s.clock.now = s.clock.now.Add(time.Second)
+	s.serve(h, http.MethodGet, "/other", 1)
+	s.Equal("GET /retry 404\nPOST /retry 404\nrepeated GET /retry 404 count=5\nGET /other 404\n", string(s.tw.Bytes))
+
+	s.serve(h, http.MethodGet, "/retry", 1)
+	s.Equal(2, strings.Count(string(s.tw.Bytes), "GET /retry 404\n"))
+}
+
+func (s *DedupSuite) TestClose() {
+	h := NewLogger(http.NotFoundHandler(), WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":method :url"),
+		WithDeduplication(time.Minute))
+
+	s.serve(h, http.MethodGet, "/", 3)
+	s.serve(h, http.MethodGet, "/single", 1)
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal("GET /\nGET /single\nrepeated GET / 404 count=2\n", string(s.tw.Bytes))
+}
+
+func (s *DedupSuite) TestJSON() {
+	h := NewLogger(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&s.tw)),
+		WithClock(s.clock), WithDeduplication(time.Second))
+
+	s.serve(h, http.MethodGet, "/", 2)
+	s.tw.Bytes = nil
+	s.Nil(h.Close(context.Background()))
+
+	line := map[string]interface{}{}
+	s.Nil(json.NewDecoder(bytes.NewReader(s.tw.Bytes)).Decode(&line))
+	s.Equal("repeated GET / 404 count=1", line["msg"])
+	s.Equal("info", line["level"])
+	s.Equal("/", line["request.path"])
+	s.Equal(404.0, line["response.status"])
+	s.Equal(1.0, line["count"])
+}
+
+func (s *DedupSuite) TestGELF() {
+	h := NewLogger(http.NotFoundHandler(), WithWriter(&s.tw), WithFormat(GELFLoggerType), WithClock(s.clock),
+		WithDeduplication(time.Second))
+
+	s.serve(h, http.MethodGet, "/", 2)
+	s.tw.Bytes = nil
+	s.Nil(h.Close(context.Background()))
+
+	line := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.tw.Bytes, &line))
+	s.Equal("repeated GET / 404 count=1", line["short_message"])
+	s.Equal("/", line["_request.path"])
+	s.Equal(1.0, line["_count"])
+}
+
+func (s *DedupSuite) TestCSV() {
+	h := NewLogger(http.NotFoundHandler(), WithWriter(&s.tw), WithFormat(CSVLoggerType), WithClock(s.clock),
+		WithDeduplication(time.Second))
+
+	s.serve(h, http.MethodGet, "/", 2)
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal(1, strings.Count(string(s.tw.Bytes), "\n"))
+	s.NotContains(string(s.tw.Bytes), "repeated")
+}
+
+func (s *DedupSuite) TestPanicsAlwaysLogged() {
+	h := New(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":status"), WithRecovery(true),
+		WithDeduplication(time.Second))
+
+	s.serve(h, http.MethodGet, "/", 3)
+
+	s.Equal(3, strings.Count(string(s.tw.Bytes), "500\n"))
+}
+
+func (s *DedupSuite) TestSweep() {
+	d := newDeduplicator(time.Second)
+	now := s.clock.now
+
+	ok, over := d.allow(dedupKey{"GET", "/a", 200}, now)
+	s.True(ok)
+	s.Empty(over)
+
+	ok, _ = d.allow(dedupKey{"GET", "/a", 200}, now)
+	s.False(ok)
+
+	// /a stopped repeating, its window is closed by another key
+	ok, over = d.allow(dedupKey{"GET", "/b", 200}, now.Add(time.Second))
+	s.True(ok)
+	s.Equal([]duplicates{{dedupKey{"GET", "/a", 200}, 1}}, over)
+	s.Len(d.windows, 1)
+}
+
+func TestDedup(t *testing.T) {
+	suite.Run(t, new(DedupSuite))
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DurationUnit is the unit text formats print durations in
+type DurationUnit time.Duration
+
+// Units for WithDurationUnit
+const (
+	Microsecond = DurationUnit(time.Microsecond)
+	Millisecond = DurationUnit(time.Millisecond)
+	Second      = DurationUnit(time.Second)
+)
+
+func (u DurationUnit) String() string {
+	switch u {
+	case Microsecond:
+		return "µs"
+	case Second:
+		return "s"
+	}
+
+	return "ms"
+}
+
+// durationFormat prints the durations of text formats, the zero value
+// prints milliseconds with 3 decimals
+type durationFormat struct {
+	unit   DurationUnit
+	format string
+}
+
+func (df durationFormat) text(d time.Duration) string {
+	return string(df.append(nil, d))
+}
+
+// append appends d as text to b
+func (df durationFormat) append(b []byte, d time.Duration) []byte {
+	unit := df.unit
+	if unit <= 0 {
+		unit = Millisecond
+	}
+
+	value := float64(d) / float64(unit)
+	if df.format == "" {
+		return strconv.AppendFloat(b, value, 'f', 3, 64)
+	}
+
+	return fmt.Appendf(b, df.format, value)
+}
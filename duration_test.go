@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DurationSuite struct {
+	suite.Suite
+}
+
+func (s *DurationSuite) TestText() {
+	s.Equal("1.500", durationFormat{}.text(1500*time.Microsecond))
+	s.Equal("1500.000", durationFormat{unit: Microsecond}.text(1500*time.Microsecond))
+	s.Equal("0.002", durationFormat{unit: Second}.text(1500*time.Microsecond))
+	s.Equal("2", durationFormat{format: "%.0f"}.text(1500*time.Microsecond))
+}
+
+func (s *DurationSuite) TestUnit() {
+	s.Equal("ms", Millisecond.String())
+	s.Equal("µs", Microsecond.String())
+	s.Equal("s", Second.String())
+	s.Equal("ms", DurationUnit(0).String())
+}
+
+func (s *DurationSuite) TestOptions() {
+	tw := testWriter{}
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), step: 1250 * time.Millisecond}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(clock), WithFormat(TinyLoggerType),
+		WithDurationUnit(Second), WithDurationFormat("%.2f"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 404 19 - 2.50 s\n", string(tw.Bytes))
+}
+
+func TestDuration(t *testing.T) {
+	suite.Run(t, new(DurationSuite))
+}
@@ -0,0 +1,61 @@
+// Package echoadapter logs the requests of an echo server through the
+// formats and options of the logger package, as an echo middleware. The
+// route is the path of the matched echo route, e.g. "/users/:id".
+package echoadapter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-http-utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type contextKey int
+
+const callKey contextKey = iota
+
+// call is the rest of the middleware chain of a logged request
+type call struct {
+	c    echo.Context
+	next echo.HandlerFunc
+}
+
+// Middleware returns an echo middleware logging every request, configured
+// by opts like logger.New. The handlers down the chain get the context of
+// the logged request, e.g. for logger.AddField(c.Request().Context(), ...).
+//
+// Errors returned down the chain are handled by c.Error, so the status
+// logged is the one of the error response, and aren't returned further.
+func Middleware(opts ...logger.Option) echo.MiddlewareFunc {
+	h := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			h.ServeHTTP(c.Response().Writer, req.WithContext(context.WithValue(req.Context(), callKey, &call{c, next})))
+
+			return nil
+		}
+	}
+}
+
+// serve runs the rest of the chain of the call of req as the handler
+// wrapped by the logger
+func serve(res http.ResponseWriter, req *http.Request) {
+	call := req.Context().Value(callKey).(*call)
+	c := call.c
+
+	r := c.Response()
+	w := r.Writer
+	defer func() { r.Writer = w }()
+
+	c.SetRequest(req)
+	r.Writer = res
+
+	if err := call.next(c); err != nil {
+		c.Error(err)
+	}
+
+	req.Pattern = c.Path()
+}
@@ -0,0 +1,62 @@
+package echoadapter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+type EchoSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+	e   *echo.Echo
+}
+
+func (s *EchoSuite) SetupTest() {
+	s.buf.Reset()
+
+	s.e = echo.New()
+	s.e.Use(Middleware(logger.WithWriter(&s.buf), logger.WithCustomFormat(":method :url :route :status :custom[tenant]")))
+}
+
+func (s *EchoSuite) serve(method, url string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, httptest.NewRequest(method, url, nil))
+
+	return rec
+}
+
+func (s *EchoSuite) TestMiddleware() {
+	s.e.GET("/users/:id", func(c echo.Context) error {
+		logger.AddField(c.Request().Context(), "tenant", "acme")
+
+		return c.String(http.StatusCreated, "hello "+c.Param("id"))
+	})
+
+	rec := s.serve(http.MethodGet, "/users/42")
+
+	s.Equal(http.StatusCreated, rec.Code)
+	s.Equal("hello 42", rec.Body.String())
+	s.Equal("GET /users/42 /users/:id 201 acme\n", s.buf.String())
+}
+
+func (s *EchoSuite) TestError() {
+	s.e.GET("/users/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusForbidden)
+	})
+
+	rec := s.serve(http.MethodGet, "/users/42")
+
+	s.Equal(http.StatusForbidden, rec.Code)
+	s.Equal("GET /users/42 /users/:id 403 -\n", s.buf.String())
+}
+
+func TestEcho(t *testing.T) {
+	suite.Run(t, new(EchoSuite))
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Encoder is a binary serialization of the entries, for pipelines where the
+// cost and size of JSON matter, see WithEncoder
+type Encoder int
+
+const (
+	// MsgpackEncoder writes every entry as a MessagePack map with the keys
+	// and schema_version of NDJSONLoggerType. The maps are self-delimiting,
+	// nothing separates them.
+	MsgpackEncoder Encoder = iota + 1
+	// ProtobufEncoder writes every entry as a logger.v1.Entry message of
+	// entry.proto prefixed with its length as a varint, as read by
+	// protodelim.UnmarshalFrom
+	ProtobufEncoder
+)
+
+// newFormatter returns the Formatter of the encoder, nil for unknown ones
+func (enc Encoder) newFormatter() Formatter {
+	switch enc {
+	case MsgpackEncoder:
+		return msgpackFormatter{}
+	case ProtobufEncoder:
+		return protobufFormatter{}
+	}
+
+	return nil
+}
+
+// writeNotice writes msg with fields as a notice about the log output
+// itself, see loggerHanlder.notice
+func (enc Encoder) writeNotice(w io.Writer, t time.Time, level Level, msg string, fields map[string]interface{}) error {
+	var b []byte
+
+	switch enc {
+	case MsgpackEncoder:
+		b = appendMsgpackNotice(nil, t, level, msg, fields)
+	case ProtobufEncoder:
+		b = appendProtobufNotice(nil, t, level, msg, fields)
+	default:
+		return fmt.Errorf("logger: unknown encoder %d", enc)
+	}
+
+	_, err := w.Write(b)
+
+	return err
+}
@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EncoderSuite struct {
+	suite.Suite
+}
+
+func (s *EncoderSuite) serve(enc Encoder, opts ...Option) []byte {
+	tw := testWriter{}
+	opts = append([]Option{WithWriter(&tw), WithEncoder(enc),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 123e6, time.UTC)})}, opts...)
+
+	New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		AddField(req.Context(), "tenant", "acme")
+		AddField(req.Context(), "attempt", 2)
+		http.Error(res, "oops", http.StatusBadGateway)
+	}), opts...).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+
+	return tw.Bytes
+}
+
+func (s *EncoderSuite) TestMsgpack() {
+	b := s.serve(MsgpackEncoder, WithLoggedRequestHeaders("X-Test"))
+
+	v, rest := decodeMsgpack(b)
+	s.Empty(rest)
+
+	m := v.(map[string]interface{})
+	s.Equal(int64(NDJSONSchemaVersion), m["schema_version"])
+	s.Equal("2017-01-02T15:04:05.123Z", m["time"])
+	s.Equal("error", m["level"])
+	s.Equal("GET", m["request.method"])
+	s.Equal("/users?id=1", m["request.url"])
+	s.Equal(int64(502), m["response.status"])
+	s.Equal(int64(5), m["response.size"])
+	s.Equal(0.0, m["response.duration_ms"])
+	s.Equal("192.0.2.1:1234", m["client.address"])
+	s.Equal(map[string]interface{}{"tenant": "acme", "attempt": int64(2)}, m["fields"])
+	s.NotContains(m, "request.user_agent")
+}
+
+func (s *EncoderSuite) TestMsgpackValues() {
+	s.Equal([]byte{0x7f}, appendMsgpackInt(nil, 127))
+	s.Equal([]byte{0xcc, 0x80}, appendMsgpackInt(nil, 128))
+	s.Equal([]byte{0xff}, appendMsgpackInt(nil, -1))
+	s.Equal([]byte{0xd0, 0xdf}, appendMsgpackInt(nil, -33))
+	s.Equal([]byte{0xd1, 0xff, 0x7f}, appendMsgpackInt(nil, -129))
+	s.Equal([]byte{0xa2, 'o', 'k'}, appendMsgpackString(nil, "ok"))
+	s.Equal([]byte{0xd9, 32}, appendMsgpackString(nil, string(make([]byte, 32)))[:2])
+	s.Equal([]byte{0xc4, 1, 'x'}, appendMsgpackValue(nil, []byte("x")))
+	s.Equal([]byte{0x92, 0xc3, 0xc0}, appendMsgpackValue(nil, []interface{}{true, nil}))
+	s.Equal([]byte{0xa2, '1', 's'}, appendMsgpackValue(nil, time.Second))
+}
+
+func (s *EncoderSuite) TestProtobuf() {
+	b := s.serve(ProtobufEncoder)
+
+	n, l := binary.Uvarint(b)
+	s.Require().Greater(l, 0)
+	s.Len(b[l:], int(n))
+
+	entry := decodeProto(b[l:])
+	s.Equal(uint64(NDJSONSchemaVersion), entry[1][0])
+	s.Equal(uint64(time.Date(2017, time.January, 2, 15, 4, 5, 123e6, time.UTC).UnixNano()), entry[2][0])
+	s.Equal(uint64(LevelError), entry[3][0])
+	s.NotContains(entry, 4)
+	s.Equal("192.0.2.1:1234", string(entry[7][0].([]byte)))
+
+	req := decodeProto(entry[5][0].([]byte))
+	s.Equal("GET", string(req[1][0].([]byte)))
+	s.Equal("/users?id=1", string(req[3][0].([]byte)))
+	s.NotContains(req, 12)
+
+	res := decodeProto(entry[6][0].([]byte))
+	s.Equal(uint64(502), res[1][0])
+	s.Equal(uint64(5), res[2][0])
+	s.NotContains(res, 3)
+	s.NotContains(res, 5)
+
+	s.Len(entry[12], 2)
+	attempt := decodeProto(entry[12][0].([]byte))
+	s.Equal("attempt", string(attempt[1][0].([]byte)))
+	s.Equal("2", string(attempt[2][0].([]byte)))
+}
+
+func (s *EncoderSuite) TestProtobufHeader() {
+	b := appendProtoHeader(nil, 13, http.Header{"Accept": {"a", ""}})
+
+	entry := decodeProto(decodeProto(b)[13][0].([]byte))
+	s.Equal("Accept", string(entry[1][0].([]byte)))
+
+	values := decodeProto(entry[2][0].([]byte))
+	s.Equal("a", string(values[1][0].([]byte)))
+	s.Equal("", string(values[1][1].([]byte)))
+}
+
+func (s *EncoderSuite) TestNotice() {
+	tw := testWriter{}
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}
+	h := New(noopHandler{}, WithWriter(&tw), WithEncoder(MsgpackEncoder), WithClock(clock),
+		WithRateLimit(1, time.Second))
+
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	tw.Bytes = nil
+
+	clock.now = clock.now.Add(time.Second)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	v, _ := decodeMsgpack(tw.Bytes)
+	notice := v.(map[string]interface{})
+	s.Equal("suppressed 1 entries", notice["msg"])
+	s.Equal("warn", notice["level"])
+	s.Equal(map[string]interface{}{"suppressed": int64(1)}, notice["fields"])
+}
+
+func (s *EncoderSuite) TestFormatResets() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithEncoder(ProtobufEncoder), WithFormat(TinyLoggerType),
+		WithClock(testClock{}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 404 19 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func TestEncoder(t *testing.T) {
+	suite.Run(t, new(EncoderSuite))
+}
+
+// decodeMsgpack decodes the first value of b, the formats written by
+// appendMsgpackValue only
+func decodeMsgpack(b []byte) (interface{}, []byte) {
+	c := b[0]
+	b = b[1:]
+
+	str := func(n int) (interface{}, []byte) {
+		return string(b[:n]), b[n:]
+	}
+	seq := func(n int, isMap bool) (interface{}, []byte) {
+		if !isMap {
+			a := make([]interface{}, n)
+			for i := range a {
+				a[i], b = decodeMsgpack(b)
+			}
+
+			return a, b
+		}
+
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			var k, v interface{}
+			k, b = decodeMsgpack(b)
+			v, b = decodeMsgpack(b)
+			m[k.(string)] = v
+		}
+
+		return m, b
+	}
+
+	switch {
+	case c < 0x80:
+		return int64(c), b
+	case c >= 0xe0:
+		return int64(int8(c)), b
+	case c&0xf0 == 0x80:
+		return seq(int(c&0x0f), true)
+	case c&0xf0 == 0x90:
+		return seq(int(c&0x0f), false)
+	case c&0xe0 == 0xa0:
+		return str(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, b
+	case 0xc2, 0xc3:
+		return c == 0xc3, b
+	case 0xc4:
+		return b[1 : 1+b[0]], b[1+b[0]:]
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), b[8:]
+	case 0xcc:
+		return int64(b[0]), b[1:]
+	case 0xcd:
+		return int64(binary.BigEndian.Uint16(b)), b[2:]
+	case 0xd0:
+		return int64(int8(b[0])), b[1:]
+	case 0xd9:
+		n := int(b[0])
+		b = b[1:]
+
+		return str(n)
+	case 0xda:
+		n := int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+
+		return str(n)
+	case 0xde:
+		n := int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+
+		return seq(n, true)
+	}
+
+	panic("unsupported msgpack format")
+}
+
+// decodeProto returns the values of the fields of the message b by number,
+// uint64 for varints and fixed64 and []byte for the others
+func decodeProto(b []byte) map[int][]interface{} {
+	fields := map[int][]interface{}{}
+
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+
+		field := int(key >> 3)
+
+		switch key & 7 {
+		case protoVarint:
+			v, n := binary.Uvarint(b)
+			fields[field] = append(fields[field], v)
+			b = b[n:]
+		case protoFixed64:
+			fields[field] = append(fields[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case protoBytes:
+			l, n := binary.Uvarint(b)
+			fields[field] = append(fields[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		}
+	}
+
+	return fields
+}
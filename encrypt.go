@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// EncryptedChunkSize is the size of the chunks of output sealed by the
+// writers of EncryptedWriter, which also seal what was written every
+// EncryptedFlushInterval
+const (
+	EncryptedChunkSize     = 64 << 10
+	EncryptedFlushInterval = time.Second
+)
+
+// encryptedWriter seals what it's written by chunks, on a timer, when a
+// chunk is full, on Flush and on Close
+type encryptedWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	recipient *[32]byte
+	buf       []byte
+	closed    bool
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// EncryptedWriter returns an io.WriteCloser encrypting what it's written to
+// w with NaCl anonymous sealed boxes for the recipient public key, so logs
+// holding user data are stored encrypted and only the owner of the private
+// key, e.g. a security team, can read them, with DecryptedReader. The
+// output is sealed by chunks of up to EncryptedChunkSize bytes, every
+// EncryptedFlushInterval and on Flush, each one written as its big-endian
+// uint32 length followed by the sealed box. Close seals what's left and
+// closes w when it's an io.Closer.
+func EncryptedWriter(w io.Writer, recipient *[32]byte) io.WriteCloser {
+	ew := &encryptedWriter{w: w, recipient: recipient, done: make(chan struct{})}
+
+	ew.wg.Add(1)
+	go ew.run(EncryptedFlushInterval)
+
+	return ew
+}
+
+func (ew *encryptedWriter) run(interval time.Duration) {
+	defer ew.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ew.Flush()
+		case <-ew.done:
+			return
+		}
+	}
+}
+
+func (ew *encryptedWriter) Write(p []byte) (int, error) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.closed {
+		return 0, ErrClosed
+	}
+
+	ew.buf = append(ew.buf, p...)
+
+	for len(ew.buf) >= EncryptedChunkSize {
+		if err := ew.seal(ew.buf[:EncryptedChunkSize]); err != nil {
+			return 0, err
+		}
+
+		ew.buf = ew.buf[:copy(ew.buf, ew.buf[EncryptedChunkSize:])]
+	}
+
+	return len(p), nil
+}
+
+// Flush seals what was written since the last chunk and flushes the
+// underlying writer when it buffers its output
+func (ew *encryptedWriter) Flush() error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	return ew.flush()
+}
+
+func (ew *encryptedWriter) flush() error {
+	if len(ew.buf) == 0 {
+		return nil
+	}
+
+	err := ew.seal(ew.buf)
+	ew.buf = ew.buf[:0]
+
+	if f, ok := ew.w.(interface{ Flush() error }); ok && err == nil {
+		err = f.Flush()
+	}
+
+	return err
+}
+
+// seal writes the chunk p sealed for the recipient
+func (ew *encryptedWriter) seal(p []byte) error {
+	sealed, err := box.SealAnonymous(binary.BigEndian.AppendUint32(nil, uint32(len(p)+box.AnonymousOverhead)), p, ew.recipient, rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	_, err = ew.w.Write(sealed)
+
+	return err
+}
+
+// Close stops the timer, seals what's left and closes the underlying
+// writer when it's an io.Closer
+func (ew *encryptedWriter) Close() error {
+	ew.once.Do(func() {
+		close(ew.done)
+		ew.wg.Wait()
+	})
+
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.closed {
+		return nil
+	}
+
+	ew.closed = true
+
+	err := ew.flush()
+	if c, ok := ew.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// errDecrypt is returned by the readers of DecryptedReader for chunks which
+// can't be opened with their key pair
+var errDecrypt = errors.New("logger: chunk can't be decrypted")
+
+// maxSealedChunk bounds the chunks read by DecryptedReader, larger ones are
+// corrupted
+const maxSealedChunk = EncryptedChunkSize + box.AnonymousOverhead
+
+// decryptedReader reads the output of an EncryptedWriter back
+type decryptedReader struct {
+	r                     io.Reader
+	publicKey, privateKey *[32]byte
+
+	sealed []byte
+	chunk  []byte
+}
+
+// DecryptedReader returns an io.Reader decrypting the output of an
+// EncryptedWriter read from r with the key pair of its recipient
+func DecryptedReader(r io.Reader, publicKey, privateKey *[32]byte) io.Reader {
+	return &decryptedReader{r: r, publicKey: publicKey, privateKey: privateKey}
+}
+
+func (dr *decryptedReader) Read(p []byte) (int, error) {
+	for len(dr.chunk) == 0 {
+		if err := dr.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, dr.chunk)
+	dr.chunk = dr.chunk[n:]
+
+	return n, nil
+}
+
+// next reads and opens the next chunk
+func (dr *decryptedReader) next() error {
+	var length [4]byte
+	if _, err := io.ReadFull(dr.r, length[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("logger: truncated chunk length: %w", err)
+		}
+
+		return err
+	}
+
+	n := binary.BigEndian.Uint32(length[:])
+	if n < box.AnonymousOverhead || n > maxSealedChunk {
+		return fmt.Errorf("logger: invalid chunk length %d", n)
+	}
+
+	if cap(dr.sealed) < int(n) {
+		dr.sealed = make([]byte, n)
+	}
+	dr.sealed = dr.sealed[:n]
+
+	if _, err := io.ReadFull(dr.r, dr.sealed); err != nil {
+		return fmt.Errorf("logger: truncated chunk: %w", io.ErrUnexpectedEOF)
+	}
+
+	chunk, ok := box.OpenAnonymous(dr.chunk[:0], dr.sealed, dr.publicKey, dr.privateKey)
+	if !ok {
+		return errDecrypt
+	}
+	dr.chunk = chunk
+
+	return nil
+}
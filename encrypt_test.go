@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/crypto/nacl/box"
+)
+
+type EncryptSuite struct {
+	suite.Suite
+
+	public, private *[32]byte
+}
+
+func (s *EncryptSuite) SetupTest() {
+	var err error
+	s.public, s.private, err = box.GenerateKey(rand.Reader)
+	s.Require().Nil(err)
+}
+
+func (s *EncryptSuite) decrypt(b []byte) (string, error) {
+	out, err := io.ReadAll(DecryptedReader(bytes.NewReader(b), s.public, s.private))
+
+	return string(out), err
+}
+
+func (s *EncryptSuite) TestWriter() {
+	var buf bytes.Buffer
+	ew := EncryptedWriter(&buf, s.public)
+
+	h := New(http.NotFoundHandler(), WithWriter(ew), WithCustomFormat(":method :url :status"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a?email=jane@example.com", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Nil(ew.Close())
+	s.NotContains(buf.String(), "jane@example.com")
+
+	out, err := s.decrypt(buf.Bytes())
+	s.Nil(err)
+	s.Equal("GET /a?email=jane@example.com 404\nGET /b 404\n", out)
+
+	_, err = ew.Write([]byte("c\n"))
+	s.ErrorIs(err, ErrClosed)
+	s.Nil(ew.Close())
+}
+
+func (s *EncryptSuite) TestChunks() {
+	var buf bytes.Buffer
+	ew := EncryptedWriter(&buf, s.public)
+
+	line := strings.Repeat("x", 1023) + "\n"
+	for i := 0; i < 2*EncryptedChunkSize/len(line)+1; i++ {
+		ew.Write([]byte(line))
+	}
+
+	// the two full chunks are sealed as they're written
+	s.Equal(2*(4+EncryptedChunkSize+box.AnonymousOverhead), buf.Len())
+
+	s.Nil(ew.Close())
+	s.Equal(2*(4+EncryptedChunkSize+box.AnonymousOverhead)+4+len(line)+box.AnonymousOverhead, buf.Len())
+
+	out, err := s.decrypt(buf.Bytes())
+	s.Nil(err)
+	s.Equal(strings.Repeat(line, 2*EncryptedChunkSize/len(line)+1), out)
+}
+
+func (s *EncryptSuite) TestFlush() {
+	sw := &syncWriter{}
+	ew := EncryptedWriter(sw, s.public)
+	defer ew.Close()
+
+	l := NewLogger(http.NotFoundHandler(), WithWriter(ew), WithCustomFormat(":method :url"))
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	s.Nil(l.Flush())
+
+	out, err := s.decrypt([]byte(sw.String()))
+	s.Nil(err)
+	s.Equal("GET /a\n", out)
+}
+
+func (s *EncryptSuite) TestWrongKey() {
+	var buf bytes.Buffer
+	ew := EncryptedWriter(&buf, s.public)
+	ew.Write([]byte("GET /\n"))
+	s.Nil(ew.Close())
+
+	public, private, err := box.GenerateKey(rand.Reader)
+	s.Require().Nil(err)
+
+	_, err = io.ReadAll(DecryptedReader(bytes.NewReader(buf.Bytes()), public, private))
+	s.ErrorIs(err, errDecrypt)
+}
+
+func (s *EncryptSuite) TestTruncated() {
+	var buf bytes.Buffer
+	ew := EncryptedWriter(&buf, s.public)
+	ew.Write([]byte("GET /\n"))
+	s.Nil(ew.Close())
+
+	_, err := s.decrypt(buf.Bytes()[:buf.Len()-1])
+	s.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	_, err = s.decrypt([]byte{0xff, 0xff, 0xff, 0xff})
+	s.NotNil(err)
+}
+
+func TestEncrypt(t *testing.T) {
+	suite.Run(t, new(EncryptSuite))
+}
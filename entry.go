@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
+// Entry is the data logged for a request, built once the handler returned
+// and printed by the Formatter of the handler
+type Entry struct {
+	// Request is the request as it's logged, i.e. redacted and with the
+	// client address resolved through trusted proxies
+	Request *http.Request
+
+	Start time.Time
+
+	Host       string
+	Method     string
+	URL        string
+	Proto      string
+	RemoteAddr string
+	RemoteUser string
+	Referer    string
+	UserAgent  string
+	Header     http.Header
+	RequestID  string
+	Route      string
+	TraceID    string
+	SpanID     string
+	// TLS is nil for plain HTTP requests
+	TLS *TLSInfo
+	// ConnID is the ID given to the connection of the request by a
+	// ConnStateHook, empty without one
+	ConnID string
+	// CorrelationID and AmznTraceID are the X-Correlation-ID and
+	// X-Amzn-Trace-Id headers, see WithCorrelationIDs
+	CorrelationID string
+	AmznTraceID   string
+	// Body is nil unless the request body is captured, see WithBodyCapture
+	Body []byte
+	// RequestSize is the number of request body bytes read by the handler,
+	// the Content-Length of the request when it read none
+	RequestSize int64
+	// Range is the Range header of the request, ContentRange the
+	// Content-Range one of the response
+	Range        string
+	ContentRange string
+	// CacheState is the state of the cache in front of the handler, e.g.
+	// CacheHit, as told by the X-Cache, X-Cache-Status or Age headers of
+	// the response, empty when they don't tell
+	CacheState string
+	// Aborted is the error of the request context when it was canceled, or
+	// its deadline exceeded, while the handler ran, e.g. context.Canceled
+	// when the client went away. It's nil otherwise.
+	Aborted error
+
+	Status int
+	// Size is the number of body bytes written, ContentLength the
+	// Content-Length declared by the response, -1 when there's none. They
+	// differ e.g. for HEAD responses.
+	Size          int
+	ContentLength int64
+	Hijacked      bool
+	TTFB          time.Duration
+	Duration      time.Duration
+	// ContentEncoding is the Content-Encoding of the response, BodySize the
+	// size of its body before it's encoded: the one reported with
+	// AddBodySize, read from the gzip trailer, or Size when the logger sees
+	// the body before a wrapping middleware encodes it. It's -1 when it
+	// can't be derived, Size for responses which aren't encoded.
+	ContentEncoding string
+	BodySize        int64
+	// Empty is set for responses without a body where one was expected,
+	// so they can be told apart from 304 Not Modified, 204 No Content and
+	// HEAD responses, which have none either
+	Empty bool
+	// Flushes is the number of times the handler flushed the response,
+	// Streamed is set when it did at least once, e.g. for server-sent
+	// events. TTFB is then when the stream started and Duration how long
+	// it lasted.
+	Flushes  int
+	Streamed bool
+	// Preliminary is set on the entry logged when a streamed response
+	// started, before the one logged when it ended, see WithStreamEntries
+	Preliminary bool
+	// WebSocket is nil unless the request was upgraded to a WebSocket
+	// session, see WithWebSocketSessions
+	WebSocket *WebSocketSession
+	// ResponseHeader is nil unless response headers are logged, see
+	// WithLoggedResponseHeaders
+	ResponseHeader http.Header
+	// Trailer holds the response trailers, nil when there are none
+	Trailer http.Header
+	// ResponseBody is nil unless the response body is captured, see
+	// WithResponseBodyCapture
+	ResponseBody []byte
+
+	Panicked   bool
+	PanicValue string
+	Stack      []byte
+
+	// Fields are the fields added by AddField and WithFields, nil when
+	// there are none unless hooks are set, so they can add some
+	Fields map[string]interface{}
+	Level  Level
+
+	durations  durationFormat
+	timeLayout string
+	// header is the whole response header, for the :res[name] tokens
+	header http.Header
+}
+
+// Formatter prints entries, see WithFormatter
+type Formatter interface {
+	Format(w io.Writer, e *Entry) error
+}
+
+// FormatterFunc is a function used as a Formatter
+type FormatterFunc func(w io.Writer, e *Entry) error
+
+// Format calls f(w, e)
+func (f FormatterFunc) Format(w io.Writer, e *Entry) error {
+	return f(w, e)
+}
+
+// entry returns the entry of the request served with rl
+func (rh loggerHanlder) entry(rl *responseLogger, req *http.Request) *Entry {
+	// before the Authorization header is redacted
+	user := rh.remoteUser(req)
+	req = rh.logged(req)
+
+	start := rl.start
+	if rh.utc {
+		start = start.UTC()
+	}
+
+	e := &Entry{
+		Request: req,
+		Start:   start,
+
+		Host:       req.Host,
+		Method:     req.Method,
+		URL:        req.RequestURI,
+		Proto:      req.Proto,
+		RemoteAddr: req.RemoteAddr,
+		RemoteUser: user,
+		Referer:    req.Referer(),
+		UserAgent:  req.UserAgent(),
+		Header:     req.Header,
+		RequestID:  rl.requestID,
+		Route:      rl.route,
+		TraceID:    rl.traceID,
+		SpanID:     rl.spanID,
+		TLS:        tlsInfo(req.TLS),
+		ConnID:     rl.connID,
+
+		CorrelationID: rl.correlationID,
+		AmznTraceID:   rl.amznTraceID,
+		RequestSize:   requestSize(rl.reqBody.n, req),
+		Aborted:       rl.aborted,
+
+		Status:   rl.status,
+		Size:     rl.size,
+		Hijacked: rl.hijacked,
+		TTFB:     rl.ttfb,
+		Duration: rl.duration,
+
+		Flushes:     rl.flushes,
+		Streamed:    rl.flushes > 0,
+		Preliminary: rl.preliminary,
+
+		Panicked:   rl.panicked,
+		PanicValue: rl.panicValue,
+		Stack:      rl.stack,
+
+		Level: rh.level(rl.status),
+
+		durations:  rl.durations,
+		timeLayout: rh.timeLayout,
+	}
+
+	header := rl.responseHeader()
+	e.ContentLength = contentLength(header)
+	e.header = header
+	e.Trailer = trailers(header)
+	e.Range = req.Header.Get("Range")
+	e.ContentRange = header.Get("Content-Range")
+	e.CacheState = cacheState(header)
+	e.Empty = emptyResponse(e)
+	e.ContentEncoding = contentEncoding(header)
+	e.BodySize = rl.bodySize(e.ContentEncoding)
+
+	if rh.requestHeaders != nil {
+		e.Header = rh.requestHeaders.filter(req.Header)
+	}
+
+	if rh.responseHeaders != nil {
+		e.ResponseHeader = rh.responseHeaders.filter(header)
+	}
+
+	if rl.session != nil {
+		e.WebSocket = rl.session.info()
+	}
+
+	if rl.body != nil {
+		e.Body = []byte(rl.body.String())
+	}
+
+	if rl.resBody != nil {
+		e.ResponseBody = []byte(rl.resBody.String())
+	}
+
+	if n := rl.custom.len() + len(rh.fields); n > 0 || len(rh.hooks) > 0 {
+		e.Fields = make(map[string]interface{}, n)
+	}
+
+	// fields added during the request win over the static ones
+	rl.custom.each(func(k string, v interface{}) {
+		e.Fields[k] = v
+	})
+
+	for k, v := range rh.fields {
+		if _, ok := e.Fields[k]; !ok {
+			e.Fields[k] = v
+		}
+	}
+
+	if rh.masker.enabled() {
+		rh.masker.entry(e)
+	}
+
+	return e
+}
+
+// newFormatter returns the Formatter of the built-in log output types
+func (rh loggerHanlder) newFormatter() Formatter {
+	if rh.encoder != 0 {
+		return rh.encoder.newFormatter()
+	}
+
+	switch rh.formatType {
+	case JsonLoggerType:
+		return jsonFormatter{rh.backend}
+	case SlogLoggerType:
+		return slogFormatter{rh.slog}
+	case W3CLoggerType:
+		return w3cFormatter{rh.directives, rh.clock}
+	case GELFLoggerType:
+		return newGELFFormatter()
+	case CEFLoggerType:
+		return cefFormatter{}
+	case LEEFLoggerType:
+		return leefFormatter{}
+	case GCPLoggerType:
+		return newGCPFormatter(rh.gcpProject)
+	case NDJSONLoggerType:
+		return ndjsonFormatter{}
+	case CSVLoggerType, TSVLoggerType:
+		comma := ','
+		if rh.formatType == TSVLoggerType {
+			comma = '\t'
+		}
+
+		columns := rh.columns
+		if columns == nil {
+			columns = compileColumns(DefaultColumns)
+		}
+
+		return csvFormatter{columns, comma, rh.csvHeader, rh.directives}
+	}
+
+	tokens := rh.tokens
+	if tokens == nil {
+		tokens = formats[rh.formatType]
+	}
+
+	if tokens == nil {
+		return nil
+	}
+
+	return textFormatter{tokens}
+}
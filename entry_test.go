@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EntrySuite struct {
+	suite.Suite
+}
+
+func (s *EntrySuite) TestEntry() {
+	var got *Entry
+	start := time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		AddField(req.Context(), "service", "override")
+		res.WriteHeader(http.StatusCreated)
+		res.Write([]byte("created"))
+	}), WithClock(testClock{start}), WithFields(map[string]interface{}{"service": "api", "env": "test"}),
+		WithFormatter(FormatterFunc(func(w io.Writer, e *Entry) error {
+			got = e
+
+			return nil
+		})))
+
+	req := httptest.NewRequest(http.MethodPost, "/users?id=1", strings.NewReader("{}"))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "test-agent")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.NotNil(got)
+	s.Equal(start, got.Start)
+	s.Equal(http.MethodPost, got.Method)
+	s.Equal("/users?id=1", got.URL)
+	s.Equal("HTTP/1.1", got.Proto)
+	s.Equal("example.com", got.Host)
+	s.Equal("192.0.2.1:1234", got.RemoteAddr)
+	s.Equal("test-agent", got.UserAgent)
+	s.Equal(http.StatusCreated, got.Status)
+	s.Equal(7, got.Size)
+	s.Equal(LevelInfo, got.Level)
+	s.Nil(got.Body)
+	s.Nil(got.ResponseBody)
+	s.Equal(map[string]interface{}{"service": "override", "env": "test"}, got.Fields)
+}
+
+func (s *EntrySuite) TestCustomFormatter() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormatter(FormatterFunc(func(w io.Writer, e *Entry) error {
+		_, err := fmt.Fprintf(w, "%s %s -> %d\n", e.Method, e.URL, e.Status)
+
+		return err
+	})))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	s.Equal("GET /missing -> 404\n", string(tw.Bytes))
+}
+
+func (s *EntrySuite) TestFormatOverridesFormatter() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormatter(FormatterFunc(func(io.Writer, *Entry) error {
+		return nil
+	})), WithCustomFormat(":status"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("404\n", string(tw.Bytes))
+}
+
+func (s *EntrySuite) TestHooks() {
+	tw := testWriter{}
+	order := []string{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":url :custom[tenant] :custom[secret]"),
+		WithFields(map[string]interface{}{"secret": "s3cr3t"}),
+		WithHook(func(e *Entry) {
+			order = append(order, "first")
+			e.URL = "/users/{id}"
+			delete(e.Fields, "secret")
+		}),
+		WithHook(func(e *Entry) {
+			order = append(order, "second")
+			e.Fields["tenant"] = "acme"
+		}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	s.Equal([]string{"first", "second"}, order)
+	s.Equal("/users/{id} acme -\n", string(tw.Bytes))
+}
+
+func TestEntry(t *testing.T) {
+	suite.Run(t, new(EntrySuite))
+}
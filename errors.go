@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrQueueFull is passed to the handler of WithErrorHandler for the
+// entries dropped by OverflowDrop
+var ErrQueueFull = errors.New("logger: async queue full")
+
+// counters counts the entries written and those which could not be, whose
+// errors are reported to the handler of WithErrorHandler. It's shared by
+// the outputs and the route overrides of a handler.
+type counters struct {
+	written     atomic.Uint64
+	dropped     atomic.Uint64
+	writeErrors atomic.Uint64
+}
+
+// failed counts an entry lost to err, the error of the writer or
+// ErrQueueFull
+func (rh loggerHanlder) failed(err error) {
+	if errors.Is(err, ErrQueueFull) {
+		rh.counters.dropped.Add(1)
+	} else {
+		rh.counters.writeErrors.Add(1)
+	}
+
+	if rh.onError != nil {
+		rh.onError(err)
+	}
+}
+
+// Dropped returns the number of entries which could not be written, their
+// writer failing or the WithAsync queue being full under OverflowDrop. The
+// http.Handler returned by New implements it.
+func (rh loggerHanlder) Dropped() uint64 {
+	return rh.counters.dropped.Load() + rh.counters.writeErrors.Load()
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ErrorsSuite struct {
+	suite.Suite
+}
+
+func (s *ErrorsSuite) TestHandler() {
+	w := &failingWriter{failing: true}
+
+	var errs []error
+	h := New(http.NotFoundHandler(), WithWriter(w), WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	w.failing = false
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Len(errs, 2)
+	s.EqualError(errs[0], "connection refused")
+	s.Equal(uint64(2), h.(loggerHanlder).Dropped())
+	s.Contains(w.String(), `"GET / HTTP/1.1" 404`)
+}
+
+func (s *ErrorsSuite) TestSilent() {
+	l := NewLogger(http.NotFoundHandler(), WithWriter(&failingWriter{failing: true}))
+
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(uint64(1), l.Dropped())
+	s.Nil(l.Close(context.Background()))
+}
+
+func (s *ErrorsSuite) TestAsync() {
+	var mu sync.Mutex
+	var errs []error
+
+	h := New(http.NotFoundHandler(), WithWriter(&failingWriter{failing: true}), WithAsync(10),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(h.(loggerHanlder).Flush())
+
+	s.Len(errs, 1)
+	s.EqualError(errs[0], "connection refused")
+	s.Equal(uint64(1), h.(loggerHanlder).Dropped())
+}
+
+func (s *ErrorsSuite) TestQueueFull() {
+	bw := &blockingWriter{release: make(chan struct{})}
+
+	var mu sync.Mutex
+	var errs []error
+
+	h := New(http.NotFoundHandler(), WithWriter(bw), WithAsync(1), WithOverflowPolicy(OverflowDrop),
+		WithErrorHandler(func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}))
+
+	for i := 0; i < 10; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	mu.Lock()
+	s.True(len(errs) >= 8)
+	s.Equal(ErrQueueFull, errs[0])
+	mu.Unlock()
+	s.True(h.(loggerHanlder).Dropped() >= 8)
+
+	close(bw.release)
+	s.Nil(h.(loggerHanlder).Close())
+}
+
+func (s *ErrorsSuite) TestOutputs() {
+	var errs []error
+	onError := WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	})
+
+	h := New(http.NotFoundHandler(), onError, WithTargets(
+		Target{Writer: &failingWriter{failing: true}, Type: TinyLoggerType},
+		Target{Writer: &testWriter{}, Type: TinyLoggerType},
+	))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Len(errs, 1)
+	s.Equal(uint64(1), h.(loggerHanlder).Dropped())
+}
+
+func TestErrors(t *testing.T) {
+	suite.Run(t, new(ErrorsSuite))
+}
@@ -0,0 +1,168 @@
+//go:build windows
+
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// event types of ReportEvent
+const (
+	eventlogError       = 0x0001
+	eventlogWarning     = 0x0002
+	eventlogInformation = 0x0004
+)
+
+// eventlogID is the event ID of the entries, the message files of
+// EventCreate.exe print the string of the IDs 1 to 1000 as is
+const eventlogID = 1
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSourceW  = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEventW          = advapi32.NewProc("ReportEventW")
+)
+
+// eventLogWriter reports every Write as one event of the Windows Event Log
+type eventLogWriter struct {
+	mu     sync.Mutex
+	handle syscall.Handle
+}
+
+// EventLogWriter returns an io.WriteCloser reporting each write as an
+// information event of source in the Application log. See EventLogTarget
+// for events at the level of the entry.
+//
+// The source should be registered beforehand, e.g. with
+// `eventcreate /l APPLICATION /so api /t information /id 1 /d installed`
+// or New-EventLog, so Event Viewer shows the messages without a warning.
+func EventLogWriter(source string) (io.WriteCloser, error) {
+	return newEventLogWriter(source)
+}
+
+func newEventLogWriter(source string) (*eventLogWriter, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+
+	h, _, err := procRegisterEventSourceW.Call(0, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return nil, err
+	}
+
+	return &eventLogWriter{handle: syscall.Handle(h)}, nil
+}
+
+// EventLogTarget returns a Target reporting every request as an event of
+// source in the Application log, an error event for 5xx responses, a
+// warning for 4xx and an information event otherwise, following the level
+// of the entry. The message is the line printed by the formatter of the
+// Target's Type and Options, default to CombineLoggerType.
+func EventLogTarget(source string) (Target, error) {
+	ew, err := newEventLogWriter(source)
+	if err != nil {
+		return Target{}, err
+	}
+
+	return Target{
+		Writer:  ew,
+		Type:    CombineLoggerType,
+		Options: []Option{eventLogFormatter(ew)},
+	}, nil
+}
+
+// eventLogFormatter reports the entries to ew at their level
+func eventLogFormatter(ew *eventLogWriter) Option {
+	return func(rh *loggerHanlder) {
+		rh.wrapFormatter = func(line Formatter) Formatter {
+			switch line.(type) {
+			case nil, jsonFormatter, slogFormatter:
+				// they don't print to the writer
+				line = textFormatter{formats[CombineLoggerType]}
+			}
+
+			return FormatterFunc(func(_ io.Writer, e *Entry) error {
+				var b bytes.Buffer
+				if err := line.Format(&b, e); err != nil {
+					return err
+				}
+
+				return ew.report(e.Level.eventType(), b.String())
+			})
+		}
+		rh.owned = append(rh.owned, ew)
+	}
+}
+
+// eventType returns the event type the entries of the level are reported
+// as
+func (l Level) eventType() uint16 {
+	switch l {
+	case LevelWarn:
+		return eventlogWarning
+	case LevelError:
+		return eventlogError
+	}
+
+	return eventlogInformation
+}
+
+func (ew *eventLogWriter) Write(p []byte) (int, error) {
+	if err := ew.report(eventlogInformation, string(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// report reports msg as an event of type etype
+func (ew *eventLogWriter) report(etype uint16, msg string) error {
+	// a NUL would end the message
+	msg = strings.ReplaceAll(strings.TrimRight(msg, "\r\n"), "\x00", "")
+
+	s, err := syscall.UTF16PtrFromString(msg)
+	if err != nil {
+		return err
+	}
+
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.handle == 0 {
+		return errors.New("logger: event log closed")
+	}
+
+	ok, _, err := procReportEventW.Call(uintptr(ew.handle), uintptr(etype), 0, eventlogID, 0, 1, 0,
+		uintptr(unsafe.Pointer(&s)), 0)
+	if ok == 0 {
+		return err
+	}
+
+	return nil
+}
+
+func (ew *eventLogWriter) Close() error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.handle == 0 {
+		return nil
+	}
+
+	ok, _, err := procDeregisterEventSource.Call(uintptr(ew.handle))
+	ew.handle = 0
+
+	if ok == 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EventLogSuite struct {
+	suite.Suite
+}
+
+func (s *EventLogSuite) TestEventType() {
+	s.Equal(uint16(eventlogInformation), DefaultLevel(http.StatusOK).eventType())
+	s.Equal(uint16(eventlogInformation), LevelDebug.eventType())
+	s.Equal(uint16(eventlogWarning), DefaultLevel(http.StatusNotFound).eventType())
+	s.Equal(uint16(eventlogError), DefaultLevel(http.StatusBadGateway).eventType())
+}
+
+func (s *EventLogSuite) TestTarget() {
+	target, err := EventLogTarget("logger-test")
+	s.Require().Nil(err)
+
+	var errs []error
+	h := NewLogger(http.NotFoundHandler(), WithTargets(target), WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Empty(errs)
+	s.Nil(h.Close(context.Background()))
+}
+
+func (s *EventLogSuite) TestClosed() {
+	w, err := EventLogWriter("logger-test")
+	s.Require().Nil(err)
+	s.Nil(w.Close())
+
+	_, err = w.Write([]byte("x"))
+	s.NotNil(err)
+	s.Nil(w.Close())
+}
+
+func TestEventLog(t *testing.T) {
+	suite.Run(t, new(EventLogSuite))
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/go-http-utils/logger"
 )
 
-func Example_DefaultHandler() {
+func ExampleDefaultHandler() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
 		res.Write([]byte("Hello World"))
@@ -16,7 +16,7 @@ func Example_DefaultHandler() {
 	http.ListenAndServe(":8080", logger.DefaultHandler(mux))
 }
 
-func Example_Handler() {
+func ExampleHandler() {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
 		res.Write([]byte("Hello World"))
@@ -25,3 +25,25 @@ func Example_Handler() {
 	http.ListenAndServe(":8080", logger.Handler(mux, os.Stdout,
 		logger.DevLoggerType))
 }
+
+func ExampleHandlerWithFormat() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("Hello World"))
+	})
+
+	http.ListenAndServe(":8080", logger.HandlerWithFormat(mux, os.Stdout,
+		":remote-addr :method :url :status :response-time ms"))
+}
+
+func ExampleNew() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("Hello World"))
+	})
+
+	http.ListenAndServe(":8080", logger.New(mux,
+		logger.WithWriter(os.Stderr),
+		logger.WithFormat(logger.JsonLoggerType),
+		logger.WithFields(map[string]interface{}{"service": "hello"})))
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// failoverWriter writes to primary, or to secondary when primary fails
+type failoverWriter struct {
+	primary   io.Writer
+	secondary io.Writer
+	interval  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	warned   time.Time
+}
+
+// FailoverWriter returns an io.Writer writing to primary, e.g. a network
+// sink or a pipe, and to secondary, e.g. os.Stderr, the writes primary
+// fails, so entries are never lost silently. A warning line with the
+// number of failures and the last error is written to secondary before
+// the failed entries, at most once every warnInterval.
+func FailoverWriter(primary, secondary io.Writer, warnInterval time.Duration) io.Writer {
+	return &failoverWriter{primary: primary, secondary: secondary, interval: warnInterval, now: time.Now}
+}
+
+func (fw *failoverWriter) Write(p []byte) (int, error) {
+	n, err := fw.primary.Write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	fw.failures++
+	if now := fw.now(); now.Sub(fw.warned) >= fw.interval {
+		fmt.Fprintf(fw.secondary, "logger: %d writes failed on the primary writer, last error: %v\n", fw.failures, err)
+		fw.failures, fw.warned = 0, now
+	}
+
+	return fw.secondary.Write(p)
+}
+
+// Flush flushes the writers which buffer
+func (fw *failoverWriter) Flush() error {
+	errs := []error{}
+
+	for _, w := range []io.Writer{fw.primary, fw.secondary} {
+		if f, ok := w.(interface{ Flush() error }); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// failingWriter fails every write while failing is set
+type failingWriter struct {
+	bytes.Buffer
+	failing bool
+}
+
+func (fw *failingWriter) Write(p []byte) (int, error) {
+	if fw.failing {
+		return 0, errors.New("connection refused")
+	}
+
+	return fw.Buffer.Write(p)
+}
+
+type FailoverSuite struct {
+	suite.Suite
+}
+
+func (s *FailoverSuite) TestFailover() {
+	primary, secondary := &failingWriter{}, &bytes.Buffer{}
+	clock := &stepClock{now: time.Unix(0, 0)}
+	w := FailoverWriter(primary, secondary, time.Minute).(*failoverWriter)
+	w.now = clock.Now
+
+	w.Write([]byte("a\n"))
+	s.Equal("a\n", primary.String())
+	s.Empty(secondary.String())
+
+	primary.failing = true
+	n, err := w.Write([]byte("b\n"))
+	s.Nil(err)
+	s.Equal(2, n)
+	w.Write([]byte("c\n"))
+
+	clock.now = clock.now.Add(time.Minute)
+	w.Write([]byte("d\n"))
+
+	s.Equal("logger: 1 writes failed on the primary writer, last error: connection refused\nb\nc\n"+
+		"logger: 2 writes failed on the primary writer, last error: connection refused\nd\n", secondary.String())
+
+	primary.failing = false
+	w.Write([]byte("e\n"))
+	s.Equal("a\ne\n", primary.String())
+}
+
+func (s *FailoverSuite) TestHandler() {
+	primary, secondary := &failingWriter{failing: true}, &bytes.Buffer{}
+	h := New(http.NotFoundHandler(), WithWriter(FailoverWriter(primary, secondary, time.Hour)),
+		WithFormat(TinyLoggerType), WithClock(testClock{}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Contains(secondary.String(), "GET / 404 19 - 0.000 ms\n")
+}
+
+func TestFailover(t *testing.T) {
+	suite.Run(t, new(FailoverSuite))
+}
@@ -0,0 +1,71 @@
+// Package fakeclock provides a logger.Clock for deterministic tests of the
+// logger middleware and of custom formatters, see Clock.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a logger.Clock whose time only moves when told to, so the
+// timestamps and durations of the log output are known in advance, e.g.:
+//
+//	clock := fakeclock.New(time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC))
+//	h := logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+//		clock.Advance(150 * time.Millisecond)
+//	}), logger.WithClock(clock))
+//
+// logs requests started at 15:04:05 which took 150ms. It's safe for
+// concurrent use.
+type Clock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+// New returns a Clock set to now
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time of the clock, then moves it forward by the step of
+// SetStep
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now
+	c.now = c.now.Add(c.step)
+
+	return now
+}
+
+// Since returns the time elapsed on the clock since t, without moving it
+func (c *Clock) Since(t time.Time) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now.Sub(t)
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// Set sets the time of the clock
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// SetStep makes every call of Now move the clock forward by d, the
+// requests of a handler which doesn't advance the clock then take d
+func (c *Clock) SetStep(d time.Duration) {
+	c.mu.Lock()
+	c.step = d
+	c.mu.Unlock()
+}
@@ -0,0 +1,71 @@
+package fakeclock
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClockSuite struct {
+	suite.Suite
+
+	start time.Time
+}
+
+func (s *ClockSuite) SetupTest() {
+	s.start = time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+}
+
+func (s *ClockSuite) TestClock() {
+	c := New(s.start)
+
+	s.Equal(s.start, c.Now())
+	s.Equal(s.start, c.Now())
+
+	c.Advance(time.Second)
+	s.Equal(time.Second, c.Since(s.start))
+	s.Equal(s.start.Add(time.Second), c.Now())
+
+	c.Set(s.start)
+	c.SetStep(time.Minute)
+	s.Equal(s.start, c.Now())
+	s.Equal(time.Minute, c.Since(s.start))
+	s.Equal(s.start.Add(time.Minute), c.Now())
+}
+
+func (s *ClockSuite) TestAdvance() {
+	var b bytes.Buffer
+	c := New(s.start)
+
+	h := logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		c.Advance(150 * time.Millisecond)
+		res.WriteHeader(http.StatusOK)
+	}), logger.WithWriter(&b), logger.WithClock(c), logger.WithFormat(logger.DevLoggerType))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 200 150.000 ms - 0\n", b.String())
+}
+
+func (s *ClockSuite) TestStep() {
+	var b bytes.Buffer
+	c := New(s.start)
+	c.SetStep(10 * time.Millisecond)
+
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(&b), logger.WithClock(c),
+		logger.WithFormat(logger.TinyLoggerType))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 404 19 - 10.000 ms\nGET / 404 19 - 10.000 ms\n", b.String())
+}
+
+func TestClock(t *testing.T) {
+	suite.Run(t, new(ClockSuite))
+}
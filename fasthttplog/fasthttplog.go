@@ -0,0 +1,103 @@
+// Package fasthttplog logs the requests of a fasthttp server through the
+// formats and options of the logger package. fasthttp has its own request
+// context, so each request is logged from a net/http copy of it, with the
+// status, headers and size of the fasthttp response once the handler
+// returned.
+package fasthttplog
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-http-utils/logger"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+type contextKey int
+
+const (
+	callKey contextKey = iota
+	requestContextKey
+)
+
+// call is a fasthttp request being logged
+type call struct {
+	ctx *fasthttp.RequestCtx
+	h   fasthttp.RequestHandler
+}
+
+// Handler returns a fasthttp.RequestHandler that wraps h and logs every
+// request, configured by opts like logger.New
+func Handler(h fasthttp.RequestHandler, opts ...logger.Option) fasthttp.RequestHandler {
+	lh := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		req := &http.Request{}
+		if err := fasthttpadaptor.ConvertRequest(ctx, req, true); err != nil {
+			h(ctx)
+
+			return
+		}
+
+		lh.ServeHTTP(&discardWriter{}, req.WithContext(context.WithValue(ctx, callKey, &call{ctx, h})))
+	}
+}
+
+// Context returns the context of the request of ctx being logged, e.g. for
+// logger.AddField, or context.Background() outside of a Handler
+func Context(ctx *fasthttp.RequestCtx) context.Context {
+	if c, ok := ctx.UserValue(requestContextKey).(context.Context); ok {
+		return c
+	}
+
+	return context.Background()
+}
+
+// serve runs the call of req as the handler wrapped by the logger
+func serve(res http.ResponseWriter, req *http.Request) {
+	c := req.Context().Value(callKey).(*call)
+
+	c.ctx.SetUserValue(requestContextKey, req.Context())
+	defer c.ctx.RemoveUserValue(requestContextKey)
+
+	c.h(c.ctx)
+
+	c.ctx.Response.Header.VisitAll(func(k, v []byte) {
+		res.Header().Add(string(k), string(v))
+	})
+	res.WriteHeader(c.ctx.Response.StatusCode())
+	writeN(res, len(c.ctx.Response.Body()))
+}
+
+// discardWriter is the http.ResponseWriter requests are served with,
+// fasthttp writing the actual response, it only lets the logger record it
+type discardWriter struct {
+	header http.Header
+}
+
+func (dw *discardWriter) Header() http.Header {
+	if dw.header == nil {
+		dw.header = http.Header{}
+	}
+
+	return dw.header
+}
+
+func (dw *discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (dw *discardWriter) WriteHeader(int) {}
+
+var zeros [4096]byte
+
+// writeN writes n bytes to w so the logger records the size of the
+// response body
+func writeN(w http.ResponseWriter, n int) {
+	for n > 0 {
+		chunk := min(n, len(zeros))
+		w.Write(zeros[:chunk])
+		n -= chunk
+	}
+}
@@ -0,0 +1,73 @@
+package fasthttplog
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+	"github.com/valyala/fasthttp"
+)
+
+type FasthttpSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+}
+
+func (s *FasthttpSuite) SetupTest() {
+	s.buf.Reset()
+}
+
+func (s *FasthttpSuite) serve(h fasthttp.RequestHandler, method, uri string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	req.Header.Set("User-Agent", "fasthttp-test")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}, nil)
+
+	h(ctx)
+
+	return ctx
+}
+
+func (s *FasthttpSuite) TestHandler() {
+	h := Handler(func(ctx *fasthttp.RequestCtx) {
+		logger.AddField(Context(ctx), "tenant", "acme")
+
+		ctx.SetStatusCode(http.StatusCreated)
+		ctx.SetContentType("text/plain")
+		ctx.SetBodyString("hello")
+	}, logger.WithWriter(&s.buf),
+		logger.WithCustomFormat(":remote-addr :method :url :status :res[content-type] :res[content-length] :user-agent :custom[tenant]"))
+
+	ctx := s.serve(h, http.MethodPost, "/users?page=2")
+
+	s.Equal(http.StatusCreated, ctx.Response.StatusCode())
+	s.Equal("192.0.2.1:1234 POST /users?page=2 201 text/plain 5 fasthttp-test acme\n", s.buf.String())
+}
+
+func (s *FasthttpSuite) TestJSON() {
+	h := Handler(func(ctx *fasthttp.RequestCtx) {
+		ctx.NotFound()
+	}, logger.WithWriter(&s.buf), logger.WithFormat(logger.NDJSONLoggerType))
+
+	s.serve(h, http.MethodGet, "/missing")
+
+	s.Contains(s.buf.String(), `"request.method":"GET"`)
+	s.Contains(s.buf.String(), `"response.status":404`)
+}
+
+func (s *FasthttpSuite) TestContext() {
+	s.NotNil(Context(&fasthttp.RequestCtx{}))
+}
+
+func TestFasthttp(t *testing.T) {
+	suite.Run(t, new(FasthttpSuite))
+}
@@ -0,0 +1,97 @@
+// Package fiberadapter logs the requests of a fiber app through the
+// formats and options of the logger package, as a fiber middleware. Fiber
+// is built on fasthttp, so each request is logged from a net/http copy of
+// it, with the status, headers and size of the fiber response once the
+// chain returned. The route is the path of the matched fiber route, e.g.
+// "/users/:id".
+package fiberadapter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-http-utils/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+type contextKey int
+
+const ctxKey contextKey = iota
+
+// Middleware returns a fiber middleware logging every request, configured
+// by opts like logger.New. The handlers down the chain get the context of
+// the logged request as their user context, e.g. for
+// logger.AddField(c.UserContext(), ...).
+//
+// Errors returned down the chain are handled by the error handler of the
+// app, so the status logged is the one of the error response, and aren't
+// returned further.
+func Middleware(opts ...logger.Option) fiber.Handler {
+	h := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(c *fiber.Ctx) error {
+		req := &http.Request{}
+		if err := fasthttpadaptor.ConvertRequest(c.Context(), req, true); err != nil {
+			return c.Next()
+		}
+
+		h.ServeHTTP(&discardWriter{}, req.WithContext(context.WithValue(c.UserContext(), ctxKey, c)))
+
+		return nil
+	}
+}
+
+// serve runs the rest of the chain of the fiber context of req as the
+// handler wrapped by the logger
+func serve(res http.ResponseWriter, req *http.Request) {
+	c := req.Context().Value(ctxKey).(*fiber.Ctx)
+
+	c.SetUserContext(req.Context())
+
+	if err := c.Next(); err != nil {
+		if err := c.App().ErrorHandler(c, err); err != nil {
+			c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+
+	req.Pattern = c.Route().Path
+
+	c.Response().Header.VisitAll(func(k, v []byte) {
+		res.Header().Add(string(k), string(v))
+	})
+	res.WriteHeader(c.Response().StatusCode())
+	writeN(res, len(c.Response().Body()))
+}
+
+// discardWriter is the http.ResponseWriter requests are served with, fiber
+// writing the actual response, it only lets the logger record it
+type discardWriter struct {
+	header http.Header
+}
+
+func (dw *discardWriter) Header() http.Header {
+	if dw.header == nil {
+		dw.header = http.Header{}
+	}
+
+	return dw.header
+}
+
+func (dw *discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (dw *discardWriter) WriteHeader(int) {}
+
+var zeros [4096]byte
+
+// writeN writes n bytes to w so the logger records the size of the
+// response body
+func writeN(w http.ResponseWriter, n int) {
+	for n > 0 {
+		chunk := min(n, len(zeros))
+		w.Write(zeros[:chunk])
+		n -= chunk
+	}
+}
@@ -0,0 +1,65 @@
+package fiberadapter
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/suite"
+)
+
+type FiberSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+	app *fiber.App
+}
+
+func (s *FiberSuite) SetupTest() {
+	s.buf.Reset()
+
+	s.app = fiber.New()
+	s.app.Use(Middleware(logger.WithWriter(&s.buf),
+		logger.WithCustomFormat(":method :url :route :status :res[content-length] :custom[tenant]")))
+}
+
+func (s *FiberSuite) serve(method, url string) *http.Response {
+	res, err := s.app.Test(httptest.NewRequest(method, url, nil))
+	s.Require().Nil(err)
+
+	return res
+}
+
+func (s *FiberSuite) TestMiddleware() {
+	s.app.Get("/users/:id", func(c *fiber.Ctx) error {
+		logger.AddField(c.UserContext(), "tenant", "acme")
+
+		return c.Status(http.StatusCreated).SendString("hello " + c.Params("id"))
+	})
+
+	res := s.serve(http.MethodGet, "/users/42?page=2")
+	body, _ := io.ReadAll(res.Body)
+
+	s.Equal(http.StatusCreated, res.StatusCode)
+	s.Equal("hello 42", string(body))
+	s.Equal("GET /users/42?page=2 /users/:id 201 8 acme\n", s.buf.String())
+}
+
+func (s *FiberSuite) TestError() {
+	s.app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return fiber.ErrForbidden
+	})
+
+	res := s.serve(http.MethodGet, "/users/42")
+
+	s.Equal(http.StatusForbidden, res.StatusCode)
+	s.Equal("GET /users/42 /users/:id 403 9 -\n", s.buf.String())
+}
+
+func TestFiber(t *testing.T) {
+	suite.Run(t, new(FiberSuite))
+}
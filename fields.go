@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// requestFields holds the fields added by handlers during a request
+type requestFields struct {
+	mu     sync.Mutex
+	keys   []string
+	values map[string]interface{}
+
+	// bodySize is reported by AddBodySize
+	bodySize    int64
+	bodySizeSet bool
+}
+
+// AddField attaches a field to the log entry of the request ctx belongs
+// to, e.g. the tenant or user ID or a cache hit. It shows up in the
+// structured log outputs and as the :custom[key] token. Adding a key again
+// replaces its value. It does nothing for contexts not coming from the
+// middleware, and must not be called once the handler returned.
+func AddField(ctx context.Context, key string, value interface{}) {
+	rf, ok := ctx.Value(fieldsKey).(*requestFields)
+	if !ok {
+		return
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if _, ok := rf.values[key]; !ok {
+		rf.keys = append(rf.keys, key)
+	}
+
+	rf.values[key] = value
+}
+
+func (rh loggerHanlder) withFields(req *http.Request, rl *responseLogger) *http.Request {
+	if rl.fields.values == nil {
+		rl.fields.values = map[string]interface{}{}
+	}
+	rl.custom = &rl.fields
+
+	return req.WithContext(context.WithValue(req.Context(), fieldsKey, rl.custom))
+}
+
+// len returns the number of added fields
+func (rf *requestFields) len() int {
+	if rf == nil {
+		return 0
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return len(rf.keys)
+}
+
+// each calls f with the added fields in the order they were first added
+func (rf *requestFields) each(f func(key string, value interface{})) {
+	if rf == nil {
+		return
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	for _, key := range rf.keys {
+		f(key, rf.values[key])
+	}
+}
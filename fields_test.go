@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FieldsSuite struct {
+	suite.Suite
+
+	h http.Handler
+}
+
+func (s *FieldsSuite) SetupTest() {
+	s.h = http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		AddField(req.Context(), "tenant", "acme")
+		AddField(req.Context(), "cache", "miss")
+		AddField(req.Context(), "cache", "hit")
+		AddField(req.Context(), "request.method", "overridden")
+	})
+}
+
+func (s *FieldsSuite) TestToken() {
+	tw := testWriter{}
+	h := New(s.h, WithWriter(&tw), WithCustomFormat(":custom[tenant] :custom[cache] :custom[user] :custom[]"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("acme hit - :custom[]\n", string(tw.Bytes))
+}
+
+func (s *FieldsSuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(s.h, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("acme", entry["tenant"])
+	s.Equal("hit", entry["cache"])
+	s.Equal("GET", entry["request.method"])
+}
+
+func (s *FieldsSuite) TestOrder() {
+	rf := &requestFields{values: map[string]interface{}{}}
+	ctx := context.WithValue(context.Background(), fieldsKey, rf)
+
+	AddField(ctx, "b", 1)
+	AddField(ctx, "a", 2)
+	AddField(ctx, "b", 3)
+
+	keys := []string{}
+	rf.each(func(k string, v interface{}) {
+		keys = append(keys, k)
+	})
+
+	s.Equal([]string{"b", "a"}, keys)
+	s.Equal(3, rf.values["b"])
+}
+
+func (s *FieldsSuite) TestOutsideMiddleware() {
+	s.NotPanics(func() {
+		AddField(context.Background(), "tenant", "acme")
+	})
+}
+
+func TestFields(t *testing.T) {
+	suite.Run(t, new(FieldsSuite))
+}
@@ -0,0 +1,337 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// fluentAckTimeout is how long a FluentWriter in ack mode waits for the
+// acknowledgment of a message
+const fluentAckTimeout = 10 * time.Second
+
+// ErrFluentAck is returned when Fluentd acknowledges another chunk than
+// the one sent
+var ErrFluentAck = errors.New("logger: unexpected fluentd ack")
+
+// fluentWriter sends every Write as one Fluentd Forward protocol message,
+// reconnecting when the connection fails
+type fluentWriter struct {
+	network string
+	addr    string
+	tag     string
+	ack     bool
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// FluentWriter returns an io.WriteCloser sending each write as a message
+// of the Fluentd Forward protocol tagged with tag, over network ("tcp" or
+// "unix") to addr, e.g. a Fluentd or Fluent Bit forward input. JSON
+// entries, such as the JsonLoggerType output, are sent as the record,
+// others as its message field. With ack each message waits for Fluentd to
+// acknowledge it. A failed write reconnects and retries once.
+func FluentWriter(network, addr, tag string, ack bool) (io.WriteCloser, error) {
+	fw := &fluentWriter{network: network, addr: addr, tag: tag, ack: ack}
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if err := fw.connect(); err != nil {
+		return nil, err
+	}
+
+	return fw, nil
+}
+
+func (fw *fluentWriter) connect() error {
+	if fw.conn != nil {
+		fw.conn.Close()
+		fw.conn = nil
+	}
+
+	conn, err := net.Dial(fw.network, fw.addr)
+	if err != nil {
+		return err
+	}
+
+	fw.conn = conn
+	fw.r = bufio.NewReader(conn)
+
+	return nil
+}
+
+func (fw *fluentWriter) Write(p []byte) (int, error) {
+	chunk := ""
+	if fw.ack {
+		chunk = fluentChunk()
+	}
+
+	msg := fluentMessage(fw.tag, time.Now(), bytes.TrimRight(p, "\n"), chunk)
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.conn != nil {
+		if err := fw.send(msg, chunk); err == nil {
+			return len(p), nil
+		}
+	}
+
+	if err := fw.connect(); err != nil {
+		return 0, err
+	}
+
+	if err := fw.send(msg, chunk); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// send writes msg and waits for the ack of chunk, if any
+func (fw *fluentWriter) send(msg []byte, chunk string) error {
+	if _, err := fw.conn.Write(msg); err != nil {
+		return err
+	}
+
+	if chunk == "" {
+		return nil
+	}
+
+	fw.conn.SetReadDeadline(time.Now().Add(fluentAckTimeout))
+	defer fw.conn.SetReadDeadline(time.Time{})
+
+	resp, err := readMsgpackStringMap(fw.r)
+	if err != nil {
+		return err
+	}
+
+	if resp["ack"] != chunk {
+		return ErrFluentAck
+	}
+
+	return nil
+}
+
+func (fw *fluentWriter) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.conn == nil {
+		return nil
+	}
+
+	err := fw.conn.Close()
+	fw.conn = nil
+
+	return err
+}
+
+// fluentChunk returns a random chunk ID for the ack mode
+func fluentChunk() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// fluentMessage returns the Message mode event [tag, time, record, option]
+// of entry, the option holding the chunk to acknowledge if any
+func fluentMessage(tag string, t time.Time, entry []byte, chunk string) []byte {
+	var record map[string]interface{}
+
+	d := json.NewDecoder(bytes.NewReader(entry))
+	d.UseNumber()
+	if d.Decode(&record) != nil || record == nil {
+		record = map[string]interface{}{"message": string(entry)}
+	}
+
+	var b bytes.Buffer
+
+	if chunk != "" {
+		b.WriteByte(0x94)
+	} else {
+		b.WriteByte(0x93)
+	}
+
+	writeMsgpack(&b, tag)
+
+	// EventTime extension: seconds and nanoseconds as big endian uint32
+	b.Write([]byte{0xd7, 0x00})
+	binary.Write(&b, binary.BigEndian, uint32(t.Unix()))
+	binary.Write(&b, binary.BigEndian, uint32(t.Nanosecond()))
+
+	writeMsgpack(&b, record)
+
+	if chunk != "" {
+		writeMsgpack(&b, map[string]interface{}{"chunk": chunk})
+	}
+
+	return b.Bytes()
+}
+
+// writeMsgpack encodes the JSON value v as MessagePack, map keys are sorted
+func writeMsgpack(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMsgpackInt(b, i)
+		} else {
+			f, _ := v.Float64()
+			writeMsgpack(b, f)
+		}
+	case int:
+		writeMsgpackInt(b, int64(v))
+	case float64:
+		b.WriteByte(0xcb)
+		binary.Write(b, binary.BigEndian, math.Float64bits(v))
+	case string:
+		writeMsgpackHeader(b, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		b.WriteString(v)
+	case []interface{}:
+		writeMsgpackHeader(b, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			writeMsgpack(b, e)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		writeMsgpackHeader(b, len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			writeMsgpack(b, k)
+			writeMsgpack(b, v[k])
+		}
+	default:
+		writeMsgpack(b, fmt.Sprint(v))
+	}
+}
+
+func writeMsgpackInt(b *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		b.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		b.WriteByte(byte(int8(i)))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, i)
+	}
+}
+
+// writeMsgpackHeader writes the header of a string, array or map of n
+// elements: the fix type when n fits in fixMax, then the 8 (if any), 16
+// or 32 bits length types
+func writeMsgpackHeader(b *bytes.Buffer, n int, fix byte, fixMax int, t8, t16, t32 byte) {
+	switch {
+	case n <= fixMax:
+		b.WriteByte(fix | byte(n))
+	case t8 != 0 && n <= math.MaxUint8:
+		b.Write([]byte{t8, byte(n)})
+	case n <= math.MaxUint16:
+		b.WriteByte(t16)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(t32)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
+
+// readMsgpackStringMap decodes a MessagePack map of strings, such as the
+// {"ack": chunk} response of Fluentd
+func readMsgpackStringMap(r *bufio.Reader) (map[string]string, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	switch {
+	case t&0xf0 == 0x80:
+		n = int(t & 0x0f)
+	case t == 0xde:
+		var n16 uint16
+		if err := binary.Read(r, binary.BigEndian, &n16); err != nil {
+			return nil, err
+		}
+		n = int(n16)
+	default:
+		return nil, fmt.Errorf("logger: unexpected msgpack type 0x%x, want a map", t)
+	}
+
+	m := make(map[string]string, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpackString(r)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := readMsgpackString(r)
+		if err != nil {
+			return nil, err
+		}
+
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func readMsgpackString(r *bufio.Reader) (string, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var n int
+	switch {
+	case t&0xe0 == 0xa0:
+		n = int(t & 0x1f)
+	case t == 0xd9:
+		n8, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		n = int(n8)
+	case t == 0xda:
+		var n16 uint16
+		if err := binary.Read(r, binary.BigEndian, &n16); err != nil {
+			return "", err
+		}
+		n = int(n16)
+	default:
+		return "", fmt.Errorf("logger: unexpected msgpack type 0x%x, want a string", t)
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
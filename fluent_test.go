@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FluentSuite struct {
+	suite.Suite
+}
+
+func (s *FluentSuite) TestMsgpack() {
+	var b bytes.Buffer
+	writeMsgpack(&b, map[string]interface{}{"b": "x", "a": 1, "c": []interface{}{true, nil, -1}})
+
+	s.Equal([]byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0xa1, 'x', 0xa1, 'c', 0x93, 0xc3, 0xc0, 0xff}, b.Bytes())
+
+	b.Reset()
+	writeMsgpack(&b, string(bytes.Repeat([]byte("x"), 40)))
+	s.Equal([]byte{0xd9, 40}, b.Bytes()[:2])
+
+	b.Reset()
+	writeMsgpack(&b, 1.5)
+	s.Equal([]byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, b.Bytes())
+}
+
+func (s *FluentSuite) TestMessage() {
+	t := time.Unix(1483369445, 5)
+
+	msg := fluentMessage("web", t, []byte("GET / 200"), "")
+	s.Equal(append([]byte{0x93, 0xa3, 'w', 'e', 'b', 0xd7, 0x00, 0x58, 0x6a, 0x6b, 0xe5, 0, 0, 0, 5,
+		0x81, 0xa7}, append([]byte("message\xa9"), "GET / 200"...)...), msg)
+
+	msg = fluentMessage("web", t, []byte(`{"status":404}`), "abc")
+	s.Equal(byte(0x94), msg[0])
+	s.True(bytes.HasSuffix(msg, []byte("\x81\xa6status\xd3\x00\x00\x00\x00\x00\x00\x01\x94\x81\xa5chunk\xa3abc")))
+}
+
+func (s *FluentSuite) TestTCP() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		b := make([]byte, 4096)
+		n, _ := conn.Read(b)
+		received <- b[:n]
+	}()
+
+	w, err := FluentWriter("tcp", ln.Addr().String(), "web.access", false)
+	s.Nil(err)
+	defer w.Close()
+
+	h := New(http.NotFoundHandler(), WithWriter(w), WithClock(testClock{}), WithFormat(TinyLoggerType))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	msg := <-received
+	s.Equal(byte(0x93), msg[0])
+	s.True(bytes.HasPrefix(msg[1:], []byte("\xaaweb.access")))
+	s.True(bytes.HasSuffix(msg, []byte("\x81\xa7message\xb7GET / 404 19 - 0.000 ms")))
+}
+
+func (s *FluentSuite) TestAck() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go fluentAcker(conn)
+		}
+	}()
+
+	w, err := FluentWriter("tcp", ln.Addr().String(), "web", true)
+	s.Nil(err)
+	defer w.Close()
+
+	n, err := w.Write([]byte("GET / 200\n"))
+	s.Nil(err)
+	s.Equal(10, n)
+
+	_, err = w.Write([]byte("wrong"))
+	s.Equal(ErrFluentAck, err)
+}
+
+func (s *FluentSuite) TestReadStringMap() {
+	m, err := readMsgpackStringMap(bufio.NewReader(bytes.NewReader([]byte("\x81\xa3ack\xa2id"))))
+	s.Nil(err)
+	s.Equal(map[string]string{"ack": "id"}, m)
+
+	_, err = readMsgpackStringMap(bufio.NewReader(bytes.NewReader([]byte{0x01})))
+	s.NotNil(err)
+}
+
+// fluentAcker acknowledges the messages read from conn, with a wrong chunk
+// for those containing "wrong"
+func fluentAcker(conn net.Conn) {
+	defer conn.Close()
+
+	b := make([]byte, 4096)
+	for {
+		n, err := conn.Read(b)
+		if err != nil {
+			return
+		}
+
+		// the chunk is the last 24 bytes, a base64 fixstr
+		chunk := string(b[n-24 : n])
+		if bytes.Contains(b[:n], []byte("wrong")) {
+			chunk = "nope"
+		}
+
+		var resp bytes.Buffer
+		writeMsgpack(&resp, map[string]interface{}{"ack": chunk})
+		conn.Write(resp.Bytes())
+	}
+}
+
+func TestFluent(t *testing.T) {
+	suite.Run(t, new(FluentSuite))
+}
@@ -0,0 +1,386 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// token appends a single piece of a compiled log format to a buffer
+type token func(b []byte, e *Entry) []byte
+
+// hijackedStatus replaces the status of hijacked connections which never
+// had one written through the http.ResponseWriter, e.g. WebSocket upgrades
+const hijackedStatus = "hijacked"
+
+var tokenRegexp = regexp.MustCompile(`:([a-z][a-z-]*)(?:\[([^\]]*)\])?`)
+
+// compileFormat parses a morgan-style format string such as
+// ":method :url :status" into a list of tokens. Unknown tokens are kept
+// as literal text.
+func compileFormat(format string) []token {
+	tokens := []token{}
+	last := 0
+
+	for _, m := range tokenRegexp.FindAllStringSubmatchIndex(format, -1) {
+		name := format[m[2]:m[3]]
+		arg := ""
+		if m[4] >= 0 {
+			arg = format[m[4]:m[5]]
+		}
+
+		t := newToken(name, arg)
+		if t == nil {
+			continue
+		}
+
+		if m[0] > last {
+			tokens = append(tokens, literalToken(format[last:m[0]]))
+		}
+		tokens = append(tokens, t)
+		last = m[1]
+	}
+
+	if last < len(format) {
+		tokens = append(tokens, literalToken(format[last:]))
+	}
+
+	return tokens
+}
+
+func literalToken(s string) token {
+	return func(b []byte, _ *Entry) []byte {
+		return append(b, s...)
+	}
+}
+
+// stringToken returns the token appending the string returned by f
+func stringToken(f func(e *Entry) string) token {
+	return func(b []byte, e *Entry) []byte {
+		return append(b, f(e)...)
+	}
+}
+
+// headerToken returns the token appending the values of the header name
+// of the header returned by f, "-" when it's missing
+func headerToken(name string, f func(e *Entry) http.Header) token {
+	if name == "" {
+		return nil
+	}
+
+	name = http.CanonicalHeaderKey(name)
+
+	return stringToken(func(e *Entry) string {
+		switch values := f(e)[name]; len(values) {
+		case 0:
+			return "-"
+		case 1:
+			return orDash(values[0])
+		default:
+			return strings.Join(values, ", ")
+		}
+	})
+}
+
+func newToken(name, arg string) token {
+	switch name {
+	case "remote-addr":
+		return stringToken(func(e *Entry) string {
+			return e.RemoteAddr
+		})
+	case "remote-user":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.RemoteUser)
+		})
+	case "date":
+		layout := ""
+
+		switch arg {
+		case "", "clf":
+			return func(b []byte, e *Entry) []byte {
+				return appendTime(b, e.Start, e.timeLayout)
+			}
+		case "iso":
+			layout = "2006-01-02T15:04:05.000Z07:00"
+		case "web":
+			layout = http.TimeFormat
+		default:
+			return nil
+		}
+
+		return func(b []byte, e *Entry) []byte {
+			return e.Start.UTC().AppendFormat(b, layout)
+		}
+	case "method":
+		return stringToken(func(e *Entry) string {
+			return e.Method
+		})
+	case "url":
+		return stringToken(func(e *Entry) string {
+			return e.URL
+		})
+	case "http-version":
+		return stringToken(func(e *Entry) string {
+			return strings.TrimPrefix(e.Proto, "HTTP/")
+		})
+	case "status":
+		return appendStatus
+	case "res":
+		if !strings.EqualFold(arg, "content-length") {
+			return headerToken(arg, func(e *Entry) http.Header {
+				return e.header
+			})
+		}
+
+		// the declared length when there's one, like Apache
+		return func(b []byte, e *Entry) []byte {
+			if e.ContentLength >= 0 {
+				return strconv.AppendInt(b, e.ContentLength, 10)
+			}
+
+			return strconv.AppendInt(b, int64(e.Size), 10)
+		}
+	case "req":
+		if !strings.EqualFold(arg, "content-length") {
+			return headerToken(arg, func(e *Entry) http.Header {
+				if e.Request != nil {
+					return e.Request.Header
+				}
+
+				return e.Header
+			})
+		}
+
+		return func(b []byte, e *Entry) []byte {
+			return strconv.AppendInt(b, e.RequestSize, 10)
+		}
+	case "referrer", "referer":
+		return stringToken(func(e *Entry) string {
+			return e.Referer
+		})
+	case "user-agent":
+		return stringToken(func(e *Entry) string {
+			return e.UserAgent
+		})
+	case "request-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.RequestID)
+		})
+	case "correlation-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.CorrelationID)
+		})
+	case "amzn-trace-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.AmznTraceID)
+		})
+	case "route":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.Route)
+		})
+	case "tls-version":
+		return tlsToken(func(info *TLSInfo) string { return info.Version })
+	case "tls-cipher":
+		return tlsToken(func(info *TLSInfo) string { return info.CipherSuite })
+	case "tls-sni":
+		return tlsToken(func(info *TLSInfo) string { return info.ServerName })
+	case "tls-client-subject":
+		return tlsToken(func(info *TLSInfo) string { return info.ClientSubject })
+	case "trace-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.TraceID)
+		})
+	case "span-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.SpanID)
+		})
+	case "custom":
+		if arg == "" {
+			return nil
+		}
+
+		return stringToken(func(e *Entry) string {
+			return fieldText(e.Fields, arg)
+		})
+	case "ttfb":
+		return func(b []byte, e *Entry) []byte {
+			return e.durations.append(b, e.TTFB)
+		}
+	case "response-time":
+		return func(b []byte, e *Entry) []byte {
+			return e.durations.append(b, e.Duration)
+		}
+	case "duration-unit":
+		return stringToken(func(e *Entry) string {
+			return e.durations.unit.String()
+		})
+	case "conn-id":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.ConnID)
+		})
+	case "body-size":
+		return func(b []byte, e *Entry) []byte {
+			if e.BodySize < 0 {
+				return append(b, '-')
+			}
+
+			return strconv.AppendInt(b, e.BodySize, 10)
+		}
+	case "cache-state":
+		return stringToken(func(e *Entry) string {
+			return orDash(e.CacheState)
+		})
+	case "flushes":
+		return func(b []byte, e *Entry) []byte {
+			return strconv.AppendInt(b, int64(e.Flushes), 10)
+		}
+	case "aborted":
+		return stringToken(func(e *Entry) string {
+			return abortText(e.Aborted)
+		})
+	}
+
+	return nil
+}
+
+// appendTokens appends the rendered tokens to b
+func appendTokens(b []byte, tokens []token, e *Entry) []byte {
+	for _, t := range tokens {
+		b = t(b, e)
+	}
+
+	return b
+}
+
+func render(tokens []token, e *Entry) string {
+	return string(appendTokens(nil, tokens, e))
+}
+
+// bufferPool holds the buffers entries are rendered in
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 1024)
+
+		return &b
+	},
+}
+
+// maxPooledBuffer is the capacity above which a buffer is not pooled, so a
+// single huge entry doesn't keep its memory alive
+const maxPooledBuffer = 64 << 10
+
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
+
+// textFormatter prints entries as a line of compiled tokens, followed by
+// the recovered panic if any
+type textFormatter struct {
+	tokens []token
+}
+
+func (tf textFormatter) Format(w io.Writer, e *Entry) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b := append(appendTokens(*buf, tf.tokens, e), '\n')
+	if e.Panicked {
+		b = append(b, panicText(e)...)
+	}
+
+	_, err := w.Write(b)
+	*buf = b
+
+	return err
+}
+
+// abortText returns how the request context of an entry was aborted:
+// canceled, deadline-exceeded, or - when it wasn't
+func abortText(err error) string {
+	switch {
+	case err == nil:
+		return "-"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline-exceeded"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+
+	return err.Error()
+}
+
+// statusText returns the status of e as text formats print it
+func statusText(e *Entry) string {
+	return string(appendStatus(nil, e))
+}
+
+// appendStatus appends the status of e as text formats print it
+func appendStatus(b []byte, e *Entry) []byte {
+	if e.Hijacked && e.Status == 0 {
+		return append(b, hijackedStatus...)
+	}
+
+	return strconv.AppendInt(b, int64(e.Status), 10)
+}
+
+// orDash returns s, or "-" when it's empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+// fieldText returns the field key as the :custom[key] token prints it
+func fieldText(fields map[string]interface{}, key string) string {
+	value, ok := fields[key]
+	if !ok {
+		return "-"
+	}
+
+	return orDash(fmt.Sprint(value))
+}
+
+// EpochMillis is the WithTimeFormat layout printing times as the number of
+// milliseconds since the Unix epoch
+const EpochMillis = "epochmillis"
+
+// timeText returns t formatted with the WithTimeFormat layout, the Apache
+// CLF layout when it's empty
+func timeText(t time.Time, layout string) string {
+	return string(appendTime(nil, t, layout))
+}
+
+// appendTime appends t formatted with the WithTimeFormat layout to b
+func appendTime(b []byte, t time.Time, layout string) []byte {
+	switch layout {
+	case "":
+		return t.AppendFormat(b, timeFormat)
+	case EpochMillis:
+		return strconv.AppendInt(b, t.UnixMilli(), 10)
+	}
+
+	return t.AppendFormat(b, layout)
+}
+
+// milliseconds returns d in fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
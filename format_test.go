@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type FormatSuite struct {
+	suite.Suite
+
+	req *http.Request
+	rl  *responseLogger
+}
+
+func (s *FormatSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+	s.req.RemoteAddr = "192.0.2.1:1234"
+	s.req.Header.Set("User-Agent", "test-agent")
+	s.req.Header.Set("Referer", "http://example.com")
+
+	s.rl = &responseLogger{
+		rw:     testResponseWriter{},
+		start:  time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC),
+		status: http.StatusCreated,
+		size:   42,
+	}
+}
+
+func (s *FormatSuite) entry() *Entry {
+	return loggerHanlder{level: DefaultLevel}.entry(s.rl, s.req)
+}
+
+func (s *FormatSuite) TestTokens() {
+	tokens := compileFormat(":remote-addr :remote-user :method :url HTTP/:http-version :status :res[content-length] :referrer :user-agent")
+
+	s.Equal("192.0.2.1:1234 - POST /users?id=1 HTTP/1.1 201 42 http://example.com test-agent", render(tokens, s.entry()))
+}
+
+func (s *FormatSuite) TestDate() {
+	s.Equal("[02/Jan/2017:15:04:05 +0000]", render(compileFormat("[:date]"), s.entry()))
+	s.Equal("02/Jan/2017:15:04:05 +0000", render(compileFormat(":date[clf]"), s.entry()))
+	s.Equal("2017-01-02T15:04:05.000Z", render(compileFormat(":date[iso]"), s.entry()))
+	s.Equal("Mon, 02 Jan 2017 15:04:05 GMT", render(compileFormat(":date[web]"), s.entry()))
+}
+
+func (s *FormatSuite) TestTimeFormat() {
+	s.rl.start = time.Date(2017, time.January, 2, 15, 4, 5, 123456789, time.FixedZone("CET", 3600))
+
+	e := New(noopHandler{}, WithTimeFormat(time.RFC3339Nano)).(loggerHanlder).entry(s.rl, s.req)
+	s.Equal("2017-01-02T15:04:05.123456789+01:00", render(compileFormat(":date[clf]"), e))
+
+	e = New(noopHandler{}, WithTimeFormat(time.RFC3339Nano), WithUTC(true)).(loggerHanlder).entry(s.rl, s.req)
+	s.Equal("2017-01-02T14:04:05.123456789Z", render(compileFormat(":date"), e))
+
+	e = New(noopHandler{}, WithTimeFormat(EpochMillis)).(loggerHanlder).entry(s.rl, s.req)
+	s.Equal("1483365845123", render(compileFormat(":date[clf]"), e))
+}
+
+func (s *FormatSuite) TestUTC() {
+	s.rl.start = time.Date(2017, time.January, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))
+
+	e := New(noopHandler{}, WithUTC(true)).(loggerHanlder).entry(s.rl, s.req)
+
+	s.Equal("02/Jan/2017:14:04:05 +0000", render(compileFormat(":date[clf]"), e))
+	s.Equal(time.UTC, e.Start.Location())
+}
+
+func (s *FormatSuite) TestUnknownToken() {
+	tokens := compileFormat("at 10:30 :foo :status :date[nope] :res[]")
+
+	s.Equal("at 10:30 :foo 201 :date[nope] :res[]", render(tokens, s.entry()))
+}
+
+func (s *FormatSuite) TestLiteralOnly() {
+	s.Equal("plain", render(compileFormat("plain"), s.entry()))
+	s.Equal("", render(compileFormat(""), s.entry()))
+}
+
+func (s *FormatSuite) TestHandlerWithFormat() {
+	tw := testWriter{}
+	h := HandlerWithFormat(http.NotFoundHandler(), &tw, ":method :url -> :status")
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("POST /users?id=1 -> 404\n", string(tw.Bytes))
+}
+
+func TestFormat(t *testing.T) {
+	suite.Run(t, new(FormatSuite))
+}
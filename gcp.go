@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gcpFormatter prints the structured payload of Google Cloud Logging, see
+// GCPLoggerType
+type gcpFormatter struct {
+	project string
+}
+
+func newGCPFormatter(project string) gcpFormatter {
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+
+	return gcpFormatter{project}
+}
+
+func (gf gcpFormatter) Format(w io.Writer, e *Entry) error {
+	scheme := "http"
+	if e.TLS != nil {
+		scheme = "https"
+	}
+
+	request := map[string]interface{}{
+		"requestMethod": e.Method,
+		"requestUrl":    scheme + "://" + e.Host + e.URL,
+		"requestSize":   strconv.FormatInt(e.RequestSize, 10),
+		"status":        e.Status,
+		"responseSize":  strconv.Itoa(e.Size),
+		"latency":       gcpDuration(e.Duration),
+		"protocol":      e.Proto,
+	}
+
+	if e.UserAgent != "" {
+		request["userAgent"] = e.UserAgent
+	}
+
+	if e.Referer != "" {
+		request["referer"] = e.Referer
+	}
+
+	if host, _, err := net.SplitHostPort(e.RemoteAddr); err == nil {
+		request["remoteIp"] = host
+	} else if e.RemoteAddr != "" {
+		request["remoteIp"] = e.RemoteAddr
+	}
+
+	msg := map[string]interface{}{
+		"message":     e.Method + " " + e.URL + " " + statusText(e),
+		"severity":    e.Level.gcp(),
+		"time":        e.Start.Format(time.RFC3339Nano),
+		"httpRequest": request,
+	}
+
+	if traceID, spanID, sampled := gf.trace(e); traceID != "" {
+		msg["logging.googleapis.com/trace"] = traceID
+		if spanID != "" {
+			msg["logging.googleapis.com/spanId"] = spanID
+		}
+		msg["logging.googleapis.com/trace_sampled"] = sampled
+	}
+
+	if e.RequestID != "" {
+		msg["request.id"] = e.RequestID
+	}
+
+	if e.Route != "" {
+		msg["request.route"] = e.Route
+	}
+
+	if e.RemoteUser != "" {
+		msg["request.user"] = e.RemoteUser
+	}
+
+	if e.Panicked {
+		// picked up by Error Reporting
+		msg["panic"] = e.PanicValue
+		msg["stack_trace"] = string(e.Stack)
+	}
+
+	for k, v := range e.Fields {
+		if _, ok := msg[k]; !ok {
+			msg[k] = v
+		}
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// writeGCPNotice writes msg with fields as a structured payload about the
+// log output itself
+func writeGCPNotice(w io.Writer, t time.Time, level Level, msg string, fields map[string]interface{}) error {
+	m := map[string]interface{}{
+		"message":  msg,
+		"severity": level.gcp(),
+		"time":     t.Format(time.RFC3339Nano),
+	}
+
+	for k, v := range fields {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// trace returns the trace resource name, span ID and sampling decision of
+// e, read from the X-Cloud-Trace-Context header, TRACE_ID/SPAN_ID;o=1, or
+// the trace and span IDs of the entry
+func (gf gcpFormatter) trace(e *Entry) (traceID, spanID string, sampled bool) {
+	header := e.Header
+	if e.Request != nil {
+		header = e.Request.Header
+	}
+
+	if tc := header.Get("X-Cloud-Trace-Context"); tc != "" {
+		traceID, rest, _ := strings.Cut(tc, "/")
+		span, options, _ := strings.Cut(rest, ";")
+
+		if validTraceID(traceID) {
+			if n, err := strconv.ParseUint(span, 10, 64); err == nil && n != 0 {
+				spanID = fmt.Sprintf("%016x", n)
+			}
+
+			return gf.resource(traceID), spanID, options == "o=1"
+		}
+	}
+
+	if e.TraceID != "" {
+		return gf.resource(e.TraceID), e.SpanID, false
+	}
+
+	return "", "", false
+}
+
+// resource returns the resource name of the trace, the bare trace ID when
+// the project is unknown
+func (gf gcpFormatter) resource(traceID string) string {
+	if gf.project == "" {
+		return traceID
+	}
+
+	return "projects/" + gf.project + "/traces/" + traceID
+}
+
+// gcpDuration formats d as a protobuf Duration in JSON, e.g. "0.215s"
+func gcpDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// gcp returns the LogSeverity of l
+func (l Level) gcp() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelWarn:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	}
+
+	return "INFO"
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GCPSuite struct {
+	suite.Suite
+}
+
+func (s *GCPSuite) entry(req *http.Request, opts ...Option) map[string]interface{} {
+	tw := testWriter{}
+	opts = append([]Option{WithWriter(&tw), WithFormat(GCPLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 123e6, time.UTC)})}, opts...)
+
+	New(http.NotFoundHandler(), opts...).ServeHTTP(httptest.NewRecorder(), req)
+
+	msg := map[string]interface{}{}
+	s.Nil(json.Unmarshal(tw.Bytes, &msg))
+
+	return msg
+}
+
+func (s *GCPSuite) TestFormat() {
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+
+	msg := s.entry(req, WithFields(map[string]interface{}{"service": "api"}))
+
+	s.Equal("GET /users?id=1 404", msg["message"])
+	s.Equal("WARNING", msg["severity"])
+	s.Equal("2017-01-02T15:04:05.123Z", msg["time"])
+	s.Equal("api", msg["service"])
+	s.Equal(map[string]interface{}{
+		"requestMethod": "GET",
+		"requestUrl":    "http://example.com/users?id=1",
+		"requestSize":   "0",
+		"status":        float64(404),
+		"responseSize":  "19",
+		"latency":       "0s",
+		"protocol":      "HTTP/1.1",
+		"userAgent":     "test-agent",
+		"remoteIp":      "192.0.2.1",
+	}, msg["httpRequest"])
+	s.NotContains(msg, "logging.googleapis.com/trace")
+}
+
+func (s *GCPSuite) TestCloudTraceContext() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Cloud-Trace-Context", "105445aa7843bc8bf206b12000100000/1;o=1")
+
+	msg := s.entry(req, WithGCPProject("my-project"))
+
+	s.Equal("projects/my-project/traces/105445aa7843bc8bf206b12000100000", msg["logging.googleapis.com/trace"])
+	s.Equal("0000000000000001", msg["logging.googleapis.com/spanId"])
+	s.Equal(true, msg["logging.googleapis.com/trace_sampled"])
+}
+
+func (s *GCPSuite) TestTraceparent() {
+	s.T().Setenv("GOOGLE_CLOUD_PROJECT", "env-project")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	msg := s.entry(req)
+
+	s.Equal("projects/env-project/traces/4bf92f3577b34da6a3ce929d0e0e4736", msg["logging.googleapis.com/trace"])
+	s.Equal("00f067aa0ba902b7", msg["logging.googleapis.com/spanId"])
+}
+
+func (s *GCPSuite) TestSeverity() {
+	s.Equal("DEBUG", LevelDebug.gcp())
+	s.Equal("INFO", LevelInfo.gcp())
+	s.Equal("ERROR", LevelError.gcp())
+	s.Equal("1.5s", gcpDuration(1500*time.Millisecond))
+}
+
+func TestGCP(t *testing.T) {
+	suite.Run(t, new(GCPSuite))
+}
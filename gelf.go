@@ -0,0 +1,329 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// gelfChunkSize is the payload size of UDP chunks, small enough for
+	// WAN links
+	gelfChunkSize = 1420
+	gelfMaxChunks = 128
+)
+
+var gelfFieldRegexp = regexp.MustCompile(`^[\w.\-]+$`)
+
+// ErrGELFTooLarge is returned for messages needing more than 128 UDP chunks
+var ErrGELFTooLarge = errors.New("logger: GELF message too large")
+
+// gelfFormatter prints entries as GELF 1.1 JSON messages, one per line
+type gelfFormatter struct {
+	hostname string
+}
+
+// newGELFFormatter returns a gelfFormatter, resolving the hostname once
+func newGELFFormatter() gelfFormatter {
+	hostname, _ := os.Hostname()
+
+	return gelfFormatter{orDash(hostname)}
+}
+
+func (gf gelfFormatter) Format(w io.Writer, e *Entry) error {
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          gf.hostname,
+		"short_message": e.Method + " " + e.URL + " " + statusText(e),
+		"timestamp":     math.Round(float64(e.Start.UnixNano())/1e6) / 1e3,
+		"level":         e.Level.syslog(),
+
+		"_method":      e.Method,
+		"_url":         e.URL,
+		"_proto":       e.Proto,
+		"_status":      e.Status,
+		"_size":        e.Size,
+		"_ttfb_ms":     milliseconds(e.TTFB),
+		"_duration_ms": milliseconds(e.Duration),
+		"_remote_addr": e.RemoteAddr,
+		"_remote_user": orDash(e.RemoteUser),
+		"_user_agent":  e.UserAgent,
+		"_referer":     e.Referer,
+	}
+
+	msg["_request_size"] = e.RequestSize
+
+	if e.ContentLength >= 0 {
+		msg["_content_length"] = e.ContentLength
+	}
+
+	if e.RequestID != "" {
+		msg["_request_id"] = e.RequestID
+	}
+
+	if e.CorrelationID != "" {
+		msg["_correlation_id"] = e.CorrelationID
+	}
+
+	if e.AmznTraceID != "" {
+		msg["_amzn_trace_id"] = e.AmznTraceID
+	}
+
+	if e.Route != "" {
+		msg["_route"] = e.Route
+	}
+
+	if e.ConnID != "" {
+		msg["_connection_id"] = e.ConnID
+	}
+
+	if e.Empty {
+		msg["_empty"] = true
+	}
+
+	if e.ContentEncoding != "" {
+		msg["_content_encoding"] = e.ContentEncoding
+
+		if e.BodySize >= 0 {
+			msg["_body_size"] = e.BodySize
+		}
+	}
+
+	if e.Range != "" {
+		msg["_range"] = e.Range
+	}
+
+	if e.ContentRange != "" {
+		msg["_content_range"] = e.ContentRange
+	}
+
+	if e.CacheState != "" {
+		msg["_cache_state"] = e.CacheState
+	}
+
+	if e.Streamed {
+		msg["_streamed"] = true
+		msg["_flushes"] = e.Flushes
+	}
+
+	if e.Preliminary {
+		msg["_preliminary"] = true
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		msg["_websocket_state"] = ws.state()
+		msg["_websocket_duration_ms"] = milliseconds(ws.Duration)
+		msg["_websocket_frames_in"] = ws.FramesIn
+		msg["_websocket_frames_out"] = ws.FramesOut
+		msg["_websocket_bytes_in"] = ws.BytesIn
+		msg["_websocket_bytes_out"] = ws.BytesOut
+
+		if ws.CloseCode != 0 {
+			msg["_websocket_close_code"] = ws.CloseCode
+		}
+	}
+
+	if e.Aborted != nil {
+		msg["_aborted"] = true
+		msg["_abort_error"] = e.Aborted.Error()
+	}
+
+	if e.TraceID != "" {
+		msg["_trace_id"] = e.TraceID
+		msg["_span_id"] = e.SpanID
+	}
+
+	if e.TLS != nil {
+		msg["_tls_version"] = e.TLS.Version
+		msg["_tls_cipher"] = e.TLS.CipherSuite
+		msg["_tls_sni"] = e.TLS.ServerName
+
+		if e.TLS.ClientSubject != "" {
+			msg["_tls_client_subject"] = e.TLS.ClientSubject
+		}
+	}
+
+	if e.Panicked {
+		msg["_panic"] = e.PanicValue
+		msg["full_message"] = string(e.Stack)
+	}
+
+	for k, v := range e.Fields {
+		if k != "id" && gelfFieldRegexp.MatchString(k) {
+			if _, ok := msg["_"+k]; !ok {
+				msg["_"+k] = v
+			}
+		}
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// writeNotice writes msg with fields as a GELF message about the log
+// output itself
+func (gf gelfFormatter) writeNotice(w io.Writer, t time.Time, level Level, msg string, fields map[string]interface{}) error {
+	m := map[string]interface{}{
+		"version":       "1.1",
+		"host":          gf.hostname,
+		"short_message": msg,
+		"timestamp":     math.Round(float64(t.UnixNano())/1e6) / 1e3,
+		"level":         level.syslog(),
+	}
+
+	for k, v := range fields {
+		if gelfFieldRegexp.MatchString(k) {
+			m["_"+k] = v
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// syslog returns the syslog severity of l, as GELF expects
+func (l Level) syslog() SyslogPriority {
+	switch l {
+	case LevelDebug:
+		return SyslogDebug
+	case LevelWarn:
+		return SyslogWarning
+	case LevelError:
+		return SyslogErr
+	}
+
+	return SyslogInfo
+}
+
+// gelfWriter sends every line written as one GELF message, chunked over
+// UDP or null byte delimited over TCP
+type gelfWriter struct {
+	compress bool
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// GELFWriter returns an io.WriteCloser sending each line written, such as
+// the GELFLoggerType output, as a GELF message to a Graylog input at addr.
+// network is "udp", where large messages are chunked and compress gzips
+// them, or "tcp", where messages are null byte delimited and never
+// compressed.
+func GELFWriter(network, addr string, compress bool) (io.WriteCloser, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gelfWriter{compress: compress, conn: conn}, nil
+}
+
+func (gw *gelfWriter) Write(p []byte) (int, error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	// a line is a message, several of them being written at once when the
+	// output is buffered
+	for _, msg := range bytes.Split(p, []byte("\n")) {
+		if len(msg) == 0 {
+			continue
+		}
+
+		if err := gw.send(msg); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// send sends msg as one GELF message
+func (gw *gelfWriter) send(msg []byte) error {
+	if _, ok := gw.conn.(*net.UDPConn); !ok {
+		_, err := gw.conn.Write(append(append([]byte{}, msg...), 0))
+
+		return err
+	}
+
+	if gw.compress {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(msg)
+		gz.Close()
+
+		msg = buf.Bytes()
+	}
+
+	chunks := gelfChunks(msg)
+	if chunks == nil {
+		return ErrGELFTooLarge
+	}
+
+	for _, chunk := range chunks {
+		if _, err := gw.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (gw *gelfWriter) Close() error {
+	return gw.conn.Close()
+}
+
+// gelfChunks splits msg into UDP datagrams: as is when it fits in one,
+// otherwise in chunks prefixed with the magic bytes, a message ID, the
+// sequence number and the sequence count
+func gelfChunks(msg []byte) [][]byte {
+	if len(msg) <= gelfChunkSize {
+		return [][]byte{msg}
+	}
+
+	count := (len(msg) + gelfChunkSize - 1) / gelfChunkSize
+	if count > gelfMaxChunks {
+		return nil
+	}
+
+	var id [8]byte
+	rand.Read(id[:])
+
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * gelfChunkSize
+		if end > len(msg) {
+			end = len(msg)
+		}
+
+		chunk := make([]byte, 0, 12+end-i*gelfChunkSize)
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*gelfChunkSize:end]...)
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
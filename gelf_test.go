@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GELFSuite struct {
+	suite.Suite
+}
+
+func (s *GELFSuite) TestFormat() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(GELFLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 123e6, time.UTC)}),
+		WithFields(map[string]interface{}{"service": "api", "id": "reserved", "bad key": 1}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+
+	s.True(strings.HasSuffix(string(tw.Bytes), "}\n"))
+
+	msg := map[string]interface{}{}
+	s.Nil(json.Unmarshal(tw.Bytes, &msg))
+	s.Equal("1.1", msg["version"])
+	s.Equal("GET /users?id=1 404", msg["short_message"])
+	s.Equal(1483369445.123, msg["timestamp"])
+	s.Equal(float64(SyslogWarning), msg["level"])
+	s.Equal(float64(404), msg["_status"])
+	s.Equal(float64(19), msg["_size"])
+	s.Equal("api", msg["_service"])
+	s.NotContains(msg, "_id")
+	s.NotContains(msg, "_bad key")
+	s.NotEmpty(msg["host"])
+}
+
+func (s *GELFSuite) TestChunks() {
+	s.Len(gelfChunks([]byte("small")), 1)
+	s.Equal([]byte("small"), gelfChunks([]byte("small"))[0])
+
+	msg := bytes.Repeat([]byte("x"), gelfChunkSize*2+10)
+	chunks := gelfChunks(msg)
+
+	s.Len(chunks, 3)
+	for i, chunk := range chunks {
+		s.Equal([]byte{0x1e, 0x0f}, chunk[:2])
+		s.Equal(chunks[0][2:10], chunk[2:10])
+		s.Equal(byte(i), chunk[10])
+		s.Equal(byte(3), chunk[11])
+	}
+	s.Len(chunks[2], 12+10)
+
+	s.Nil(gelfChunks(make([]byte, gelfChunkSize*gelfMaxChunks+1)))
+}
+
+func (s *GELFSuite) TestUDP() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Nil(err)
+	defer conn.Close()
+
+	w, err := GELFWriter("udp", conn.LocalAddr().String(), true)
+	s.Nil(err)
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"version":"1.1"}` + "\n"))
+	s.Nil(err)
+
+	b := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(b)
+	s.Nil(err)
+
+	gz, err := gzip.NewReader(bytes.NewReader(b[:n]))
+	s.Nil(err)
+	msg, _ := ioutil.ReadAll(gz)
+	s.Equal(`{"version":"1.1"}`, string(msg))
+}
+
+func (s *GELFSuite) TestLines() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Nil(err)
+	defer conn.Close()
+
+	w, err := GELFWriter("udp", conn.LocalAddr().String(), false)
+	s.Nil(err)
+	defer w.Close()
+
+	p := []byte(`{"short_message":"a"}` + "\n" + `{"short_message":"b"}` + "\n")
+	n, err := w.Write(p)
+	s.Nil(err)
+	s.Equal(len(p), n)
+
+	b := make([]byte, 2048)
+	for _, expected := range []string{`{"short_message":"a"}`, `{"short_message":"b"}`} {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := conn.ReadFrom(b)
+		s.Nil(err)
+		s.Equal(expected, string(b[:n]))
+	}
+}
+
+func (s *GELFSuite) TestTCP() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		b := make([]byte, 1024)
+		n, _ := conn.Read(b)
+		received <- b[:n]
+	}()
+
+	w, err := GELFWriter("tcp", ln.Addr().String(), true)
+	s.Nil(err)
+	defer w.Close()
+
+	w.Write([]byte(`{"version":"1.1"}` + "\n"))
+
+	s.Equal([]byte(`{"version":"1.1"}`+"\x00"), <-received)
+}
+
+func TestGELF(t *testing.T) {
+	suite.Run(t, new(GELFSuite))
+}
@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"container/list"
+	"net/netip"
+	"sync"
+)
+
+// geoCacheSize is the number of client addresses WithGeoIP caches the
+// location of
+const geoCacheSize = 4096
+
+// GeoLocation is where an IP address is located, fields unknown to the
+// resolver are left zero
+type GeoLocation struct {
+	// Country is the ISO 3166-1 code of the country, e.g. "FR"
+	Country string
+	City    string
+	// ASN is the number of the autonomous system and ASOrg its
+	// organization
+	ASN   uint
+	ASOrg string
+}
+
+// GeoResolver locates IP addresses, see WithGeoIP and the geoip subpackage
+// for a MaxMind GeoIP2 / GeoLite2 database resolver
+type GeoResolver interface {
+	Resolve(addr netip.Addr) (GeoLocation, error)
+}
+
+// geoEnricher adds the location of the client address to the fields of the
+// entries, caching the last locations
+type geoEnricher struct {
+	resolver GeoResolver
+
+	mu    sync.Mutex
+	size  int
+	items map[netip.Addr]*list.Element
+	order *list.List
+}
+
+type geoItem struct {
+	addr     netip.Addr
+	location GeoLocation
+}
+
+func newGeoEnricher(resolver GeoResolver, size int) *geoEnricher {
+	return &geoEnricher{
+		resolver: resolver,
+		size:     size,
+		items:    map[netip.Addr]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// enrich is the hook adding the geo.country, geo.city, geo.asn and
+// geo.as_org fields, those which are known
+func (ge *geoEnricher) enrich(e *Entry) {
+	addr, ok := parseAddr(e.RemoteAddr)
+	if !ok || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() {
+		return
+	}
+
+	location := ge.locate(addr)
+
+	if location.Country != "" {
+		e.Fields["geo.country"] = location.Country
+	}
+
+	if location.City != "" {
+		e.Fields["geo.city"] = location.City
+	}
+
+	if location.ASN != 0 {
+		e.Fields["geo.asn"] = location.ASN
+	}
+
+	if location.ASOrg != "" {
+		e.Fields["geo.as_org"] = location.ASOrg
+	}
+}
+
+// locate returns the location of addr, from the cache when it's there.
+// Failed lookups are cached as unknown locations.
+func (ge *geoEnricher) locate(addr netip.Addr) GeoLocation {
+	ge.mu.Lock()
+	if el, ok := ge.items[addr]; ok {
+		ge.order.MoveToFront(el)
+		ge.mu.Unlock()
+
+		return el.Value.(*geoItem).location
+	}
+	ge.mu.Unlock()
+
+	location, err := ge.resolver.Resolve(addr)
+	if err != nil {
+		location = GeoLocation{}
+	}
+
+	ge.mu.Lock()
+	defer ge.mu.Unlock()
+
+	if el, ok := ge.items[addr]; ok {
+		// resolved concurrently
+		ge.order.MoveToFront(el)
+
+		return location
+	}
+
+	ge.items[addr] = ge.order.PushFront(&geoItem{addr, location})
+
+	if ge.order.Len() > ge.size {
+		oldest := ge.order.Back()
+		ge.order.Remove(oldest)
+		delete(ge.items, oldest.Value.(*geoItem).addr)
+	}
+
+	return location
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeGeoResolver locates 203.0.113.0/24 in Paris and fails for other
+// addresses
+type fakeGeoResolver struct {
+	mu      sync.Mutex
+	lookups int
+}
+
+func (fr *fakeGeoResolver) Resolve(addr netip.Addr) (GeoLocation, error) {
+	fr.mu.Lock()
+	fr.lookups++
+	fr.mu.Unlock()
+
+	if !netip.MustParsePrefix("203.0.113.0/24").Contains(addr) {
+		return GeoLocation{}, errors.New("not found")
+	}
+
+	return GeoLocation{Country: "FR", City: "Paris", ASN: 64496, ASOrg: "Example"}, nil
+}
+
+type GeoSuite struct {
+	suite.Suite
+}
+
+func (s *GeoSuite) log(resolver GeoResolver, remoteAddr string) map[string]interface{} {
+	var b bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&b), WithFormat(GELFLoggerType), WithGeoIP(resolver))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	fields := map[string]interface{}{}
+	s.Nil(json.Unmarshal(b.Bytes(), &fields))
+
+	return fields
+}
+
+func (s *GeoSuite) TestFields() {
+	fields := s.log(&fakeGeoResolver{}, "203.0.113.7:4321")
+
+	s.Equal("FR", fields["_geo.country"])
+	s.Equal("Paris", fields["_geo.city"])
+	s.Equal(float64(64496), fields["_geo.asn"])
+	s.Equal("Example", fields["_geo.as_org"])
+}
+
+func (s *GeoSuite) TestUnknown() {
+	resolver := &fakeGeoResolver{}
+
+	fields := s.log(resolver, "198.51.100.1:4321")
+	s.NotContains(fields, "_geo.country")
+	s.Equal(1, resolver.lookups)
+
+	fields = s.log(resolver, "10.0.0.1:4321")
+	s.NotContains(fields, "_geo.country")
+	s.Equal(1, resolver.lookups)
+}
+
+func (s *GeoSuite) TestCache() {
+	resolver := &fakeGeoResolver{}
+	ge := newGeoEnricher(resolver, 2)
+
+	a, b, c := netip.MustParseAddr("203.0.113.1"), netip.MustParseAddr("203.0.113.2"), netip.MustParseAddr("203.0.113.3")
+
+	s.Equal("Paris", ge.locate(a).City)
+	ge.locate(b)
+	ge.locate(a)
+	s.Equal(2, resolver.lookups)
+
+	// b is the least recently used
+	ge.locate(c)
+	s.Len(ge.items, 2)
+	s.NotContains(ge.items, b)
+
+	ge.locate(a)
+	s.Equal(3, resolver.lookups)
+	ge.locate(b)
+	s.Equal(4, resolver.lookups)
+}
+
+func (s *GeoSuite) TestConcurrent() {
+	resolver := &fakeGeoResolver{}
+	ge := newGeoEnricher(resolver, 16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			ge.locate(netip.MustParseAddr("203.0.113." + strconv.Itoa(i%32)))
+		}(i)
+	}
+	wg.Wait()
+
+	s.Len(ge.items, 16)
+	s.Equal(16, ge.order.Len())
+}
+
+func TestGeo(t *testing.T) {
+	suite.Run(t, new(GeoSuite))
+}
@@ -0,0 +1,107 @@
+// Package geoip locates the client addresses logged by the logger package
+// with MaxMind GeoIP2 or GeoLite2 databases, see Open.
+package geoip
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+
+	"github.com/go-http-utils/logger"
+	"github.com/oschwald/geoip2-golang"
+)
+
+// cityReader and asnReader are the parts of *geoip2.Reader used by
+// Resolver
+type cityReader interface {
+	City(ip net.IP) (*geoip2.City, error)
+}
+
+type asnReader interface {
+	ASN(ip net.IP) (*geoip2.ASN, error)
+}
+
+// Resolver is a logger.GeoResolver reading a City and an ASN database
+type Resolver struct {
+	city cityReader
+	asn  asnReader
+
+	closers []*geoip2.Reader
+}
+
+// Open returns a Resolver reading the country and city from the City (or
+// Country) database at cityPath and the autonomous system from the ASN
+// database at asnPath, e.g. GeoLite2-City.mmdb and GeoLite2-ASN.mmdb. Either
+// path may be empty. Close it on shutdown, e.g.:
+//
+//	geo, err := geoip.Open("GeoLite2-City.mmdb", "GeoLite2-ASN.mmdb")
+//	defer geo.Close()
+//
+//	h := logger.New(mux, logger.WithFormat(logger.JsonLoggerType), logger.WithGeoIP(geo))
+func Open(cityPath, asnPath string) (*Resolver, error) {
+	r := &Resolver{}
+
+	if cityPath != "" {
+		db, err := geoip2.Open(cityPath)
+		if err != nil {
+			return nil, err
+		}
+
+		r.city = db
+		r.closers = append(r.closers, db)
+	}
+
+	if asnPath != "" {
+		db, err := geoip2.Open(asnPath)
+		if err != nil {
+			r.Close()
+
+			return nil, err
+		}
+
+		r.asn = db
+		r.closers = append(r.closers, db)
+	}
+
+	return r, nil
+}
+
+// Resolve returns the location of addr, an error if a database failed
+func (r *Resolver) Resolve(addr netip.Addr) (logger.GeoLocation, error) {
+	var location logger.GeoLocation
+
+	ip := net.IP(addr.AsSlice())
+
+	if r.city != nil {
+		city, err := r.city.City(ip)
+		if err != nil {
+			return location, err
+		}
+
+		location.Country = city.Country.IsoCode
+		location.City = city.City.Names["en"]
+	}
+
+	if r.asn != nil {
+		asn, err := r.asn.ASN(ip)
+		if err != nil {
+			return location, err
+		}
+
+		location.ASN = asn.AutonomousSystemNumber
+		location.ASOrg = asn.AutonomousSystemOrganization
+	}
+
+	return location, nil
+}
+
+// Close closes the databases
+func (r *Resolver) Close() error {
+	errs := []error{}
+
+	for _, db := range r.closers {
+		errs = append(errs, db.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package geoip
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/oschwald/geoip2-golang"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeReader struct {
+	err error
+}
+
+func (fr fakeReader) City(ip net.IP) (*geoip2.City, error) {
+	if fr.err != nil {
+		return nil, fr.err
+	}
+
+	city := &geoip2.City{}
+	city.Country.IsoCode = "FR"
+	city.City.Names = map[string]string{"en": "Paris", "fr": "Paris"}
+
+	return city, nil
+}
+
+func (fr fakeReader) ASN(ip net.IP) (*geoip2.ASN, error) {
+	if fr.err != nil {
+		return nil, fr.err
+	}
+
+	return &geoip2.ASN{AutonomousSystemNumber: 64496, AutonomousSystemOrganization: "Example"}, nil
+}
+
+type GeoIPSuite struct {
+	suite.Suite
+
+	addr netip.Addr
+}
+
+func (s *GeoIPSuite) SetupTest() {
+	s.addr = netip.MustParseAddr("203.0.113.7")
+}
+
+func (s *GeoIPSuite) TestResolve() {
+	r := &Resolver{city: fakeReader{}, asn: fakeReader{}}
+
+	location, err := r.Resolve(s.addr)
+	s.Nil(err)
+	s.Equal(logger.GeoLocation{Country: "FR", City: "Paris", ASN: 64496, ASOrg: "Example"}, location)
+	s.Nil(r.Close())
+}
+
+func (s *GeoIPSuite) TestPartial() {
+	location, err := (&Resolver{asn: fakeReader{}}).Resolve(s.addr)
+	s.Nil(err)
+	s.Equal(logger.GeoLocation{ASN: 64496, ASOrg: "Example"}, location)
+
+	_, err = (&Resolver{city: fakeReader{errors.New("corrupt")}}).Resolve(s.addr)
+	s.EqualError(err, "corrupt")
+}
+
+func (s *GeoIPSuite) TestOpen() {
+	_, err := Open("missing-City.mmdb", "")
+	s.Error(err)
+
+	r, err := Open("", "")
+	s.Nil(err)
+	s.Nil(r.Close())
+}
+
+func TestGeoIP(t *testing.T) {
+	suite.Run(t, new(GeoIPSuite))
+}
@@ -0,0 +1,90 @@
+// Package ginadapter logs the requests of a gin engine through the formats
+// and options of the logger package, as a gin middleware. The route is the
+// full path of the matched gin route, e.g. "/users/:id".
+package ginadapter
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/logger"
+)
+
+type contextKey int
+
+const ginKey contextKey = iota
+
+// Middleware returns a gin middleware logging every request, configured by
+// opts like logger.New. The handlers down the chain get the context of the
+// logged request, e.g. for logger.AddField(c.Request.Context(), ...).
+func Middleware(opts ...logger.Option) gin.HandlerFunc {
+	h := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(c *gin.Context) {
+		h.ServeHTTP(c.Writer, c.Request.WithContext(context.WithValue(c.Request.Context(), ginKey, c)))
+	}
+}
+
+// serve runs the rest of the chain of the gin context of req as the
+// handler wrapped by the logger
+func serve(res http.ResponseWriter, req *http.Request) {
+	c := req.Context().Value(ginKey).(*gin.Context)
+
+	w := c.Writer
+	defer func() { c.Writer = w }()
+
+	c.Request = req
+	c.Writer = &responseWriter{ResponseWriter: w, res: res}
+	c.Next()
+
+	// gin only writes the status of responses without a body once the
+	// chain returned, the logger records it without writing it yet
+	if !w.Written() {
+		res.WriteHeader(w.Status())
+	}
+	req.Pattern = c.FullPath()
+}
+
+// responseWriter is the gin.ResponseWriter of the handlers, writing
+// through the logger
+type responseWriter struct {
+	gin.ResponseWriter
+
+	res http.ResponseWriter
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.res.Header()
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.res.WriteHeader(code)
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.res.WriteHeader(w.Status())
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.res.Write(b)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return io.WriteString(w.res, s)
+}
+
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	http.NewResponseController(w.res).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.res).Hijack()
+}
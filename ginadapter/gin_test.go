@@ -0,0 +1,70 @@
+package ginadapter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type GinSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+}
+
+func (s *GinSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+	s.buf.Reset()
+}
+
+func (s *GinSuite) engine() *gin.Engine {
+	r := gin.New()
+	r.Use(Middleware(logger.WithWriter(&s.buf), logger.WithCustomFormat(":method :url :route :status :res[content-type] :custom[tenant]")))
+
+	return r
+}
+
+func (s *GinSuite) TestMiddleware() {
+	r := s.engine()
+	r.GET("/users/:id", func(c *gin.Context) {
+		logger.AddField(c.Request.Context(), "tenant", "acme")
+		c.String(http.StatusCreated, "hello %s", c.Param("id"))
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	s.Equal(http.StatusCreated, rec.Code)
+	s.Equal("hello 42", rec.Body.String())
+	s.Equal("GET /users/42 /users/:id 201 text/plain; charset=utf-8 acme\n", s.buf.String())
+}
+
+func (s *GinSuite) TestStatusOnly() {
+	r := s.engine()
+	r.DELETE("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users/42", nil))
+
+	s.Equal(http.StatusNoContent, rec.Code)
+	s.Equal("DELETE /users/42 /users/:id 204 - -\n", s.buf.String())
+}
+
+func (s *GinSuite) TestNotFound() {
+	rec := httptest.NewRecorder()
+	s.engine().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	s.Equal(http.StatusNotFound, rec.Code)
+	s.Equal("GET /missing - 404 - -\n", s.buf.String())
+}
+
+func TestGin(t *testing.T) {
+	suite.Run(t, new(GinSuite))
+}
@@ -0,0 +1,227 @@
+// Package grpc logs gRPC calls through the formats and options of the
+// logger package, so services serving both HTTP and gRPC get uniform
+// access logs. A call is logged as a POST of its full method name, with
+// the status mapped from its code and the size of the messages sent,
+// along with the grpc.code and grpc.request_size fields.
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/go-http-utils/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// errPanicked is returned for calls whose handler panicked, see
+// logger.WithRecovery
+var errPanicked = status.Error(codes.Internal, "grpc: handler panicked")
+
+type contextKey int
+
+const callKey contextKey = iota
+
+// call is a gRPC call being logged
+type call struct {
+	run      func(ctx context.Context) error
+	err      error
+	received int
+	sent     int
+}
+
+// UnaryServerInterceptor returns an interceptor logging every unary call,
+// configured by opts like logger.New
+func UnaryServerInterceptor(opts ...logger.Option) grpc.UnaryServerInterceptor {
+	h := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var res any
+
+		c := &call{received: size(req)}
+		c.run = func(ctx context.Context) (err error) {
+			res, err = handler(ctx, req)
+			c.sent = size(res)
+
+			return err
+		}
+
+		h.ServeHTTP(&discardWriter{}, c.request(ctx, info.FullMethod))
+
+		return res, c.err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging every streaming
+// call once it ends, configured by opts like logger.New
+func StreamServerInterceptor(opts ...logger.Option) grpc.StreamServerInterceptor {
+	h := logger.New(http.HandlerFunc(serve), opts...)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c := &call{}
+		c.run = func(ctx context.Context) error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx, call: c})
+		}
+
+		h.ServeHTTP(&discardWriter{}, c.request(ss.Context(), info.FullMethod))
+
+		return c.err
+	}
+}
+
+// request returns the HTTP request logged for c
+func (c *call) request(ctx context.Context, method string) *http.Request {
+	req := &http.Request{
+		Method:     http.MethodPost,
+		URL:        &url.URL{Path: method},
+		RequestURI: method,
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for k, values := range md {
+			if k == ":authority" {
+				req.Host = values[0]
+
+				continue
+			}
+
+			for _, v := range values {
+				req.Header.Add(k, v)
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	return req.WithContext(context.WithValue(ctx, callKey, c))
+}
+
+// serve runs the call of req as the handler wrapped by the logger
+func serve(res http.ResponseWriter, req *http.Request) {
+	c := req.Context().Value(callKey).(*call)
+
+	c.err = errPanicked
+	err := c.run(req.Context())
+	c.err = err
+
+	code := status.Code(err)
+	logger.AddField(req.Context(), "grpc.code", code.String())
+	logger.AddField(req.Context(), "grpc.request_size", c.received)
+
+	res.WriteHeader(httpStatus(code))
+	writeN(res, c.sent)
+}
+
+// serverStream counts the messages of a streaming call and gives its
+// handler the context of the logged request
+type serverStream struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	call *call
+}
+
+func (ss *serverStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *serverStream) SendMsg(m any) error {
+	err := ss.ServerStream.SendMsg(m)
+	if err == nil {
+		ss.call.sent += size(m)
+	}
+
+	return err
+}
+
+func (ss *serverStream) RecvMsg(m any) error {
+	err := ss.ServerStream.RecvMsg(m)
+	if err == nil {
+		ss.call.received += size(m)
+	}
+
+	return err
+}
+
+// size returns the encoded size of the proto message m, zero for other
+// values
+func size(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+
+	return 0
+}
+
+// httpStatus maps code to the closest HTTP status
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+// discardWriter is the http.ResponseWriter calls are served with, it only
+// lets the logger count the bytes written
+type discardWriter struct {
+	header http.Header
+}
+
+func (dw *discardWriter) Header() http.Header {
+	if dw.header == nil {
+		dw.header = http.Header{}
+	}
+
+	return dw.header
+}
+
+func (dw *discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (dw *discardWriter) WriteHeader(int) {}
+
+var zeros [4096]byte
+
+// writeN writes n bytes to w so the logger records the size of the
+// messages sent
+func writeN(w http.ResponseWriter, n int) {
+	for n > 0 {
+		chunk := min(n, len(zeros))
+		w.Write(zeros[:chunk])
+		n -= chunk
+	}
+}
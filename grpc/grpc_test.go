@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type GRPCSuite struct {
+	suite.Suite
+
+	ctx context.Context
+	buf bytes.Buffer
+}
+
+func (s *GRPCSuite) SetupTest() {
+	s.buf.Reset()
+
+	s.ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 1234}})
+	s.ctx = metadata.NewIncomingContext(s.ctx, metadata.Pairs(":authority", "api.example.com", "user-agent", "grpc-go/1.0"))
+}
+
+func (s *GRPCSuite) entry() map[string]interface{} {
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.buf.Bytes(), &entry))
+
+	return entry
+}
+
+func (s *GRPCSuite) json() []logger.Option {
+	l := log.New()
+	l.Formatter = &log.JSONFormatter{}
+	l.Out = &s.buf
+
+	return []logger.Option{logger.WithFormat(logger.JsonLoggerType), logger.WithLogrusLogger(l)}
+}
+
+func (s *GRPCSuite) TestUnary() {
+	interceptor := UnaryServerInterceptor(logger.WithWriter(&s.buf),
+		logger.WithCustomFormat(":remote-addr :method :url :status :res[content-length] :user-agent"))
+
+	res, err := interceptor(s.ctx, wrapperspb.String("ping"), &grpc.UnaryServerInfo{FullMethod: "/echo.Echo/Say"},
+		func(ctx context.Context, req any) (any, error) {
+			return wrapperspb.String(req.(*wrapperspb.StringValue).Value + "-pong"), nil
+		})
+
+	s.Nil(err)
+	s.Equal("ping-pong", res.(*wrapperspb.StringValue).Value)
+	s.Equal("192.0.2.1:1234 POST /echo.Echo/Say 200 11 grpc-go/1.0\n", s.buf.String())
+}
+
+func (s *GRPCSuite) TestUnaryError() {
+	interceptor := UnaryServerInterceptor(s.json()...)
+
+	_, err := interceptor(s.ctx, wrapperspb.String("ping"), &grpc.UnaryServerInfo{FullMethod: "/echo.Echo/Say"},
+		func(ctx context.Context, req any) (any, error) {
+			logger.AddField(ctx, "tenant", "acme")
+
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+
+	s.Equal(codes.NotFound, status.Code(err))
+
+	entry := s.entry()
+	s.Equal("404", entry["response.status"])
+	s.Equal("NotFound", entry["grpc.code"])
+	s.Equal(float64(6), entry["grpc.request_size"])
+	s.Equal("api.example.com", entry["request.host"])
+	s.Equal("acme", entry["tenant"])
+}
+
+func (s *GRPCSuite) TestUnaryPanic() {
+	interceptor := UnaryServerInterceptor(append(s.json(), logger.WithRecovery(true))...)
+
+	_, err := interceptor(s.ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/echo.Echo/Say"},
+		func(ctx context.Context, req any) (any, error) {
+			panic("boom")
+		})
+
+	s.Equal(codes.Internal, status.Code(err))
+	s.Equal("500", s.entry()["response.status"])
+}
+
+func (s *GRPCSuite) TestStream() {
+	interceptor := StreamServerInterceptor(s.json()...)
+	ss := &testStream{ctx: s.ctx, in: []string{"a", "bc"}}
+
+	err := interceptor(nil, ss, &grpc.StreamServerInfo{FullMethod: "/echo.Echo/Chat"},
+		func(srv any, stream grpc.ServerStream) error {
+			logger.AddField(stream.Context(), "tenant", "acme")
+
+			for {
+				m := &wrapperspb.StringValue{}
+				if err := stream.RecvMsg(m); err != nil {
+					return nil
+				}
+
+				if err := stream.SendMsg(m); err != nil {
+					return err
+				}
+			}
+		})
+
+	s.Nil(err)
+	s.Len(ss.out, 2)
+
+	entry := s.entry()
+	s.Equal("200", entry["response.status"])
+	s.Equal("7", entry["response.size"])
+	s.Equal(float64(7), entry["grpc.request_size"])
+	s.Equal("OK", entry["grpc.code"])
+	s.Equal("acme", entry["tenant"])
+}
+
+func (s *GRPCSuite) TestHTTPStatus() {
+	s.Equal(http.StatusOK, httpStatus(codes.OK))
+	s.Equal(http.StatusUnauthorized, httpStatus(codes.Unauthenticated))
+	s.Equal(http.StatusServiceUnavailable, httpStatus(codes.Unavailable))
+	s.Equal(http.StatusInternalServerError, httpStatus(codes.DataLoss))
+}
+
+// testStream receives in and records the messages sent
+type testStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	in  []string
+	out []any
+}
+
+func (ts *testStream) Context() context.Context {
+	return ts.ctx
+}
+
+func (ts *testStream) RecvMsg(m any) error {
+	if len(ts.in) == 0 {
+		return io.EOF
+	}
+
+	m.(*wrapperspb.StringValue).Value, ts.in = ts.in[0], ts.in[1:]
+
+	return nil
+}
+
+func (ts *testStream) SendMsg(m any) error {
+	ts.out = append(ts.out, m)
+
+	return nil
+}
+
+func TestGRPC(t *testing.T) {
+	suite.Run(t, new(GRPCSuite))
+}
@@ -0,0 +1,128 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"sync"
+	"time"
+)
+
+// GzipFlushInterval is how often the writers of GzipWriter flush what was
+// written, so the output can be decompressed up to the last entries
+const GzipFlushInterval = time.Second
+
+// gzipWriter compresses the entries written, flushed on a timer, on Flush
+// and on Close
+type gzipWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	gz     *gzip.Writer
+	dirty  bool
+	closed bool
+
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// GzipWriter returns an io.WriteCloser compressing what it's written with
+// gzip at level, e.g. gzip.BestSpeed, to w, for log files or network sinks.
+// The compressed stream is flushed every GzipFlushInterval and on Flush,
+// so it can be read while it's written. Close ends the stream and closes w
+// when it's an io.Closer. An error is returned for invalid levels.
+func GzipWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	gz, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, err
+	}
+
+	gw := &gzipWriter{w: w, gz: gz, done: make(chan struct{})}
+
+	gw.wg.Add(1)
+	go gw.run(GzipFlushInterval)
+
+	return gw, nil
+}
+
+func (gw *gzipWriter) run(interval time.Duration) {
+	defer gw.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			gw.Flush()
+		case <-gw.done:
+			return
+		}
+	}
+}
+
+func (gw *gzipWriter) Write(p []byte) (int, error) {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed {
+		return 0, ErrClosed
+	}
+
+	gw.dirty = true
+
+	return gw.gz.Write(p)
+}
+
+// Flush writes the data compressed so far to the underlying writer, and
+// flushes it when it buffers it, e.g. a *bufio.Writer
+func (gw *gzipWriter) Flush() error {
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed || !gw.dirty {
+		return nil
+	}
+
+	gw.dirty = false
+
+	if err := gw.gz.Flush(); err != nil {
+		return err
+	}
+
+	if f, ok := gw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// Close stops the timer, ends the gzip stream and closes the underlying
+// writer when it's an io.Closer
+func (gw *gzipWriter) Close() error {
+	gw.once.Do(func() {
+		close(gw.done)
+		gw.wg.Wait()
+	})
+
+	gw.mu.Lock()
+	defer gw.mu.Unlock()
+
+	if gw.closed {
+		return nil
+	}
+
+	gw.closed = true
+
+	err := gw.gz.Close()
+	if f, ok := gw.w.(interface{ Flush() error }); ok && err == nil {
+		err = f.Flush()
+	}
+
+	if c, ok := gw.w.(io.Closer); ok {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GzipSuite struct {
+	suite.Suite
+}
+
+// gunzip decompresses what's in b so far, the stream may not be ended yet
+func gunzip(b []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+
+	out, _ := io.ReadAll(r)
+
+	return string(out)
+}
+
+func (s *GzipSuite) TestWriter() {
+	var buf bytes.Buffer
+	gw, err := GzipWriter(&buf, gzip.BestSpeed)
+	s.Require().Nil(err)
+
+	h := New(http.NotFoundHandler(), WithWriter(gw), WithCustomFormat(":method :url :status"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Nil(gw.Close())
+	s.Equal("GET /a 404\nGET /b 404\n", gunzip(buf.Bytes()))
+
+	_, err = gw.Write([]byte("c\n"))
+	s.ErrorIs(err, ErrClosed)
+	s.Nil(gw.Close())
+}
+
+func (s *GzipSuite) TestFlush() {
+	sw := &syncWriter{}
+	bw := bufio.NewWriter(sw)
+	gw, err := GzipWriter(bw, gzip.DefaultCompression)
+	s.Require().Nil(err)
+	defer gw.Close()
+
+	l := NewLogger(http.NotFoundHandler(), WithWriter(gw), WithCustomFormat(":method :url"))
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// through the gzip stream and the *bufio.Writer it wraps
+	s.Nil(l.Flush())
+	s.Equal("GET /a\n", gunzip([]byte(sw.String())))
+}
+
+func (s *GzipSuite) TestInvalidLevel() {
+	_, err := GzipWriter(io.Discard, 42)
+	s.NotNil(err)
+}
+
+func (s *GzipSuite) TestCloseUnderlying() {
+	f, err := os.CreateTemp(s.T().TempDir(), "access-*.log.gz")
+	s.Require().Nil(err)
+
+	gw, err := GzipWriter(f, gzip.BestCompression)
+	s.Require().Nil(err)
+	gw.Write([]byte("GET /\n"))
+	s.Nil(gw.Close())
+
+	s.ErrorIs(f.Close(), os.ErrClosed)
+
+	b, err := os.ReadFile(f.Name())
+	s.Require().Nil(err)
+	s.Equal("GET /\n", gunzip(b))
+}
+
+func TestGzip(t *testing.T) {
+	suite.Run(t, new(GzipSuite))
+}
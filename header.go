@@ -0,0 +1,25 @@
+package logger
+
+import "net/http"
+
+// headerAllowlist are the canonical names of the headers logged by
+// WithLoggedRequestHeaders and WithLoggedResponseHeaders
+type headerAllowlist []string
+
+func (al *headerAllowlist) add(names ...string) {
+	for _, name := range names {
+		*al = append(*al, http.CanonicalHeaderKey(name))
+	}
+}
+
+// filter returns a copy of h with only the allowed headers
+func (al headerAllowlist) filter(h http.Header) http.Header {
+	allowed := make(http.Header, len(al))
+	for _, name := range al {
+		if values, ok := h[name]; ok {
+			allowed[name] = values
+		}
+	}
+
+	return allowed
+}
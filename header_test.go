@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HeaderSuite struct {
+	suite.Suite
+
+	req *http.Request
+	h   http.Handler
+}
+
+func (s *HeaderSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/", nil)
+	s.req.Header.Set("Content-Type", "application/json")
+	s.req.Header.Set("Cookie", "session=s3cr3t")
+	s.req.Header.Set(RequestIDHeader, "abc")
+
+	s.h = http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		res.Header().Set("Set-Cookie", "session=s3cr3t")
+	})
+}
+
+func (s *HeaderSuite) entry(opts ...Option) map[string]interface{} {
+	var buf bytes.Buffer
+
+	opts = append(opts, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)))
+	New(s.h, opts...).ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+
+	return entry
+}
+
+func (s *HeaderSuite) TestFilter() {
+	var al headerAllowlist
+	al.add("content-type", "x-missing")
+
+	s.Equal(http.Header{"Content-Type": {"application/json"}}, al.filter(s.req.Header))
+}
+
+func (s *HeaderSuite) TestDefault() {
+	entry := s.entry()
+
+	s.Len(entry["request.header"], 3)
+	s.NotContains(entry, "response.header")
+}
+
+func (s *HeaderSuite) TestAllowlists() {
+	entry := s.entry(WithLoggedRequestHeaders("Content-Type", "X-Request-ID"),
+		WithLoggedResponseHeaders("content-type"))
+
+	s.Equal(map[string]interface{}{
+		"Content-Type": []interface{}{"application/json"},
+		"X-Request-Id": []interface{}{"abc"},
+	}, entry["request.header"])
+	s.Equal(map[string]interface{}{"Content-Type": []interface{}{"text/plain"}}, entry["response.header"])
+}
+
+func (s *HeaderSuite) TestTokens() {
+	s.req.Header.Add("X-Tenant", "acme")
+	s.req.Header.Add("X-Tenant", "globex")
+
+	tw := testWriter{}
+	New(s.h, WithWriter(&tw), WithRedactedHeaders("Cookie"),
+		WithCustomFormat(":req[content-type] :req[x-tenant] :req[cookie] :req[x-missing] :res[Content-Type] :res[x-cache]"),
+	).ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("application/json acme, globex [REDACTED] - text/plain -\n", string(tw.Bytes))
+}
+
+func TestHeader(t *testing.T) {
+	suite.Run(t, new(HeaderSuite))
+}
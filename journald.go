@@ -0,0 +1,233 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// journalSocket is the socket of the native protocol of systemd-journald
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalWriter sends every Write as one journal entry over the native
+// protocol
+type journalWriter struct {
+	path       string
+	identifier string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// JournaldWriter returns an io.WriteCloser sending each write as the
+// MESSAGE of a systemd journal entry, at the info priority, tagged with
+// identifier as SYSLOG_IDENTIFIER, default to the program name. See
+// JournaldTarget for structured entries.
+func JournaldWriter(identifier string) io.WriteCloser {
+	return newJournalWriter(journalSocket, identifier)
+}
+
+func newJournalWriter(path, identifier string) *journalWriter {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	return &journalWriter{path: path, identifier: identifier}
+}
+
+// JournaldTarget returns a Target sending every request to systemd-journald
+// over its native protocol, so `journalctl -o verbose` shows the request
+// as fields: HTTP_METHOD, HTTP_URL, HTTP_STATUS, HTTP_SIZE,
+// HTTP_DURATION_MS, HTTP_REMOTE_ADDR, REQUEST_ID, TRACE_ID and those of the
+// custom fields, named in upper case, e.g. `journalctl HTTP_STATUS=503`.
+// The PRIORITY is the syslog severity of the level of the entry, error for
+// 5xx responses and warning for 4xx by default.
+//
+// The MESSAGE is the line printed by the formatter of the Target's Type
+// and Options, default to CombineLoggerType.
+func JournaldTarget(identifier string) Target {
+	jw := newJournalWriter(journalSocket, identifier)
+
+	return Target{
+		Writer:  jw,
+		Type:    CombineLoggerType,
+		Options: []Option{journaldFormatter(jw)},
+	}
+}
+
+// journaldFormatter sends the entries to jw as structured journal entries
+func journaldFormatter(jw *journalWriter) Option {
+	return func(rh *loggerHanlder) {
+		rh.wrapFormatter = func(line Formatter) Formatter {
+			switch line.(type) {
+			case nil, jsonFormatter, slogFormatter:
+				// they don't print to the writer
+				line = textFormatter{formats[CombineLoggerType]}
+			}
+
+			return journalFormatter{jw, line}
+		}
+		rh.owned = append(rh.owned, jw)
+	}
+}
+
+// journalFormatter sends the fields of the entry along with the line
+// printed by line
+type journalFormatter struct {
+	jw   *journalWriter
+	line Formatter
+}
+
+func (jf journalFormatter) Format(_ io.Writer, e *Entry) error {
+	var line bytes.Buffer
+	if err := jf.line.Format(&line, e); err != nil {
+		return err
+	}
+
+	b := jf.jw.header(strings.TrimRight(line.String(), "\n"), e.Level.syslog())
+	b = appendJournalField(b, "HTTP_METHOD", e.Method)
+	b = appendJournalField(b, "HTTP_URL", e.URL)
+	b = appendJournalField(b, "HTTP_PROTO", e.Proto)
+	b = appendJournalField(b, "HTTP_HOST", e.Host)
+	b = appendJournalField(b, "HTTP_STATUS", strconv.Itoa(e.Status))
+	b = appendJournalField(b, "HTTP_SIZE", strconv.Itoa(e.Size))
+	b = appendJournalField(b, "HTTP_REQUEST_SIZE", strconv.FormatInt(e.RequestSize, 10))
+	b = appendJournalField(b, "HTTP_DURATION_MS", strconv.FormatFloat(milliseconds(e.Duration), 'f', 3, 64))
+	b = appendJournalField(b, "HTTP_TTFB_MS", strconv.FormatFloat(milliseconds(e.TTFB), 'f', 3, 64))
+	b = appendJournalField(b, "HTTP_REMOTE_ADDR", e.RemoteAddr)
+
+	optional := []struct{ key, value string }{
+		{"HTTP_REMOTE_USER", e.RemoteUser},
+		{"HTTP_USER_AGENT", e.UserAgent},
+		{"HTTP_REFERER", e.Referer},
+		{"HTTP_ROUTE", e.Route},
+		{"REQUEST_ID", e.RequestID},
+		{"CORRELATION_ID", e.CorrelationID},
+		{"TRACE_ID", e.TraceID},
+		{"SPAN_ID", e.SpanID},
+		{"PANIC", e.PanicValue},
+	}
+	for _, f := range optional {
+		if f.value != "" {
+			b = appendJournalField(b, f.key, f.value)
+		}
+	}
+
+	for k, v := range e.Fields {
+		if name := journalFieldName(k); name != "" {
+			b = appendJournalField(b, name, fmt.Sprint(v))
+		}
+	}
+
+	return jf.jw.send(b)
+}
+
+// header returns the MESSAGE, PRIORITY and SYSLOG_IDENTIFIER fields of an
+// entry
+func (jw *journalWriter) header(msg string, priority SyslogPriority) []byte {
+	b := appendJournalField(nil, "MESSAGE", msg)
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(int(priority&7)))
+
+	return appendJournalField(b, "SYSLOG_IDENTIFIER", jw.identifier)
+}
+
+func (jw *journalWriter) Write(p []byte) (int, error) {
+	if err := jw.send(jw.header(strings.TrimRight(string(p), "\n"), SyslogInfo)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// send sends the entry b, through a file descriptor when it's too large
+// for a datagram. The socket isn't connected, so entries keep going to
+// journald when it restarts.
+func (jw *journalWriter) send(b []byte) error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.conn == nil {
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+		if err != nil {
+			return err
+		}
+
+		jw.conn = conn
+	}
+
+	addr := &net.UnixAddr{Name: jw.path, Net: "unixgram"}
+
+	_, err := jw.conn.WriteToUnix(b, addr)
+	if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+		return sendJournalFile(jw.conn, addr, b)
+	}
+
+	return err
+}
+
+func (jw *journalWriter) Close() error {
+	jw.mu.Lock()
+	defer jw.mu.Unlock()
+
+	if jw.conn == nil {
+		return nil
+	}
+
+	err := jw.conn.Close()
+	jw.conn = nil
+
+	return err
+}
+
+// appendJournalField appends the field key=value to b, in the binary form
+// when value spans lines
+func appendJournalField(b []byte, key, value string) []byte {
+	b = append(b, key...)
+
+	if !strings.Contains(value, "\n") {
+		b = append(b, '=')
+		b = append(b, value...)
+
+		return append(b, '\n')
+	}
+
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+
+	return append(b, '\n')
+}
+
+// journalFieldName returns the journal field name of a custom field, in
+// upper case with the characters other than letters, digits and
+// underscores replaced, e.g. USER_AGENT_BROWSER for user_agent.browser. It's
+// empty for names which can't be made valid.
+func journalFieldName(k string) string {
+	name := []byte(strings.ToUpper(k))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+
+	// fields starting with _ are trusted fields set by journald itself
+	s := strings.TrimLeft(string(name), "_")
+	if len(s) > 64 {
+		s = s[:64]
+	}
+
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,33 @@
+//go:build linux
+
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func (s *JournaldSuite) TestLargeEntry() {
+	msg := strings.Repeat("x", 1<<20)
+	s.Nil(s.jw.send(s.jw.header(msg, SyslogInfo)))
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	s.conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, oobn, _, _, err := s.conn.ReadMsgUnix(nil, oob)
+	s.Require().Nil(err)
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	s.Require().Nil(err)
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	s.Require().Nil(err)
+
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+
+	b, err := os.ReadFile("/proc/self/fd/" + strconv.Itoa(fds[0]))
+	s.Require().Nil(err)
+	s.Equal(msg, parseJournalEntry(b)["MESSAGE"])
+}
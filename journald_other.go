@@ -0,0 +1,12 @@
+//go:build !unix
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+func sendJournalFile(_ *net.UnixConn, _ *net.UnixAddr, _ []byte) error {
+	return errors.New("logger: journal entry too large")
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type JournaldSuite struct {
+	suite.Suite
+
+	conn *net.UnixConn
+	jw   *journalWriter
+}
+
+func (s *JournaldSuite) SetupTest() {
+	path := filepath.Join(s.T().TempDir(), "journal.sock")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	s.Require().Nil(err)
+
+	s.conn = conn
+	s.jw = newJournalWriter(path, "test")
+}
+
+func (s *JournaldSuite) TearDownTest() {
+	s.jw.Close()
+	s.conn.Close()
+}
+
+// read returns the fields of the next entry
+func (s *JournaldSuite) read() map[string]string {
+	b := make([]byte, 65536)
+	s.conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := s.conn.Read(b)
+	s.Require().Nil(err)
+
+	return parseJournalEntry(b[:n])
+}
+
+func parseJournalEntry(b []byte) map[string]string {
+	fields := map[string]string{}
+
+	for len(b) > 0 {
+		i := strings.IndexAny(string(b), "=\n")
+		key := string(b[:i])
+
+		if b[i] == '=' {
+			end := i + 1 + strings.IndexByte(string(b[i+1:]), '\n')
+			fields[key] = string(b[i+1 : end])
+			b = b[end+1:]
+
+			continue
+		}
+
+		n := int(binary.LittleEndian.Uint64(b[i+1 : i+9]))
+		fields[key] = string(b[i+9 : i+9+n])
+		b = b[i+9+n+1:]
+	}
+
+	return fields
+}
+
+func (s *JournaldSuite) TestTarget() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		AddField(req.Context(), "user_agent.browser", "curl")
+		AddField(req.Context(), "_hidden", "x")
+		http.Error(res, "oops", http.StatusBadGateway)
+	}), WithClock(testClock{}), WithTargets(Target{
+		Writer:  s.jw,
+		Type:    TinyLoggerType,
+		Options: []Option{journaldFormatter(s.jw)},
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+
+	fields := s.read()
+	s.Equal("GET /users?id=1 502 5 - 0.000 ms", fields["MESSAGE"])
+	s.Equal("3", fields["PRIORITY"])
+	s.Equal("test", fields["SYSLOG_IDENTIFIER"])
+	s.Equal("GET", fields["HTTP_METHOD"])
+	s.Equal("/users?id=1", fields["HTTP_URL"])
+	s.Equal("502", fields["HTTP_STATUS"])
+	s.Equal("5", fields["HTTP_SIZE"])
+	s.Equal("192.0.2.1:1234", fields["HTTP_REMOTE_ADDR"])
+	s.Equal("curl", fields["USER_AGENT_BROWSER"])
+	s.Equal("x", fields["HIDDEN"])
+	s.NotContains(fields, "HTTP_USER_AGENT")
+}
+
+func (s *JournaldSuite) TestWriter() {
+	h := New(http.NotFoundHandler(), WithWriter(s.jw), WithClock(testClock{}), WithFormat(TinyLoggerType))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(map[string]string{
+		"MESSAGE":           "GET / 404 19 - 0.000 ms",
+		"PRIORITY":          "6",
+		"SYSLOG_IDENTIFIER": "test",
+	}, s.read())
+}
+
+func (s *JournaldSuite) TestMultiline() {
+	b := appendJournalField(nil, "STACK", "a\nb")
+
+	s.Equal("STACK\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n", string(b))
+	s.Equal(map[string]string{"STACK": "a\nb"}, parseJournalEntry(b))
+}
+
+func (s *JournaldSuite) TestFieldName() {
+	s.Equal("GEO_COUNTRY", journalFieldName("geo.country"))
+	s.Equal("TENANT_ID", journalFieldName("tenant-id"))
+	s.Equal("", journalFieldName("1st"))
+	s.Equal("", journalFieldName("__"))
+	s.Len(journalFieldName(strings.Repeat("a", 100)), 64)
+}
+
+func (s *JournaldSuite) TestRestart() {
+	s.Nil(s.jw.send(s.jw.header("first", SyslogInfo)))
+	s.Equal("first", s.read()["MESSAGE"])
+
+	path := s.jw.path
+	s.conn.Close()
+
+	// the socket file of a closed datagram socket remains
+	s.Require().Nil(os.Remove(path))
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	s.Require().Nil(err)
+	s.conn = conn
+
+	s.Nil(s.jw.send(s.jw.header("second", SyslogInfo)))
+	s.Equal("second", s.read()["MESSAGE"])
+}
+
+func TestJournald(t *testing.T) {
+	suite.Run(t, new(JournaldSuite))
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package logger
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// sendJournalFile sends the entry b through a file descriptor, in a
+// deleted file of /dev/shm, as journald expects entries too large for a
+// datagram
+func sendJournalFile(conn *net.UnixConn, addr *net.UnixAddr, b []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "journal.")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		return err
+	}
+
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+
+	return err
+}
@@ -0,0 +1,141 @@
+// Package kafka ships the log output of the logger package to a Kafka
+// topic, see Writer.
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Option configures the writer returned by Writer
+type Option func(*writer)
+
+// producer is the part of *kafkago.Writer used by writer
+type producer interface {
+	WriteMessages(ctx context.Context, msgs ...kafkago.Message) error
+	Close() error
+}
+
+// writer produces every Write as one Kafka message
+type writer struct {
+	kw       *kafkago.Writer
+	producer producer
+	key      func(p []byte) []byte
+	errors   func(error)
+}
+
+// Writer returns an io.WriteCloser producing each write, i.e. each entry
+// of the log output, as a message to topic, e.g.
+// logger.WithWriter(kafka.Writer(brokers, "access-logs")). Messages are
+// batched and sent in the background, and retried when the brokers fail,
+// so writes never wait for them. Close sends the pending batches, call it
+// on shutdown.
+func Writer(brokers []string, topic string, opts ...Option) io.WriteCloser {
+	w := &writer{
+		kw: &kafkago.Writer{
+			Addr:         kafkago.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafkago.Hash{},
+			BatchTimeout: 100 * time.Millisecond,
+			Async:        true,
+		},
+		errors: func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.kw.Completion = func(_ []kafkago.Message, err error) {
+		if err != nil {
+			w.errors(err)
+		}
+	}
+	w.producer = w.kw
+
+	return w
+}
+
+// WithBatchSize sets the number of messages sent in one request, default
+// to 100
+func WithBatchSize(n int) Option {
+	return func(w *writer) {
+		w.kw.BatchSize = n
+	}
+}
+
+// WithBatchTimeout sets how long an incomplete batch waits for more
+// messages before it's sent, default to 100ms
+func WithBatchTimeout(d time.Duration) Option {
+	return func(w *writer) {
+		w.kw.BatchTimeout = d
+	}
+}
+
+// WithRetries sets how many times a batch is sent before it's dropped,
+// default to 10
+func WithRetries(n int) Option {
+	return func(w *writer) {
+		w.kw.MaxAttempts = n
+	}
+}
+
+// WithPartitionKey sets the function giving the key of the message of an
+// entry, messages with the same key go to the same partition, e.g.
+// WithPartitionKey(KeyByField("_remote_addr")) for GELFLoggerType.
+// Messages have no key by default and are spread over the partitions.
+func WithPartitionKey(key func(entry []byte) []byte) Option {
+	return func(w *writer) {
+		w.key = key
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the batches
+// that could not be sent, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(w *writer) {
+		w.errors = f
+	}
+}
+
+// KeyByField returns a WithPartitionKey function keying the entries of the
+// JSON log outputs, such as JsonLoggerType or GELFLoggerType, by the value
+// of their field name, e.g. "client_address" or "_remote_addr"
+func KeyByField(name string) func(entry []byte) []byte {
+	return func(entry []byte) []byte {
+		fields := map[string]interface{}{}
+		if json.Unmarshal(entry, &fields) != nil {
+			return nil
+		}
+
+		value, ok := fields[name]
+		if !ok {
+			return nil
+		}
+
+		return []byte(fmt.Sprint(value))
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	// the message is sent after Write returned, p can't be kept
+	msg := kafkago.Message{Value: append([]byte{}, p...)}
+	if w.key != nil {
+		msg.Key = w.key(p)
+	}
+
+	if err := w.producer.WriteMessages(context.Background(), msg); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (w *writer) Close() error {
+	return w.producer.Close()
+}
@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/suite"
+)
+
+type testProducer struct {
+	msgs   []kafkago.Message
+	err    error
+	closed bool
+}
+
+func (tp *testProducer) WriteMessages(_ context.Context, msgs ...kafkago.Message) error {
+	tp.msgs = append(tp.msgs, msgs...)
+
+	return tp.err
+}
+
+func (tp *testProducer) Close() error {
+	tp.closed = true
+
+	return nil
+}
+
+type KafkaSuite struct {
+	suite.Suite
+}
+
+func (s *KafkaSuite) TestOptions() {
+	var got error
+	w := Writer([]string{"a:9092", "b:9092"}, "access-logs", WithBatchSize(500),
+		WithBatchTimeout(time.Second), WithRetries(3), WithErrorHandler(func(err error) { got = err })).(*writer)
+
+	s.Equal("access-logs", w.kw.Topic)
+	s.Equal("a:9092,b:9092", w.kw.Addr.String())
+	s.Equal(500, w.kw.BatchSize)
+	s.Equal(time.Second, w.kw.BatchTimeout)
+	s.Equal(3, w.kw.MaxAttempts)
+	s.True(w.kw.Async)
+
+	w.kw.Completion(nil, errors.New("broker down"))
+	s.EqualError(got, "broker down")
+}
+
+func (s *KafkaSuite) TestWrite() {
+	tp := &testProducer{}
+	w := Writer([]string{"localhost:9092"}, "access-logs", WithPartitionKey(KeyByField("_remote_addr"))).(*writer)
+	w.producer = tp
+
+	h := logger.New(http.NotFoundHandler(), logger.WithFormat(logger.GELFLoggerType), logger.WithWriter(w))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Len(tp.msgs, 1)
+	s.Equal("192.0.2.1:1234", string(tp.msgs[0].Key))
+	s.Contains(string(tp.msgs[0].Value), `"_url":"/users"`)
+
+	s.Nil(w.Close())
+	s.True(tp.closed)
+}
+
+func (s *KafkaSuite) TestWriteError() {
+	tp := &testProducer{err: errors.New("queue full")}
+	w := Writer([]string{"localhost:9092"}, "access-logs").(*writer)
+	w.producer = tp
+
+	n, err := w.Write([]byte("GET / 200\n"))
+	s.Equal(0, n)
+	s.EqualError(err, "queue full")
+	s.Nil(tp.msgs[0].Key)
+}
+
+func (s *KafkaSuite) TestKeyByField() {
+	key := KeyByField("client_address")
+
+	s.Equal("192.0.2.1:1234", string(key([]byte(`{"client_address":"192.0.2.1:1234"}`))))
+	s.Equal("42", string(key([]byte(`{"client_address":42}`))))
+	s.Nil(key([]byte(`{"request.url":"/"}`)))
+	s.Nil(key([]byte(`GET / 200`)))
+}
+
+func TestKafka(t *testing.T) {
+	suite.Run(t, new(KafkaSuite))
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"log/slog"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Level is the severity structured log outputs are emitted at
+type Level int
+
+const (
+	// LevelDebug is for verbose entries
+	LevelDebug Level = iota
+	// LevelInfo is for successful requests
+	LevelInfo
+	// LevelWarn is for client errors
+	LevelWarn
+	// LevelError is for server errors
+	LevelError
+)
+
+// DefaultLevel logs 5xx responses as LevelError, 4xx as LevelWarn and
+// everything else as LevelInfo
+func DefaultLevel(status int) Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return LevelError
+	case status >= http.StatusBadRequest:
+		return LevelWarn
+	}
+
+	return LevelInfo
+}
+
+func (l Level) logrus() log.Level {
+	switch l {
+	case LevelDebug:
+		return log.DebugLevel
+	case LevelWarn:
+		return log.WarnLevel
+	case LevelError:
+		return log.ErrorLevel
+	}
+
+	return log.InfoLevel
+}
+
+func (l Level) slog() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	}
+
+	return slog.LevelInfo
+}
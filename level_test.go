@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type LevelSuite struct {
+	suite.Suite
+}
+
+func (s *LevelSuite) TestDefaultLevel() {
+	s.Equal(LevelInfo, DefaultLevel(http.StatusOK))
+	s.Equal(LevelInfo, DefaultLevel(http.StatusFound))
+	s.Equal(LevelWarn, DefaultLevel(http.StatusNotFound))
+	s.Equal(LevelError, DefaultLevel(http.StatusServiceUnavailable))
+}
+
+func (s *LevelSuite) TestConversions() {
+	s.Equal(log.DebugLevel, LevelDebug.logrus())
+	s.Equal(log.ErrorLevel, LevelError.logrus())
+	s.Equal(slog.LevelWarn, LevelWarn.slog())
+	s.Equal(slog.LevelInfo, LevelInfo.slog())
+}
+
+func (s *LevelSuite) TestLogrus() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("warning", entry["level"])
+}
+
+func (s *LevelSuite) TestLevelFunc() {
+	th := &testSlogHandler{}
+	h := New(http.NotFoundHandler(), WithFormat(SlogLoggerType), WithSlogLogger(slog.New(th)),
+		WithLevelFunc(func(status int) Level { return LevelDebug }))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(slog.LevelDebug, th.records[0].Level)
+}
+
+func TestLevel(t *testing.T) {
+	suite.Run(t, new(LevelSuite))
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// lockedWriter serializes the writes of concurrent requests, so writers
+// which are not safe for concurrent use, such as a bytes.Buffer, never see
+// two entries at once
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (lw *lockedWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	return lw.w.Write(p)
+}
+
+// Flush flushes the underlying writer when it buffers the output
+func (lw *lockedWriter) Flush() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if f, ok := lw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
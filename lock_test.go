@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// countingWriter counts the Write calls, it's not safe for concurrent use
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.writes++
+
+	return cw.Buffer.Write(p)
+}
+
+type LockSuite struct {
+	suite.Suite
+}
+
+func (s *LockSuite) TestSingleWrite() {
+	panicking := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+
+	for _, t := range []Type{CombineLoggerType, CommonLoggerType, DevLoggerType, ShortLoggerType, TinyLoggerType,
+		JsonLoggerType, SlogLoggerType, W3CLoggerType, GELFLoggerType, CEFLoggerType, LEEFLoggerType, CSVLoggerType,
+		TSVLoggerType} {
+		cw := &countingWriter{}
+		h := New(panicking, WithFormat(t), WithWriter(cw), WithLogrusLogger(newTestLogrus(cw)), WithRecovery(true))
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		s.Equal(1, cw.writes, "type %d", t)
+	}
+}
+
+func (s *LockSuite) TestConcurrentRequests() {
+	cw := &countingWriter{}
+	h := New(noopHandler{}, WithWriter(cw), WithCustomFormat(":method :url"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/concurrent", nil))
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(50, cw.writes)
+	s.Equal(strings.Repeat("GET /concurrent\n", 50), cw.String())
+}
+
+func (s *LockSuite) TestFlush() {
+	var b bytes.Buffer
+	bw := &lockedWriter{w: &b}
+	s.Nil(bw.Flush())
+
+	lw := &lockedWriter{w: newBufferedWriter(&b, 1024, 0)}
+	lw.Write([]byte("a\n"))
+	s.Equal("", b.String())
+
+	s.Nil(lw.Flush())
+	s.Equal("a\n", b.String())
+}
+
+func TestLock(t *testing.T) {
+	suite.Run(t, new(LockSuite))
+}
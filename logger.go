@@ -1,13 +1,15 @@
 package logger
 
 import (
-	"fmt"
+	"bufio"
+	"errors"
 	"io"
-	"io/ioutil"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -41,189 +43,980 @@ const (
 	//
 	// format:
 	//
-	// :method :url :status :response-time ms - :res[content-length]
+	// :method :url :status :response-time :duration-unit - :res[content-length]
 	DevLoggerType
 	// ShortLoggerType is shorter than common, including response time
 	//
 	// format:
 	//
 	// :remote-addr :remote-user :method :url HTTP/:http-version :status
-	// :res[content-length] - :response-time ms
+	// :res[content-length] - :response-time :duration-unit
 	ShortLoggerType
 	// TinyLoggerType is the minimal ouput
 	//
 	// format:
 	//
-	// :method :url :status :res[content-length] - :response-time ms
+	// :method :url :status :res[content-length] - :response-time :duration-unit
 	TinyLoggerType
+	// CustomLoggerType uses a user defined format made of morgan-style
+	// tokens, see HandlerWithFormat
+	CustomLoggerType
+	// SlogLoggerType emits each request as a structured log/slog record with
+	// typed attributes, see WithSlogLogger
+	SlogLoggerType
+	// W3CLoggerType is the W3C Extended Log File Format used by IIS, the
+	// #Version, #Date and #Fields directives are written before the first
+	// entry
+	//
+	// fields:
+	//
+	// date time c-ip cs-username cs-method cs-uri-stem cs-uri-query
+	// sc-status sc-bytes time-taken cs-version cs(User-Agent) cs(Referer)
+	W3CLoggerType
+	// GELFLoggerType is the Graylog Extended Log Format, one JSON document
+	// per entry, see GELFWriter to ship it to Graylog
+	GELFLoggerType
+	// CEFLoggerType is the ArcSight Common Event Format used by SIEMs, the
+	// signature ID is the status
+	CEFLoggerType
+	// LEEFLoggerType is the IBM QRadar Log Event Extended Format 2.0, the
+	// event ID is the status
+	LEEFLoggerType
+	// CSVLoggerType prints comma separated values quoted as in RFC 4180,
+	// the columns are DefaultColumns unless set by WithColumns, see
+	// WithCSVHeader to write a header row
+	CSVLoggerType
+	// TSVLoggerType is CSVLoggerType with tab separated values
+	TSVLoggerType
+	// GCPLoggerType is the structured payload of Google Cloud Logging, one
+	// JSON document per entry with the httpRequest, severity and trace
+	// special fields, see WithGCPProject
+	GCPLoggerType
+	// NDJSONLoggerType prints one compact JSON object per line, with a
+	// schema_version and stable key names, without going through logrus
+	// or a Backend, see NDJSONSchemaVersion
+	NDJSONLoggerType
 
 	timeFormat = "02/Jan/2006:15:04:05 -0700"
 )
 
+var formats = map[Type][]token{
+	CombineLoggerType: compileFormat(`:remote-addr - :remote-user [:date[clf]] ":method :url HTTP/:http-version" :status :res[content-length] ":referrer" ":user-agent"`),
+	CommonLoggerType:  compileFormat(`:remote-addr - :remote-user [:date[clf]] ":method :url HTTP/:http-version" :status :res[content-length]`),
+	DevLoggerType:     compileFormat(`:method :url :status :response-time :duration-unit - :res[content-length]`),
+	ShortLoggerType:   compileFormat(`:remote-addr :remote-user :method :url HTTP/:http-version :status :res[content-length] - :response-time :duration-unit`),
+	TinyLoggerType:    compileFormat(`:method :url :status :res[content-length] - :response-time :duration-unit`),
+}
+
 type responseLogger struct {
 	rw     http.ResponseWriter
+	clock  Clock
 	start  time.Time
 	status int
 	size   int
+	body   *bodyCapture
+	// reqBody counts the bytes read from the request body
+	reqBody requestBody
+
+	resBody *responseCapture
+	// header is the response header of the requests logged by the
+	// Transport, which have no rw
+	header http.Header
+
+	hijacked      bool
+	requestID     string
+	correlationID string
+	amznTraceID   string
+	traceID       string
+	spanID        string
+	connID        string
+
+	// aborted is the error of the request context canceled while the
+	// handler ran
+	aborted error
+
+	// handler and req are those of the request, for the callbacks of
+	// streamed responses and WebSocket upgrades
+	handler *loggerHanlder
+	req     *http.Request
+
+	// flushes counts the flushes of the response, the preliminary entry
+	// is logged on the first one when streamed is set
+	flushes      int
+	streamed     bool
+	preliminary  bool
+	streamLogged bool
+
+	// webSocket wraps the hijacked connection of WebSocket upgrades, see
+	// WithWebSocketSessions
+	webSocket bool
+	session   *wsSession
+
+	panicked   bool
+	panicValue string
+	stack      []byte
+
+	custom *requestFields
+	fields requestFields
+	route  string
+
+	// wrapper is kept across the requests of a pooled responseLogger
+	wrapper     http.ResponseWriter
+	wrapperKind int
+
+	durations durationFormat
+
+	wrote    bool
+	ttfb     time.Duration
+	duration time.Duration
+
+	// head and tail are the first and last bytes of the response body,
+	// unless some were written by ReadFrom untracked, see bodySize
+	head      [2]byte
+	tail      [4]byte
+	untracked bool
+}
+
+// firstByte records the time to first byte on the first write of the
+// response
+func (rl *responseLogger) firstByte() {
+	if rl.wrote {
+		return
+	}
+
+	clock := rl.clock
+	if clock == nil {
+		clock = realClock{}
+	}
+
+	rl.wrote = true
+	rl.ttfb = elapsed(clock, rl.start)
 }
 
 func (rl *responseLogger) Header() http.Header {
 	return rl.rw.Header()
 }
 
+// responseHeader returns the header of the logged response
+func (rl *responseLogger) responseHeader() http.Header {
+	if rl.rw == nil {
+		return rl.header
+	}
+
+	return rl.rw.Header()
+}
+
 func (rl *responseLogger) Write(bytes []byte) (int, error) {
 	if rl.status == 0 {
 		rl.status = http.StatusOK
 	}
 
+	rl.firstByte()
+
 	size, err := rl.rw.Write(bytes)
 
+	rl.trackBody(rl.size, bytes[:size])
 	rl.size += size
 
+	if rl.resBody != nil {
+		rl.resBody.capture(rl.Header(), bytes[:size])
+	}
+
 	return size, err
 }
 
 func (rl *responseLogger) WriteHeader(status int) {
 	rl.status = status
 
+	rl.firstByte()
+
 	rl.rw.WriteHeader(status)
 }
 
 func (rl *responseLogger) Flush() {
+	if rl.status == 0 {
+		rl.status = http.StatusOK
+	}
+
+	rl.firstByte()
+	rl.flushes++
+
 	f, ok := rl.rw.(http.Flusher)
 
 	if ok {
 		f.Flush()
 	}
+
+	if rl.flushes == 1 && rl.streamed {
+		rl.handler.logStreamStart(rl, rl.req)
+	}
+}
+
+func (rl *responseLogger) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rl.rw.(http.Hijacker)
+
+	if !ok {
+		return nil, nil, errors.New("logger: http.ResponseWriter does not implement http.Hijacker")
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		rl.hijacked = true
+
+		if rl.webSocket {
+			conn, rw = rl.handler.startWebSocket(rl, rl.req, conn, rw)
+		}
+	}
+
+	return conn, rw, err
+}
+
+func (rl *responseLogger) Push(target string, opts *http.PushOptions) error {
+	p, ok := rl.rw.(http.Pusher)
+
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return p.Push(target, opts)
+}
+
+func (rl *responseLogger) ReadFrom(r io.Reader) (int64, error) {
+	if rl.status == 0 {
+		rl.status = http.StatusOK
+	}
+
+	rl.firstByte()
+
+	var size int64
+	var err error
+
+	if rf, ok := rl.rw.(io.ReaderFrom); ok && rl.resBody == nil {
+		size, err = rf.ReadFrom(r)
+		rl.size += int(size)
+
+		if size > 0 {
+			rl.untracked = true
+		}
+	} else {
+		// go through Write so the bytes are counted and captured
+		size, err = io.Copy(struct{ io.Writer }{rl}, r)
+	}
+
+	return size, err
+}
+
+func (rl *responseLogger) Unwrap() http.ResponseWriter {
+	return rl.rw
 }
 
 type loggerHanlder struct {
 	h          http.Handler
 	formatType Type
 	writer     io.Writer
+	tokens     []token
+	bodyLimit  int
+	bodyFilter func(*http.Request) bool
+	clock      Clock
+	skippers   []func(*http.Request) bool
+	fields     map[string]interface{}
+	backend    Backend
+	formatter  Formatter
+	hooks      []func(*Entry)
+	slog       *slog.Logger
+	requestID  bool
+	redactor   redactor
+	masker     masker
+	limits     fieldLimits
+	jwtSubject bool
+
+	correlation     bool
+	echoCorrelation bool
+
+	audit    bool
+	auditKey []byte
+
+	// requestLogger is the base logger of FromRequest
+	requestLogger *slog.Logger
+
+	requestHeaders  headerAllowlist
+	responseHeaders headerAllowlist
+
+	// wrapFormatter wraps the formatter once the options are applied
+	wrapFormatter func(Formatter) Formatter
+
+	bufferSize    int
+	flushInterval time.Duration
+
+	asyncSize    int
+	asyncWorkers int
+	overflow     OverflowPolicy
+	async        *asyncWriter
+
+	// directives writes the W3C directives or the CSV header row once
+	directives *sync.Once
+
+	columns   []csvColumn
+	csvHeader bool
+
+	// gcpProject names the traces of GCPLoggerType
+	gcpProject string
+
+	// encoder replaces the log output type when set, see WithEncoder
+	encoder Encoder
+	// typedFormatter is set when the formatter is the one of the log output
+	// type or encoder, not set by WithFormatter
+	typedFormatter bool
+
+	timeLayout string
+	utc        bool
+
+	color         ColorMode
+	slowThreshold time.Duration
+
+	proxies trustedProxies
+
+	resBodyLimit int
+	resBodyTypes []string
+
+	// routeFuncs return the route patterns of the routers, see
+	// WithRouteFunc
+	routeFuncs []RouteFunc
+
+	metrics    *metrics
+	statsd     *statsd
+	stats      *StatsAggregator
+	sampler    Sampler
+	conditions []func(*http.Request, Stats) bool
+	limiter    *rateLimiter
+	repeats    *deduplicator
+	level      func(status int) Level
+
+	recovery bool
+
+	// streamEntries logs a preliminary entry for streamed responses, see
+	// WithStreamEntries
+	streamEntries bool
+	// webSocketSessions logs the sessions of WebSocket upgrades, see
+	// WithWebSocketSessions
+	webSocketSessions bool
+
+	durations durationFormat
+
+	targets       []Target
+	outputs       []loggerHanlder
+	routes        []routeOverride
+	statusWriters []statusWriter
+
+	// owned are the writers created by the options, closed with the handler
+	owned []io.Closer
+
+	// expvarName publishes the counters of the handler, see WithExpvar
+	expvarName string
+
+	onError  func(error)
+	counters *counters
+
+	// self is a copy of the handler, see withSelf
+	self *loggerHanlder
 }
 
 func (rh loggerHanlder) ServeHTTP(res http.ResponseWriter, req *http.Request) {
-	rl := &responseLogger{rw: res, start: time.Now()}
+	for _, ro := range rh.routes {
+		if ro.matches(req) {
+			ro.handler.ServeHTTP(res, req)
+
+			return
+		}
+	}
+
+	if rh.skip(req) {
+		rh.h.ServeHTTP(res, req)
+
+		return
+	}
+
+	if rh.metrics != nil {
+		rh.metrics.inFlight.Inc()
+		defer rh.metrics.inFlight.Dec()
+	}
+
+	if rh.statsd != nil {
+		rh.statsd.inFlight.Add(1)
+		defer rh.statsd.inFlight.Add(-1)
+	}
+
+	rl := rh.newResponseLogger(res)
+	// before a hijack stops tracking the connection
+	rl.connID = connID(req)
+
+	if rh.requestID {
+		req = rh.withRequestID(res, req, rl)
+	}
+
+	if rh.correlation {
+		req = rh.withCorrelationIDs(res, req, rl)
+	}
+
+	rl.traceID, rl.spanID = traceContext(req)
+
+	if rh.requestLogger != nil {
+		req = rh.withRequestLogger(req)
+	}
+
+	req = rh.withFields(req, rl)
+
+	if rh.bodyLimit > 0 && req.Body != nil && req.Body != http.NoBody && rh.bodyFilter(req) {
+		body := newBodyCapture(req.Body, rh.bodyLimit)
+		rl.body = body
+		req.Body = body
+
+		// rl is released by then
+		defer func() { req.Body = body.ReadCloser }()
+	}
 
-	log.SetFormatter(&log.JSONFormatter{})
+	if req.Body != nil && req.Body != http.NoBody {
+		rl.reqBody.ReadCloser = req.Body
+		req.Body = &rl.reqBody
+	}
+
+	if rh.resBodyLimit > 0 {
+		rl.resBody = newResponseCapture(rh.resBodyLimit, rh.resBodyTypes)
+	}
+
+	// a closure would make rh escape on every request
+	rl.streamed = rh.streamEntries
+	rl.webSocket = rh.webSocketSessions && isWebSocketUpgrade(req)
+	if rl.streamed || rl.webSocket {
+		rl.handler, rl.req = rh.self, req
+	}
 
-	rh.h.ServeHTTP(rl, req)
+	rh.serve(rl.wrapped(), req, rl)
+
+	// the server only cancels the context once the handler returned, unless
+	// the client went away or a deadline was exceeded
+	rl.aborted = req.Context().Err()
+
+	rl.duration = elapsed(rh.clock, rl.start)
+	if !rl.wrote {
+		// the server writes the response once the handler returns
+		rl.ttfb = rl.duration
+	}
+	rl.route = rh.route(req)
+
+	if rh.metrics != nil {
+		rh.metrics.observe(rl, req)
+	}
+
+	if rh.statsd != nil {
+		rh.statsd.observe(rl, req)
+	}
+
+	if rh.stats != nil {
+		rh.stats.observe(rl, req)
+	}
+
+	if rl.session != nil {
+		if rl.reqBody.ReadCloser != nil {
+			req.Body = rl.reqBody.ReadCloser
+		}
+
+		// the session logs its end and releases rl once its connection is
+		// closed too
+		rl.session.handlerReturned()
+
+		return
+	}
+
+	if rl.streamLogged {
+		// the end of a stream whose start was logged is always logged
+		rh.write(rl, req)
+	} else {
+		rh.log(rl, req)
+	}
+
+	if rl.reqBody.ReadCloser != nil {
+		req.Body = rl.reqBody.ReadCloser
+	}
+	responseLoggers.Put(rl)
+}
+
+// responseLoggers pools the responseLogger of the requests, put back once
+// their entry is written
+var responseLoggers = sync.Pool{
+	New: func() interface{} {
+		return &responseLogger{}
+	},
+}
+
+// newResponseLogger returns a pooled responseLogger for a request answered
+// with res
+func (rh loggerHanlder) newResponseLogger(res http.ResponseWriter) *responseLogger {
+	rl := responseLoggers.Get().(*responseLogger)
+
+	wrapper, kind := rl.wrapper, rl.wrapperKind
+	keys, values := rl.fields.keys[:0], rl.fields.values
+	clear(values)
+
+	*rl = responseLogger{
+		rw:        res,
+		clock:     rh.clock,
+		start:     rh.clock.Now(),
+		durations: rh.durations,
+
+		wrapper:     wrapper,
+		wrapperKind: kind,
+	}
+	rl.fields.keys, rl.fields.values = keys, values
+
+	return rl
+}
+
+// log writes the entry of rl unless the sampler, the conditions of
+// WithCondition or the rate limit leave it out, and reports whether it
+// was written
+func (rh loggerHanlder) log(rl *responseLogger, req *http.Request) bool {
+	if rh.sampler != nil && !rl.panicked && !rh.sampler(req, rl.status) {
+		return false
+	}
+
+	if !rl.panicked && !rh.meets(req, rl) {
+		return false
+	}
+
+	if !rl.panicked && !rh.dedup(req, rl.status, rh.clock.Now()) {
+		return false
+	}
+
+	if !rl.panicked && !rh.limit(rh.clock.Now()) {
+		return false
+	}
 
 	rh.write(rl, req)
+
+	return true
+}
+
+// logStreamStart logs the preliminary entry of the streamed response of
+// rl, once the handler flushed it the first time
+func (rh loggerHanlder) logStreamStart(rl *responseLogger, req *http.Request) {
+	rl.duration = elapsed(rh.clock, rl.start)
+	rl.route = rh.route(req)
+
+	rl.preliminary = true
+	rl.streamLogged = rh.log(rl, req)
+	rl.preliminary = false
 }
 
 func (rh loggerHanlder) write(rl *responseLogger, req *http.Request) {
-	username := "-"
+	if sw := rh.statusWriter(rl.status); sw != nil {
+		sw.write(rl, req)
+
+		return
+	}
 
-	if req.URL.User != nil {
-		if name := req.URL.User.Username(); name != "" {
-			username = name
+	if len(rh.outputs) > 0 {
+		for _, o := range rh.outputs {
+			o.write(rl, req)
 		}
+
+		return
+	}
+
+	if rh.formatter == nil {
+		return
+	}
+
+	e := rh.entry(rl, req)
+	for _, hook := range rh.hooks {
+		hook(e)
 	}
 
-	body, err := ioutil.ReadAll(req.Body)
-	if err != nil {
-		panic(err)
-	}
-
-	switch rh.formatType {
-	case CombineLoggerType:
-		fmt.Fprintln(rh.writer, strings.Join([]string{
-			req.RemoteAddr,
-			"-",
-			username,
-			"[" + rl.start.Format(timeFormat) + "]",
-			`"` + req.Method,
-			req.RequestURI,
-			req.Proto + `"`,
-			strconv.Itoa(rl.status),
-			strconv.Itoa(rl.size),
-			`"` + req.Referer() + `"`,
-			`"` + req.UserAgent() + `"`,
-		}, " "))
-	case JsonLoggerType:
-		log.WithFields(log.Fields{
-			// request
-			"request.host":       req.Host,
-			"request.method":     req.Method,
-			"request.proto":      req.Proto,
-			"request.url":        req.URL,
-			"request.referer":    req.Referer(),
-			"request.user_agent": req.UserAgent(),
-			"request.header":     req.Header,
-			"start_time":         rl.start.Format(timeFormat),
-			"body":               string(body),
-			// response
-			"response.status": strconv.Itoa(rl.status),
-			"response.size":   strconv.Itoa(rl.size),
-			"client_address":  req.RemoteAddr,
-		}).Info("request processed")
-	case CommonLoggerType:
-		fmt.Fprintln(rh.writer, strings.Join([]string{
-			req.RemoteAddr,
-			"-",
-			username,
-			"[" + rl.start.Format(timeFormat) + "]",
-			`"` + req.Method,
-			req.RequestURI,
-			req.Proto + `"`,
-			strconv.Itoa(rl.status),
-			strconv.Itoa(rl.size),
-		}, " "))
-	case DevLoggerType:
-		fmt.Fprintln(rh.writer, strings.Join([]string{
-			req.Method,
-			req.RequestURI,
-			strconv.Itoa(rl.status),
-			parseResponseTime(rl.start),
-			"-",
-			strconv.Itoa(rl.size),
-		}, " "))
-	case ShortLoggerType:
-		fmt.Fprintln(rh.writer, strings.Join([]string{
-			req.RemoteAddr,
-			username,
-			req.Method,
-			req.RequestURI,
-			req.Proto,
-			strconv.Itoa(rl.status),
-			strconv.Itoa(rl.size),
-			"-",
-			parseResponseTime(rl.start),
-		}, " "))
-	case TinyLoggerType:
-		fmt.Fprintln(rh.writer, strings.Join([]string{
-			req.Method,
-			req.RequestURI,
-			strconv.Itoa(rl.status),
-			strconv.Itoa(rl.size),
-			"-",
-			parseResponseTime(rl.start),
-		}, " "))
-	}
-}
-
-func parseResponseTime(start time.Time) string {
-	return fmt.Sprintf("%.3f ms", time.Now().Sub(start).Seconds()/1e6)
+	if err := rh.formatter.Format(rh.writer, e); err != nil {
+		rh.failed(err)
+	} else if rh.async == nil {
+		// the workers count the queued ones once written
+		rh.counters.written.Add(1)
+	}
 }
 
-// DefaultHandler returns a http.Handler that wraps h by using
-// Apache combined log output and print to os.Stdout
-func DefaultHandler(h http.Handler) http.Handler {
-	return loggerHanlder{
+// logged returns req as it appears in the log output
+func (rh loggerHanlder) logged(req *http.Request) *http.Request {
+	if rh.redactor.enabled() {
+		req = rh.redactor.request(req)
+	}
+
+	if rh.limits != nil {
+		req = rh.limits.request(req)
+	}
+
+	if rh.proxies != nil {
+		if addr := rh.proxies.clientAddr(req); addr != req.RemoteAddr {
+			resolved := *req
+			resolved.RemoteAddr = addr
+			req = &resolved
+		}
+	}
+
+	return req
+}
+
+// Flush blocks until every entry queued by WithAsync has been written and
+// flushes writers buffering the output, such as a *bufio.Writer. The
+// http.Handler returned by New implements it.
+func (rh loggerHanlder) Flush() error {
+	if rh.async != nil {
+		rh.async.Flush()
+	}
+
+	return errors.Join(rh.flushRoutes(), rh.flushStatusWriters(), rh.flushWriter(), rh.flushOutputs())
+}
+
+// Close writes the entries queued by WithAsync, stops the background
+// workers, flushes buffering writers and closes the writers created by the
+// options such as WithSyslog. Call it on shutdown once the server stopped
+// serving requests. The http.Handler returned by New implements io.Closer.
+func (rh loggerHanlder) Close() error {
+	// the overrides may write to the writer of rh
+	errs := []error{rh.closeRoutes(), rh.closeStatusWriters()}
+
+	if rh.repeats != nil {
+		for _, d := range rh.repeats.reset() {
+			rh.repeated(d)
+		}
+	}
+
+	if rh.limiter != nil {
+		if n := rh.limiter.reset(); n > 0 {
+			rh.summary(n)
+		}
+	}
+
+	if rh.async != nil {
+		errs = append(errs, rh.async.Close())
+	}
+
+	errs = append(errs, rh.flushWriter())
+
+	for _, c := range rh.owned {
+		errs = append(errs, c.Close())
+	}
+
+	return errors.Join(append(errs, rh.closeOutputs())...)
+}
+
+// flushWriter flushes the writer entries end up in when it buffers them
+func (rh loggerHanlder) flushWriter() error {
+	w := rh.writer
+	if rh.async != nil {
+		w = rh.async.w
+	}
+
+	if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// jsonFormatter logs entries with dotted field names to a Backend, logrus
+// by default
+type jsonFormatter struct {
+	backend Backend
+}
+
+func (jf jsonFormatter) Format(_ io.Writer, e *Entry) error {
+	fields := log.Fields{
+		// request
+		"request.host":       e.Host,
+		"request.method":     e.Method,
+		"request.proto":      e.Proto,
+		"request.url":        e.URL,
+		"request.referer":    e.Referer,
+		"request.user_agent": e.UserAgent,
+		"request.header":     e.Header,
+		"request.size":       strconv.FormatInt(e.RequestSize, 10),
+		"start_time":         timeText(e.Start, e.timeLayout),
+		// response
+		"response.status":   strconv.Itoa(e.Status),
+		"response.size":     strconv.Itoa(e.Size),
+		"response.ttfb_ms":  milliseconds(e.TTFB),
+		"response.total_ms": milliseconds(e.Duration),
+		"client_address":    e.RemoteAddr,
+	}
+
+	if e.ContentLength >= 0 {
+		fields["response.content_length"] = strconv.FormatInt(e.ContentLength, 10)
+	}
+
+	if e.ResponseHeader != nil {
+		fields["response.header"] = e.ResponseHeader
+	}
+
+	if e.Trailer != nil {
+		fields["response.trailer"] = e.Trailer
+	}
+
+	if e.Hijacked {
+		fields["response.hijacked"] = true
+	}
+
+	if e.Empty {
+		fields["response.empty"] = true
+	}
+
+	if e.ContentEncoding != "" {
+		fields["response.content_encoding"] = e.ContentEncoding
+
+		if e.BodySize >= 0 {
+			fields["response.body_size"] = strconv.FormatInt(e.BodySize, 10)
+		}
+	}
+
+	if e.Range != "" {
+		fields["request.range"] = e.Range
+	}
+
+	if e.ContentRange != "" {
+		fields["response.content_range"] = e.ContentRange
+	}
+
+	if e.CacheState != "" {
+		fields["response.cache_state"] = e.CacheState
+	}
+
+	if e.Streamed {
+		fields["response.streamed"] = true
+		fields["response.flushes"] = strconv.Itoa(e.Flushes)
+	}
+
+	if e.Preliminary {
+		fields["response.preliminary"] = true
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		fields["websocket.state"] = ws.state()
+		fields["websocket.duration_ms"] = milliseconds(ws.Duration)
+		fields["websocket.frames_in"] = strconv.FormatInt(ws.FramesIn, 10)
+		fields["websocket.frames_out"] = strconv.FormatInt(ws.FramesOut, 10)
+		fields["websocket.bytes_in"] = strconv.FormatInt(ws.BytesIn, 10)
+		fields["websocket.bytes_out"] = strconv.FormatInt(ws.BytesOut, 10)
+
+		if ws.CloseCode != 0 {
+			fields["websocket.close_code"] = strconv.Itoa(ws.CloseCode)
+		}
+	}
+
+	if e.ConnID != "" {
+		fields["connection.id"] = e.ConnID
+	}
+
+	if e.Aborted != nil {
+		fields["request.aborted"] = true
+		fields["request.abort_error"] = e.Aborted.Error()
+	}
+
+	if e.RequestID != "" {
+		fields["request.id"] = e.RequestID
+	}
+
+	if e.CorrelationID != "" {
+		fields["request.correlation_id"] = e.CorrelationID
+	}
+
+	if e.AmznTraceID != "" {
+		fields["request.amzn_trace_id"] = e.AmznTraceID
+	}
+
+	if e.RemoteUser != "" {
+		fields["request.user"] = e.RemoteUser
+	}
+
+	if e.Route != "" {
+		fields["request.route"] = e.Route
+	}
+
+	if e.TraceID != "" {
+		fields["trace_id"] = e.TraceID
+		fields["span_id"] = e.SpanID
+	}
+
+	if e.TLS != nil {
+		fields["tls.version"] = e.TLS.Version
+		fields["tls.cipher"] = e.TLS.CipherSuite
+		fields["tls.sni"] = e.TLS.ServerName
+
+		if e.TLS.ClientSubject != "" {
+			fields["tls.client_subject"] = e.TLS.ClientSubject
+		}
+	}
+
+	if e.Panicked {
+		fields["panic"] = true
+		fields["panic.value"] = e.PanicValue
+		fields["panic.stack"] = string(e.Stack)
+	}
+
+	for k, v := range e.Fields {
+		if _, ok := fields[k]; !ok {
+			fields[k] = v
+		}
+	}
+
+	if e.Body != nil {
+		fields["body"] = string(e.Body)
+	}
+
+	if e.ResponseBody != nil {
+		fields["response.body"] = string(e.ResponseBody)
+	}
+
+	jf.backend.Log(e.Level, "request processed", fields)
+
+	return nil
+}
+
+// newLogrusLogger returns the logger used by JsonLoggerType when none is
+// given, so the global logrus configuration is left alone
+func newLogrusLogger() *log.Logger {
+	l := log.New()
+	l.Formatter = &log.JSONFormatter{}
+
+	return l
+}
+
+// New returns a http.Handler that wraps h and logs every request, it
+// defaults to Apache combined log output printed to os.Stdout
+func New(h http.Handler, opts ...Option) http.Handler {
+	rh := loggerHanlder{
 		h:          h,
 		formatType: CombineLoggerType,
 		writer:     os.Stdout,
+		clock:      realClock{},
+		directives: &sync.Once{},
+
+		slowThreshold: DefaultSlowThreshold,
+		bodyFilter:    DefaultBodyFilter,
+		resBodyTypes:  DefaultResponseBodyTypes,
+		level:         DefaultLevel,
+		counters:      &counters{},
+	}
+
+	for _, opt := range opts {
+		opt(&rh)
+	}
+
+	for _, t := range rh.targets {
+		rh.outputs = append(rh.outputs, rh.output(t))
+	}
+
+	base := rh
+	rh = rh.prepare()
+	rh.statusWriters = base.prepareStatusWriters(nil)
+
+	for i, ro := range rh.routes {
+		rh.routes[i].handler = base.override(ro, rh)
 	}
+
+	if rh.expvarName != "" {
+		rh.publish(rh.expvarName)
+	}
+
+	return rh.withSelf()
+}
+
+// withSelf returns rh along with a copy of it, which the responseLogger of
+// its requests calls back into
+func (rh loggerHanlder) withSelf() loggerHanlder {
+	self := new(loggerHanlder)
+	rh.self = self
+	*self = rh
+
+	return rh
+}
+
+// prepare finishes the setup of rh once the options are applied
+func (rh loggerHanlder) prepare() loggerHanlder {
+	if rh.formatType == DevLoggerType && rh.color.enabled(rh.writer) {
+		rh.tokens = colorDevFormat(rh.slowThreshold)
+	}
+
+	if rh.audit && rh.writer != nil {
+		rh.writer = newAuditWriter(rh.writer, rh.auditKey)
+	}
+
+	// every entry is a single Write, the lock keeps them from overlapping
+	if rh.writer != nil {
+		rh.writer = &lockedWriter{w: rh.writer}
+	}
+
+	// with targets the outputs buffer and write asynchronously on their own
+	if rh.bufferSize > 0 && len(rh.outputs) == 0 {
+		bw := newBufferedWriter(rh.writer, rh.bufferSize, rh.flushInterval)
+		rh.writer = bw
+		// flushed before the writers it may write to are closed
+		rh.owned = append([]io.Closer{bw}, rh.owned...)
+	}
+
+	if rh.asyncSize > 0 && len(rh.outputs) == 0 {
+		rh.async = newAsyncWriter(rh.writer, rh.asyncSize, rh.asyncWorkers, rh.overflow, rh.failed)
+		rh.async.written = &rh.counters.written
+		rh.writer = rh.async
+	}
+
+	if rh.backend == nil {
+		l := newLogrusLogger()
+		l.Out = rh.writer
+		rh.backend = LogrusBackend(l)
+	}
+
+	if rh.slog == nil {
+		rh.slog = slog.New(slog.NewJSONHandler(rh.writer, nil))
+	}
+
+	if rh.formatter == nil {
+		rh.formatter = rh.newFormatter()
+		rh.typedFormatter = true
+	}
+
+	if rh.wrapFormatter != nil {
+		rh.formatter = rh.wrapFormatter(rh.formatter)
+	}
+
+	return rh
+}
+
+// DefaultHandler returns a http.Handler that wraps h by using
+// Apache combined log output and print to os.Stdout
+func DefaultHandler(h http.Handler) http.Handler {
+	return New(h)
 }
 
 // Handler returns a http.Hanlder that wraps h by using t type log output
 // and print to writer
 func Handler(h http.Handler, writer io.Writer, t Type) http.Handler {
-	return loggerHanlder{
-		h:          h,
-		formatType: t,
-		writer:     writer,
-	}
+	return New(h, WithWriter(writer), WithFormat(t))
+}
+
+// HandlerWithFormat returns a http.Handler that wraps h by using a custom
+// log output and print to writer. format is made of morgan-style tokens,
+// e.g. ":remote-addr :method :url :status :response-time ms", and is parsed
+// once here rather than on every request.
+//
+// Supported tokens: :remote-addr, :remote-user, :date[clf|iso|web], :method,
+// :url, :http-version, :status, :res[content-length], :referrer,
+// :user-agent, :request-id, :correlation-id, :amzn-trace-id, :route,
+// :trace-id, :span-id, :tls-version, :tls-cipher, :tls-sni,
+// :tls-client-subject, :custom[key], :ttfb, :response-time and
+// :duration-unit
+func HandlerWithFormat(h http.Handler, writer io.Writer, format string) http.Handler {
+	return New(h, WithWriter(writer), WithCustomFormat(format))
+}
+
+// HandlerWithBody returns a http.Handler like Handler that also captures up
+// to maxBytes of the request body as the downstream handler reads it, for
+// the JSON log output, see DefaultBodyFilter for the bodies captured. The
+// body is never read by the middleware itself.
+func HandlerWithBody(h http.Handler, writer io.Writer, t Type, maxBytes int) http.Handler {
+	return New(h, WithWriter(writer), WithFormat(t), WithBodyCapture(maxBytes))
 }
@@ -1,11 +1,16 @@
 package logger
 
 import (
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -46,64 +51,196 @@ func (s *LoggerSuite) TestHanlder() {
 
 	dh.ServeHTTP(s.rl, s.req)
 
-	s.Equal("GET / 404 19 - 0.000 ms\n", string(tw.Bytes))
+	s.Regexp(`^GET / 404 19 - \d+\.\d{3} ms\n$`, string(tw.Bytes))
 }
 
 func (s *LoggerSuite) TestTiny() {
-	lh := loggerHanlder{
-		h:          http.NotFoundHandler(),
-		formatType: TinyLoggerType,
-		writer:     s.w,
-	}
+	lh := New(http.NotFoundHandler(), WithFormat(TinyLoggerType), WithWriter(s.w)).(loggerHanlder)
 	lh.write(s.rl, s.req)
 
 	s.Equal("GET / 200 11 - 0.000 ms\n", string(s.w.Bytes))
 }
 
 func (s *LoggerSuite) TestShort() {
-	lh := loggerHanlder{
-		h:          http.NotFoundHandler(),
-		formatType: ShortLoggerType,
-		writer:     s.w,
-	}
+	lh := New(http.NotFoundHandler(), WithFormat(ShortLoggerType), WithWriter(s.w)).(loggerHanlder)
 	lh.write(s.rl, s.req)
 
 	s.Equal("192.0.2.1:1234 - GET / HTTP/1.1 200 11 - 0.000 ms\n", string(s.w.Bytes))
 }
 
 func (s *LoggerSuite) TestDev() {
-	lh := loggerHanlder{
-		h:          http.NotFoundHandler(),
-		formatType: DevLoggerType,
-		writer:     s.w,
-	}
+	lh := New(http.NotFoundHandler(), WithFormat(DevLoggerType), WithWriter(s.w)).(loggerHanlder)
 	lh.write(s.rl, s.req)
 
 	s.Equal("GET / 200 0.000 ms - 11\n", string(s.w.Bytes))
 }
 
 func (s *LoggerSuite) TestCommon() {
-	lh := loggerHanlder{
-		h:          http.NotFoundHandler(),
-		formatType: CommonLoggerType,
-		writer:     s.w,
-	}
+	lh := New(http.NotFoundHandler(), WithFormat(CommonLoggerType), WithWriter(s.w)).(loggerHanlder)
 	lh.write(s.rl, s.req)
 
 	s.Equal(`192.0.2.1:1234 - - [`+s.rl.start.Format(timeFormat)+`] "GET / HTTP/1.1" 200 11`+"\n", string(s.w.Bytes))
 }
 
 func (s *LoggerSuite) TestCombined() {
-	lh := loggerHanlder{
-		h:          http.NotFoundHandler(),
-		formatType: CombineLoggerType,
-		writer:     s.w,
-	}
+	lh := New(http.NotFoundHandler(), WithFormat(CombineLoggerType), WithWriter(s.w)).(loggerHanlder)
 	lh.write(s.rl, s.req)
 
 	s.Equal(`192.0.2.1:1234 - - [`+s.rl.start.Format(timeFormat)+`] "GET / HTTP/1.1" 200 11 "" ""`+"\n", string(s.w.Bytes))
 }
 
+func (s *LoggerSuite) TestTTFB() {
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), step: 10 * time.Millisecond}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusOK)
+		clock.Now()
+		res.Write([]byte("streamed"))
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(s.w)), WithClock(clock))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal(10.0, entry["response.ttfb_ms"])
+	s.Equal(30.0, entry["response.total_ms"])
+}
+
+func (s *LoggerSuite) TestTTFBNoWrite() {
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), step: 10 * time.Millisecond}
+	h := New(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(s.w)), WithClock(clock))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal(10.0, entry["response.ttfb_ms"])
+	s.Equal(10.0, entry["response.total_ms"])
+}
+
+func (s *LoggerSuite) TestTimeFormatJSON() {
+	clock := testClock{time.Date(2017, time.January, 2, 15, 4, 5, 0, time.FixedZone("CET", 3600))}
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(s.w)),
+		WithClock(clock), WithTimeFormat(time.RFC3339), WithUTC(true))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal("2017-01-02T14:04:05Z", entry["start_time"])
+}
+
+func (s *LoggerSuite) TestJSONWriter() {
+	h := Handler(http.NotFoundHandler(), s.w, JsonLoggerType)
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal("404", entry["response.status"])
+	s.Equal("request processed", entry["msg"])
+}
+
+func (s *LoggerSuite) TestAborted() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// the client goes away
+		cancel()
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(s.w)))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req.WithContext(ctx))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal(true, entry["request.aborted"])
+	s.Equal("context canceled", entry["request.abort_error"])
+}
+
+func (s *LoggerSuite) TestAbortedToken() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}), WithWriter(s.w), WithCustomFormat(":method :aborted"))
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req.WithContext(ctx))
+	h.ServeHTTP(httptest.NewRecorder(), s.req.WithContext(canceled))
+	New(http.NotFoundHandler(), WithWriter(s.w), WithCustomFormat(":method :aborted")).
+		ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("GET deadline-exceeded\nGET canceled\nGET -\n", string(s.w.Bytes))
+}
+
+func (s *LoggerSuite) TestStreamed() {
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), step: 10 * time.Millisecond}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		for _, event := range []string{"data: a\n\n", "data: b\n\n"} {
+			res.Write([]byte(event))
+			res.(http.Flusher).Flush()
+		}
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(s.w)), WithClock(clock))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.w.Bytes, &entry))
+	s.Equal(true, entry["response.streamed"])
+	s.Equal("2", entry["response.flushes"])
+	s.Equal(10.0, entry["response.ttfb_ms"])
+	s.Equal(20.0, entry["response.total_ms"])
+	s.NotContains(entry, "response.preliminary")
+}
+
+func (s *LoggerSuite) TestStreamEntries() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		for i := 0; i < 3; i++ {
+			res.Write([]byte("data: ping\n\n"))
+			res.(http.Flusher).Flush()
+		}
+	}), WithWriter(s.w), WithFormat(NDJSONLoggerType), WithStreamEntries(true), WithDeduplication(time.Minute))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	lines := strings.Split(strings.TrimSpace(string(s.w.Bytes)), "\n")
+	s.Require().Len(lines, 2)
+
+	start, end := map[string]interface{}{}, map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(lines[0]), &start))
+	s.Nil(json.Unmarshal([]byte(lines[1]), &end))
+
+	s.Equal(true, start["response.preliminary"])
+	s.Equal(float64(1), start["response.flushes"])
+	s.Equal(float64(12), start["response.size"])
+
+	s.NotContains(end, "response.preliminary")
+	s.Equal(true, end["response.streamed"])
+	s.Equal(float64(3), end["response.flushes"])
+	s.Equal(float64(36), end["response.size"])
+}
+
+func (s *LoggerSuite) TestFlushesToken() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/events" {
+			res.(http.Flusher).Flush()
+
+			return
+		}
+
+		res.WriteHeader(http.StatusNoContent)
+	}), WithWriter(s.w), WithCustomFormat(":url :status :flushes"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("/events 200 1\n/ 204 0\n", string(s.w.Bytes))
+}
+
 func TestLogger(t *testing.T) {
 	suite.Run(t, new(LoggerSuite))
 }
@@ -135,3 +272,24 @@ func (tw *testWriter) Write(b []byte) (n int, err error) {
 
 	return len(b), nil
 }
+
+// stepClock moves forward by step every time it's read
+type stepClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (sc *stepClock) Now() time.Time {
+	now := sc.now
+	sc.now = sc.now.Add(sc.step)
+
+	return now
+}
+
+func newTestLogrus(w io.Writer) *log.Logger {
+	l := log.New()
+	l.Out = w
+	l.Formatter = &log.JSONFormatter{}
+
+	return l
+}
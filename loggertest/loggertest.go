@@ -0,0 +1,161 @@
+// Package loggertest helps testing what services log with the logger
+// package, see Recorder.
+package loggertest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/go-http-utils/logger/parse"
+)
+
+// Recorder is an io.Writer parsing the log output back into entries, e.g.:
+//
+//	rec := loggertest.NewRecorder(logger.JsonLoggerType)
+//	h := logger.New(mux, rec.Options()...)
+//
+//	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+//	rec.AssertLogged(t, http.MethodGet, "/users/1", http.StatusOK)
+//
+// The formats supported by parse.Line can be recorded. It's safe for
+// concurrent use.
+type Recorder struct {
+	t logger.Type
+
+	mu      sync.Mutex
+	lines   []string
+	entries []*parse.Entry
+	err     error
+}
+
+// NewRecorder returns a Recorder parsing the t log output
+func NewRecorder(t logger.Type) *Recorder {
+	return &Recorder{t: t}
+}
+
+// Options returns the options writing the log output of a handler to r,
+// in its format
+func (r *Recorder) Options() []logger.Option {
+	return []logger.Option{logger.WithWriter(r), logger.WithFormat(r.t)}
+}
+
+// Write parses the lines of p, the lines which don't parse are only kept
+// by Lines and make Err return an error
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimRight(line, "\r"); line == "" {
+			continue
+		}
+
+		r.lines = append(r.lines, line)
+
+		e, err := parse.Line(r.t, line)
+		if err != nil {
+			if r.err == nil {
+				r.err = err
+			}
+
+			continue
+		}
+
+		r.entries = append(r.entries, e)
+	}
+
+	return len(p), nil
+}
+
+// Lines returns the lines written so far
+func (r *Recorder) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string{}, r.lines...)
+}
+
+// Entries returns the entries written so far
+func (r *Recorder) Entries() []*parse.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*parse.Entry{}, r.entries...)
+}
+
+// LastEntry returns the last entry written, nil if there's none
+func (r *Recorder) LastEntry() *parse.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) == 0 {
+		return nil
+	}
+
+	return r.entries[len(r.entries)-1]
+}
+
+// EntriesFor returns the entries of the requests to path, whatever their
+// query string
+func (r *Recorder) EntriesFor(path string) []*parse.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []*parse.Entry
+	for _, e := range r.entries {
+		if p, _, _ := strings.Cut(e.URL, "?"); p == path {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries
+}
+
+// Err returns the error of the first line which didn't parse
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// Reset forgets the lines written so far
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines, r.entries, r.err = nil, nil, nil
+}
+
+// AssertLogged reports an error to tb unless a method request to path was
+// logged with status, it returns whether one was
+func (r *Recorder) AssertLogged(tb testing.TB, method, path string, status int) bool {
+	tb.Helper()
+
+	for _, e := range r.EntriesFor(path) {
+		if e.Method == method && e.Status == status {
+			return true
+		}
+	}
+
+	tb.Errorf("loggertest: no %s %s request logged with status %d in:\n%s", method, path, status,
+		strings.Join(r.Lines(), "\n"))
+
+	return false
+}
+
+// AssertNotLogged reports an error to tb if a request to path was logged,
+// it returns whether none was
+func (r *Recorder) AssertNotLogged(tb testing.TB, path string) bool {
+	tb.Helper()
+
+	if entries := r.EntriesFor(path); len(entries) > 0 {
+		tb.Errorf("loggertest: %d requests to %s logged", len(entries), path)
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,102 @@
+package loggertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeTB records the errors reported by the assertions
+type fakeTB struct {
+	testing.TB
+
+	errors []string
+}
+
+func (tb *fakeTB) Helper() {}
+
+func (tb *fakeTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+type RecorderSuite struct {
+	suite.Suite
+}
+
+func (s *RecorderSuite) serve(h http.Handler, method, url string) {
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, url, nil))
+}
+
+func (s *RecorderSuite) TestEntries() {
+	for _, t := range []logger.Type{logger.JsonLoggerType, logger.CombineLoggerType, logger.TinyLoggerType} {
+		rec := NewRecorder(t)
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{id}", func(res http.ResponseWriter, req *http.Request) {
+			res.Write([]byte("user"))
+		})
+		h := logger.New(mux, rec.Options()...)
+
+		s.Nil(rec.LastEntry())
+
+		s.serve(h, http.MethodGet, "/users/1?fields=name")
+		s.serve(h, http.MethodGet, "/users/2")
+		s.serve(h, http.MethodDelete, "/users/1")
+
+		s.Nil(rec.Err(), "type %d", t)
+		s.Len(rec.Entries(), 3)
+		s.Len(rec.Lines(), 3)
+
+		last := rec.LastEntry()
+		s.Equal(http.MethodDelete, last.Method)
+		s.Equal(http.StatusMethodNotAllowed, last.Status)
+
+		entries := rec.EntriesFor("/users/1")
+		s.Len(entries, 2)
+		s.Equal("/users/1?fields=name", entries[0].URL)
+		s.Equal(http.StatusOK, entries[0].Status)
+		s.Equal(4, entries[0].Size)
+
+		rec.Reset()
+		s.Empty(rec.Entries())
+		s.Nil(rec.LastEntry())
+	}
+}
+
+func (s *RecorderSuite) TestAssertions() {
+	rec := NewRecorder(logger.CombineLoggerType)
+	h := logger.New(http.NotFoundHandler(), rec.Options()...)
+
+	s.serve(h, http.MethodGet, "/missing")
+
+	tb := &fakeTB{}
+	s.True(rec.AssertLogged(tb, http.MethodGet, "/missing", http.StatusNotFound))
+	s.True(rec.AssertNotLogged(tb, "/other"))
+	s.Empty(tb.errors)
+
+	s.False(rec.AssertLogged(tb, http.MethodGet, "/missing", http.StatusOK))
+	s.False(rec.AssertNotLogged(tb, "/missing"))
+	s.Len(tb.errors, 2)
+	s.Contains(tb.errors[0], "loggertest: no GET /missing request logged with status 200 in:\n192.0.2.1")
+	s.Equal("loggertest: 1 requests to /missing logged", tb.errors[1])
+}
+
+func (s *RecorderSuite) TestUnparsed() {
+	rec := NewRecorder(logger.CombineLoggerType)
+
+	n, err := rec.Write([]byte("not an access log line\n"))
+	s.Nil(err)
+	s.Equal(23, n)
+
+	s.Error(rec.Err())
+	s.Equal([]string{"not an access log line"}, rec.Lines())
+	s.Empty(rec.Entries())
+}
+
+func TestRecorder(t *testing.T) {
+	suite.Run(t, new(RecorderSuite))
+}
@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Loki batching and retry defaults
+const (
+	lokiBatchWait  = time.Second
+	lokiBatchSize  = 1000
+	lokiRetries    = 5
+	lokiMinBackoff = 500 * time.Millisecond
+	lokiMaxBackoff = 30 * time.Second
+)
+
+// LokiTarget returns a Target pushing every request to the Grafana Loki
+// push API at url, e.g. "http://localhost:3100/loki/api/v1/push". Entries
+// are grouped in streams labeled with labels, the status class (status
+// "2xx"), the method, "OTHER" for non standard ones, and service_name,
+// default to the program name. They are pushed in batches every second or
+// 1000 entries, retried with backoff on 429 and 5xx responses.
+//
+// The lines are printed by the formatter of the Target's Type and Options,
+// default to CombineLoggerType, set the Type to GELFLoggerType for JSON
+// lines. Close the handler on shutdown to push the last batch.
+func LokiTarget(url string, labels map[string]string) Target {
+	lc := newLokiClient(url, labels)
+
+	return Target{
+		Writer: lc,
+		Type:   CombineLoggerType,
+		Options: []Option{func(rh *loggerHanlder) {
+			rh.wrapFormatter = func(line Formatter) Formatter {
+				switch line.(type) {
+				case nil, jsonFormatter, slogFormatter:
+					// they don't print to the writer
+					line = textFormatter{formats[CombineLoggerType]}
+				}
+
+				return lokiFormatter{lc, line}
+			}
+			rh.owned = append(rh.owned, lc)
+		}},
+	}
+}
+
+// lokiFormatter pushes the line printed by line to the stream of the
+// entry
+type lokiFormatter struct {
+	lc   *lokiClient
+	line Formatter
+}
+
+func (lf lokiFormatter) Format(_ io.Writer, e *Entry) error {
+	var b bytes.Buffer
+	if err := lf.line.Format(&b, e); err != nil {
+		return err
+	}
+
+	lf.lc.push(map[string]string{
+		"status": strconv.Itoa(e.Status/100) + "xx",
+		"method": metricMethod(e.Method),
+	}, e.Start, b.String())
+
+	return nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiClient batches lines into streams and pushes them to Loki in the
+// background
+type lokiClient struct {
+	url    string
+	labels map[string]string
+	client *http.Client
+
+	batchWait  time.Duration
+	batchSize  int
+	retries    int
+	minBackoff time.Duration
+
+	mu      sync.Mutex
+	streams map[string]*lokiStream
+	size    int
+
+	// sending serializes the pushes
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+func newLokiClient(url string, labels map[string]string) *lokiClient {
+	base := map[string]string{"service_name": filepath.Base(os.Args[0])}
+	for k, v := range labels {
+		base[k] = v
+	}
+
+	lc := &lokiClient{
+		url:    url,
+		labels: base,
+		client: &http.Client{Timeout: 10 * time.Second},
+
+		batchWait:  lokiBatchWait,
+		batchSize:  lokiBatchSize,
+		retries:    lokiRetries,
+		minBackoff: lokiMinBackoff,
+
+		streams: map[string]*lokiStream{},
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	lc.wg.Add(1)
+	go lc.run()
+
+	return lc
+}
+
+func (lc *lokiClient) run() {
+	defer lc.wg.Done()
+
+	ticker := time.NewTicker(lc.batchWait)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-lc.wake:
+		case <-lc.done:
+			return
+		}
+
+		lc.Flush()
+	}
+}
+
+// push adds line to the stream of the base labels merged with labels
+func (lc *lokiClient) push(labels map[string]string, t time.Time, line string) {
+	stream := make(map[string]string, len(lc.labels)+len(labels))
+	for k, v := range lc.labels {
+		stream[k] = v
+	}
+	for k, v := range labels {
+		stream[k] = v
+	}
+
+	keys := make([]string, 0, len(stream))
+	for k := range stream {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var id strings.Builder
+	for _, k := range keys {
+		id.WriteString(k + "=" + stream[k] + ",")
+	}
+
+	lc.mu.Lock()
+	s, ok := lc.streams[id.String()]
+	if !ok {
+		s = &lokiStream{Stream: stream}
+		lc.streams[id.String()] = s
+	}
+
+	s.Values = append(s.Values, [2]string{strconv.FormatInt(t.UnixNano(), 10), strings.TrimRight(line, "\n")})
+	lc.size++
+	full := lc.size >= lc.batchSize
+	lc.mu.Unlock()
+
+	if full {
+		select {
+		case lc.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Write pushes p to the stream of the base labels, e.g. the summary lines
+// of WithRateLimit
+func (lc *lokiClient) Write(p []byte) (int, error) {
+	lc.push(nil, time.Now(), string(p))
+
+	return len(p), nil
+}
+
+// Flush pushes the pending batch
+func (lc *lokiClient) Flush() error {
+	lc.sending.Lock()
+	defer lc.sending.Unlock()
+
+	lc.mu.Lock()
+	streams := make([]*lokiStream, 0, len(lc.streams))
+	for _, s := range lc.streams {
+		streams = append(streams, s)
+	}
+	lc.streams, lc.size = map[string]*lokiStream{}, 0
+	lc.mu.Unlock()
+
+	if len(streams) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return err
+	}
+
+	return lc.send(body)
+}
+
+// send posts body, retrying with an exponential backoff when Loki is
+// unreachable, rate limits or fails
+func (lc *lokiClient) send(body []byte) error {
+	backoff := lc.minBackoff
+
+	var err error
+	for attempt := 0; attempt <= lc.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff = min(2*backoff, lokiMaxBackoff)
+		}
+
+		var res *http.Response
+		res, err = lc.client.Post(lc.url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			continue
+		}
+
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		if res.StatusCode < 300 {
+			return nil
+		}
+
+		err = fmt.Errorf("logger: loki push failed with status %d", res.StatusCode)
+		if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+			return err
+		}
+	}
+
+	return err
+}
+
+// Close stops the background pushes and pushes the pending batch
+func (lc *lokiClient) Close() error {
+	var err error
+
+	lc.once.Do(func() {
+		close(lc.done)
+		lc.wg.Wait()
+
+		err = lc.Flush()
+	})
+
+	return err
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LokiSuite struct {
+	suite.Suite
+
+	mu       sync.Mutex
+	pushes   []map[string][]lokiStream
+	statuses []int
+	server   *httptest.Server
+}
+
+func (s *LokiSuite) SetupTest() {
+	s.pushes, s.statuses = nil, nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			res.WriteHeader(status)
+
+			return
+		}
+
+		push := map[string][]lokiStream{}
+		json.NewDecoder(req.Body).Decode(&push)
+		s.pushes = append(s.pushes, push)
+		res.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func (s *LokiSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *LokiSuite) TestStreams() {
+	target := LokiTarget(s.server.URL, map[string]string{"service_name": "api", "env": "prod"})
+	target.Options = append(target.Options, WithCustomFormat(":method :status"))
+
+	h := NewLogger(http.NotFoundHandler(), WithTargets(target), WithClock(testClock{time.Unix(1, 5)}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(h.Flush())
+
+	s.Len(s.pushes, 1)
+	streams := s.pushes[0]["streams"]
+	s.Len(streams, 2)
+
+	byMethod := map[string]lokiStream{}
+	for _, stream := range streams {
+		byMethod[stream.Stream["method"]] = stream
+	}
+
+	s.Equal(map[string]string{"service_name": "api", "env": "prod", "status": "4xx", "method": "GET"},
+		byMethod["GET"].Stream)
+	s.Equal([][2]string{{"1000000005", "GET 404"}, {"1000000005", "GET 404"}}, byMethod["GET"].Values)
+	s.Equal([][2]string{{"1000000005", "POST 404"}}, byMethod["POST"].Values)
+}
+
+func (s *LokiSuite) TestClose() {
+	target := LokiTarget(s.server.URL, nil)
+	target.Type = GELFLoggerType
+
+	h := NewLogger(http.NotFoundHandler(), WithTargets(target))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	s.Nil(h.Close(context.Background()))
+
+	s.Len(s.pushes, 1)
+	stream := s.pushes[0]["streams"][0]
+	s.NotEmpty(stream.Stream["service_name"])
+
+	line := map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(stream.Values[0][1]), &line))
+	s.Equal("/users", line["_url"])
+}
+
+func (s *LokiSuite) TestRetry() {
+	lc := LokiTarget(s.server.URL, nil).Writer.(*lokiClient)
+	defer lc.Close()
+	lc.minBackoff = time.Millisecond
+
+	s.statuses = []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	lc.Write([]byte("line\n"))
+	s.Nil(lc.Flush())
+	s.Len(s.pushes, 1)
+	s.Equal("line", s.pushes[0]["streams"][0].Values[0][1])
+
+	s.statuses = []int{http.StatusBadRequest}
+	lc.Write([]byte("bad\n"))
+	s.EqualError(lc.Flush(), "logger: loki push failed with status 400")
+	s.Len(s.pushes, 1)
+
+	lc.retries = 1
+	s.statuses = []int{http.StatusBadGateway, http.StatusBadGateway}
+	lc.Write([]byte("down\n"))
+	s.EqualError(lc.Flush(), "logger: loki push failed with status 502")
+}
+
+func (s *LokiSuite) TestBatchSize() {
+	lc := LokiTarget(s.server.URL, nil).Writer.(*lokiClient)
+	defer lc.Close()
+	lc.batchSize = 2
+
+	lc.Write([]byte("a"))
+	lc.Write([]byte("b"))
+
+	s.Eventually(func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return len(s.pushes) == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestLoki(t *testing.T) {
+	suite.Run(t, new(LokiSuite))
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// MaskRule tells which sensitive data WithMasking replaces with
+// [REDACTED]: the matches of a pattern in any text, or the value at a path
+// of JSON bodies
+type MaskRule struct {
+	re      *regexp.Regexp
+	replace func(match string) string
+	path    []string
+}
+
+// Built-in MaskRules
+var (
+	// MaskEmails masks email addresses
+	MaskEmails = MaskPattern(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// MaskCreditCards masks the card numbers of 13 to 19 digits, separated
+	// by spaces or dashes or not, which pass the Luhn check
+	MaskCreditCards = MaskRule{
+		re: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		replace: func(match string) string {
+			if !luhn(match) {
+				return match
+			}
+
+			return redactedValue
+		},
+	}
+	// MaskSSNs masks US social security numbers, e.g. 078-05-1120
+	MaskSSNs = MaskPattern(`\b\d{3}-\d{2}-\d{4}\b`)
+	// MaskBearerTokens masks the tokens of bearer authorizations, keeping
+	// the scheme
+	MaskBearerTokens = MaskRule{
+		re: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+		replace: func(match string) string {
+			return match[:len("bearer")] + " " + redactedValue
+		},
+	}
+)
+
+// MaskPattern returns a MaskRule masking the matches of the regular
+// expression pattern, it panics when pattern doesn't compile
+func MaskPattern(pattern string) MaskRule {
+	return MaskRule{re: regexp.MustCompile(pattern)}
+}
+
+// MaskJSONPath returns a MaskRule masking the value at path of JSON bodies,
+// keys separated by dots with "*" matching any key or array element, e.g.
+// "password", "card.number" or "users.*.email". A leading "$." is allowed.
+// Bodies which aren't complete JSON documents are masked as a whole.
+func MaskJSONPath(path string) MaskRule {
+	return MaskRule{path: strings.Split(strings.TrimPrefix(path, "$."), ".")}
+}
+
+// masker applies the MaskRules of WithMasking
+type masker struct {
+	patterns []MaskRule
+	paths    [][]string
+	fields   map[string]bool
+}
+
+func (m *masker) add(rules ...MaskRule) {
+	for _, rule := range rules {
+		if rule.path != nil {
+			m.paths = append(m.paths, rule.path)
+		} else {
+			m.patterns = append(m.patterns, rule)
+		}
+	}
+}
+
+func (m masker) enabled() bool {
+	return len(m.patterns) > 0 || len(m.paths) > 0
+}
+
+// entry masks the bodies of e and the string values of the selected
+// fields
+func (m masker) entry(e *Entry) {
+	if e.Body != nil {
+		e.Body = m.body(e.Body)
+	}
+
+	if e.ResponseBody != nil {
+		e.ResponseBody = m.body(e.ResponseBody)
+	}
+
+	for k, v := range e.Fields {
+		if s, ok := v.(string); ok && m.fields[k] {
+			e.Fields[k] = m.text(s)
+		}
+	}
+}
+
+// body masks the JSON paths of body then the patterns. With JSON paths,
+// a body which isn't a complete JSON document, e.g. cut off by the capture
+// limit, is replaced with [REDACTED] as a whole.
+func (m masker) body(body []byte) []byte {
+	if len(m.paths) > 0 && len(body) > 0 {
+		var doc interface{}
+
+		d := json.NewDecoder(bytes.NewReader(body))
+		d.UseNumber()
+		if d.Decode(&doc) != nil {
+			return []byte(redactedValue)
+		}
+
+		for _, path := range m.paths {
+			doc = maskPath(doc, path)
+		}
+
+		masked, err := json.Marshal(doc)
+		if err != nil {
+			return []byte(redactedValue)
+		}
+		body = masked
+	}
+
+	return []byte(m.text(string(body)))
+}
+
+func (m masker) text(s string) string {
+	for _, rule := range m.patterns {
+		if rule.replace != nil {
+			s = rule.re.ReplaceAllStringFunc(s, rule.replace)
+		} else {
+			s = rule.re.ReplaceAllLiteralString(s, redactedValue)
+		}
+	}
+
+	return s
+}
+
+// maskPath returns v with the values at path replaced with [REDACTED]
+func maskPath(v interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return redactedValue
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if path[0] == "*" || path[0] == k {
+				v[k] = maskPath(child, path[1:])
+			}
+		}
+	case []interface{}:
+		if path[0] == "*" {
+			for i, child := range v {
+				v[i] = maskPath(child, path[1:])
+			}
+		}
+	}
+
+	return v
+}
+
+// luhn reports whether the digits of number pass the Luhn checksum
+func luhn(number string) bool {
+	sum, double := 0, false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics records Prometheus metrics from the same data as the log output
+type metrics struct {
+	requests *prometheus.CounterVec
+	inFlight prometheus.Gauge
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+var metricsLabels = []string{"method", "status", "route"}
+
+// metricMethod returns method when it is a standard HTTP method and "OTHER"
+// otherwise, so arbitrary methods can't create unbounded series
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "OTHER"
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests processed.",
+		}, metricsLabels),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being processed.",
+		}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		}, metricsLabels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP responses.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 7),
+		}, metricsLabels),
+	}
+
+	m.requests = register(registerer, m.requests).(*prometheus.CounterVec)
+	m.inFlight = register(registerer, m.inFlight).(prometheus.Gauge)
+	m.duration = register(registerer, m.duration).(*prometheus.HistogramVec)
+	m.size = register(registerer, m.size).(*prometheus.HistogramVec)
+
+	return m
+}
+
+// register registers c, or returns the identical collector already
+// registered so several handlers can share the same metrics
+func register(registerer prometheus.Registerer, c prometheus.Collector) prometheus.Collector {
+	if err := registerer.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector
+		}
+
+		panic(err)
+	}
+
+	return c
+}
+
+func (m *metrics) observe(rl *responseLogger, req *http.Request) {
+	labels := prometheus.Labels{
+		"method": metricMethod(req.Method),
+		"status": strconv.Itoa(rl.status),
+		"route":  rl.route,
+	}
+
+	m.requests.With(labels).Inc()
+	m.duration.With(labels).Observe(rl.duration.Seconds())
+	m.size.With(labels).Observe(float64(rl.size))
+}
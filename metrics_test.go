@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsSuite struct {
+	suite.Suite
+}
+
+func (s *MetricsSuite) TestMetrics() {
+	reg := prometheus.NewRegistry()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("user"))
+	})
+
+	h := New(mux, WithWriter(&testWriter{}), WithMetrics(reg))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	s.Nil(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP http_requests_total Number of HTTP requests processed.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="",status="404"} 1
+http_requests_total{method="GET",route="/users/{id}",status="200"} 2
+# HELP http_requests_in_flight Number of HTTP requests being processed.
+# TYPE http_requests_in_flight gauge
+http_requests_in_flight 0
+`), "http_requests_total", "http_requests_in_flight"))
+
+	s.Equal(2, testutil.CollectAndCount(reg, "http_request_duration_seconds"))
+	s.Equal(2, testutil.CollectAndCount(reg, "http_response_size_bytes"))
+}
+
+func (s *MetricsSuite) TestMethod() {
+	reg := prometheus.NewRegistry()
+
+	h := New(http.NotFoundHandler(), WithWriter(&testWriter{}), WithMetrics(reg))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PROPFIND", "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("X-RANDOM-1", "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/", nil))
+
+	s.Nil(testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP http_requests_total Number of HTTP requests processed.
+# TYPE http_requests_total counter
+http_requests_total{method="DELETE",route="",status="404"} 1
+http_requests_total{method="OTHER",route="",status="404"} 2
+`), "http_requests_total"))
+}
+
+func (s *MetricsSuite) TestInFlight() {
+	reg := prometheus.NewRegistry()
+	var inFlight float64
+
+	var m *metrics
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		inFlight = testutil.ToFloat64(m.inFlight)
+	}), WithWriter(&testWriter{}), WithMetrics(reg))
+	m = h.(loggerHanlder).metrics
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(float64(1), inFlight)
+	s.Equal(float64(0), testutil.ToFloat64(m.inFlight))
+}
+
+func (s *MetricsSuite) TestShared() {
+	reg := prometheus.NewRegistry()
+
+	h1 := New(http.NotFoundHandler(), WithWriter(&testWriter{}), WithMetrics(reg))
+	h2 := New(http.NotFoundHandler(), WithWriter(&testWriter{}), WithMetrics(reg))
+
+	h1.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h2.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(float64(2), testutil.ToFloat64(h1.(loggerHanlder).metrics.requests))
+}
+
+func TestMetrics(t *testing.T) {
+	suite.Run(t, new(MetricsSuite))
+}
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware returns a middleware logging the requests of the handlers it
+// wraps, configured by opts like New, e.g. for chi:
+//
+//	r := chi.NewRouter()
+//	r.Use(logger.Middleware(logger.WithFormat(logger.JsonLoggerType), logger.WithRouteFunc(chiroute.Route)))
+//
+// The handlers it wraps share a single logger, so its outputs are opened
+// once however many routes use it.
+func Middleware(opts ...Option) func(http.Handler) http.Handler {
+	h := New(http.HandlerFunc(serveNext), opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			h.ServeHTTP(res, req.WithContext(context.WithValue(req.Context(), nextKey, next)))
+		})
+	}
+}
+
+// serveNext serves req with the handler wrapped by Middleware
+func serveNext(res http.ResponseWriter, req *http.Request) {
+	req.Context().Value(nextKey).(http.Handler).ServeHTTP(res, req)
+}
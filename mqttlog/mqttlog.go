@@ -0,0 +1,234 @@
+// Package mqttlog publishes the entries of the logger package to an MQTT
+// broker, for edge and IoT gateways shipping their access logs over the
+// MQTT infrastructure they already have, see New.
+package mqttlog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-http-utils/logger"
+)
+
+// errTimeout is returned for the messages not acknowledged within the
+// publish timeout
+var errTimeout = errors.New("mqttlog: publish timeout")
+
+// Option configures the Publisher returned by New
+type Option func(*Publisher)
+
+// WithQoS sets the QoS of the messages, 0, 1 or 2, default to 0. New fails
+// for other values.
+func WithQoS(qos byte) Option {
+	return func(p *Publisher) {
+		p.qos = qos
+	}
+}
+
+// WithRetained sets whether the messages are retained by the broker,
+// default to false
+func WithRetained(retained bool) Option {
+	return func(p *Publisher) {
+		p.retained = retained
+	}
+}
+
+// WithPayload sets the formatter of the payloads, default to
+// logger.NDJSONFormatter, e.g. a logger.TemplateFormatter for constrained
+// consumers
+func WithPayload(f logger.Formatter) Option {
+	return func(p *Publisher) {
+		p.payload = f
+	}
+}
+
+// WithMaxPending sets the number of messages waiting for the broker past
+// which the entries are dropped with logger.ErrQueueFull, and counted by
+// the Dropped method of the handler, default to 1000. New fails for n <= 0.
+func WithMaxPending(n int) Option {
+	return func(p *Publisher) {
+		p.maxPending = n
+	}
+}
+
+// WithPublishTimeout sets how long a message waits for the broker, to be
+// sent with QoS 0 or acknowledged otherwise, default to 10 seconds. New
+// fails for d <= 0.
+func WithPublishTimeout(d time.Duration) Option {
+	return func(p *Publisher) {
+		p.timeout = d
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// messages published in the background, by default they are dropped
+// silently
+func WithErrorHandler(f func(error)) Option {
+	return func(p *Publisher) {
+		p.errors = f
+	}
+}
+
+// Publisher is a logger.Formatter publishing every entry as a message of
+// the topic rendered from its template, e.g.
+// logger.WithFormatter(publisher). Messages are published in the
+// background, the requests never wait for the broker. Close waits for the
+// pending messages, call it on shutdown.
+type Publisher struct {
+	client     mqtt.Client
+	topic      *template.Template
+	qos        byte
+	retained   bool
+	payload    logger.Formatter
+	maxPending int
+	timeout    time.Duration
+	errors     func(error)
+
+	// slots holds a value per pending message
+	slots chan struct{}
+
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+}
+
+// New returns a Publisher publishing to the topics rendered from the
+// text/template topic against every logger.Entry, e.g.
+// "gateways/gw-1/http/{{.Method}}/{{.Status}}", the '+' and '#' wildcards
+// being replaced with '_'. It fails when topic can't be parsed or an option
+// is invalid.
+func New(client mqtt.Client, topic string, opts ...Option) (*Publisher, error) {
+	t, err := template.New("topic").Parse(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Publisher{
+		client:     client,
+		topic:      t,
+		payload:    logger.NDJSONFormatter,
+		maxPending: 1000,
+		timeout:    10 * time.Second,
+		errors:     func(error) {},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	switch {
+	case p.qos > 2:
+		return nil, fmt.Errorf("mqttlog: invalid QoS %d", p.qos)
+	case p.maxPending <= 0:
+		return nil, fmt.Errorf("mqttlog: invalid max pending %d", p.maxPending)
+	case p.timeout <= 0:
+		return nil, fmt.Errorf("mqttlog: invalid publish timeout %s", p.timeout)
+	}
+
+	p.slots = make(chan struct{}, p.maxPending)
+
+	return p, nil
+}
+
+// Connect connects to broker, e.g. "tcp://localhost:1883" or
+// "ssl://broker.example.com:8883" with tlsConfig, nil for the defaults of
+// crypto/tls, and returns a client reconnecting when the connection is
+// lost, for New
+func Connect(broker, clientID string, tlsConfig *tls.Config) (mqtt.Client, error) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetAutoReconnect(true)
+
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(opts)
+
+	token := client.Connect()
+	if !token.WaitTimeout(30 * time.Second) {
+		return nil, errors.New("mqttlog: connect timeout")
+	}
+
+	if err := token.Error(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// topicEscaper replaces the wildcards of the rendered topics
+var topicEscaper = strings.NewReplacer("+", "_", "#", "_")
+
+// Format publishes e, the writer of the handler is unused. It returns
+// logger.ErrQueueFull when e is dropped, and logger.ErrClosed once the
+// Publisher is closed.
+func (p *Publisher) Format(_ io.Writer, e *logger.Entry) error {
+	var topic strings.Builder
+	if err := p.topic.Execute(&topic, e); err != nil {
+		return err
+	}
+
+	var payload bytes.Buffer
+	if err := p.payload.Format(&payload, e); err != nil {
+		return err
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return logger.ErrClosed
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		return logger.ErrQueueFull
+	}
+
+	token := p.client.Publish(topicEscaper.Replace(topic.String()), p.qos, p.retained,
+		bytes.TrimRight(payload.Bytes(), "\n"))
+
+	p.pending.Add(1)
+	go p.wait(token)
+
+	return nil
+}
+
+// wait waits for the message of token to be published
+func (p *Publisher) wait(token mqtt.Token) {
+	defer p.pending.Done()
+	defer func() { <-p.slots }()
+
+	if !token.WaitTimeout(p.timeout) {
+		p.errors(errTimeout)
+
+		return
+	}
+
+	if err := token.Error(); err != nil {
+		p.errors(err)
+	}
+}
+
+// Close waits for the pending messages, the entries logged afterwards are
+// dropped with logger.ErrClosed. The client is left connected.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	p.pending.Wait()
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package mqttlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+// testToken completes once done is closed, with err
+type testToken struct {
+	done chan struct{}
+	err  error
+}
+
+func (t *testToken) Wait() bool {
+	<-t.done
+
+	return true
+}
+
+func (t *testToken) WaitTimeout(d time.Duration) bool {
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (t *testToken) Done() <-chan struct{} {
+	return t.done
+}
+
+func (t *testToken) Error() error {
+	return t.err
+}
+
+type message struct {
+	topic    string
+	qos      byte
+	retained bool
+	payload  []byte
+}
+
+// testClient records the messages published, the other methods of
+// mqtt.Client are left unimplemented
+type testClient struct {
+	mqtt.Client
+
+	mu       sync.Mutex
+	messages []message
+	tokens   []*testToken
+	// hold keeps the tokens pending until they're released
+	hold bool
+	err  error
+}
+
+func (tc *testClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	tc.messages = append(tc.messages, message{topic, qos, retained, payload.([]byte)})
+
+	t := &testToken{done: make(chan struct{}), err: tc.err}
+	if !tc.hold {
+		close(t.done)
+	}
+	tc.tokens = append(tc.tokens, t)
+
+	return t
+}
+
+func (tc *testClient) release() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	for _, t := range tc.tokens {
+		select {
+		case <-t.done:
+		default:
+			close(t.done)
+		}
+	}
+}
+
+type MQTTSuite struct {
+	suite.Suite
+}
+
+func (s *MQTTSuite) TestPublisher() {
+	tc := &testClient{}
+	p, err := New(tc, "gateways/gw-1/http/{{.Method}}/{{.Status}}", WithQoS(1), WithRetained(true))
+	s.Require().Nil(err)
+
+	h := logger.New(http.NotFoundHandler(), logger.WithFormatter(p))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	s.Nil(p.Close())
+
+	s.Require().Len(tc.messages, 1)
+	s.Equal("gateways/gw-1/http/GET/404", tc.messages[0].topic)
+	s.Equal(byte(1), tc.messages[0].qos)
+	s.True(tc.messages[0].retained)
+
+	record := map[string]interface{}{}
+	s.Nil(json.Unmarshal(tc.messages[0].payload, &record))
+	s.Equal("/a", record["request.url"])
+	s.Equal(float64(404), record["response.status"])
+	s.NotContains(string(tc.messages[0].payload), "\n")
+}
+
+func (s *MQTTSuite) TestPayload() {
+	tc := &testClient{}
+	payload, err := logger.TemplateFormatter("{{.Method}} {{.URL}} {{.Status}}")
+	s.Require().Nil(err)
+
+	p, err := New(tc, "http", WithPayload(payload))
+	s.Require().Nil(err)
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(p)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	s.Nil(p.Close())
+
+	s.Equal("GET /a 404", string(tc.messages[0].payload))
+}
+
+func (s *MQTTSuite) TestWildcards() {
+	tc := &testClient{}
+	p, err := New(tc, "http/{{.Host}}")
+	s.Require().Nil(err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "a+b#c"
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(p)).ServeHTTP(httptest.NewRecorder(), req)
+	s.Nil(p.Close())
+
+	s.Equal("http/a_b_c", tc.messages[0].topic)
+}
+
+func (s *MQTTSuite) TestInvalidTopic() {
+	_, err := New(&testClient{}, "http/{{.Method")
+	s.NotNil(err)
+}
+
+func (s *MQTTSuite) TestInvalidOptions() {
+	_, err := New(&testClient{}, "http", WithMaxPending(-1))
+	s.EqualError(err, "mqttlog: invalid max pending -1")
+
+	_, err = New(&testClient{}, "http", WithMaxPending(0))
+	s.EqualError(err, "mqttlog: invalid max pending 0")
+
+	_, err = New(&testClient{}, "http", WithQoS(3))
+	s.EqualError(err, "mqttlog: invalid QoS 3")
+
+	_, err = New(&testClient{}, "http", WithPublishTimeout(0))
+	s.EqualError(err, "mqttlog: invalid publish timeout 0s")
+}
+
+func (s *MQTTSuite) TestMaxPending() {
+	tc := &testClient{hold: true}
+	p, err := New(tc, "http", WithMaxPending(1))
+	s.Require().Nil(err)
+
+	var errs []error
+	h := logger.New(http.NotFoundHandler(), logger.WithFormatter(p),
+		logger.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Equal([]error{logger.ErrQueueFull}, errs)
+	s.Len(tc.messages, 1)
+
+	tc.release()
+	s.Nil(p.Close())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/c", nil))
+	s.Equal(logger.ErrClosed, errs[1])
+}
+
+func (s *MQTTSuite) TestErrors() {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	handler := WithErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+
+	tc := &testClient{err: errors.New("not authorized")}
+	p, err := New(tc, "http", handler)
+	s.Require().Nil(err)
+	p.Format(nil, &logger.Entry{})
+	s.Nil(p.Close())
+
+	tc = &testClient{hold: true}
+	p, err = New(tc, "http", handler, WithPublishTimeout(10*time.Millisecond))
+	s.Require().Nil(err)
+	p.Format(nil, &logger.Entry{})
+	s.Nil(p.Close())
+
+	s.Equal([]error{errors.New("not authorized"), errTimeout}, errs)
+}
+
+func TestMQTT(t *testing.T) {
+	suite.Run(t, new(MQTTSuite))
+}
@@ -0,0 +1,377 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// msgpackFormatter writes entries as MessagePack maps, see MsgpackEncoder
+type msgpackFormatter struct{}
+
+func (msgpackFormatter) Format(w io.Writer, e *Entry) error {
+	_, err := w.Write(appendMsgpackEntry(nil, e))
+
+	return err
+}
+
+// appendMsgpackEntry appends e as a map with the keys of NDJSONLoggerType,
+// omitting the same empty ones
+func appendMsgpackEntry(b []byte, e *Entry) []byte {
+	var m msgpackMap
+
+	m.int("schema_version", NDJSONSchemaVersion)
+	m.string("time", e.Start.UTC().Format(time.RFC3339Nano))
+	m.string("level", e.Level.name())
+
+	m.string("request.method", e.Method)
+	m.string("request.host", e.Host)
+	m.string("request.url", e.URL)
+	m.string("request.proto", e.Proto)
+	m.optional("request.route", e.Route)
+	m.optional("request.id", e.RequestID)
+	m.optional("request.correlation_id", e.CorrelationID)
+	m.optional("request.amzn_trace_id", e.AmznTraceID)
+	m.optional("request.user", e.RemoteUser)
+	m.optional("request.referer", e.Referer)
+	m.optional("request.user_agent", e.UserAgent)
+	m.int("request.size", e.RequestSize)
+	m.header("request.header", e.Header)
+
+	if e.Body != nil {
+		m.string("request.body", string(e.Body))
+	}
+
+	m.optional("request.range", e.Range)
+
+	if e.Aborted != nil {
+		m.value("request.aborted", true)
+		m.string("request.abort_error", e.Aborted.Error())
+	}
+
+	m.string("client.address", e.RemoteAddr)
+	m.optional("connection.id", e.ConnID)
+
+	m.int("response.status", int64(e.Status))
+	m.int("response.size", int64(e.Size))
+
+	if e.ContentLength >= 0 {
+		m.int("response.content_length", e.ContentLength)
+	}
+
+	m.float("response.ttfb_ms", milliseconds(e.TTFB))
+	m.float("response.duration_ms", milliseconds(e.Duration))
+
+	if e.Hijacked {
+		m.value("response.hijacked", true)
+	}
+
+	if e.Empty {
+		m.value("response.empty", true)
+	}
+
+	if e.ContentEncoding != "" {
+		m.string("response.content_encoding", e.ContentEncoding)
+
+		if e.BodySize >= 0 {
+			m.int("response.body_size", e.BodySize)
+		}
+	}
+
+	m.optional("response.content_range", e.ContentRange)
+	m.optional("response.cache_state", e.CacheState)
+
+	if e.Streamed {
+		m.value("response.streamed", true)
+		m.int("response.flushes", int64(e.Flushes))
+	}
+
+	if e.Preliminary {
+		m.value("response.preliminary", true)
+	}
+
+	m.header("response.header", e.ResponseHeader)
+	m.header("response.trailer", e.Trailer)
+
+	if e.ResponseBody != nil {
+		m.string("response.body", string(e.ResponseBody))
+	}
+
+	m.optional("trace_id", e.TraceID)
+	m.optional("span_id", e.SpanID)
+
+	if e.TLS != nil {
+		m.optional("tls.version", e.TLS.Version)
+		m.optional("tls.cipher", e.TLS.CipherSuite)
+		m.optional("tls.sni", e.TLS.ServerName)
+		m.optional("tls.client_subject", e.TLS.ClientSubject)
+	}
+
+	if e.Panicked {
+		m.value("panic", true)
+		m.optional("panic.value", e.PanicValue)
+		m.optional("panic.stack", string(e.Stack))
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		m.string("websocket.state", ws.state())
+		m.float("websocket.duration_ms", milliseconds(ws.Duration))
+		m.int("websocket.frames_in", ws.FramesIn)
+		m.int("websocket.frames_out", ws.FramesOut)
+		m.int("websocket.bytes_in", ws.BytesIn)
+		m.int("websocket.bytes_out", ws.BytesOut)
+
+		if ws.CloseCode != 0 {
+			m.int("websocket.close_code", int64(ws.CloseCode))
+		}
+	}
+
+	if len(e.Fields) > 0 {
+		m.value("fields", e.Fields)
+	}
+
+	return m.appendTo(b)
+}
+
+// appendMsgpackNotice appends a notice about the log output itself, with
+// the keys of the NDJSONLoggerType ones
+func appendMsgpackNotice(b []byte, t time.Time, level Level, msg string, fields map[string]interface{}) []byte {
+	var m msgpackMap
+
+	m.int("schema_version", NDJSONSchemaVersion)
+	m.string("time", t.UTC().Format(time.RFC3339Nano))
+	m.string("level", level.name())
+	m.string("msg", msg)
+
+	if len(fields) > 0 {
+		m.value("fields", fields)
+	}
+
+	return m.appendTo(b)
+}
+
+// msgpackMap collects the pairs of a map, its header is written once they
+// are counted
+type msgpackMap struct {
+	n    int
+	body []byte
+}
+
+func (m *msgpackMap) key(k string) {
+	m.n++
+	m.body = appendMsgpackString(m.body, k)
+}
+
+func (m *msgpackMap) string(k, v string) {
+	m.key(k)
+	m.body = appendMsgpackString(m.body, v)
+}
+
+// optional adds k unless v is empty
+func (m *msgpackMap) optional(k, v string) {
+	if v != "" {
+		m.string(k, v)
+	}
+}
+
+func (m *msgpackMap) int(k string, v int64) {
+	m.key(k)
+	m.body = appendMsgpackInt(m.body, v)
+}
+
+func (m *msgpackMap) float(k string, v float64) {
+	m.key(k)
+	m.body = appendMsgpackFloat(m.body, v)
+}
+
+// header adds k unless h is nil
+func (m *msgpackMap) header(k string, h http.Header) {
+	if h != nil {
+		m.value(k, h)
+	}
+}
+
+func (m *msgpackMap) value(k string, v interface{}) {
+	m.key(k)
+	m.body = appendMsgpackValue(m.body, v)
+}
+
+func (m *msgpackMap) appendTo(b []byte) []byte {
+	return append(appendMsgpackHeader(b, m.n, 0x80, 0xde), m.body...)
+}
+
+// appendMsgpackValue appends v, the values of unsupported types as the
+// string printed by fmt.Sprint
+func appendMsgpackValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+
+		return append(b, 0xc2)
+	case string:
+		return appendMsgpackString(b, v)
+	case []byte:
+		return appendMsgpackBinary(b, v)
+	case int:
+		return appendMsgpackInt(b, int64(v))
+	case int8:
+		return appendMsgpackInt(b, int64(v))
+	case int16:
+		return appendMsgpackInt(b, int64(v))
+	case int32:
+		return appendMsgpackInt(b, int64(v))
+	case int64:
+		return appendMsgpackInt(b, v)
+	case uint8:
+		return appendMsgpackInt(b, int64(v))
+	case uint16:
+		return appendMsgpackInt(b, int64(v))
+	case uint32:
+		return appendMsgpackInt(b, int64(v))
+	case uint:
+		return appendMsgpackUint(b, uint64(v))
+	case uint64:
+		return appendMsgpackUint(b, v)
+	case float32:
+		return appendMsgpackFloat(b, float64(v))
+	case float64:
+		return appendMsgpackFloat(b, v)
+	case time.Time:
+		return appendMsgpackString(b, v.Format(time.RFC3339Nano))
+	case error:
+		return appendMsgpackString(b, v.Error())
+	case []string:
+		b = appendMsgpackHeader(b, len(v), 0x90, 0xdc)
+		for _, s := range v {
+			b = appendMsgpackString(b, s)
+		}
+
+		return b
+	case []interface{}:
+		b = appendMsgpackHeader(b, len(v), 0x90, 0xdc)
+		for _, item := range v {
+			b = appendMsgpackValue(b, item)
+		}
+
+		return b
+	case http.Header:
+		m := make(map[string]interface{}, len(v))
+		for k, values := range v {
+			m[k] = values
+		}
+
+		return appendMsgpackValue(b, m)
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			m[k] = value
+		}
+
+		return appendMsgpackValue(b, m)
+	case map[string]interface{}:
+		// sorted so the output is stable
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b = appendMsgpackHeader(b, len(v), 0x80, 0xde)
+		for _, k := range keys {
+			b = appendMsgpackString(b, k)
+			b = appendMsgpackValue(b, v[k])
+		}
+
+		return b
+	}
+
+	return appendMsgpackString(b, fmt.Sprint(v))
+}
+
+// appendMsgpackHeader appends the header of an array or a map of n items,
+// fix being its fix format and format16 its 16 bits one, followed by the
+// 32 bits one
+func appendMsgpackHeader(b []byte, n int, fix, format16 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, format16), uint16(n))
+	}
+
+	return binary.BigEndian.AppendUint32(append(b, format16+1), uint32(n))
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+
+	return append(b, s...)
+}
+
+func appendMsgpackBinary(b []byte, p []byte) []byte {
+	switch n := len(p); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+
+	return append(b, p...)
+}
+
+// appendMsgpackInt appends v in its shortest format
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+}
+
+// appendMsgpackUint appends v in its shortest format
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v < 128:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	}
+
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+}
+
+func appendMsgpackFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
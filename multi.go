@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Target is one output of a handler logging to several writers, each with
+// its own format
+type Target struct {
+	Writer io.Writer
+	// Type is the log output type, default to CombineLoggerType
+	Type Type
+	// Options only apply to this output, e.g. WithCustomFormat, WithColor
+	// or WithAsync. Options about the request itself, such as WithClock or
+	// WithRequestID, are ignored here.
+	Options []Option
+}
+
+// WithTargets logs every request to each of targets instead of the writer
+// set by WithWriter. The targets inherit the other options, their own
+// Options applied on top. A JsonLoggerType target without a Backend writes
+// to its Writer.
+func WithTargets(targets ...Target) Option {
+	return func(rh *loggerHanlder) {
+		rh.targets = append(rh.targets, targets...)
+	}
+}
+
+// MultiHandler returns a http.Handler that wraps h once and logs every
+// request to each of targets, e.g. a CombineLoggerType line to a file and
+// a JsonLoggerType entry to os.Stdout
+func MultiHandler(h http.Handler, targets ...Target) http.Handler {
+	return New(h, WithTargets(targets...))
+}
+
+// output returns the handler writing to t, rh being configured but not
+// yet prepared
+func (rh loggerHanlder) output(t Target) loggerHanlder {
+	rh.writer = t.Writer
+	rh.formatType = t.Type
+	if rh.formatType == 0 {
+		rh.formatType = CombineLoggerType
+	}
+	rh.tokens, rh.formatter, rh.encoder = nil, nil, 0
+	rh.targets, rh.outputs, rh.owned = nil, nil, nil
+	rh.statusWriters = nil
+	rh.directives = &sync.Once{}
+
+	for _, opt := range t.Options {
+		opt(&rh)
+	}
+
+	return rh.prepare()
+}
+
+func (rh loggerHanlder) flushOutputs() error {
+	errs := []error{}
+
+	for _, o := range rh.outputs {
+		errs = append(errs, o.Flush())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (rh loggerHanlder) closeOutputs() error {
+	errs := []error{}
+
+	for _, o := range rh.outputs {
+		errs = append(errs, o.Close())
+	}
+
+	return errors.Join(errs...)
+}
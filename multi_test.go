@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MultiSuite struct {
+	suite.Suite
+}
+
+func (s *MultiSuite) TestTargets() {
+	text, custom, doc := testWriter{}, testWriter{}, testWriter{}
+	h := MultiHandler(http.NotFoundHandler(),
+		Target{Writer: &text, Type: CommonLoggerType},
+		Target{Writer: &custom, Type: CustomLoggerType, Options: []Option{WithCustomFormat(":method :status")}},
+		Target{Writer: &doc, Type: JsonLoggerType},
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.True(strings.HasPrefix(string(text.Bytes), `192.0.2.1:1234 - - [`))
+	s.True(strings.HasSuffix(string(text.Bytes), `] "GET / HTTP/1.1" 404 19`+"\n"))
+	s.Equal("GET 404\n", string(custom.Bytes))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(doc.Bytes, &entry))
+	s.Equal("404", entry["response.status"])
+}
+
+func (s *MultiSuite) TestInheritedOptions() {
+	a, b := testWriter{}, testWriter{}
+	h := New(http.NotFoundHandler(), WithRequestID(), WithTargets(
+		Target{Writer: &a, Type: CustomLoggerType, Options: []Option{WithCustomFormat(":request-id")}},
+		Target{Writer: &b, Type: CustomLoggerType, Options: []Option{WithCustomFormat(":request-id")}},
+	))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(rec.Header().Get(RequestIDHeader)+"\n", string(a.Bytes))
+	s.Equal(string(a.Bytes), string(b.Bytes))
+}
+
+func (s *MultiSuite) TestDefaultType() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithTargets(Target{Writer: &tw}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.True(strings.HasSuffix(string(tw.Bytes), `] "GET / HTTP/1.1" 404 19 "" ""`+"\n"))
+}
+
+func (s *MultiSuite) TestAsync() {
+	sw := &syncWriter{}
+	h := New(http.NotFoundHandler(), WithClock(testClock{}), WithTargets(
+		Target{Writer: sw, Type: TinyLoggerType, Options: []Option{WithAsync(8)}},
+	))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Nil(h.(interface{ Close() error }).Close())
+	s.Equal("GET / 404 19 - 0.000 ms\n", sw.String())
+}
+
+func TestMulti(t *testing.T) {
+	suite.Run(t, new(MultiSuite))
+}
@@ -0,0 +1,27 @@
+// Package muxroute takes the route patterns of the requests from
+// gorilla/mux, e.g.
+//
+//	r := mux.NewRouter()
+//	r.Use(logger.Middleware(logger.WithRouteFunc(muxroute.Route)))
+//
+// gorilla/mux only exposes the route to its own middlewares, mount the
+// logger with Use.
+package muxroute
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Route returns the path template of the gorilla/mux route req was matched
+// against, e.g. "/users/{id}", it's a logger.RouteFunc
+func Route(req *http.Request) string {
+	if r := mux.CurrentRoute(req); r != nil {
+		if pattern, err := r.GetPathTemplate(); err == nil {
+			return pattern
+		}
+	}
+
+	return ""
+}
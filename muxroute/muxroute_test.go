@@ -0,0 +1,41 @@
+package muxroute
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/suite"
+)
+
+type MuxSuite struct {
+	suite.Suite
+
+	buf bytes.Buffer
+}
+
+func (s *MuxSuite) SetupTest() {
+	s.buf.Reset()
+}
+
+func (s *MuxSuite) TestRoute() {
+	r := mux.NewRouter()
+	r.Use(logger.Middleware(logger.WithWriter(&s.buf), logger.WithCustomFormat(":route"),
+		logger.WithRouteFunc(Route)))
+	r.HandleFunc("/users/{id}", func(res http.ResponseWriter, req *http.Request) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	s.Equal("/users/{id}\n", s.buf.String())
+}
+
+func (s *MuxSuite) TestUnrouted() {
+	s.Equal("", Route(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestMux(t *testing.T) {
+	suite.Run(t, new(MuxSuite))
+}
@@ -0,0 +1,263 @@
+// Package natslog publishes the log output of the logger package to a NATS
+// JetStream subject, for lightweight real-time consumers such as
+// dashboards or anomaly detectors, see Writer.
+package natslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Publisher is the part of jetstream.JetStream used by Writer
+type Publisher interface {
+	PublishAsync(subject string, payload []byte, opts ...jetstream.PublishOpt) (jetstream.PubAckFuture, error)
+}
+
+// errAckTimeout is returned for the batches not acknowledged within the
+// ack timeout
+var errAckTimeout = errors.New("natslog: ack timeout")
+
+// Option configures the writer returned by Writer
+type Option func(*writer)
+
+// WithFlushInterval sets how often the pending entries are published,
+// default to 100ms, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *writer) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithBatchSize sets the number of entries published before waiting for
+// their acknowledgements, and past which they're published before the
+// flush interval, default to 100, kept for n <= 0
+func WithBatchSize(n int) Option {
+	return func(w *writer) {
+		if n > 0 {
+			w.batchSize = n
+		}
+	}
+}
+
+// WithMaxPending sets the number of entries waiting to be published past
+// which the entries written are dropped with logger.ErrQueueFull, and
+// counted by the Dropped method of the handler, default to 10000, kept for
+// n <= 0
+func WithMaxPending(n int) Option {
+	return func(w *writer) {
+		if n > 0 {
+			w.maxPending = n
+		}
+	}
+}
+
+// WithAckTimeout sets how long the acknowledgements of a batch are waited
+// for, default to 5 seconds, kept for d <= 0
+func WithAckTimeout(d time.Duration) Option {
+	return func(w *writer) {
+		if d > 0 {
+			w.ackTimeout = d
+		}
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches published in the background, by default they are dropped
+// silently
+func WithErrorHandler(f func(error)) Option {
+	return func(w *writer) {
+		w.errors = f
+	}
+}
+
+// writer publishes every Write as one message of a subject, in batches
+type writer struct {
+	js         Publisher
+	subject    string
+	interval   time.Duration
+	batchSize  int
+	maxPending int
+	ackTimeout time.Duration
+	errors     func(error)
+
+	mu      sync.Mutex
+	pending [][]byte
+	closed  bool
+
+	// sending serializes the batches, so the messages are published in
+	// order
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Writer returns an io.WriteCloser publishing each write, i.e. each entry
+// of the log output, as a message of subject, which a stream of js has to
+// capture, e.g.:
+//
+//	nc, _ := nats.Connect(nats.DefaultURL)
+//	js, _ := jetstream.New(nc)
+//	w := natslog.Writer(js, "logs.access")
+//	defer w.Close()
+//
+// Messages are published asynchronously in batches in the background, so
+// writes never wait for the server, and a batch waits for its
+// acknowledgements before the next one is published. Close publishes the
+// pending entries, call it on shutdown.
+func Writer(js Publisher, subject string, opts ...Option) io.WriteCloser {
+	w := &writer{
+		js:         js,
+		subject:    subject,
+		interval:   100 * time.Millisecond,
+		batchSize:  100,
+		maxPending: 10000,
+		ackTimeout: 5 * time.Second,
+		errors:     func(error) {},
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w
+}
+
+func (w *writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			w.errors(err)
+		}
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	msg := bytes.Clone(bytes.TrimRight(p, "\n"))
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+
+		return 0, logger.ErrClosed
+	}
+
+	if len(w.pending) >= w.maxPending {
+		w.mu.Unlock()
+
+		return 0, logger.ErrQueueFull
+	}
+
+	w.pending = append(w.pending, msg)
+	full := len(w.pending) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush publishes the pending entries
+func (w *writer) Flush() error {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	msgs := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	for len(msgs) > 0 {
+		n := min(len(msgs), w.batchSize)
+		if err := w.publish(msgs[:n]); err != nil {
+			return err
+		}
+		msgs = msgs[n:]
+	}
+
+	return nil
+}
+
+// publish publishes batch and waits for its acknowledgements, the first
+// error is returned once they were all received
+func (w *writer) publish(batch [][]byte) error {
+	futures := make([]jetstream.PubAckFuture, 0, len(batch))
+	for _, msg := range batch {
+		f, err := w.js.PublishAsync(w.subject, msg)
+		if err != nil {
+			return err
+		}
+
+		futures = append(futures, f)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.ackTimeout)
+	defer cancel()
+
+	var first error
+	for _, f := range futures {
+		select {
+		case <-f.Ok():
+		case err := <-f.Err():
+			if first == nil {
+				first = err
+			}
+		case <-ctx.Done():
+			return errAckTimeout
+		}
+	}
+
+	return first
+}
+
+// Close stops the background publishing and publishes the pending
+// entries, later writes fail with logger.ErrClosed
+func (w *writer) Close() error {
+	var err error
+
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.done)
+		w.wg.Wait()
+
+		err = w.Flush()
+	})
+
+	return err
+}
@@ -0,0 +1,169 @@
+package natslog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/suite"
+)
+
+// testFuture is acknowledged with err, or never when pending is set
+type testFuture struct {
+	msg     *nats.Msg
+	err     error
+	pending bool
+}
+
+func (f *testFuture) Ok() <-chan *jetstream.PubAck {
+	ch := make(chan *jetstream.PubAck, 1)
+	if !f.pending && f.err == nil {
+		ch <- &jetstream.PubAck{Stream: "LOGS"}
+	}
+
+	return ch
+}
+
+func (f *testFuture) Err() <-chan error {
+	ch := make(chan error, 1)
+	if !f.pending && f.err != nil {
+		ch <- f.err
+	}
+
+	return ch
+}
+
+func (f *testFuture) Msg() *nats.Msg {
+	return f.msg
+}
+
+type testPublisher struct {
+	mu       sync.Mutex
+	messages []*nats.Msg
+	// acks are the errors of the next acknowledgements, errPending for
+	// the ones which never come
+	acks []error
+}
+
+var errPending = errors.New("pending")
+
+func (tp *testPublisher) PublishAsync(subject string, payload []byte, _ ...jetstream.PublishOpt) (jetstream.PubAckFuture, error) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	msg := &nats.Msg{Subject: subject, Data: payload}
+	tp.messages = append(tp.messages, msg)
+
+	f := &testFuture{msg: msg}
+	if len(tp.acks) > 0 {
+		f.err, tp.acks = tp.acks[0], tp.acks[1:]
+		f.pending = f.err == errPending
+	}
+
+	return f, nil
+}
+
+func (tp *testPublisher) len() int {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	return len(tp.messages)
+}
+
+type NATSSuite struct {
+	suite.Suite
+}
+
+func (s *NATSSuite) TestWriter() {
+	tp := &testPublisher{}
+	w := Writer(tp, "logs.access", WithFlushInterval(time.Hour))
+
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithCustomFormat(":method :url"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(w.Close())
+
+	s.Require().Len(tp.messages, 2)
+	s.Equal("logs.access", tp.messages[0].Subject)
+	s.Equal("GET /a", string(tp.messages[0].Data))
+	s.Equal("GET /b", string(tp.messages[1].Data))
+
+	_, err := w.Write([]byte("GET /c\n"))
+	s.Equal(logger.ErrClosed, err)
+}
+
+func (s *NATSSuite) TestBatchSize() {
+	tp := &testPublisher{}
+	w := Writer(tp, "logs.access", WithBatchSize(2), WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+
+	s.Eventually(func() bool {
+		return tp.len() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *NATSSuite) TestInvalidOptions() {
+	tp := &testPublisher{}
+	w := Writer(tp, "logs.access", WithBatchSize(0), WithFlushInterval(0), WithMaxPending(-1),
+		WithAckTimeout(-time.Second))
+
+	ww := w.(*writer)
+	s.Equal(100, ww.batchSize)
+	s.Equal(100*time.Millisecond, ww.interval)
+	s.Equal(10000, ww.maxPending)
+	s.Equal(5*time.Second, ww.ackTimeout)
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+	s.Equal(1, tp.len())
+}
+
+func (s *NATSSuite) TestMaxPending() {
+	tp := &testPublisher{}
+	w := Writer(tp, "logs.access", WithMaxPending(1), WithFlushInterval(time.Hour))
+
+	var errs []error
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithCustomFormat(":url"),
+		logger.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Equal([]error{logger.ErrQueueFull}, errs)
+	s.Equal(uint64(1), h.(interface{ Dropped() uint64 }).Dropped())
+
+	s.Nil(w.Close())
+	s.Len(tp.messages, 1)
+}
+
+func (s *NATSSuite) TestAckError() {
+	tp := &testPublisher{acks: []error{nil, jetstream.ErrNoStreamResponse}}
+	w := Writer(tp, "logs.access", WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+	s.Equal(jetstream.ErrNoStreamResponse, w.(*writer).Flush())
+	s.Len(tp.messages, 2)
+}
+
+func (s *NATSSuite) TestAckTimeout() {
+	tp := &testPublisher{acks: []error{errPending}}
+	w := Writer(tp, "logs.access", WithAckTimeout(10*time.Millisecond), WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	s.Equal(errAckTimeout, w.(*writer).Flush())
+}
+
+func TestNATS(t *testing.T) {
+	suite.Run(t, new(NATSSuite))
+}
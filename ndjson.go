@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// NDJSONSchemaVersion is the schema_version of the NDJSONLoggerType
+// records. It's bumped whenever a key is renamed or removed or its type
+// changes, adding keys keeps it.
+const NDJSONSchemaVersion = 1
+
+// ndjsonRecord is the schema of NDJSONLoggerType, the fields are printed
+// in this order
+type ndjsonRecord struct {
+	SchemaVersion int    `json:"schema_version"`
+	Time          string `json:"time"`
+	Level         string `json:"level"`
+
+	Method        string      `json:"request.method"`
+	Host          string      `json:"request.host"`
+	URL           string      `json:"request.url"`
+	Proto         string      `json:"request.proto"`
+	Route         string      `json:"request.route,omitempty"`
+	RequestID     string      `json:"request.id,omitempty"`
+	CorrelationID string      `json:"request.correlation_id,omitempty"`
+	AmznTraceID   string      `json:"request.amzn_trace_id,omitempty"`
+	User          string      `json:"request.user,omitempty"`
+	Referer       string      `json:"request.referer,omitempty"`
+	UserAgent     string      `json:"request.user_agent,omitempty"`
+	RequestSize   int64       `json:"request.size"`
+	Header        http.Header `json:"request.header,omitempty"`
+	Body          *string     `json:"request.body,omitempty"`
+	Range         string      `json:"request.range,omitempty"`
+	Aborted       bool        `json:"request.aborted,omitempty"`
+	AbortError    string      `json:"request.abort_error,omitempty"`
+	ClientAddress string      `json:"client.address"`
+	ConnID        string      `json:"connection.id,omitempty"`
+
+	Status         int         `json:"response.status"`
+	Size           int         `json:"response.size"`
+	ContentLength  *int64      `json:"response.content_length,omitempty"`
+	TTFB           float64     `json:"response.ttfb_ms"`
+	Duration       float64     `json:"response.duration_ms"`
+	Hijacked       bool        `json:"response.hijacked,omitempty"`
+	Empty          bool        `json:"response.empty,omitempty"`
+	Encoding       string      `json:"response.content_encoding,omitempty"`
+	BodySize       *int64      `json:"response.body_size,omitempty"`
+	ContentRange   string      `json:"response.content_range,omitempty"`
+	CacheState     string      `json:"response.cache_state,omitempty"`
+	Streamed       bool        `json:"response.streamed,omitempty"`
+	Flushes        int         `json:"response.flushes,omitempty"`
+	Preliminary    bool        `json:"response.preliminary,omitempty"`
+	ResponseHeader http.Header `json:"response.header,omitempty"`
+	Trailer        http.Header `json:"response.trailer,omitempty"`
+	ResponseBody   *string     `json:"response.body,omitempty"`
+
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	TLSVersion       string `json:"tls.version,omitempty"`
+	TLSCipher        string `json:"tls.cipher,omitempty"`
+	TLSServerName    string `json:"tls.sni,omitempty"`
+	TLSClientSubject string `json:"tls.client_subject,omitempty"`
+
+	Panic      bool   `json:"panic,omitempty"`
+	PanicValue string `json:"panic.value,omitempty"`
+	PanicStack string `json:"panic.stack,omitempty"`
+
+	WebSocketState     string  `json:"websocket.state,omitempty"`
+	WebSocketDuration  float64 `json:"websocket.duration_ms,omitempty"`
+	WebSocketFramesIn  int64   `json:"websocket.frames_in,omitempty"`
+	WebSocketFramesOut int64   `json:"websocket.frames_out,omitempty"`
+	WebSocketBytesIn   int64   `json:"websocket.bytes_in,omitempty"`
+	WebSocketBytesOut  int64   `json:"websocket.bytes_out,omitempty"`
+	WebSocketCloseCode int     `json:"websocket.close_code,omitempty"`
+
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// NDJSONFormatter prints entries as NDJSONLoggerType records, for the
+// outputs formatting the entries themselves, e.g. the payloads of the
+// mqttlog subpackage
+var NDJSONFormatter Formatter = ndjsonFormatter{}
+
+// ndjsonFormatter prints entries as NDJSONLoggerType records
+type ndjsonFormatter struct{}
+
+func (ndjsonFormatter) Format(w io.Writer, e *Entry) error {
+	r := ndjsonRecord{
+		SchemaVersion: NDJSONSchemaVersion,
+		Time:          e.Start.UTC().Format(time.RFC3339Nano),
+		Level:         e.Level.name(),
+
+		Method:        e.Method,
+		Host:          e.Host,
+		URL:           e.URL,
+		Proto:         e.Proto,
+		Route:         e.Route,
+		RequestID:     e.RequestID,
+		CorrelationID: e.CorrelationID,
+		AmznTraceID:   e.AmznTraceID,
+		User:          e.RemoteUser,
+		Referer:       e.Referer,
+		UserAgent:     e.UserAgent,
+		RequestSize:   e.RequestSize,
+		Range:         e.Range,
+		Header:        e.Header,
+		ClientAddress: e.RemoteAddr,
+		ConnID:        e.ConnID,
+
+		Status:         e.Status,
+		Size:           e.Size,
+		TTFB:           milliseconds(e.TTFB),
+		Duration:       milliseconds(e.Duration),
+		Hijacked:       e.Hijacked,
+		Empty:          e.Empty,
+		Encoding:       e.ContentEncoding,
+		ContentRange:   e.ContentRange,
+		CacheState:     e.CacheState,
+		Streamed:       e.Streamed,
+		Flushes:        e.Flushes,
+		Preliminary:    e.Preliminary,
+		ResponseHeader: e.ResponseHeader,
+		Trailer:        e.Trailer,
+
+		TraceID: e.TraceID,
+		SpanID:  e.SpanID,
+
+		Panic:      e.Panicked,
+		PanicValue: e.PanicValue,
+		PanicStack: string(e.Stack),
+
+		Fields: e.Fields,
+	}
+
+	if e.ContentLength >= 0 {
+		r.ContentLength = &e.ContentLength
+	}
+
+	if e.ContentEncoding != "" && e.BodySize >= 0 {
+		r.BodySize = &e.BodySize
+	}
+
+	if e.Body != nil {
+		body := string(e.Body)
+		r.Body = &body
+	}
+
+	if e.Aborted != nil {
+		r.Aborted = true
+		r.AbortError = e.Aborted.Error()
+	}
+
+	if e.ResponseBody != nil {
+		body := string(e.ResponseBody)
+		r.ResponseBody = &body
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		r.WebSocketState = ws.state()
+		r.WebSocketDuration = milliseconds(ws.Duration)
+		r.WebSocketFramesIn = ws.FramesIn
+		r.WebSocketFramesOut = ws.FramesOut
+		r.WebSocketBytesIn = ws.BytesIn
+		r.WebSocketBytesOut = ws.BytesOut
+		r.WebSocketCloseCode = ws.CloseCode
+	}
+
+	if e.TLS != nil {
+		r.TLSVersion = e.TLS.Version
+		r.TLSCipher = e.TLS.CipherSuite
+		r.TLSServerName = e.TLS.ServerName
+		r.TLSClientSubject = e.TLS.ClientSubject
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// ndjsonNotice is the schema of the NDJSONLoggerType lines about the log
+// output itself, e.g. the summaries of WithRateLimit, told apart from the
+// requests by their msg
+type ndjsonNotice struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Time          string                 `json:"time"`
+	Level         string                 `json:"level"`
+	Msg           string                 `json:"msg"`
+	Fields        map[string]interface{} `json:"fields,omitempty"`
+}
+
+// writeNDJSONNotice writes msg with fields as a NDJSONLoggerType notice
+func writeNDJSONNotice(w io.Writer, t time.Time, level Level, msg string, fields map[string]interface{}) error {
+	b, err := json.Marshal(ndjsonNotice{
+		SchemaVersion: NDJSONSchemaVersion,
+		Time:          t.UTC().Format(time.RFC3339Nano),
+		Level:         level.name(),
+		Msg:           msg,
+		Fields:        fields,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// name returns the level as NDJSONLoggerType prints it
+func (l Level) name() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+
+	return "info"
+}
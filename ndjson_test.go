@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NDJSONSuite struct {
+	suite.Suite
+}
+
+func (s *NDJSONSuite) serve(h http.HandlerFunc, opts ...Option) string {
+	tw := testWriter{}
+	opts = append([]Option{WithWriter(&tw), WithFormat(NDJSONLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 123e6, time.UTC)})}, opts...)
+
+	New(h, opts...).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users?id=1", nil))
+
+	return string(tw.Bytes)
+}
+
+func (s *NDJSONSuite) TestFormat() {
+	line := s.serve(func(res http.ResponseWriter, req *http.Request) {
+		AddField(req.Context(), "tenant", "acme")
+		http.Error(res, "oops", http.StatusBadGateway)
+	}, WithRequestID())
+
+	s.True(strings.HasSuffix(line, "}\n"))
+	s.Equal(1, strings.Count(line, "\n"))
+	s.True(strings.HasPrefix(line, `{"schema_version":1,"time":"2017-01-02T15:04:05.123Z","level":"error","request.method":"GET"`))
+
+	record := map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(line), &record))
+
+	s.Equal("/users?id=1", record["request.url"])
+	s.Equal("example.com", record["request.host"])
+	s.Equal(float64(502), record["response.status"])
+	s.Equal(float64(5), record["response.size"])
+	s.Equal(float64(0), record["request.size"])
+	s.Equal("192.0.2.1:1234", record["client.address"])
+	s.NotEmpty(record["request.id"])
+	s.Equal(map[string]interface{}{"tenant": "acme"}, record["fields"])
+	s.NotContains(record, "request.user_agent")
+	s.NotContains(record, "panic")
+}
+
+func (s *NDJSONSuite) TestLevel() {
+	s.Equal("info", LevelInfo.name())
+	s.Equal("debug", LevelDebug.name())
+	s.Equal("warn", DefaultLevel(http.StatusNotFound).name())
+	s.Equal("error", DefaultLevel(http.StatusInternalServerError).name())
+}
+
+func (s *NDJSONSuite) TestNotice() {
+	tw := testWriter{}
+	clock := &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}
+	h := New(noopHandler{}, WithWriter(&tw), WithFormat(NDJSONLoggerType), WithClock(clock),
+		WithRateLimit(1, time.Second))
+
+	for i := 0; i < 2; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	tw.Bytes = nil
+
+	clock.now = clock.now.Add(time.Second)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	notice := map[string]interface{}{}
+	s.Nil(json.NewDecoder(strings.NewReader(string(tw.Bytes))).Decode(&notice))
+	s.Equal(float64(NDJSONSchemaVersion), notice["schema_version"])
+	s.Equal("suppressed 1 entries", notice["msg"])
+	s.Equal("warn", notice["level"])
+	s.Equal(map[string]interface{}{"suppressed": float64(1)}, notice["fields"])
+}
+
+func TestNDJSON(t *testing.T) {
+	suite.Run(t, new(NDJSONSuite))
+}
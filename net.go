@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetWriter buffering, timeout and reconnection defaults
+const (
+	netBufferSize   = 1024
+	netWriteTimeout = 5 * time.Second
+	netMinBackoff   = 100 * time.Millisecond
+	netMaxBackoff   = 10 * time.Second
+)
+
+// netWriter queues writes to a bounded channel sent over the connection by
+// a background goroutine, which reconnects with backoff when it fails
+type netWriter struct {
+	network string
+	addr    string
+
+	timeout    time.Duration
+	minBackoff time.Duration
+
+	entries chan []byte
+	dropped uint64
+	conn    net.Conn
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NetWriter returns an io.WriteCloser sending each write over network
+// ("tcp", "unix" or any stream network of net.Dial) to addr, e.g. a local
+// log collector socket. Writes are queued to a buffer of 1024 entries and
+// never wait for the connection: the entries are dropped when the buffer
+// is full. The connection is made in the background and remade with an
+// exponential backoff whenever it fails, the failed entry being sent again
+// once reconnected, so the log output survives collector restarts. Each
+// send times out after 5 seconds. Close sends the buffered entries, call
+// it on shutdown.
+func NetWriter(network, addr string) io.WriteCloser {
+	return newNetWriter(network, addr, netBufferSize)
+}
+
+func newNetWriter(network, addr string, size int) *netWriter {
+	nw := &netWriter{
+		network:    network,
+		addr:       addr,
+		timeout:    netWriteTimeout,
+		minBackoff: netMinBackoff,
+		entries:    make(chan []byte, size),
+		done:       make(chan struct{}),
+	}
+
+	nw.wg.Add(1)
+	go nw.run()
+
+	return nw
+}
+
+func (nw *netWriter) Write(p []byte) (int, error) {
+	nw.mu.RLock()
+	defer nw.mu.RUnlock()
+
+	if nw.closed {
+		return 0, ErrClosed
+	}
+
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	select {
+	case nw.entries <- entry:
+	default:
+		atomic.AddUint64(&nw.dropped, 1)
+	}
+
+	return len(p), nil
+}
+
+func (nw *netWriter) run() {
+	defer nw.wg.Done()
+
+	for entry := range nw.entries {
+		nw.send(entry)
+	}
+
+	if nw.conn != nil {
+		nw.conn.Close()
+	}
+}
+
+// send writes entry, reconnecting until it's written or the writer is
+// closed
+func (nw *netWriter) send(entry []byte) {
+	backoff := nw.minBackoff
+
+	for {
+		if nw.write(entry) == nil {
+			return
+		}
+
+		select {
+		case <-nw.done:
+			// closing, the remaining entries get a single attempt
+			return
+		default:
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff = min(2*backoff, netMaxBackoff)
+		case <-nw.done:
+		}
+	}
+}
+
+func (nw *netWriter) write(entry []byte) error {
+	if nw.conn == nil {
+		conn, err := net.DialTimeout(nw.network, nw.addr, nw.timeout)
+		if err != nil {
+			return err
+		}
+
+		nw.conn = conn
+	}
+
+	nw.conn.SetWriteDeadline(time.Now().Add(nw.timeout))
+
+	if _, err := nw.conn.Write(entry); err != nil {
+		nw.conn.Close()
+		nw.conn = nil
+
+		return err
+	}
+
+	return nil
+}
+
+// Close sends the buffered entries, giving up on those which fail once,
+// and closes the connection. Later writes fail with ErrClosed.
+func (nw *netWriter) Close() error {
+	nw.mu.Lock()
+	if !nw.closed {
+		nw.closed = true
+		close(nw.done)
+		close(nw.entries)
+	}
+	nw.mu.Unlock()
+
+	nw.wg.Wait()
+
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the buffer was
+// full
+func (nw *netWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&nw.dropped)
+}
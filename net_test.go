@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type NetSuite struct {
+	suite.Suite
+}
+
+// accept returns the data received by ln on its first connection
+func accept(ln net.Listener) <-chan string {
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		b, _ := io.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	return received
+}
+
+func (s *NetSuite) TestTCP() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	received := accept(ln)
+
+	w := NetWriter("tcp", ln.Addr().String())
+
+	h := New(http.NotFoundHandler(), WithWriter(w), WithClock(testClock{}), WithFormat(TinyLoggerType))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	s.Nil(w.Close())
+	s.Equal("GET / 404 19 - 0.000 ms\nGET /a 404 19 - 0.000 ms\n", <-received)
+}
+
+func (s *NetSuite) TestReconnect() {
+	addr := filepath.Join(s.T().TempDir(), "collector.sock")
+
+	nw := newNetWriter("unix", addr, netBufferSize)
+	nw.minBackoff = time.Millisecond
+
+	// the collector isn't listening yet
+	nw.Write([]byte("first\n"))
+	nw.Write([]byte("second\n"))
+	time.Sleep(10 * time.Millisecond)
+
+	ln, err := net.Listen("unix", addr)
+	s.Nil(err)
+	defer ln.Close()
+
+	received := accept(ln)
+
+	for len(nw.entries) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Nil(nw.Close())
+	s.Equal("first\nsecond\n", <-received)
+}
+
+func (s *NetSuite) TestDrop() {
+	addr := filepath.Join(s.T().TempDir(), "missing.sock")
+
+	nw := newNetWriter("unix", addr, 2)
+	nw.minBackoff = time.Hour
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		n, err := nw.Write([]byte("entry\n"))
+		s.Nil(err)
+		s.Equal(6, n)
+	}
+
+	s.True(nw.Dropped() >= 7)
+	s.Nil(nw.Close())
+	s.True(time.Since(start) < time.Second)
+
+	_, err := nw.Write([]byte("late\n"))
+	s.Equal(ErrClosed, err)
+}
+
+func TestNet(t *testing.T) {
+	suite.Run(t, new(NetSuite))
+}
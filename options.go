@@ -0,0 +1,676 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// Option configures the http.Handler returned by New
+type Option func(*loggerHanlder)
+
+// Clock tells the current time, it lets tests control the timestamps and
+// durations that end up in the log output, see the fakeclock package.
+// Durations are measured with the Since(start time.Time) time.Duration
+// method of clocks which have one, e.g. on the monotonic clock, and by
+// subtracting start from Now otherwise.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// elapsed returns the time elapsed on clock since start
+func elapsed(clock Clock, start time.Time) time.Duration {
+	if c, ok := clock.(interface{ Since(time.Time) time.Duration }); ok {
+		return c.Since(start)
+	}
+
+	return clock.Now().Sub(start)
+}
+
+// WithWriter sets where the log output is printed, default to os.Stdout.
+// Every entry is written with a single Write call, the writes of
+// concurrent requests are serialized so writer needs not be safe for
+// concurrent use.
+func WithWriter(writer io.Writer) Option {
+	return func(rh *loggerHanlder) {
+		rh.writer = writer
+	}
+}
+
+// WithFormat sets the log output type, default to CombineLoggerType
+func WithFormat(t Type) Option {
+	return func(rh *loggerHanlder) {
+		rh.formatType = t
+		rh.tokens = nil
+		rh.formatter = nil
+		rh.encoder = 0
+	}
+}
+
+// WithEncoder writes the entries in a binary format instead of the log
+// output type, e.g. WithEncoder(MsgpackEncoder) or
+// WithEncoder(ProtobufEncoder)
+func WithEncoder(enc Encoder) Option {
+	return func(rh *loggerHanlder) {
+		rh.encoder = enc
+		rh.formatter = nil
+	}
+}
+
+// WithTimeFormat sets the layout of the :date and :date[clf] tokens and of
+// the start_time structured field, e.g. time.RFC3339Nano or EpochMillis,
+// default to the Apache CLF layout 02/Jan/2006:15:04:05 -0700
+func WithTimeFormat(layout string) Option {
+	return func(rh *loggerHanlder) {
+		rh.timeLayout = layout
+	}
+}
+
+// WithUTC sets whether the request start time is logged in UTC instead of
+// the local time zone, default to false
+func WithUTC(utc bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.utc = utc
+	}
+}
+
+// WithColumns sets the columns of CSVLoggerType and TSVLoggerType, one
+// token each, e.g. WithColumns(":date[iso]", ":method", ":url", ":status").
+// It panics for anything else than a single known token.
+func WithColumns(columns ...string) Option {
+	compiled := compileColumns(columns)
+
+	return func(rh *loggerHanlder) {
+		rh.columns = compiled
+	}
+}
+
+// WithCSVHeader writes a header row of the column names, e.g. "date[iso]"
+// or "method", before the first entry of CSVLoggerType and TSVLoggerType
+func WithCSVHeader(header bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.csvHeader = header
+	}
+}
+
+// WithGCPProject sets the Google Cloud project ID naming the traces of
+// GCPLoggerType, projects/PROJECT_ID/traces/TRACE_ID, default to the
+// GOOGLE_CLOUD_PROJECT environment variable
+func WithGCPProject(projectID string) Option {
+	return func(rh *loggerHanlder) {
+		rh.gcpProject = projectID
+	}
+}
+
+// WithHook calls hook with every entry before it's formatted, so it can
+// change or enrich it, e.g. drop fields or replace IDs in e.URL. Hooks run
+// in the order they were added.
+func WithHook(hook func(e *Entry)) Option {
+	return func(rh *loggerHanlder) {
+		rh.hooks = append(rh.hooks, hook)
+	}
+}
+
+// WithGeoIP adds the location of the client address given by resolver to
+// the fields of the structured log outputs: geo.country, geo.city, geo.asn
+// and geo.as_org, those which are known. The locations of the last 4096
+// addresses are cached, private and loopback addresses aren't resolved.
+// It runs as a hook, in the order of the options. The geoip subpackage
+// resolves addresses with MaxMind GeoIP2 or GeoLite2 databases.
+func WithGeoIP(resolver GeoResolver) Option {
+	return WithHook(newGeoEnricher(resolver, geoCacheSize).enrich)
+}
+
+// WithUserAgentParser parses the User-Agent of the requests with parser,
+// e.g. BasicUserAgentParser or an adapter of a dedicated library, and adds
+// the user_agent.browser, user_agent.version, user_agent.os and
+// user_agent.bot fields to the structured log outputs. It runs as a hook,
+// in the order of the options.
+func WithUserAgentParser(parser UserAgentParser) Option {
+	return WithHook(userAgentHook(parser))
+}
+
+// WithTrafficClassification adds the traffic.class field to the
+// structured log outputs, the TrafficClass of the request: "bot",
+// "scanner" or "human", see ClassifyTraffic. SampleTraffic sets sampling
+// rates by class. It runs as a hook, in the order of the options.
+func WithTrafficClassification() Option {
+	return WithHook(func(e *Entry) {
+		e.Fields["traffic.class"] = string(ClassifyTraffic(e.Request))
+	})
+}
+
+// WithCustomFormat sets a morgan-style token format, see HandlerWithFormat
+// for the supported tokens
+func WithCustomFormat(format string) Option {
+	return func(rh *loggerHanlder) {
+		rh.formatType = CustomLoggerType
+		rh.tokens = compileFormat(format)
+		rh.formatter = nil
+	}
+}
+
+// WithFormatter prints the entries with f instead of the log output type,
+// e.g. FormatterFunc(func(w io.Writer, e *Entry) error { ... })
+func WithFormatter(f Formatter) Option {
+	return func(rh *loggerHanlder) {
+		rh.formatter = f
+	}
+}
+
+// WithClock sets the clock used to timestamp requests, default to the
+// system clock
+func WithClock(clock Clock) Option {
+	return func(rh *loggerHanlder) {
+		rh.clock = clock
+	}
+}
+
+// WithSkipper adds a function that reports whether a request should not be
+// logged at all, a request is skipped as soon as one of the skippers
+// matches. Skipped requests are passed to the wrapped handler untouched.
+func WithSkipper(skipper func(*http.Request) bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.skippers = append(rh.skippers, skipper)
+	}
+}
+
+// WithFields adds static fields to every structured log output, e.g. the
+// service name or version
+func WithFields(fields map[string]interface{}) Option {
+	return func(rh *loggerHanlder) {
+		if rh.fields == nil {
+			rh.fields = make(map[string]interface{}, len(fields))
+		}
+
+		for k, v := range fields {
+			rh.fields[k] = v
+		}
+	}
+}
+
+// WithBodyCapture captures up to maxBytes of the request body as the
+// downstream handler reads it, see HandlerWithBody. Only the bodies
+// DefaultBodyFilter or the filter of WithBodyFilter accepts are captured.
+func WithBodyCapture(maxBytes int) Option {
+	return func(rh *loggerHanlder) {
+		rh.bodyLimit = maxBytes
+	}
+}
+
+// WithBodyFilter sets the function deciding which request bodies
+// WithBodyCapture captures, default to DefaultBodyFilter
+func WithBodyFilter(filter func(*http.Request) bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.bodyFilter = filter
+	}
+}
+
+// WithLogrusLogger sets the logrus logger used by JsonLoggerType, by
+// default the handler creates its own JSON logger writing to the writer and
+// never touches the global one
+func WithLogrusLogger(logger *log.Logger) Option {
+	return WithBackend(LogrusBackend(logger))
+}
+
+// WithBackend sets the Backend JsonLoggerType entries are written to,
+// default to a logrus JSON logger
+func WithBackend(backend Backend) Option {
+	return func(rh *loggerHanlder) {
+		rh.backend = backend
+	}
+}
+
+// WithSlogLogger sets the log/slog logger used by SlogLoggerType, default to
+// a JSON logger printing to the writer
+func WithSlogLogger(logger *slog.Logger) Option {
+	return func(rh *loggerHanlder) {
+		rh.slog = logger
+	}
+}
+
+// WithRequestLogger makes logger the base of the loggers FromRequest
+// returns to the handlers, e.g. WithRequestLogger(slog.Default()), so
+// application logs carry the request ID, client IP and route of the
+// request
+func WithRequestLogger(logger *slog.Logger) Option {
+	return func(rh *loggerHanlder) {
+		rh.requestLogger = logger
+	}
+}
+
+// WithRequestID assigns an ID to every request: the incoming X-Request-ID
+// header when present, a random UUID otherwise. The ID is sent back in the
+// X-Request-ID response header, stored in the request context (see
+// RequestIDFromContext) and logged as the :request-id token or the
+// request.id structured field.
+func WithRequestID() Option {
+	return func(rh *loggerHanlder) {
+		rh.requestID = true
+	}
+}
+
+// WithCorrelationIDs logs the X-Correlation-ID and X-Amzn-Trace-Id headers
+// of the incoming requests, as :correlation-id / request.correlation_id
+// and :amzn-trace-id / request.amzn_trace_id, and exposes them with
+// CorrelationIDFromContext and AmznTraceIDFromContext. With echo they're
+// sent back in the response. The Transport of NewTransport copies them
+// from the context to the outgoing requests which have none.
+func WithCorrelationIDs(echo bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.correlation = true
+		rh.echoCorrelation = echo
+	}
+}
+
+// WithJWTSubject sets whether the sub claim of a Bearer JWT is logged as the
+// user of requests without Basic authorization. The token signature is not
+// verified, the claim is only fit for logging.
+func WithJWTSubject(enabled bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.jwtSubject = enabled
+	}
+}
+
+// WithRedactedHeaders replaces the values of the given request headers with
+// [REDACTED] in every log output, e.g.
+// WithRedactedHeaders("Authorization", "Cookie", "X-Api-Key")
+func WithRedactedHeaders(names ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.redactor.addHeaders(names...)
+	}
+}
+
+// WithLoggedRequestHeaders only logs the given request headers, e.g.
+// WithLoggedRequestHeaders("Content-Type", "X-Request-ID"), instead of all
+// of them in the request.header field of JsonLoggerType
+func WithLoggedRequestHeaders(names ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.requestHeaders.add(names...)
+	}
+}
+
+// WithLoggedResponseHeaders logs the given response headers in the
+// response.header field of JsonLoggerType, none are logged by default
+func WithLoggedResponseHeaders(names ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.responseHeaders.add(names...)
+	}
+}
+
+// WithRedactedQueryParams replaces the values of the given query parameters
+// with [REDACTED] in the URL and referer of every log output, names are
+// matched case-insensitively
+func WithRedactedQueryParams(names ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.redactor.addParams(names...)
+	}
+}
+
+// WithMasking replaces the sensitive data the rules match in the captured
+// request and response bodies, and in the fields of WithMaskedFields, with
+// [REDACTED], e.g. WithMasking(MaskEmails, MaskCreditCards,
+// MaskJSONPath("password"))
+func WithMasking(rules ...MaskRule) Option {
+	return func(rh *loggerHanlder) {
+		rh.masker.add(rules...)
+	}
+}
+
+// WithMaskedFields applies the rules of WithMasking to the string values of
+// the given fields, added by AddField or WithFields
+func WithMaskedFields(keys ...string) Option {
+	return func(rh *loggerHanlder) {
+		if rh.masker.fields == nil {
+			rh.masker.fields = make(map[string]bool, len(keys))
+		}
+
+		for _, key := range keys {
+			rh.masker.fields[key] = true
+		}
+	}
+}
+
+// WithAuditChain makes the log output tamper-evident: every line gets an
+// audit_hash field, the HMAC-SHA256 with key (SHA-256 when key is nil) of
+// the previous line's hash followed by the line, so a changed, removed or
+// reordered line breaks the chain, see parse.VerifyChain. JSON lines get
+// one more JSON field, the others end with " audit_hash=<hex>". Each
+// handler starts a new chain, which parse.VerifyChain accepts after the
+// one of a previous process appending to the same file.
+func WithAuditChain(key []byte) Option {
+	return func(rh *loggerHanlder) {
+		rh.audit = true
+		rh.auditKey = key
+	}
+}
+
+// WithBuffering buffers up to size bytes of log output in memory, written
+// to the writer every flushInterval, when the buffer is full and when the
+// handler is flushed or closed. It saves syscalls when logging to files
+// under high request rates, close the handler on shutdown so buffered
+// entries are not lost.
+func WithBuffering(size int, flushInterval time.Duration) Option {
+	return func(rh *loggerHanlder) {
+		rh.bufferSize = size
+		rh.flushInterval = flushInterval
+	}
+}
+
+// WithMaxFieldLength truncates field to n bytes followed by "..." in every
+// log output, so huge values can't blow the record size limits of log
+// pipelines. field is "user-agent", "referer", "query" (the query string)
+// or "url" (the whole URL). It panics for other fields and negative
+// lengths.
+func WithMaxFieldLength(field string, n int) Option {
+	field = strings.ToLower(field)
+	if !truncatedFields[field] {
+		panic("logger: unknown field " + field + ", want user-agent, referer, query or url")
+	}
+
+	if n < 0 {
+		panic("logger: negative max length " + strconv.Itoa(n) + " of field " + field)
+	}
+
+	return func(rh *loggerHanlder) {
+		if rh.limits == nil {
+			rh.limits = fieldLimits{}
+		}
+
+		rh.limits[field] = n
+	}
+}
+
+// WithAsync moves log writing off the request path: entries are formatted
+// by the request goroutine then queued to a channel of bufferSize entries,
+// drained by background workers. Close the handler on shutdown so queued
+// entries are not lost.
+func WithAsync(bufferSize int) Option {
+	return func(rh *loggerHanlder) {
+		rh.asyncSize = bufferSize
+	}
+}
+
+// WithAsyncWorkers sets the number of background workers of WithAsync,
+// default to 1 which keeps entries in order
+func WithAsyncWorkers(n int) Option {
+	return func(rh *loggerHanlder) {
+		rh.asyncWorkers = n
+	}
+}
+
+// WithExpvar publishes the Counters of the handler with expvar under
+// name, e.g. "logger", so operators can check in /debug/vars that no entry
+// is silently dropped. Like expvar.Publish, New panics when name is
+// already published.
+func WithExpvar(name string) Option {
+	return func(rh *loggerHanlder) {
+		rh.expvarName = name
+	}
+}
+
+// WithErrorHandler sets the function called with the error of every entry
+// which could not be written, the error of the writer or ErrQueueFull when
+// OverflowDrop drops it. Entries are dropped on errors and counted by the
+// Dropped method of the handler, by default silently. Handle them to fail
+// loudly, e.g. alert or exit when the audit log can't be written. f may be
+// called concurrently, from the WithAsync workers too.
+func WithErrorHandler(f func(error)) Option {
+	return func(rh *loggerHanlder) {
+		rh.onError = f
+	}
+}
+
+// WithOverflowPolicy sets what WithAsync does when its queue is full,
+// default to OverflowBlock
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(rh *loggerHanlder) {
+		rh.overflow = policy
+	}
+}
+
+// WithColor sets whether DevLoggerType colors the status code by class
+// (green 2xx, cyan 3xx, yellow 4xx, red 5xx) and highlights slow requests,
+// default to ColorAuto
+func WithColor(mode ColorMode) Option {
+	return func(rh *loggerHanlder) {
+		rh.color = mode
+	}
+}
+
+// WithSlowThreshold sets the response time above which a colored
+// DevLoggerType highlights a request, default to DefaultSlowThreshold. Zero
+// disables the highlighting.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(rh *loggerHanlder) {
+		rh.slowThreshold = d
+	}
+}
+
+// WithDurationUnit sets the unit of the :response-time and :ttfb tokens,
+// printed by the :duration-unit token, default to Millisecond.
+func WithDurationUnit(unit DurationUnit) Option {
+	return func(rh *loggerHanlder) {
+		rh.durations.unit = unit
+	}
+}
+
+// WithDurationFormat sets the fmt verb the :response-time and :ttfb tokens
+// are printed with, given the float64 value in the configured unit, e.g.
+// "%.0f". Default to 3 decimals.
+func WithDurationFormat(format string) Option {
+	return func(rh *loggerHanlder) {
+		rh.durations.format = format
+	}
+}
+
+// WithTrustedProxies logs the client address found in the Forwarded,
+// X-Forwarded-For or X-Real-IP headers as :remote-addr, but only for
+// requests whose direct peer is in one of cidrs, e.g.
+// WithTrustedProxies("10.0.0.0/8", "127.0.0.1"). A hop which can't be
+// parsed, e.g. for=unknown, stops the lookup at the last trusted proxy. It
+// panics if a CIDR is invalid.
+func WithTrustedProxies(cidrs ...string) Option {
+	proxies := parseTrustedProxies(cidrs...)
+
+	return func(rh *loggerHanlder) {
+		rh.proxies = append(rh.proxies, proxies...)
+	}
+}
+
+// WithResponseBodyCapture captures up to maxBytes of the response body into
+// the response.body structured field, only for the content types allowed
+// by WithResponseBodyTypes so binary payloads are skipped
+func WithResponseBodyCapture(maxBytes int) Option {
+	return func(rh *loggerHanlder) {
+		rh.resBodyLimit = maxBytes
+	}
+}
+
+// WithResponseBodyTypes sets the content types WithResponseBodyCapture
+// captures, e.g. "application/json", "text/*" or "application/*+json",
+// default to DefaultResponseBodyTypes
+func WithResponseBodyTypes(types ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.resBodyTypes = types
+	}
+}
+
+// WithRouteFunc adds f to the functions the pattern of the route of the
+// requests is taken from, those of http.ServeMux being recorded on the
+// request already, e.g. WithRouteFunc(chiroute.Route) for chi. The first
+// one not returning "" wins.
+func WithRouteFunc(f RouteFunc) Option {
+	return func(rh *loggerHanlder) {
+		rh.routeFuncs = append(rh.routeFuncs, f)
+	}
+}
+
+// WithMetrics records Prometheus metrics alongside the log output:
+// http_requests_total, http_requests_in_flight,
+// http_request_duration_seconds and http_response_size_bytes, labeled by
+// method, status and route pattern. Handlers sharing a registerer share
+// the metrics.
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return func(rh *loggerHanlder) {
+		rh.metrics = newMetrics(registerer)
+	}
+}
+
+// WithStatsd sends statsd metrics alongside the log output, over UDP to
+// the agent at addr, e.g. "127.0.0.1:8125", for teams not running
+// Prometheus: the counter <prefix>http.requests.<status class>, e.g.
+// http.requests.5xx, the timing <prefix>http.request.duration in
+// milliseconds and the gauge <prefix>http.requests.in_flight, sent as
+// requests end. See WithDogStatsd for tagged metrics.
+func WithStatsd(addr, prefix string) Option {
+	return func(rh *loggerHanlder) {
+		rh.statsd = newStatsd(addr, prefix, false, nil)
+		rh.owned = append(rh.owned, rh.statsd)
+	}
+}
+
+// WithDogStatsd is WithStatsd for DogStatsD, the Datadog agent: the
+// metrics are tagged with tags, e.g. "env:prod", and those of the
+// requests but the gauge with method, status, status_class and route.
+func WithDogStatsd(addr, prefix string, tags ...string) Option {
+	return func(rh *loggerHanlder) {
+		rh.statsd = newStatsd(addr, prefix, true, tags)
+		rh.owned = append(rh.owned, rh.statsd)
+	}
+}
+
+// WithStats aggregates every request in stats, see NewStats. Handlers
+// given the same StatsAggregator share its totals.
+func WithStats(stats *StatsAggregator) Option {
+	return func(rh *loggerHanlder) {
+		rh.stats = stats
+	}
+}
+
+// WithSyslog sends the log output to syslog, see SyslogWriter. The
+// connection is made on the first entry and remade whenever it fails.
+func WithSyslog(network, addr, tag string, priority SyslogPriority) Option {
+	return func(rh *loggerHanlder) {
+		w := newSyslogWriter(network, addr, tag, priority)
+
+		rh.writer = w
+		rh.owned = append(rh.owned, w)
+	}
+}
+
+// WithSampling logs only the given fraction of requests, from 0 to 1, see
+// SampleRate
+func WithSampling(rate float64) Option {
+	return WithSampler(SampleRate(rate))
+}
+
+// WithSampler sets a Sampler deciding after each request whether it is
+// logged, e.g. WithSampler(SampleErrors(SampleRate(0.01))). Metrics are
+// recorded for every request.
+func WithSampler(sampler Sampler) Option {
+	return func(rh *loggerHanlder) {
+		rh.sampler = sampler
+	}
+}
+
+// WithCondition only logs the requests for which condition holds once the
+// handler returned, e.g. WithCondition(Any(SlowerThan(500*time.Millisecond),
+// StatusAtLeast(400))). Several conditions must all hold. Panics are always
+// logged.
+func WithCondition(condition func(*http.Request, Stats) bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.conditions = append(rh.conditions, condition)
+	}
+}
+
+// QuietSuccessfulProbes only logs the Kubernetes liveness and readiness
+// probes that fail with a 4xx or 5xx status. Probes are the requests with a
+// kube-probe User-Agent or to /healthz, /livez, /readyz, /health, /live or
+// /ready.
+func QuietSuccessfulProbes() Option {
+	return WithCondition(quietProbes)
+}
+
+// WithRateLimit logs at most n entries per period, e.g.
+// WithRateLimit(100, time.Second), so a traffic spike can't fill the disk or
+// saturate a log sink. The first entry of the next period is preceded by a
+// "suppressed N entries" summary line, Close writes the last one. The
+// summary is a warning for the structured log outputs and a #Remark for
+// W3CLoggerType, it's left out of the CSV, CEF and LEEF outputs and those
+// of WithFormatter. Panics are always logged.
+func WithRateLimit(n int, per time.Duration) Option {
+	return func(rh *loggerHanlder) {
+		rh.limiter = newRateLimiter(n, per)
+	}
+}
+
+// WithDeduplication collapses the entries repeating the method, path and
+// status of an entry logged less than window ago, e.g. the retries of a
+// broken client: the first entry is logged, the duplicates are counted
+// and written as a single "repeated GET /path 503 count=N" line once the
+// window is over, with the request.method, request.path, response.status
+// and count fields for the structured log outputs. Like the summary of
+// WithRateLimit, it's left out of the outputs which can't express it.
+// Close writes the last ones. Panics are always logged.
+func WithDeduplication(window time.Duration) Option {
+	return func(rh *loggerHanlder) {
+		rh.repeats = newDeduplicator(window)
+	}
+}
+
+// WithLevelFunc sets how the structured log outputs pick the level of an
+// entry from its status, default to DefaultLevel
+func WithLevelFunc(level func(status int) Level) Option {
+	return func(rh *loggerHanlder) {
+		rh.level = level
+	}
+}
+
+// WithStreamEntries logs streamed responses, the ones the handler flushed,
+// twice: a preliminary entry once the stream started, on the first flush,
+// marked preliminary=true, and the usual one once it ended. The entry of
+// the end of a stream is always logged when the one of its start was.
+func WithStreamEntries(enabled bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.streamEntries = enabled
+	}
+}
+
+// WithWebSocketSessions logs the WebSocket upgrades of the handler twice:
+// once the connection is hijacked, with the 101 status, and once it's
+// closed and the handler returned, with the duration of the session, the
+// frames and bytes read and written and the close code, see
+// WebSocketSession. The entry of the close is always logged when the one
+// of the upgrade was.
+func WithWebSocketSessions(enabled bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.webSocketSessions = enabled
+	}
+}
+
+// WithRecovery recovers from the panics of the wrapped handler: the panic
+// value and stack trace are logged with the entry, marked panic=true, and
+// a 500 is sent if nothing was written yet. Panics with
+// http.ErrAbortHandler are left alone.
+func WithRecovery(recovery bool) Option {
+	return func(rh *loggerHanlder) {
+		rh.recovery = recovery
+	}
+}
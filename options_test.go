@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type OptionsSuite struct {
+	suite.Suite
+
+	req *http.Request
+	w   *testWriter
+}
+
+func (s *OptionsSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/", nil)
+	s.req.RemoteAddr = "192.0.2.1:1234"
+	s.w = &testWriter{}
+}
+
+func (s *OptionsSuite) TestDefaults() {
+	rh := New(http.NotFoundHandler()).(loggerHanlder)
+
+	s.Equal(CombineLoggerType, rh.formatType)
+	s.NotNil(rh.writer)
+	s.Equal(realClock{}, rh.clock)
+}
+
+func (s *OptionsSuite) TestWithFormat() {
+	h := New(http.NotFoundHandler(), WithWriter(s.w), WithFormat(CommonLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal(`192.0.2.1:1234 - - [02/Jan/2017:15:04:05 +0000] "GET / HTTP/1.1" 404 19`+"\n", string(s.w.Bytes))
+}
+
+func (s *OptionsSuite) TestWithCustomFormat() {
+	h := New(http.NotFoundHandler(), WithWriter(s.w), WithCustomFormat(":method :status"))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("GET 404\n", string(s.w.Bytes))
+}
+
+func (s *OptionsSuite) TestWithSkipper() {
+	called := false
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		called = true
+	}), WithWriter(s.w), WithSkipper(func(req *http.Request) bool {
+		return req.URL.Path == "/"
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.True(called)
+	s.Empty(s.w.Bytes)
+}
+
+func (s *OptionsSuite) TestWithFields() {
+	rh := New(http.NotFoundHandler(),
+		WithFields(map[string]interface{}{"service": "api"}),
+		WithFields(map[string]interface{}{"version": "1.0"})).(loggerHanlder)
+
+	s.Equal(map[string]interface{}{"service": "api", "version": "1.0"}, rh.fields)
+}
+
+func (s *OptionsSuite) TestWithLogrusLogger() {
+	var buf bytes.Buffer
+	l := newTestLogrus(&buf)
+
+	formatter := log.StandardLogger().Formatter
+
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(l),
+		WithFields(map[string]interface{}{"service": "api"}))
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("GET", entry["request.method"])
+	s.Equal("404", entry["response.status"])
+	s.Equal("api", entry["service"])
+	s.Equal("request processed", entry["msg"])
+
+	s.Equal(formatter, log.StandardLogger().Formatter)
+}
+
+func TestOptions(t *testing.T) {
+	suite.Run(t, new(OptionsSuite))
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (tc testClock) Now() time.Time {
+	return tc.now
+}
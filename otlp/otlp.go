@@ -0,0 +1,334 @@
+// Package otlp exports the entries of the logger package as OpenTelemetry
+// log records over OTLP, with the attributes of the HTTP semantic
+// conventions, see Exporter.
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scope is the instrumentation scope of the records
+const scope = "github.com/go-http-utils/logger"
+
+// Option configures the Exporter returned by New, NewGRPC and NewHTTP
+type Option func(*config)
+
+type config struct {
+	headers  map[string]string
+	resource *resource.Resource
+	batch    []sdklog.BatchProcessorOption
+}
+
+// WithHeaders sets headers sent with every export request, e.g. an API
+// key. It only applies to NewGRPC and NewHTTP.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *config) {
+		c.headers = headers
+	}
+}
+
+// WithResource sets the resource describing the service, e.g. its
+// service.name, default to the one of the OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES environment variables
+func WithResource(res *resource.Resource) Option {
+	return func(c *config) {
+		c.resource = res
+	}
+}
+
+// WithBatchSize sets the maximum number of records sent in one export
+// request, default to 512
+func WithBatchSize(n int) Option {
+	return func(c *config) {
+		c.batch = append(c.batch, sdklog.WithExportMaxBatchSize(n))
+	}
+}
+
+// WithExportInterval sets how often the pending records are sent, default
+// to 1 second
+func WithExportInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.batch = append(c.batch, sdklog.WithExportInterval(d))
+	}
+}
+
+// WithMaxQueueSize sets the number of records waiting to be sent past
+// which the oldest are dropped, default to 2048
+func WithMaxQueueSize(n int) Option {
+	return func(c *config) {
+		c.batch = append(c.batch, sdklog.WithMaxQueueSize(n))
+	}
+}
+
+// Exporter is a logger.Formatter emitting every entry as a log record,
+// e.g. logger.WithFormatter(exporter). Records are batched and exported in
+// the background, so formatting never waits for the collector. Call
+// Shutdown on exit to send the pending records.
+type Exporter struct {
+	provider *sdklog.LoggerProvider
+	logger   log.Logger
+}
+
+// New returns an Exporter sending the records through exporter
+func New(exporter sdklog.Exporter, opts ...Option) *Exporter {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	providerOpts := []sdklog.LoggerProviderOption{
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter, c.batch...)),
+	}
+	if c.resource != nil {
+		providerOpts = append(providerOpts, sdklog.WithResource(c.resource))
+	}
+
+	provider := sdklog.NewLoggerProvider(providerOpts...)
+
+	return &Exporter{provider: provider, logger: provider.Logger(scope)}
+}
+
+// NewGRPC returns an Exporter sending the records over OTLP/gRPC to
+// endpoint, e.g. "http://localhost:4317", a http scheme disabling TLS.
+// Failed exports are retried with exponential backoff.
+func NewGRPC(ctx context.Context, endpoint string, opts ...Option) (*Exporter, error) {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	exporterOpts := []otlploggrpc.Option{otlploggrpc.WithEndpointURL(endpoint)}
+	if c.headers != nil {
+		exporterOpts = append(exporterOpts, otlploggrpc.WithHeaders(c.headers))
+	}
+
+	exporter, err := otlploggrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(exporter, opts...), nil
+}
+
+// NewHTTP returns an Exporter sending the records over OTLP/HTTP to
+// endpoint, e.g. "http://localhost:4318/v1/logs". Failed exports are
+// retried with exponential backoff.
+func NewHTTP(ctx context.Context, endpoint string, opts ...Option) (*Exporter, error) {
+	c := config{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	exporterOpts := []otlploghttp.Option{otlploghttp.WithEndpointURL(endpoint)}
+	if c.headers != nil {
+		exporterOpts = append(exporterOpts, otlploghttp.WithHeaders(c.headers))
+	}
+
+	exporter, err := otlploghttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(exporter, opts...), nil
+}
+
+// Format emits e as a log record, the writer of the handler is unused
+func (ex *Exporter) Format(_ io.Writer, e *logger.Entry) error {
+	rec := log.Record{}
+	rec.SetTimestamp(e.Start)
+	rec.SetObservedTimestamp(time.Now())
+	rec.SetSeverity(severity(e.Level))
+	rec.SetSeverityText(severity(e.Level).String())
+	rec.SetBody(attribute.StringValue(e.Method + " " + e.URL + " " + strconv.Itoa(e.Status)))
+	rec.AddAttributes(attributes(e)...)
+
+	ex.logger.Emit(traceContext(e), rec)
+
+	return nil
+}
+
+// Flush exports the pending records
+func (ex *Exporter) Flush(ctx context.Context) error {
+	return ex.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the pending records and stops the exporter, the entries
+// formatted afterwards are dropped
+func (ex *Exporter) Shutdown(ctx context.Context) error {
+	return ex.provider.Shutdown(ctx)
+}
+
+func severity(level logger.Level) log.Severity {
+	switch level {
+	case logger.LevelDebug:
+		return log.SeverityDebug
+	case logger.LevelWarn:
+		return log.SeverityWarn
+	case logger.LevelError:
+		return log.SeverityError
+	}
+
+	return log.SeverityInfo
+}
+
+// traceContext returns the context the trace and span IDs of the record
+// are taken from: the one of the request when it carries a span, otherwise
+// the IDs of the entry, from the traceparent or B3 headers
+func traceContext(e *logger.Entry) context.Context {
+	ctx := context.Background()
+	if e.Request != nil {
+		ctx = e.Request.Context()
+	}
+
+	if trace.SpanContextFromContext(ctx).IsValid() || e.TraceID == "" {
+		return ctx
+	}
+
+	traceID, err := trace.TraceIDFromHex(e.TraceID)
+	if err != nil {
+		return ctx
+	}
+
+	spanID, _ := trace.SpanIDFromHex(e.SpanID)
+
+	return trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}))
+}
+
+// attributes returns the attributes of e, named after the HTTP semantic
+// conventions when there's one, after the fields of JsonLoggerType
+// otherwise
+func attributes(e *logger.Entry) []attribute.KeyValue {
+	path, query, _ := strings.Cut(e.URL, "?")
+
+	scheme := "http"
+	if e.TLS != nil {
+		scheme = "https"
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", e.Method),
+		attribute.String("url.path", path),
+		attribute.String("url.scheme", scheme),
+		attribute.String("network.protocol.name", "http"),
+		attribute.String("network.protocol.version", strings.TrimPrefix(e.Proto, "HTTP/")),
+		attribute.Int("http.response.status_code", e.Status),
+		attribute.Int("http.response.body.size", e.Size),
+		attribute.Int64("http.request.body.size", e.RequestSize),
+		attribute.Float64("http.server.request.duration", e.Duration.Seconds()),
+	}
+
+	if query != "" {
+		attrs = append(attrs, attribute.String("url.query", query))
+	}
+
+	attrs = append(attrs, address("server", e.Host)...)
+	attrs = append(attrs, address("client", e.RemoteAddr)...)
+
+	if e.Route != "" {
+		attrs = append(attrs, attribute.String("http.route", e.Route))
+	}
+
+	if e.UserAgent != "" {
+		attrs = append(attrs, attribute.String("user_agent.original", e.UserAgent))
+	}
+
+	if e.RemoteUser != "" {
+		attrs = append(attrs, attribute.String("user.name", e.RemoteUser))
+	}
+
+	if e.RequestID != "" {
+		attrs = append(attrs, attribute.String("request.id", e.RequestID))
+	}
+
+	if e.CorrelationID != "" {
+		attrs = append(attrs, attribute.String("request.correlation_id", e.CorrelationID))
+	}
+
+	if e.TLS != nil {
+		attrs = append(attrs,
+			attribute.String("tls.protocol.name", "tls"),
+			attribute.String("tls.protocol.version", strings.TrimPrefix(e.TLS.Version, "TLS ")),
+			attribute.String("tls.cipher", e.TLS.CipherSuite))
+	}
+
+	for name, values := range e.Header {
+		attrs = append(attrs, attribute.StringSlice("http.request.header."+strings.ToLower(name), values))
+	}
+
+	for name, values := range e.ResponseHeader {
+		attrs = append(attrs, attribute.StringSlice("http.response.header."+strings.ToLower(name), values))
+	}
+
+	if e.Panicked {
+		attrs = append(attrs,
+			attribute.String("exception.type", "panic"),
+			attribute.String("exception.message", e.PanicValue),
+			attribute.String("exception.stacktrace", string(e.Stack)))
+	}
+
+	for k, v := range e.Fields {
+		attrs = append(attrs, field(k, v))
+	}
+
+	return attrs
+}
+
+// address returns the <prefix>.address and <prefix>.port attributes of
+// hostport
+func address(prefix, hostport string) []attribute.KeyValue {
+	if hostport == "" {
+		return nil
+	}
+
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return []attribute.KeyValue{attribute.String(prefix+".address", hostport)}
+	}
+
+	attrs := []attribute.KeyValue{attribute.String(prefix+".address", host)}
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, attribute.Int(prefix+".port", n))
+	}
+
+	return attrs
+}
+
+// field returns the attribute of a custom field, values of other types
+// than strings, booleans and numbers are formatted with fmt
+func field(k string, v interface{}) attribute.KeyValue {
+	switch v := v.(type) {
+	case string:
+		return attribute.String(k, v)
+	case bool:
+		return attribute.Bool(k, v)
+	case int:
+		return attribute.Int(k, v)
+	case int64:
+		return attribute.Int64(k, v)
+	case uint:
+		return attribute.Int64(k, int64(v))
+	case float64:
+		return attribute.Float64(k, v)
+	}
+
+	return attribute.String(k, fmt.Sprint(v))
+}
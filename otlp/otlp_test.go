@@ -0,0 +1,145 @@
+package otlp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+type testExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (te *testExporter) Export(_ context.Context, records []sdklog.Record) error {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+
+	for _, r := range records {
+		te.records = append(te.records, r.Clone())
+	}
+
+	return nil
+}
+
+func (te *testExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (te *testExporter) ForceFlush(context.Context) error {
+	return nil
+}
+
+type OTLPSuite struct {
+	suite.Suite
+
+	te *testExporter
+	ex *Exporter
+}
+
+func (s *OTLPSuite) SetupTest() {
+	s.te = &testExporter{}
+	s.ex = New(s.te)
+}
+
+func (s *OTLPSuite) serve(h http.Handler, req *http.Request) sdklog.Record {
+	logger.New(h, logger.WithFormatter(s.ex)).ServeHTTP(httptest.NewRecorder(), req)
+	s.Nil(s.ex.Flush(context.Background()))
+
+	s.Require().Len(s.te.records, 1)
+
+	return s.te.records[0]
+}
+
+func attrs(r sdklog.Record) map[string]attribute.Value {
+	m := map[string]attribute.Value{}
+	r.WalkAttributes(func(kv attribute.KeyValue) bool {
+		m[string(kv.Key)] = kv.Value
+
+		return true
+	})
+
+	return m
+}
+
+func (s *OTLPSuite) TestRecord() {
+	req := httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	r := s.serve(http.NotFoundHandler(), req)
+
+	s.Equal("GET /users?id=1 404", r.Body().AsString())
+	s.Equal(log.SeverityWarn, r.Severity())
+	s.Equal("WARN", r.SeverityText())
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", r.TraceID().String())
+	s.Equal("00f067aa0ba902b7", r.SpanID().String())
+
+	a := attrs(r)
+	s.Equal("GET", a["http.request.method"].AsString())
+	s.Equal("/users", a["url.path"].AsString())
+	s.Equal("id=1", a["url.query"].AsString())
+	s.Equal("http", a["url.scheme"].AsString())
+	s.Equal("1.1", a["network.protocol.version"].AsString())
+	s.Equal(int64(404), a["http.response.status_code"].AsInt64())
+	s.Equal("192.0.2.1", a["client.address"].AsString())
+	s.Equal(int64(1234), a["client.port"].AsInt64())
+	s.Equal("example.com", a["server.address"].AsString())
+	s.Equal("test-agent", a["user_agent.original"].AsString())
+	s.Equal([]string{"test-agent"}, a["http.request.header.user-agent"].AsStringSlice())
+}
+
+func (s *OTLPSuite) TestFields() {
+	h := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		logger.AddField(req.Context(), "tenant", "acme")
+		logger.AddField(req.Context(), "retries", 2)
+	})
+
+	a := attrs(s.serve(h, httptest.NewRequest(http.MethodPost, "/", nil)))
+
+	s.Equal("acme", a["tenant"].AsString())
+	s.Equal(int64(2), a["retries"].AsInt64())
+}
+
+func (s *OTLPSuite) TestSeverity() {
+	s.Equal(log.SeverityDebug, severity(logger.LevelDebug))
+	s.Equal(log.SeverityInfo, severity(logger.LevelInfo))
+	s.Equal(log.SeverityError, severity(logger.LevelError))
+}
+
+func (s *OTLPSuite) TestHTTP() {
+	var mu sync.Mutex
+	var paths []string
+
+	collector := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		paths = append(paths, req.URL.Path+" "+req.Header.Get("X-Api-Key"))
+		mu.Unlock()
+
+		res.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	ex, err := NewHTTP(context.Background(), collector.URL+"/v1/logs", WithHeaders(map[string]string{"X-Api-Key": "secret"}))
+	s.Nil(err)
+
+	logger.New(http.NotFoundHandler(), logger.WithFormatter(ex)).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Nil(ex.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.Equal([]string{"/v1/logs secret"}, paths)
+}
+
+func TestOTLP(t *testing.T) {
+	suite.Run(t, new(OTLPSuite))
+}
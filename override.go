@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// routeOverride is a path and the options its requests are logged with
+type routeOverride struct {
+	path    string
+	opts    []Option
+	handler loggerHanlder
+}
+
+// WithRouteOverride logs the requests to path and below it, e.g.
+// "/payments" matches "/payments" and "/payments/42", with opts applied on
+// top of the other options, e.g. WithRouteOverride("/payments",
+// WithFormat(JsonLoggerType), WithBodyCapture(4096)) for a handler logging
+// TinyLoggerType elsewhere. The first matching override wins. The targets
+// of WithTargets aren't inherited, overrides without WithWriter or their
+// own WithTargets write to the writer of the handler.
+func WithRouteOverride(path string, opts ...Option) Option {
+	return func(rh *loggerHanlder) {
+		rh.routes = append(rh.routes, routeOverride{path: path, opts: opts})
+	}
+}
+
+// matches reports whether the path of req is ro.path or below it
+func (ro routeOverride) matches(req *http.Request) bool {
+	path := req.URL.Path
+
+	return path == ro.path || strings.HasPrefix(path, strings.TrimSuffix(ro.path, "/")+"/")
+}
+
+// override returns the handler of ro, rh being configured but not yet
+// prepared and parent prepared
+func (rh loggerHanlder) override(ro routeOverride, parent loggerHanlder) loggerHanlder {
+	rh.writer = nil
+	rh.routes, rh.owned = nil, nil
+	// the outputs of parent are its own, closed by it
+	rh.targets, rh.outputs = nil, nil
+	rh.directives = &sync.Once{}
+
+	for _, opt := range ro.opts {
+		opt(&rh)
+	}
+
+	for _, t := range rh.targets {
+		rh.outputs = append(rh.outputs, rh.output(t))
+	}
+
+	statusWriters := rh.prepareStatusWriters(parent.statusWriters)
+
+	if rh.writer == nil {
+		// the writer of parent already chains, buffers and writes
+		// asynchronously
+		rh.writer = parent.writer
+		rh.audit, rh.bufferSize, rh.asyncSize = false, 0, 0
+	}
+
+	rh = rh.prepare()
+	rh.statusWriters = statusWriters
+
+	return rh.withSelf()
+}
+
+func (rh loggerHanlder) flushRoutes() error {
+	errs := []error{}
+
+	for _, ro := range rh.routes {
+		errs = append(errs, ro.handler.Flush())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (rh loggerHanlder) closeRoutes() error {
+	errs := []error{}
+
+	for _, ro := range rh.routes {
+		errs = append(errs, ro.handler.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type OverrideSuite struct {
+	suite.Suite
+}
+
+func (s *OverrideSuite) TestMatches() {
+	ro := routeOverride{path: "/payments"}
+
+	s.True(ro.matches(httptest.NewRequest(http.MethodGet, "/payments", nil)))
+	s.True(ro.matches(httptest.NewRequest(http.MethodGet, "/payments/42", nil)))
+	s.False(ro.matches(httptest.NewRequest(http.MethodGet, "/paymentsx", nil)))
+	s.False(ro.matches(httptest.NewRequest(http.MethodGet, "/", nil)))
+
+	s.True(routeOverride{path: "/static/"}.matches(httptest.NewRequest(http.MethodGet, "/static/a.css", nil)))
+}
+
+func (s *OverrideSuite) TestFormat() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&buf), WithFormat(TinyLoggerType), WithClock(testClock{}),
+		WithRouteOverride("/payments", WithFormat(JsonLoggerType), WithBodyCapture(64)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(`{"amount":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.Len(lines, 2)
+	s.Equal("GET / 404 19 - 0.000 ms", lines[0])
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(lines[1]), &entry))
+	s.Equal("/payments", entry["request.url"])
+	s.Equal("404", entry["response.status"])
+}
+
+func (s *OverrideSuite) TestWriterAndClose() {
+	tw, routeWriter := testWriter{}, &syncWriter{}
+	h := NewLogger(http.NotFoundHandler(), WithWriter(&tw), WithFormat(TinyLoggerType), WithClock(testClock{}),
+		WithRouteOverride("/upload", WithWriter(routeWriter), WithAsync(8), WithCustomFormat(":method :url")))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/upload/a", nil))
+	s.Nil(h.Close(context.Background()))
+
+	s.Empty(tw.Bytes)
+	s.Equal("PUT /upload/a\n", routeWriter.String())
+}
+
+// closeCounter counts its Close calls
+type closeCounter struct {
+	closes int
+}
+
+func (cc *closeCounter) Close() error {
+	cc.closes++
+
+	return nil
+}
+
+func (s *OverrideSuite) TestTargets() {
+	target, routeWriter := &syncWriter{}, &syncWriter{}
+	owned := &closeCounter{}
+
+	h := NewLogger(http.NotFoundHandler(), WithClock(testClock{}),
+		WithTargets(Target{Writer: target, Type: TinyLoggerType, Options: []Option{func(rh *loggerHanlder) {
+			rh.owned = append(rh.owned, owned)
+		}}}),
+		WithRouteOverride("/payments", WithWriter(routeWriter), WithCustomFormat(":method :url")))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/payments", nil))
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal("GET / 404 19 - 0.000 ms\n", target.String())
+	s.Equal("POST /payments\n", routeWriter.String())
+	s.Equal(1, owned.closes)
+}
+
+func (s *OverrideSuite) TestOwnTargets() {
+	tw, routeTarget := testWriter{}, &syncWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(TinyLoggerType),
+		WithRouteOverride("/payments", WithTargets(Target{Writer: routeTarget, Type: CustomLoggerType,
+			Options: []Option{WithCustomFormat(":method :url")}})))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/payments", nil))
+
+	s.Empty(tw.Bytes)
+	s.Equal("POST /payments\n", routeTarget.String())
+}
+
+func TestOverride(t *testing.T) {
+	suite.Run(t, new(OverrideSuite))
+}
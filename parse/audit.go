@@ -0,0 +1,90 @@
+package parse
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+)
+
+// ErrChain is returned by VerifyChain for the lines whose audit hash
+// doesn't chain with the previous one
+var ErrChain = errors.New("parse: audit chain broken")
+
+// maxAuditLine is the longest line VerifyChain reads, e.g. with a stack
+const maxAuditLine = 1 << 20
+
+var (
+	jsonAuditRegexp = regexp.MustCompile(`^(\{.*),"audit_hash":"([0-9a-f]{64})"\}$`)
+	textAuditRegexp = regexp.MustCompile(`^(.*) audit_hash=([0-9a-f]{64})$`)
+)
+
+// VerifyChain checks the audit_hash chain of the lines of r, written with
+// logger.WithAuditChain(key). It returns an error wrapping ErrChain for
+// the first line changed, inserted, removed or reordered. The chains
+// started by every handler appending to the same file, e.g. after a
+// restart, follow one another, the lines removed at the end of one of
+// them can't be told apart from a restart.
+func VerifyChain(r io.Reader, key []byte) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), maxAuditLine)
+
+	var prev []byte
+	for n := 1; s.Scan(); n++ {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+
+		line, sum, ok := splitAuditHash(s.Text())
+		if !ok {
+			return fmt.Errorf("%w: line %d has no audit hash", ErrChain, n)
+		}
+
+		expected := auditHash(key, prev, []byte(line))
+		if !hmac.Equal(expected, []byte(sum)) && prev != nil {
+			// a handler, e.g. of a restarted process, started a new chain
+			expected = auditHash(key, nil, []byte(line))
+		}
+		if !hmac.Equal(expected, []byte(sum)) {
+			return fmt.Errorf("%w at line %d", ErrChain, n)
+		}
+
+		prev = expected
+	}
+
+	return s.Err()
+}
+
+// splitAuditHash returns line without its audit hash, and the hash
+func splitAuditHash(line string) (string, string, bool) {
+	if m := jsonAuditRegexp.FindStringSubmatch(line); m != nil {
+		return m[1] + "}", m[2], true
+	}
+
+	if m := textAuditRegexp.FindStringSubmatch(line); m != nil {
+		return m[1], m[2], true
+	}
+
+	return "", "", false
+}
+
+// auditHash is the hash of logger.WithAuditChain: the hex HMAC-SHA256 with
+// key, or SHA-256 without, of the previous hash followed by line
+func auditHash(key, prev, line []byte) []byte {
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+
+	h.Write(prev)
+	h.Write(line)
+
+	return []byte(hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuditSuite struct {
+	suite.Suite
+
+	key []byte
+}
+
+func (s *AuditSuite) SetupTest() {
+	s.key = []byte("s3cr3t")
+}
+
+func (s *AuditSuite) log(t logger.Type, key []byte, urls ...string) string {
+	var buf bytes.Buffer
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(&buf), logger.WithFormat(t),
+		logger.WithAuditChain(key))
+
+	for _, url := range urls {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+
+	return buf.String()
+}
+
+func (s *AuditSuite) TestVerify() {
+	for _, t := range []logger.Type{logger.CombineLoggerType, logger.GELFLoggerType, logger.SlogLoggerType} {
+		out := s.log(t, s.key, "/a", "/b", "/c")
+
+		s.Nil(VerifyChain(strings.NewReader(out), s.key), "type %d", t)
+		s.ErrorIs(VerifyChain(strings.NewReader(out), []byte("other")), ErrChain, "type %d", t)
+	}
+
+	s.Nil(VerifyChain(strings.NewReader(s.log(logger.TinyLoggerType, nil, "/a", "/b")), nil))
+}
+
+func (s *AuditSuite) TestTampered() {
+	lines := strings.SplitAfter(s.log(logger.CombineLoggerType, s.key, "/a", "/b", "/c"), "\n")
+
+	changed := strings.Join(lines, "")
+	changed = strings.Replace(changed, "GET /b", "GET /x", 1)
+	s.EqualError(VerifyChain(strings.NewReader(changed), s.key), "parse: audit chain broken at line 2")
+
+	removed := lines[0] + lines[2]
+	s.True(errors.Is(VerifyChain(strings.NewReader(removed), s.key), ErrChain))
+
+	reordered := lines[1] + lines[0] + lines[2]
+	s.True(errors.Is(VerifyChain(strings.NewReader(reordered), s.key), ErrChain))
+
+	s.EqualError(VerifyChain(strings.NewReader("GET / 404\n"), s.key), "parse: audit chain broken: line 1 has no audit hash")
+}
+
+func (s *AuditSuite) TestRestarts() {
+	first := s.log(logger.CombineLoggerType, s.key, "/a", "/b")
+	second := s.log(logger.CombineLoggerType, s.key, "/c", "/d")
+
+	s.Nil(VerifyChain(strings.NewReader(first+second), s.key))
+
+	changed := strings.Replace(first+second, "GET /d", "GET /x", 1)
+	s.EqualError(VerifyChain(strings.NewReader(changed), s.key), "parse: audit chain broken at line 4")
+
+	removed := strings.SplitAfter(first+second, "\n")
+	s.ErrorIs(VerifyChain(strings.NewReader(removed[0]+removed[1]+removed[3]), s.key), ErrChain)
+}
+
+func (s *AuditSuite) TestLine() {
+	out := s.log(logger.CombineLoggerType, s.key, "/a")
+
+	e, err := Line(logger.CombineLoggerType, out)
+	s.Nil(err)
+	s.Equal("/a", e.URL)
+	s.Equal(http.StatusNotFound, e.Status)
+}
+
+func TestAudit(t *testing.T) {
+	suite.Run(t, new(AuditSuite))
+}
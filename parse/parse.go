@@ -0,0 +1,291 @@
+// Package parse reads the access logs written by the logger package back
+// into entries, for log replay, test assertions or offline analysis.
+package parse
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-http-utils/logger"
+)
+
+// Entry is a parsed log line, fields missing from its format are left
+// zero
+type Entry struct {
+	RemoteAddr string
+	RemoteUser string
+	Time       time.Time
+
+	Host      string
+	Method    string
+	URL       string
+	Proto     string
+	Referer   string
+	UserAgent string
+	RequestID string
+
+	Status   int
+	Hijacked bool
+	Size     int
+	Duration time.Duration
+}
+
+// ErrFormat is returned for lines not matching their format
+var ErrFormat = errors.New("parse: line does not match the log format")
+
+// timeFormat is the layout of :date[clf] and the start_time field
+const timeFormat = "02/Jan/2006:15:04:05 -0700"
+
+var (
+	commonRegexp   = regexp.MustCompile(`^(\S+) - (\S+) \[([^\]]+)\] "(\S+) (\S+) (\S+)" (\S+) (\d+)`)
+	combinedRegexp = regexp.MustCompile(commonRegexp.String() + ` "(.*?)" "(.*)"$`)
+	shortRegexp    = regexp.MustCompile(`^(\S+) (\S+) (\S+) (\S+) (\S+) (\S+) (\d+) - ([\d.]+) (\S+)$`)
+	tinyRegexp     = regexp.MustCompile(`^(\S+) (\S+) (\S+) (\d+) - ([\d.]+) (\S+)$`)
+	devRegexp      = regexp.MustCompile(`^(\S+) (\S+) (\S+) ([\d.]+) (\S+) - (\d+)$`)
+	colorRegexp    = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// Line parses a line written with the t log output. CombineLoggerType,
+// CommonLoggerType, DevLoggerType, ShortLoggerType, TinyLoggerType and
+// JsonLoggerType are supported. The audit hash of logger.WithAuditChain is
+// ignored.
+func Line(t logger.Type, line string) (*Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if m := textAuditRegexp.FindStringSubmatch(line); m != nil {
+		line = m[1]
+	}
+
+	switch t {
+	case logger.CombineLoggerType:
+		return parseCommon(combinedRegexp, line)
+	case logger.CommonLoggerType:
+		return parseCommon(commonRegexp, line)
+	case logger.DevLoggerType:
+		m := devRegexp.FindStringSubmatch(colorRegexp.ReplaceAllString(line, ""))
+		if m == nil {
+			return nil, ErrFormat
+		}
+
+		e := &Entry{Method: m[1], URL: m[2]}
+
+		return e, e.parse(m[3], m[6], m[4], m[5])
+	case logger.ShortLoggerType:
+		m := shortRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil, ErrFormat
+		}
+
+		e := &Entry{RemoteAddr: m[1], RemoteUser: dash(m[2]), Method: m[3], URL: m[4], Proto: m[5]}
+
+		return e, e.parse(m[6], m[7], m[8], m[9])
+	case logger.TinyLoggerType:
+		m := tinyRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return nil, ErrFormat
+		}
+
+		e := &Entry{Method: m[1], URL: m[2]}
+
+		return e, e.parse(m[3], m[4], m[5], m[6])
+	case logger.JsonLoggerType:
+		return parseJSON(line)
+	}
+
+	return nil, fmt.Errorf("parse: unsupported log output type %d", t)
+}
+
+// Scanner reads the entries of a log one line at a time
+type Scanner struct {
+	s     *bufio.Scanner
+	t     logger.Type
+	entry *Entry
+	err   error
+}
+
+// NewScanner returns a Scanner reading t log output lines from r
+func NewScanner(r io.Reader, t logger.Type) *Scanner {
+	return &Scanner{s: bufio.NewScanner(r), t: t}
+}
+
+// Scan parses the next non-empty line, it returns false at the end of the
+// log or on the first error
+func (s *Scanner) Scan() bool {
+	for s.err == nil && s.s.Scan() {
+		if strings.TrimSpace(s.s.Text()) == "" {
+			continue
+		}
+
+		s.entry, s.err = Line(s.t, s.s.Text())
+
+		return s.err == nil
+	}
+
+	if s.err == nil {
+		s.err = s.s.Err()
+	}
+
+	return false
+}
+
+// Entry returns the entry parsed by the last call to Scan
+func (s *Scanner) Entry() *Entry {
+	return s.entry
+}
+
+// Err returns the first error met by Scan
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func parseCommon(re *regexp.Regexp, line string) (*Entry, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil, ErrFormat
+	}
+
+	date, err := time.Parse(timeFormat, m[3])
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{RemoteAddr: m[1], RemoteUser: dash(m[2]), Time: date, Method: m[4], URL: m[5], Proto: m[6]}
+	if len(m) > 9 {
+		e.Referer, e.UserAgent = m[9], m[10]
+	}
+
+	return e, e.parse(m[7], m[8], "", "")
+}
+
+// parse fills the status, size and duration of e from their text
+func (e *Entry) parse(status, size, duration, unit string) (err error) {
+	if status == "hijacked" {
+		e.Hijacked = true
+	} else if e.Status, err = strconv.Atoi(status); err != nil {
+		return err
+	}
+
+	if e.Size, err = strconv.Atoi(size); err != nil {
+		return err
+	}
+
+	if duration != "" {
+		e.Duration, err = parseDuration(duration, unit)
+	}
+
+	return err
+}
+
+func parseDuration(value, unit string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"µs": time.Microsecond,
+		"us": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+	}
+
+	d, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("parse: unknown duration unit %q", unit)
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(f * float64(d)), nil
+}
+
+func parseJSON(line string) (*Entry, error) {
+	fields := struct {
+		Host       string          `json:"request.host"`
+		Method     string          `json:"request.method"`
+		Proto      string          `json:"request.proto"`
+		URL        json.RawMessage `json:"request.url"`
+		Referer    string          `json:"request.referer"`
+		UserAgent  string          `json:"request.user_agent"`
+		RequestID  string          `json:"request.id"`
+		User       string          `json:"request.user"`
+		StartTime  string          `json:"start_time"`
+		Status     string          `json:"response.status"`
+		Size       string          `json:"response.size"`
+		Hijacked   bool            `json:"response.hijacked"`
+		TotalMS    float64         `json:"response.total_ms"`
+		RemoteAddr string          `json:"client_address"`
+	}{}
+
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return nil, err
+	}
+
+	e := &Entry{
+		RemoteAddr: fields.RemoteAddr,
+		Host:       fields.Host,
+		Method:     fields.Method,
+		Proto:      fields.Proto,
+		Referer:    fields.Referer,
+		UserAgent:  fields.UserAgent,
+		RequestID:  fields.RequestID,
+		RemoteUser: fields.User,
+		Hijacked:   fields.Hijacked,
+		Duration:   time.Duration(fields.TotalMS * float64(time.Millisecond)),
+	}
+
+	var err error
+
+	if e.URL, err = jsonURL(fields.URL); err != nil {
+		return nil, err
+	}
+
+	if e.Time, err = time.Parse(timeFormat, fields.StartTime); err != nil {
+		return nil, err
+	}
+
+	if e.Status, err = strconv.Atoi(fields.Status); err != nil {
+		return nil, err
+	}
+
+	if e.Size, err = strconv.Atoi(fields.Size); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// jsonURL reads the request.url field, logged either as a string or as
+// the fields of a url.URL
+func jsonURL(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+
+	var u url.URL
+	if err := json.Unmarshal(raw, &u); err != nil {
+		return "", err
+	}
+
+	u.User = nil
+
+	return u.String(), nil
+}
+
+func dash(s string) string {
+	if s == "-" {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,137 @@
+package parse
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/suite"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (tc testClock) Now() time.Time {
+	return tc.now
+}
+
+type ParseSuite struct {
+	suite.Suite
+
+	now time.Time
+	req *http.Request
+}
+
+func (s *ParseSuite) SetupTest() {
+	s.now = time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	s.req = httptest.NewRequest(http.MethodGet, "/users?id=1", nil)
+	s.req.RemoteAddr = "192.0.2.1:1234"
+	s.req.Header.Set("Referer", "https://example.com/")
+	s.req.Header.Set("User-Agent", "curl/8.0")
+}
+
+func (s *ParseSuite) log(opts ...logger.Option) string {
+	var buf bytes.Buffer
+
+	l := log.New()
+	l.Formatter = &log.JSONFormatter{}
+	l.Out = &buf
+
+	opts = append([]logger.Option{logger.WithWriter(&buf), logger.WithLogrusLogger(l),
+		logger.WithClock(testClock{s.now}), logger.WithColor(logger.ColorAlways)}, opts...)
+	logger.New(http.NotFoundHandler(), opts...).ServeHTTP(httptest.NewRecorder(), s.req)
+
+	return buf.String()
+}
+
+func (s *ParseSuite) TestCombined() {
+	e, err := Line(logger.CombineLoggerType, s.log(logger.WithFormat(logger.CombineLoggerType)))
+
+	s.Nil(err)
+	s.True(s.now.Equal(e.Time))
+
+	e.Time = time.Time{}
+	s.Equal(&Entry{
+		RemoteAddr: "192.0.2.1:1234",
+		Method:     http.MethodGet,
+		URL:        "/users?id=1",
+		Proto:      "HTTP/1.1",
+		Referer:    "https://example.com/",
+		UserAgent:  "curl/8.0",
+		Status:     http.StatusNotFound,
+		Size:       19,
+	}, e)
+}
+
+func (s *ParseSuite) TestCommon() {
+	e, err := Line(logger.CommonLoggerType, `192.0.2.1 - alice [02/Jan/2017:15:04:05 +0000] "POST /login HTTP/2.0" 302 0`)
+
+	s.Nil(err)
+	s.Equal("alice", e.RemoteUser)
+	s.Equal(http.MethodPost, e.Method)
+	s.Equal("HTTP/2.0", e.Proto)
+	s.Equal(http.StatusFound, e.Status)
+}
+
+func (s *ParseSuite) TestTimed() {
+	for _, t := range []logger.Type{logger.DevLoggerType, logger.ShortLoggerType, logger.TinyLoggerType} {
+		e, err := Line(t, s.log(logger.WithFormat(t)))
+
+		s.Nil(err)
+		s.Equal(http.MethodGet, e.Method)
+		s.Equal("/users?id=1", e.URL)
+		s.Equal(http.StatusNotFound, e.Status)
+		s.Equal(19, e.Size)
+	}
+
+	e, err := Line(logger.TinyLoggerType, "GET /ws hijacked 0 - 1.500 s")
+	s.Nil(err)
+	s.True(e.Hijacked)
+	s.Equal(1500*time.Millisecond, e.Duration)
+}
+
+func (s *ParseSuite) TestJSON() {
+	e, err := Line(logger.JsonLoggerType, s.log(logger.WithFormat(logger.JsonLoggerType), logger.WithRequestID()))
+
+	s.Nil(err)
+	s.Equal("192.0.2.1:1234", e.RemoteAddr)
+	s.Equal("example.com", e.Host)
+	s.Equal("/users?id=1", e.URL)
+	s.True(s.now.Equal(e.Time))
+	s.Equal(http.StatusNotFound, e.Status)
+	s.Equal(19, e.Size)
+	s.Len(e.RequestID, 36)
+}
+
+func (s *ParseSuite) TestErrors() {
+	_, err := Line(logger.TinyLoggerType, "not a log line")
+	s.Equal(ErrFormat, err)
+
+	_, err = Line(logger.TinyLoggerType, "GET / 200 0 - 1.0 h")
+	s.NotNil(err)
+
+	_, err = Line(logger.W3CLoggerType, "")
+	s.NotNil(err)
+}
+
+func (s *ParseSuite) TestScanner() {
+	sc := NewScanner(strings.NewReader("GET / 200 2 - 0.100 ms\n\nPOST /a 201 0 - 2.000 ms\noops\n"), logger.TinyLoggerType)
+
+	s.True(sc.Scan())
+	s.Equal(http.StatusOK, sc.Entry().Status)
+	s.True(sc.Scan())
+	s.Equal(2*time.Millisecond, sc.Entry().Duration)
+	s.False(sc.Scan())
+	s.Equal(ErrFormat, sc.Err())
+}
+
+func TestParse(t *testing.T) {
+	suite.Run(t, new(ParseSuite))
+}
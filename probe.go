@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// probePaths are the usual paths of the Kubernetes liveness, readiness and
+// startup probes
+var probePaths = map[string]bool{
+	"/healthz": true,
+	"/livez":   true,
+	"/readyz":  true,
+	"/health":  true,
+	"/live":    true,
+	"/ready":   true,
+}
+
+// isProbe reports whether req is a Kubernetes probe, sent by the kubelet
+// with a "kube-probe/<version>" User-Agent or to one of probePaths
+func isProbe(req *http.Request) bool {
+	return strings.HasPrefix(req.UserAgent(), "kube-probe/") || probePaths[req.URL.Path]
+}
+
+// quietProbes is the condition of QuietSuccessfulProbes
+func quietProbes(req *http.Request, stats Stats) bool {
+	return stats.Status >= 400 || !isProbe(req)
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProbeSuite struct {
+	suite.Suite
+}
+
+func (s *ProbeSuite) TestIsProbe() {
+	kubelet := httptest.NewRequest(http.MethodGet, "/status", nil)
+	kubelet.Header.Set("User-Agent", "kube-probe/1.29")
+
+	s.True(isProbe(kubelet))
+	s.True(isProbe(httptest.NewRequest(http.MethodGet, "/readyz", nil)))
+	s.False(isProbe(httptest.NewRequest(http.MethodGet, "/readyz/extra", nil)))
+	s.False(isProbe(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func (s *ProbeSuite) TestHandler() {
+	tw := testWriter{}
+	status := http.StatusOK
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(status)
+	}), WithWriter(&tw), WithClock(testClock{}), WithFormat(TinyLoggerType), QuietSuccessfulProbes())
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	s.Empty(tw.Bytes)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Equal("GET / 200 0 - 0.000 ms\n", string(tw.Bytes))
+
+	tw.Bytes = nil
+	status = http.StatusServiceUnavailable
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	s.Equal("GET /healthz 503 0 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func TestProbe(t *testing.T) {
+	suite.Run(t, new(ProbeSuite))
+}
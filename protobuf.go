@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// protobuf wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+)
+
+// protobufFormatter writes entries as delimited logger.v1.Entry messages,
+// see ProtobufEncoder and entry.proto
+type protobufFormatter struct{}
+
+func (protobufFormatter) Format(w io.Writer, e *Entry) error {
+	_, err := w.Write(appendProtoDelimited(nil, appendProtobufEntry(nil, e)))
+
+	return err
+}
+
+// appendProtobufEntry appends e as a logger.v1.Entry message, with the
+// fields of proto3 default values left out
+func appendProtobufEntry(b []byte, e *Entry) []byte {
+	b = appendProtoInt(b, 1, NDJSONSchemaVersion)
+	b = appendProtoInt(b, 2, e.Start.UnixNano())
+	b = appendProtoInt(b, 3, int64(e.Level))
+
+	var req []byte
+	req = appendProtoString(req, 1, e.Method)
+	req = appendProtoString(req, 2, e.Host)
+	req = appendProtoString(req, 3, e.URL)
+	req = appendProtoString(req, 4, e.Proto)
+	req = appendProtoString(req, 5, e.Route)
+	req = appendProtoString(req, 6, e.RequestID)
+	req = appendProtoString(req, 7, e.CorrelationID)
+	req = appendProtoString(req, 8, e.AmznTraceID)
+	req = appendProtoString(req, 9, e.RemoteUser)
+	req = appendProtoString(req, 10, e.Referer)
+	req = appendProtoString(req, 11, e.UserAgent)
+	req = appendProtoInt(req, 12, e.RequestSize)
+	req = appendProtoHeader(req, 13, e.Header)
+
+	if e.Body != nil {
+		req = appendProtoMessage(req, 14, e.Body)
+	}
+
+	if e.Aborted != nil {
+		req = appendProtoInt(req, 15, 1)
+		req = appendProtoString(req, 16, e.Aborted.Error())
+	}
+
+	req = appendProtoString(req, 17, e.Range)
+
+	b = appendProtoMessage(b, 5, req)
+
+	var res []byte
+	res = appendProtoInt(res, 1, int64(e.Status))
+	res = appendProtoInt(res, 2, int64(e.Size))
+
+	if e.ContentLength >= 0 {
+		// optional, so written even when 0
+		res = binary.AppendUvarint(appendProtoKey(res, 3, protoVarint), uint64(e.ContentLength))
+	}
+
+	res = appendProtoDouble(res, 4, milliseconds(e.TTFB))
+	res = appendProtoDouble(res, 5, milliseconds(e.Duration))
+
+	if e.Hijacked {
+		res = appendProtoInt(res, 6, 1)
+	}
+
+	res = appendProtoHeader(res, 7, e.ResponseHeader)
+	res = appendProtoHeader(res, 8, e.Trailer)
+
+	if e.ResponseBody != nil {
+		res = appendProtoMessage(res, 9, e.ResponseBody)
+	}
+
+	if e.Streamed {
+		res = appendProtoInt(res, 10, 1)
+		res = appendProtoInt(res, 11, int64(e.Flushes))
+	}
+
+	if e.Preliminary {
+		res = appendProtoInt(res, 12, 1)
+	}
+
+	if e.Empty {
+		res = appendProtoInt(res, 13, 1)
+	}
+
+	res = appendProtoString(res, 14, e.ContentRange)
+	res = appendProtoString(res, 15, e.CacheState)
+
+	if e.ContentEncoding != "" {
+		res = appendProtoString(res, 16, e.ContentEncoding)
+
+		if e.BodySize >= 0 {
+			// optional, so written even when 0
+			res = binary.AppendUvarint(appendProtoKey(res, 17, protoVarint), uint64(e.BodySize))
+		}
+	}
+
+	b = appendProtoMessage(b, 6, res)
+	b = appendProtoString(b, 7, e.RemoteAddr)
+	b = appendProtoString(b, 13, e.ConnID)
+	b = appendProtoString(b, 8, e.TraceID)
+	b = appendProtoString(b, 9, e.SpanID)
+
+	if e.TLS != nil {
+		var tls []byte
+		tls = appendProtoString(tls, 1, e.TLS.Version)
+		tls = appendProtoString(tls, 2, e.TLS.CipherSuite)
+		tls = appendProtoString(tls, 3, e.TLS.ServerName)
+		tls = appendProtoString(tls, 4, e.TLS.ClientSubject)
+
+		b = appendProtoMessage(b, 10, tls)
+	}
+
+	if e.Panicked {
+		var panicked []byte
+		panicked = appendProtoString(panicked, 1, e.PanicValue)
+		panicked = appendProtoString(panicked, 2, string(e.Stack))
+
+		b = appendProtoMessage(b, 11, panicked)
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		var session []byte
+		if ws.Closed {
+			session = appendProtoInt(session, 1, 1)
+		}
+		session = appendProtoDouble(session, 2, milliseconds(ws.Duration))
+		session = appendProtoInt(session, 3, ws.FramesIn)
+		session = appendProtoInt(session, 4, ws.FramesOut)
+		session = appendProtoInt(session, 5, ws.BytesIn)
+		session = appendProtoInt(session, 6, ws.BytesOut)
+		session = appendProtoInt(session, 7, int64(ws.CloseCode))
+
+		b = appendProtoMessage(b, 14, session)
+	}
+
+	return appendProtoFields(b, 12, e.Fields)
+}
+
+// appendProtobufNotice appends a notice about the log output itself as a
+// logger.v1.Entry message with a msg and no request, delimited
+func appendProtobufNotice(b []byte, t time.Time, level Level, msg string, fields map[string]interface{}) []byte {
+	var m []byte
+	m = appendProtoInt(m, 1, NDJSONSchemaVersion)
+	m = appendProtoInt(m, 2, t.UnixNano())
+	m = appendProtoInt(m, 3, int64(level))
+	m = appendProtoString(m, 4, msg)
+	m = appendProtoFields(m, 12, fields)
+
+	return appendProtoDelimited(b, m)
+}
+
+// appendProtoDelimited appends msg prefixed with its length
+func appendProtoDelimited(b, msg []byte) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(msg))), msg...)
+}
+
+func appendProtoKey(b []byte, field, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+func appendProtoInt(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	return binary.AppendUvarint(appendProtoKey(b, field, protoVarint), uint64(v))
+}
+
+func appendProtoDouble(b []byte, field int, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	return binary.LittleEndian.AppendUint64(appendProtoKey(b, field, protoFixed64), math.Float64bits(v))
+}
+
+func appendProtoString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = binary.AppendUvarint(appendProtoKey(b, field, protoBytes), uint64(len(s)))
+
+	return append(b, s...)
+}
+
+// appendProtoMessage appends the message, or bytes, msg even when empty
+func appendProtoMessage(b []byte, field int, msg []byte) []byte {
+	return appendProtoDelimited(appendProtoKey(b, field, protoBytes), msg)
+}
+
+// appendProtoHeader appends h as a map<string, HeaderValues>, sorted by
+// name so the output is stable
+func appendProtoHeader(b []byte, field int, h http.Header) []byte {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var values []byte
+		for _, v := range h[name] {
+			values = appendProtoDelimited(appendProtoKey(values, 1, protoBytes), []byte(v))
+		}
+
+		entry := appendProtoString(nil, 1, name)
+		entry = appendProtoMessage(entry, 2, values)
+
+		b = appendProtoMessage(b, field, entry)
+	}
+
+	return b
+}
+
+// appendProtoFields appends the custom fields as a map<string, string>,
+// sorted by key
+func appendProtoFields(b []byte, field int, fields map[string]interface{}) []byte {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, ok := fields[k].(string)
+		if !ok {
+			v = fmt.Sprint(fields[k])
+		}
+
+		entry := appendProtoString(nil, 1, k)
+		entry = appendProtoString(entry, 2, v)
+
+		b = appendProtoMessage(b, field, entry)
+	}
+
+	return b
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxies resolves the client address of requests coming through
+// known proxies
+type trustedProxies []netip.Prefix
+
+// parseTrustedProxies parses CIDRs or single IP addresses, it panics on an
+// invalid one as this is a configuration error
+func parseTrustedProxies(cidrs ...string) trustedProxies {
+	proxies := make(trustedProxies, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				panic("logger: invalid trusted proxy " + cidr + ": " + err.Error())
+			}
+
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			panic("logger: invalid trusted proxy " + cidr + ": " + err.Error())
+		}
+
+		proxies = append(proxies, prefix.Masked())
+	}
+
+	return proxies
+}
+
+func (tp trustedProxies) trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	for _, prefix := range tp {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientAddr returns the address of the client, read from the Forwarded,
+// X-Forwarded-For or, without them, X-Real-IP headers if the direct peer is
+// a trusted proxy, req.RemoteAddr otherwise
+func (tp trustedProxies) clientAddr(req *http.Request) string {
+	peer, ok := parseAddr(req.RemoteAddr)
+	if !ok || !tp.trusted(peer) {
+		return req.RemoteAddr
+	}
+
+	chain := forwardedFor(req.Header.Values("Forwarded"))
+	if len(chain) == 0 {
+		chain = xForwardedFor(req.Header.Values("X-Forwarded-For"))
+	}
+
+	if len(chain) == 0 {
+		if addr, ok := parseAddr(req.Header.Get("X-Real-IP")); ok {
+			return addr.String()
+		}
+
+		return req.RemoteAddr
+	}
+
+	// the rightmost address not belonging to a trusted proxy is the one
+	// that connected to our infrastructure. A hop which can't be parsed,
+	// e.g. for=unknown, ends the chain at the last trusted proxy, as the
+	// hops before it and the other headers can be set by the client.
+	last := req.RemoteAddr
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(chain[i])
+		if !ok {
+			return last
+		}
+
+		if !tp.trusted(addr) || i == 0 {
+			return addr.String()
+		}
+
+		last = addr.String()
+	}
+
+	return last
+}
+
+// parseAddr parses an IP address with an optional port, IPv6 addresses may
+// be enclosed in brackets
+func parseAddr(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	addr, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+func xForwardedFor(values []string) []string {
+	chain := []string{}
+
+	for _, value := range values {
+		for _, addr := range strings.Split(value, ",") {
+			chain = append(chain, strings.TrimSpace(addr))
+		}
+	}
+
+	return chain
+}
+
+// forwardedFor returns the for= parameters of RFC 7239 Forwarded headers
+func forwardedFor(values []string) []string {
+	chain := []string{}
+
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+
+				if ok && strings.EqualFold(key, "for") {
+					chain = append(chain, strings.Trim(val, `"`))
+				}
+			}
+		}
+	}
+
+	return chain
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ProxySuite struct {
+	suite.Suite
+
+	tp trustedProxies
+}
+
+func (s *ProxySuite) SetupTest() {
+	s.tp = parseTrustedProxies("10.0.0.0/8", "192.0.2.1", "2001:db8::/32")
+}
+
+func (s *ProxySuite) request(remoteAddr string, header ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+
+	for i := 0; i < len(header); i += 2 {
+		req.Header.Add(header[i], header[i+1])
+	}
+
+	return req
+}
+
+func (s *ProxySuite) TestUntrustedPeer() {
+	req := s.request("203.0.113.9:1234", "X-Forwarded-For", "198.51.100.7")
+
+	s.Equal("203.0.113.9:1234", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestXForwardedFor() {
+	req := s.request("10.0.0.1:1234", "X-Forwarded-For", "6.6.6.6, 198.51.100.7, 10.1.1.1")
+
+	s.Equal("198.51.100.7", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestAllTrusted() {
+	req := s.request("10.0.0.1:1234", "X-Forwarded-For", "10.2.2.2", "X-Forwarded-For", "10.1.1.1")
+
+	s.Equal("10.2.2.2", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestForwarded() {
+	req := s.request("[2001:db8::1]:443",
+		"Forwarded", `for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"`,
+		"X-Forwarded-For", "6.6.6.6")
+
+	s.Equal("192.0.2.60", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestXRealIP() {
+	req := s.request("192.0.2.1:1234", "X-Real-IP", "198.51.100.7")
+
+	s.Equal("198.51.100.7", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestUnparseableHop() {
+	req := s.request("10.0.0.1:1234", "Forwarded", "for=198.51.100.7, for=unknown, for=10.1.1.1",
+		"X-Real-IP", "6.6.6.6")
+	s.Equal("10.1.1.1", s.tp.clientAddr(req))
+
+	req = s.request("10.0.0.1:1234", "X-Forwarded-For", "198.51.100.7, garbage", "X-Real-IP", "6.6.6.6")
+	s.Equal("10.0.0.1:1234", s.tp.clientAddr(req))
+}
+
+func (s *ProxySuite) TestNoHeader() {
+	s.Equal("10.0.0.1:1234", s.tp.clientAddr(s.request("10.0.0.1:1234")))
+}
+
+func (s *ProxySuite) TestInvalid() {
+	s.Panics(func() { parseTrustedProxies("10.0.0.0/33") })
+	s.Panics(func() { parseTrustedProxies("not-an-ip") })
+}
+
+func (s *ProxySuite) TestHandler() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":remote-addr"),
+		WithTrustedProxies("10.0.0.0/8"))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.request("10.0.0.1:1234", "X-Forwarded-For", "198.51.100.7"))
+
+	s.Equal("198.51.100.7\n", string(tw.Bytes))
+}
+
+func TestProxy(t *testing.T) {
+	suite.Run(t, new(ProxySuite))
+}
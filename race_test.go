@@ -0,0 +1,7 @@
+//go:build race
+
+package logger
+
+// raceEnabled tells whether the race detector, which makes sync.Pool drop
+// items, is on
+const raceEnabled = true
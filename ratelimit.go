@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// rateLimiter caps the number of entries logged per window, see
+// WithRateLimit
+type rateLimiter struct {
+	mu sync.Mutex
+
+	n   int
+	per time.Duration
+
+	window     time.Time
+	count      int
+	suppressed int
+}
+
+func newRateLimiter(n int, per time.Duration) *rateLimiter {
+	return &rateLimiter{n: n, per: per}
+}
+
+// allow reports whether an entry can be logged at now, along with the
+// number of entries suppressed during the previous window when now starts
+// a new one
+func (l *rateLimiter) allow(now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	suppressed := 0
+	if now.Sub(l.window) >= l.per {
+		suppressed = l.suppressed
+		l.window, l.count, l.suppressed = now, 0, 0
+	}
+
+	if l.count >= l.n {
+		l.suppressed++
+
+		return false, suppressed
+	}
+
+	l.count++
+
+	return true, suppressed
+}
+
+// reset returns the number of entries suppressed since the last summary
+// and starts counting again
+func (l *rateLimiter) reset() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	suppressed := l.suppressed
+	l.suppressed = 0
+
+	return suppressed
+}
+
+// limit reports whether the entry of a request served at now is logged
+// under WithRateLimit, writing the summary of the previous window first
+func (rh loggerHanlder) limit(now time.Time) bool {
+	if rh.limiter == nil {
+		return true
+	}
+
+	ok, suppressed := rh.limiter.allow(now)
+	if suppressed > 0 {
+		rh.summary(suppressed)
+	}
+
+	return ok
+}
+
+// summary writes a line telling that n entries were suppressed by
+// WithRateLimit, as a warning for the structured log outputs
+func (rh loggerHanlder) summary(n int) {
+	rh.notice(LevelWarn, fmt.Sprintf("suppressed %d entries", n), map[string]interface{}{"suppressed": n})
+}
+
+// notice writes a line about the log output itself, msg for the text
+// formats and msg with fields at level for the structured ones. It's left
+// out of the outputs a line can't be added to without breaking their
+// parsers, CSV, CEF, LEEF and the formatters of WithFormatter.
+func (rh loggerHanlder) notice(level Level, msg string, fields map[string]interface{}) {
+	if len(rh.outputs) > 0 {
+		for _, o := range rh.outputs {
+			o.notice(level, msg, fields)
+		}
+
+		return
+	}
+
+	if !rh.typedFormatter {
+		return
+	}
+
+	if rh.encoder != 0 {
+		rh.encoder.writeNotice(rh.writer, rh.clock.Now(), level, msg, fields)
+
+		return
+	}
+
+	switch rh.formatType {
+	case JsonLoggerType:
+		rh.backend.Log(level, msg, fields)
+	case SlogLoggerType:
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]any, 0, 2*len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, k, fields[k])
+		}
+
+		rh.slog.Log(context.Background(), level.slog(), msg, attrs...)
+	case NDJSONLoggerType:
+		writeNDJSONNotice(rh.writer, rh.clock.Now(), level, msg, fields)
+	case GELFLoggerType:
+		gf, ok := rh.formatter.(gelfFormatter)
+		if !ok {
+			gf = newGELFFormatter()
+		}
+
+		gf.writeNotice(rh.writer, rh.clock.Now(), level, msg, fields)
+	case GCPLoggerType:
+		writeGCPNotice(rh.writer, rh.clock.Now(), level, msg, fields)
+	case W3CLoggerType:
+		w3cFormatter{rh.directives, rh.clock}.remark(rh.writer, msg)
+	case CombineLoggerType, CommonLoggerType, DevLoggerType, ShortLoggerType, TinyLoggerType, CustomLoggerType:
+		io.WriteString(rh.writer, msg+"\n")
+	}
+}
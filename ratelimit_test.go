@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitSuite struct {
+	suite.Suite
+
+	tw    testWriter
+	clock *stepClock
+}
+
+func (s *RateLimitSuite) SetupTest() {
+	s.tw = testWriter{}
+	s.clock = &stepClock{now: time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}
+}
+
+func (s *RateLimitSuite) serve(h http.Handler, n int) {
+	for i := 0; i < n; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+}
+
+func (s *RateLimitSuite) TestLimit() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":method :url"),
+		WithRateLimit(2, time.Second))
+
+	s.serve(h, 5)
+	s.Equal("GET /\nGET /\n", string(s.tw.Bytes))
+
+	s.clock.now = s.clock.now.Add(time.Second)
+	s.serve(h, 1)
+	s.Equal("GET /\nGET /\nsuppressed 3 entries\nGET /\n", string(s.tw.Bytes))
+
+	s.clock.now = s.clock.now.Add(time.Second)
+	s.serve(h, 1)
+	s.Equal(4, strings.Count(string(s.tw.Bytes), "GET /"))
+	s.Equal(1, strings.Count(string(s.tw.Bytes), "suppressed"))
+}
+
+func (s *RateLimitSuite) TestClose() {
+	h := NewLogger(noopHandler{}, WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":method :url"),
+		WithRateLimit(1, time.Minute))
+
+	s.serve(h, 3)
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal("GET /\nsuppressed 2 entries\n", string(s.tw.Bytes))
+}
+
+func (s *RateLimitSuite) TestJSONSummary() {
+	h := New(noopHandler{}, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&s.tw)),
+		WithClock(s.clock), WithRateLimit(1, time.Second))
+
+	s.serve(h, 2)
+	s.tw.Bytes = nil
+
+	s.clock.now = s.clock.now.Add(time.Second)
+	s.serve(h, 1)
+
+	summary := map[string]interface{}{}
+	s.Nil(json.NewDecoder(strings.NewReader(string(s.tw.Bytes))).Decode(&summary))
+	s.Equal("suppressed 1 entries", summary["msg"])
+	s.Equal("warning", summary["level"])
+	s.Equal(1.0, summary["suppressed"])
+}
+
+func (s *RateLimitSuite) TestStructuredSummary() {
+	for _, t := range []Type{GELFLoggerType, GCPLoggerType} {
+		s.tw = testWriter{}
+		h := New(noopHandler{}, WithWriter(&s.tw), WithFormat(t), WithClock(s.clock), WithRateLimit(1, time.Second))
+
+		s.serve(h, 2)
+		s.tw.Bytes = nil
+
+		s.clock.now = s.clock.now.Add(time.Second)
+		s.serve(h, 1)
+
+		lines := strings.Split(strings.TrimSpace(string(s.tw.Bytes)), "\n")
+		s.Require().Len(lines, 2)
+
+		summary := map[string]interface{}{}
+		s.Nil(json.Unmarshal([]byte(lines[0]), &summary))
+		if t == GELFLoggerType {
+			s.Equal("suppressed 1 entries", summary["short_message"])
+			s.Equal(1.0, summary["_suppressed"])
+		} else {
+			s.Equal("suppressed 1 entries", summary["message"])
+			s.Equal("WARNING", summary["severity"])
+			s.Equal(1.0, summary["suppressed"])
+		}
+	}
+}
+
+func (s *RateLimitSuite) TestW3CSummary() {
+	h := NewLogger(noopHandler{}, WithWriter(&s.tw), WithFormat(W3CLoggerType), WithClock(s.clock),
+		WithRateLimit(0, time.Second))
+
+	s.serve(h, 2)
+	s.Nil(h.Close(context.Background()))
+
+	s.Equal("#Version: 1.0\n#Date: 2017-01-02 15:04:05\n#Fields: "+w3cFields+"\n#Remark: suppressed 2 entries\n",
+		string(s.tw.Bytes))
+}
+
+func (s *RateLimitSuite) TestSummarySkipped() {
+	for _, opt := range []Option{
+		WithFormat(CSVLoggerType),
+		WithFormat(CEFLoggerType),
+		WithFormatter(FormatterFunc(func(w io.Writer, e *Entry) error {
+			_, err := io.WriteString(w, e.Method+"\n")
+
+			return err
+		})),
+	} {
+		s.tw = testWriter{}
+		h := NewLogger(noopHandler{}, WithWriter(&s.tw), opt, WithClock(s.clock), WithRateLimit(1, time.Second))
+
+		s.serve(h, 3)
+		s.Nil(h.Close(context.Background()))
+
+		s.NotContains(string(s.tw.Bytes), "suppressed")
+	}
+}
+
+func (s *RateLimitSuite) TestPanicsAlwaysLogged() {
+	h := New(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}), WithWriter(&s.tw), WithClock(s.clock), WithCustomFormat(":status"), WithRecovery(true),
+		WithRateLimit(1, time.Second))
+
+	s.serve(h, 3)
+
+	// the entries are the lines of the status alone, followed by the stacks
+	entries := 0
+	for _, line := range strings.Split(string(s.tw.Bytes), "\n") {
+		if line == "500" {
+			entries++
+		}
+	}
+	s.Equal(3, entries)
+}
+
+func TestRateLimit(t *testing.T) {
+	suite.Run(t, new(RateLimitSuite))
+}
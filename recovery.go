@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// serve calls the wrapped handler, recovering from its panics when
+// WithRecovery is used
+func (rh loggerHanlder) serve(res http.ResponseWriter, req *http.Request, rl *responseLogger) {
+	if rh.recovery {
+		defer rh.recover(rl)
+	}
+
+	rh.h.ServeHTTP(res, req)
+}
+
+func (rh loggerHanlder) recover(rl *responseLogger) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	// http.ErrAbortHandler is how handlers abort a response on purpose
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	rl.panicked = true
+	rl.panicValue = fmt.Sprint(v)
+	rl.stack = debug.Stack()
+
+	if rl.status == 0 && !rl.hijacked {
+		rl.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// panicText is the recovered panic printed after a text log output
+func panicText(e *Entry) string {
+	return fmt.Sprintf("panic: %s\n%s", e.PanicValue, e.Stack)
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoverySuite struct {
+	suite.Suite
+}
+
+func (s *RecoverySuite) TestText() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}), WithWriter(&tw), WithClock(testClock{}), WithFormat(TinyLoggerType), WithRecovery(true))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(http.StatusInternalServerError, rec.Code)
+
+	lines := strings.SplitN(string(tw.Bytes), "\n", 3)
+	s.Equal("GET / 500 0 - 0.000 ms", lines[0])
+	s.Equal("panic: boom", lines[1])
+	s.Contains(lines[2], "goroutine")
+}
+
+func (s *RecoverySuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)), WithRecovery(true))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal(http.StatusAccepted, rec.Code)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal(true, entry["panic"])
+	s.Equal("boom", entry["panic.value"])
+	s.Contains(entry["panic.stack"], "recovery_test.go")
+	s.Equal("202", entry["response.status"])
+}
+
+func (s *RecoverySuite) TestSampledOut() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}), WithWriter(&tw), WithFormat(TinyLoggerType), WithRecovery(true), WithSampling(0))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Contains(string(tw.Bytes), "panic: boom")
+}
+
+func (s *RecoverySuite) TestAbortHandler() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), WithWriter(&testWriter{}), WithRecovery(true))
+
+	s.PanicsWithValue(http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func (s *RecoverySuite) TestDisabled() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	}), WithWriter(&testWriter{}))
+
+	s.Panics(func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	suite.Run(t, new(RecoverySuite))
+}
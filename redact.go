@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces sensitive values in the log output
+const redactedValue = "[REDACTED]"
+
+// redactor replaces the values of sensitive headers and query parameters
+type redactor struct {
+	headers map[string]struct{}
+	params  map[string]struct{}
+}
+
+func (r *redactor) addHeaders(names ...string) {
+	if r.headers == nil {
+		r.headers = make(map[string]struct{}, len(names))
+	}
+
+	for _, name := range names {
+		r.headers[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+}
+
+func (r *redactor) addParams(names ...string) {
+	if r.params == nil {
+		r.params = make(map[string]struct{}, len(names))
+	}
+
+	for _, name := range names {
+		r.params[strings.ToLower(name)] = struct{}{}
+	}
+}
+
+func (r redactor) enabled() bool {
+	return len(r.headers) > 0 || len(r.params) > 0
+}
+
+// request returns a shallow copy of req, safe to log, in which the
+// sensitive headers and query parameters are redacted
+func (r redactor) request(req *http.Request) *http.Request {
+	redacted := *req
+
+	redacted.Header = r.header(req.Header)
+	redacted.RequestURI = r.uri(req.RequestURI)
+
+	if req.URL != nil {
+		u := *req.URL
+		u.RawQuery = r.query(u.RawQuery)
+		redacted.URL = &u
+	}
+
+	if referer := redacted.Header.Get("Referer"); referer != "" && len(r.params) > 0 {
+		redacted.Header.Set("Referer", r.uri(referer))
+	}
+
+	return &redacted
+}
+
+func (r redactor) header(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+
+	for name, values := range h {
+		if _, ok := r.headers[http.CanonicalHeaderKey(name)]; ok {
+			redacted[name] = []string{redactedValue}
+		} else {
+			redacted[name] = values
+		}
+	}
+
+	return redacted
+}
+
+func (r redactor) uri(uri string) string {
+	i := strings.IndexByte(uri, '?')
+	if i < 0 {
+		return uri
+	}
+
+	return uri[:i+1] + r.query(uri[i+1:])
+}
+
+func (r redactor) query(query string) string {
+	if len(r.params) == 0 || query == "" {
+		return query
+	}
+
+	pairs := strings.Split(query, "&")
+
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+
+		if _, ok := r.params[strings.ToLower(name)]; ok {
+			pairs[i] = key + "=" + redactedValue
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
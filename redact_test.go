@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RedactSuite struct {
+	suite.Suite
+
+	req *http.Request
+	r   redactor
+}
+
+func (s *RedactSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/login?user=bob&Token=s3cr3t&pass%77ord=x", nil)
+	s.req.Header.Set("Authorization", "Bearer s3cr3t")
+	s.req.Header.Set("User-Agent", "test-agent")
+	s.req.Header.Set("Referer", "http://example.com/?token=abc")
+
+	s.r = redactor{}
+	s.r.addHeaders("authorization")
+	s.r.addParams("token", "password")
+}
+
+func (s *RedactSuite) TestRequest() {
+	req := s.r.request(s.req)
+
+	s.Equal("/login?user=bob&Token=[REDACTED]&pass%77ord=[REDACTED]", req.RequestURI)
+	s.Equal("user=bob&Token=[REDACTED]&pass%77ord=[REDACTED]", req.URL.RawQuery)
+	s.Equal(redactedValue, req.Header.Get("Authorization"))
+	s.Equal("test-agent", req.Header.Get("User-Agent"))
+	s.Equal("http://example.com/?token=[REDACTED]", req.Referer())
+
+	// the request seen by the handler is left untouched
+	s.Equal("Bearer s3cr3t", s.req.Header.Get("Authorization"))
+	s.Equal("user=bob&Token=s3cr3t&pass%77ord=x", s.req.URL.RawQuery)
+}
+
+func (s *RedactSuite) TestDisabled() {
+	s.False(redactor{}.enabled())
+	s.True(s.r.enabled())
+}
+
+func (s *RedactSuite) TestText() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{}), WithFormat(TinyLoggerType),
+		WithRedactedQueryParams("token"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?token=abc&a=b", nil))
+
+	s.Equal("GET /?token=[REDACTED]&a=b 404 19 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func (s *RedactSuite) TestJSON() {
+	var buf bytes.Buffer
+	l := newTestLogrus(&buf)
+
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(l),
+		WithRedactedHeaders("Authorization", "Cookie"))
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+
+	header := entry["request.header"].(map[string]interface{})
+	s.Equal([]interface{}{redactedValue}, header["Authorization"])
+	s.Equal([]interface{}{"test-agent"}, header["User-Agent"])
+}
+
+func TestRedact(t *testing.T) {
+	suite.Run(t, new(RedactSuite))
+}
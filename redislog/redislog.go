@@ -0,0 +1,237 @@
+// Package redislog publishes the log output of the logger package to a
+// Redis Stream, for lightweight real-time consumers such as dashboards or
+// anomaly detectors, see Writer.
+package redislog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Field is the field of the stream messages holding the entry
+const Field = "entry"
+
+// Client is the part of *redis.Client, or *redis.ClusterClient, used by
+// Writer
+type Client interface {
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+}
+
+// Option configures the writer returned by Writer
+type Option func(*writer)
+
+// WithFlushInterval sets how often the pending entries are published,
+// default to 100ms, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *writer) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithBatchSize sets the number of entries published in one pipeline, and
+// past which they're published before the flush interval, default to 100,
+// kept for n <= 0
+func WithBatchSize(n int) Option {
+	return func(w *writer) {
+		if n > 0 {
+			w.batchSize = n
+		}
+	}
+}
+
+// WithMaxPending sets the number of entries waiting to be published past
+// which the entries written are dropped with logger.ErrQueueFull, and
+// counted by the Dropped method of the handler, default to 10000, kept for
+// n <= 0
+func WithMaxPending(n int) Option {
+	return func(w *writer) {
+		if n > 0 {
+			w.maxPending = n
+		}
+	}
+}
+
+// WithMaxLen trims the stream to about n messages as entries are added,
+// with XADD MAXLEN ~, the stream isn't trimmed by default
+func WithMaxLen(n int64) Option {
+	return func(w *writer) {
+		w.maxLen = n
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches published in the background, by default they are dropped
+// silently
+func WithErrorHandler(f func(error)) Option {
+	return func(w *writer) {
+		w.errors = f
+	}
+}
+
+// writer publishes every Write as one message of a stream, in batches
+type writer struct {
+	client     Client
+	stream     string
+	interval   time.Duration
+	batchSize  int
+	maxPending int
+	maxLen     int64
+	errors     func(error)
+
+	mu      sync.Mutex
+	pending [][]byte
+	closed  bool
+
+	// sending serializes the batches, so the messages are added in order
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Writer returns an io.WriteCloser adding each write, i.e. each entry of
+// the log output, as a message of stream with the entry in its Field,
+// e.g. logger.WithWriter(redislog.Writer(redis.NewClient(opts), "access")).
+// Messages are pipelined in batches in the background, so writes never
+// wait for Redis. Close publishes the pending entries, call it on
+// shutdown.
+func Writer(client Client, stream string, opts ...Option) io.WriteCloser {
+	w := &writer{
+		client:     client,
+		stream:     stream,
+		interval:   100 * time.Millisecond,
+		batchSize:  100,
+		maxPending: 10000,
+		errors:     func(error) {},
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w
+}
+
+func (w *writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			w.errors(err)
+		}
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	msg := bytes.Clone(bytes.TrimRight(p, "\n"))
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+
+		return 0, logger.ErrClosed
+	}
+
+	if len(w.pending) >= w.maxPending {
+		w.mu.Unlock()
+
+		return 0, logger.ErrQueueFull
+	}
+
+	w.pending = append(w.pending, msg)
+	full := len(w.pending) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush publishes the pending entries
+func (w *writer) Flush() error {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	msgs := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	for len(msgs) > 0 {
+		n := min(len(msgs), w.batchSize)
+		if err := w.publish(context.Background(), msgs[:n]); err != nil {
+			return err
+		}
+		msgs = msgs[n:]
+	}
+
+	return nil
+}
+
+// publish adds batch to the stream in one pipeline
+func (w *writer) publish(ctx context.Context, batch [][]byte) error {
+	_, err := w.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, msg := range batch {
+			p.XAdd(ctx, &redis.XAddArgs{
+				Stream: w.stream,
+				MaxLen: w.maxLen,
+				Approx: w.maxLen > 0,
+				Values: map[string]interface{}{Field: msg},
+			})
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// Close stops the background publishing and publishes the pending
+// entries, later writes fail with logger.ErrClosed
+func (w *writer) Close() error {
+	var err error
+
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.done)
+		w.wg.Wait()
+
+		err = w.Flush()
+	})
+
+	return err
+}
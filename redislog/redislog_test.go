@@ -0,0 +1,157 @@
+package redislog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+// testPipeliner records the XADD commands of a pipeline, the other
+// methods of redis.Pipeliner are left unimplemented
+type testPipeliner struct {
+	redis.Pipeliner
+
+	adds []*redis.XAddArgs
+}
+
+func (p *testPipeliner) XAdd(_ context.Context, a *redis.XAddArgs) *redis.StringCmd {
+	p.adds = append(p.adds, a)
+
+	return redis.NewStringResult("0-1", nil)
+}
+
+type testClient struct {
+	mu      sync.Mutex
+	batches [][]*redis.XAddArgs
+	errs    []error
+}
+
+func (tc *testClient) Pipelined(_ context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	p := &testPipeliner{}
+	if err := fn(p); err != nil {
+		return nil, err
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if len(tc.errs) > 0 {
+		err := tc.errs[0]
+		tc.errs = tc.errs[1:]
+
+		return nil, err
+	}
+
+	tc.batches = append(tc.batches, p.adds)
+
+	return nil, nil
+}
+
+func (tc *testClient) len() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return len(tc.batches)
+}
+
+type RedisSuite struct {
+	suite.Suite
+}
+
+func (s *RedisSuite) TestWriter() {
+	tc := &testClient{}
+	w := Writer(tc, "access", WithFlushInterval(time.Hour))
+
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithCustomFormat(":method :url"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(w.Close())
+
+	s.Require().Len(tc.batches, 1)
+	s.Require().Len(tc.batches[0], 2)
+	s.Equal("access", tc.batches[0][0].Stream)
+	s.Zero(tc.batches[0][0].MaxLen)
+	s.Equal(map[string]interface{}{Field: []byte("GET /a")}, tc.batches[0][0].Values)
+	s.Equal(map[string]interface{}{Field: []byte("GET /b")}, tc.batches[0][1].Values)
+
+	_, err := w.Write([]byte("GET /c\n"))
+	s.Equal(logger.ErrClosed, err)
+}
+
+func (s *RedisSuite) TestMaxLen() {
+	tc := &testClient{}
+	w := Writer(tc, "access", WithMaxLen(1000))
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+
+	s.Equal(int64(1000), tc.batches[0][0].MaxLen)
+	s.True(tc.batches[0][0].Approx)
+}
+
+func (s *RedisSuite) TestBatchSize() {
+	tc := &testClient{}
+	w := Writer(tc, "access", WithBatchSize(2), WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+
+	s.Eventually(func() bool {
+		return tc.len() == 1
+	}, time.Second, 10*time.Millisecond)
+	s.Len(tc.batches[0], 2)
+}
+
+func (s *RedisSuite) TestInvalidOptions() {
+	tc := &testClient{}
+	w := Writer(tc, "access", WithBatchSize(-1), WithFlushInterval(0), WithMaxPending(0))
+
+	ww := w.(*writer)
+	s.Equal(100, ww.batchSize)
+	s.Equal(100*time.Millisecond, ww.interval)
+	s.Equal(10000, ww.maxPending)
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+	s.Equal(1, tc.len())
+}
+
+func (s *RedisSuite) TestMaxPending() {
+	tc := &testClient{}
+	w := Writer(tc, "access", WithMaxPending(1), WithFlushInterval(time.Hour))
+
+	var errs []error
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithCustomFormat(":url"),
+		logger.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Equal([]error{logger.ErrQueueFull}, errs)
+	s.Equal(uint64(1), h.(interface{ Dropped() uint64 }).Dropped())
+
+	s.Nil(w.Close())
+	s.Len(tc.batches[0], 1)
+}
+
+func (s *RedisSuite) TestError() {
+	tc := &testClient{errs: []error{errors.New("LOADING")}}
+	w := Writer(tc, "access", WithFlushInterval(time.Hour))
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	s.EqualError(w.(*writer).Flush(), "LOADING")
+	s.Empty(tc.batches)
+}
+
+func TestRedis(t *testing.T) {
+	suite.Run(t, new(RedisSuite))
+}
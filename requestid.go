@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header an incoming request ID is read from, and
+// the one the request ID is sent back in
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	fieldsKey
+	correlationKey
+	requestLoggerKey
+	nextKey
+)
+
+// RequestIDFromContext returns the ID the middleware assigned to the
+// request, or an empty string if WithRequestID is not used
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+
+	return id
+}
+
+// requestID returns the incoming request ID when it is safe to log, or a
+// new random UUID
+func requestID(req *http.Request) string {
+	if id := req.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+
+	return newUUID()
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' || id[i] == '"' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (rh loggerHanlder) withRequestID(res http.ResponseWriter, req *http.Request, rl *responseLogger) *http.Request {
+	rl.requestID = requestID(req)
+	res.Header().Set(RequestIDHeader, rl.requestID)
+
+	return req.WithContext(context.WithValue(req.Context(), requestIDKey, rl.requestID))
+}
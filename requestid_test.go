@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+type RequestIDSuite struct {
+	suite.Suite
+}
+
+func (s *RequestIDSuite) TestGenerated() {
+	tw := testWriter{}
+	var id string
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id = RequestIDFromContext(req.Context())
+	}), WithWriter(&tw), WithCustomFormat(":request-id"), WithRequestID())
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Regexp(uuidRegexp, id)
+	s.Equal(id, rec.Header().Get(RequestIDHeader))
+	s.Equal(id+"\n", string(tw.Bytes))
+}
+
+func (s *RequestIDSuite) TestIncoming() {
+	var id string
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id = RequestIDFromContext(req.Context())
+	}), WithWriter(&testWriter{}), WithRequestID())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	s.Equal("abc-123", id)
+	s.Equal("abc-123", rec.Header().Get(RequestIDHeader))
+}
+
+func (s *RequestIDSuite) TestInvalidIncoming() {
+	for _, id := range []string{"has space", `quo"te`, strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, id)
+
+		s.Regexp(uuidRegexp, requestID(req))
+	}
+}
+
+func (s *RequestIDSuite) TestDisabled() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":request-id"))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("", rec.Header().Get(RequestIDHeader))
+	s.Equal("-\n", string(tw.Bytes))
+	s.Equal("", RequestIDFromContext(context.Background()))
+}
+
+func (s *RequestIDSuite) TestUnique() {
+	s.NotEqual(newUUID(), newUUID())
+}
+
+func TestRequestID(t *testing.T) {
+	suite.Run(t, new(RequestIDSuite))
+}
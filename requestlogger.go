@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// requestLogger is what FromRequest needs from the middleware
+type requestLogger struct {
+	base       *slog.Logger
+	remoteAddr string
+	routeFuncs []RouteFunc
+}
+
+// FromRequest returns a log/slog logger for the application logs of r,
+// with the request_id (see WithRequestID), remote_ip and route attributes
+// of its access log entry. It derives from the logger of WithRequestLogger
+// and the client address resolved through WithTrustedProxies, or from
+// slog.Default() and r.RemoteAddr for requests which didn't go through a
+// middleware using WithRequestLogger.
+func FromRequest(r *http.Request) *slog.Logger {
+	rl, ok := r.Context().Value(requestLoggerKey).(requestLogger)
+	if !ok {
+		rl = requestLogger{slog.Default(), r.RemoteAddr, nil}
+	}
+
+	ip, _, err := net.SplitHostPort(rl.remoteAddr)
+	if err != nil {
+		ip = rl.remoteAddr
+	}
+
+	attrs := make([]any, 0, 3)
+
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+
+	attrs = append(attrs, slog.String("remote_ip", ip))
+
+	if pattern := route(r, rl.routeFuncs); pattern != "" {
+		attrs = append(attrs, slog.String("route", pattern))
+	}
+
+	return rl.base.With(attrs...)
+}
+
+func (rh loggerHanlder) withRequestLogger(req *http.Request) *http.Request {
+	remoteAddr := req.RemoteAddr
+	if rh.proxies != nil {
+		remoteAddr = rh.proxies.clientAddr(req)
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), requestLoggerKey,
+		requestLogger{rh.requestLogger, remoteAddr, rh.routeFuncs}))
+}
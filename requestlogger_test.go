@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RequestLoggerSuite struct {
+	suite.Suite
+}
+
+func (s *RequestLoggerSuite) TestFromRequest() {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(_ http.ResponseWriter, req *http.Request) {
+		FromRequest(req).Info("user loaded")
+	})
+
+	h := New(mux, WithWriter(&testWriter{}), WithRequestID(), WithRequestLogger(base),
+		WithTrustedProxies("192.0.2.0/24"))
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	record := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &record))
+	s.Equal("user loaded", record["msg"])
+	s.Equal("abc", record["request_id"])
+	s.Equal("203.0.113.7", record["remote_ip"])
+	s.Equal("/users/{id}", record["route"])
+}
+
+func (s *RequestLoggerSuite) TestWithoutMiddleware() {
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	FromRequest(httptest.NewRequest(http.MethodGet, "/", nil)).Info("hello")
+
+	record := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &record))
+	s.Equal("192.0.2.1", record["remote_ip"])
+	s.NotContains(record, "request_id")
+	s.NotContains(record, "route")
+}
+
+func TestRequestLogger(t *testing.T) {
+	suite.Run(t, new(RequestLoggerSuite))
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultResponseBodyTypes are the content types WithResponseBodyCapture
+// captures unless told otherwise: text, JSON, XML and forms
+var DefaultResponseBodyTypes = []string{
+	"text/*",
+	"application/json",
+	"application/*+json",
+	"application/xml",
+	"application/*+xml",
+	"application/x-www-form-urlencoded",
+}
+
+// responseCapture tees up to max bytes of the response body, provided its
+// content type is allowed
+type responseCapture struct {
+	max   int
+	types []string
+	buf   bytes.Buffer
+
+	decided bool
+	allowed bool
+}
+
+func newResponseCapture(max int, types []string) *responseCapture {
+	return &responseCapture{max: max, types: types}
+}
+
+func (rc *responseCapture) capture(header http.Header, p []byte) {
+	if !rc.decided {
+		rc.decided = true
+
+		contentType := header.Get("Content-Type")
+		if contentType == "" {
+			contentType = http.DetectContentType(p)
+		}
+
+		rc.allowed = matchContentType(contentType, rc.types)
+	}
+
+	if !rc.allowed {
+		return
+	}
+
+	if rest := rc.max - rc.buf.Len(); rest > 0 {
+		if len(p) < rest {
+			rest = len(p)
+		}
+
+		rc.buf.Write(p[:rest])
+	}
+}
+
+func (rc *responseCapture) String() string {
+	return rc.buf.String()
+}
+
+// matchContentType reports whether contentType matches one of patterns,
+// which are either exact media types, "type/*" or "type/*+suffix"
+func matchContentType(contentType string, patterns []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	for _, pattern := range patterns {
+		ptyp, psubtype, _ := strings.Cut(strings.ToLower(pattern), "/")
+
+		if ptyp != typ {
+			continue
+		}
+
+		switch {
+		case psubtype == "*", psubtype == subtype:
+			return true
+		case strings.HasPrefix(psubtype, "*+") && strings.HasSuffix(subtype, psubtype[1:]):
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ResponseBodySuite struct {
+	suite.Suite
+}
+
+func (s *ResponseBodySuite) TestMatchContentType() {
+	s.True(matchContentType("text/html; charset=utf-8", DefaultResponseBodyTypes))
+	s.True(matchContentType("application/json", DefaultResponseBodyTypes))
+	s.True(matchContentType("application/problem+json", DefaultResponseBodyTypes))
+	s.True(matchContentType("application/atom+xml", DefaultResponseBodyTypes))
+	s.False(matchContentType("image/png", DefaultResponseBodyTypes))
+	s.False(matchContentType("application/octet-stream", DefaultResponseBodyTypes))
+	s.False(matchContentType("", DefaultResponseBodyTypes))
+}
+
+func (s *ResponseBodySuite) TestCapture() {
+	rc := newResponseCapture(8, DefaultResponseBodyTypes)
+	header := http.Header{"Content-Type": {"application/json"}}
+
+	rc.capture(header, []byte(`{"id":`))
+	rc.capture(header, []byte(`12345}`))
+
+	s.Equal(`{"id":12`, rc.String())
+}
+
+func (s *ResponseBodySuite) TestSniff() {
+	rc := newResponseCapture(8, DefaultResponseBodyTypes)
+
+	rc.capture(http.Header{}, []byte("\x89PNG\r\n\x1a\n...."))
+
+	s.Equal("", rc.String())
+}
+
+func (s *ResponseBodySuite) TestHandler() {
+	var buf bytes.Buffer
+	l := newTestLogrus(&buf)
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		io.Copy(res, strings.NewReader("hello world"))
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(l), WithResponseBodyCapture(5))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("hello", entry["response.body"])
+	s.Equal("11", entry["response.size"])
+	s.Equal("hello world", rec.Body.String())
+}
+
+func (s *ResponseBodySuite) TestTypes() {
+	var buf bytes.Buffer
+	l := newTestLogrus(&buf)
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		res.Write([]byte("hello world"))
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(l), WithResponseBodyCapture(64),
+		WithResponseBodyTypes("application/json"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("", entry["response.body"])
+}
+
+func TestResponseBody(t *testing.T) {
+	suite.Run(t, new(ResponseBodySuite))
+}
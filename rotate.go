@@ -0,0 +1,350 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFileWriter is an io.WriteCloser appending to Filename and
+// rotating it by size or time, usable as the writer of any handler.
+// Rotated files are renamed to name-<timestamp>.ext in the same directory,
+// name-<timestamp>-<n>.ext when several are rotated the same millisecond.
+type RotatingFileWriter struct {
+	// Filename is the file to write to, its directory is created if needed
+	Filename string
+	// MaxSize is the size in bytes above which the file is rotated, zero
+	// disables size based rotation
+	MaxSize int64
+	// Interval is how often the file is rotated, at the multiples of
+	// Interval since the zero time, e.g. every UTC midnight for 24 hours,
+	// zero disables time based rotation
+	Interval time.Duration
+	// MaxAge is how long rotated files are kept, zero keeps them forever
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept, zero keeps them all
+	MaxBackups int
+	// Compress gzips the rotated files
+	Compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// period is the start of the Interval the file is written in
+	period time.Time
+	now    func() time.Time
+
+	compressing sync.WaitGroup
+	// cleaning serializes the cleanups of the compressions
+	cleaning sync.Mutex
+}
+
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	rotate := w.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSize
+	if w.Interval > 0 {
+		if period := w.clock().Truncate(w.Interval); period.After(w.period) {
+			// an empty file is kept for the new period
+			rotate = rotate || w.size > 0
+			w.period = period
+		}
+	}
+
+	if rotate {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Rotate closes the current file, renames it as a backup and opens a new
+// one
+func (w *RotatingFileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.rotate()
+}
+
+// Reopen closes and reopens Filename without renaming it, for use after
+// an external tool such as logrotate moved it
+func (w *RotatingFileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	return w.open()
+}
+
+// ReopenOnSignal reopens the file every time the process receives SIGHUP,
+// as logrotate expects. The returned function stops listening.
+func (w *RotatingFileWriter) ReopenOnSignal() (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(c, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				w.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+// Close closes the file and waits for the pending compressions
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	err := w.close()
+	w.mu.Unlock()
+
+	w.compressing.Wait()
+
+	return err
+}
+
+func (w *RotatingFileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.Filename), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	w.file, w.size = f, fi.Size()
+
+	if w.Interval > 0 {
+		// a file written in a previous period is rotated on the next write
+		w.period = w.clock().Truncate(w.Interval)
+		if w.size > 0 {
+			w.period = fi.ModTime().Truncate(w.Interval)
+		}
+	}
+
+	return nil
+}
+
+func (w *RotatingFileWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file, w.size = nil, 0
+
+	return err
+}
+
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(w.Filename); err == nil {
+		backup := w.backupName()
+
+		if err := os.Rename(w.Filename, backup); err != nil {
+			return err
+		}
+
+		if w.Compress {
+			w.compressing.Add(1)
+			go func() {
+				defer w.compressing.Done()
+
+				compressFile(backup)
+				w.cleanup()
+			}()
+		} else {
+			w.cleanup()
+		}
+	}
+
+	return w.open()
+}
+
+func (w *RotatingFileWriter) clock() time.Time {
+	if w.now != nil {
+		return w.now()
+	}
+
+	return time.Now()
+}
+
+// backupName returns the name of the next rotated file, numbered after
+// the last one when there are already some for the same millisecond
+func (w *RotatingFileWriter) backupName() string {
+	prefix, ext := w.backupPrefix()
+	stamp := w.clock().Format(backupTimeFormat)
+
+	last := -1
+	for _, b := range w.backups() {
+		if b.rotated.Format(backupTimeFormat) == stamp {
+			last = max(last, b.n)
+		}
+	}
+
+	name := prefix + stamp
+	if last < 0 {
+		return name + ext
+	}
+
+	return name + "-" + strconv.Itoa(last+1) + ext
+}
+
+// backup is a rotated file, the n-th of those rotated at the same time
+type backup struct {
+	name    string
+	rotated time.Time
+	n       int
+	modTime time.Time
+}
+
+// backups returns the rotated files, newest first
+func (w *RotatingFileWriter) backups() []backup {
+	prefix, ext := w.backupPrefix()
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil
+	}
+
+	backups := []backup{}
+	for _, name := range matches {
+		rest := strings.TrimPrefix(name, prefix)
+		rest = strings.TrimSuffix(strings.TrimSuffix(rest, ".gz"), ext)
+		if len(rest) < len(backupTimeFormat) {
+			continue
+		}
+
+		rotated, err := time.Parse(backupTimeFormat, rest[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+
+		n := 0
+		if suffix := rest[len(backupTimeFormat):]; suffix != "" {
+			digits, ok := strings.CutPrefix(suffix, "-")
+			if n, err = strconv.Atoi(digits); !ok || err != nil {
+				continue
+			}
+		}
+
+		if fi, err := os.Stat(name); err == nil {
+			backups = append(backups, backup{name, rotated, n, fi.ModTime()})
+		}
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].rotated.Equal(backups[j].rotated) {
+			return backups[i].rotated.After(backups[j].rotated)
+		}
+
+		return backups[i].n > backups[j].n
+	})
+
+	return backups
+}
+
+// backupPrefix returns what rotated files start and end with
+func (w *RotatingFileWriter) backupPrefix() (prefix, ext string) {
+	ext = filepath.Ext(w.Filename)
+
+	return strings.TrimSuffix(w.Filename, ext) + "-", ext
+}
+
+// cleanup removes the rotated files exceeding MaxBackups or MaxAge
+func (w *RotatingFileWriter) cleanup() {
+	if w.MaxBackups <= 0 && w.MaxAge <= 0 {
+		return
+	}
+
+	w.cleaning.Lock()
+	defer w.cleaning.Unlock()
+
+	backups := w.backups()
+
+	for i, b := range backups {
+		if (w.MaxBackups > 0 && i >= w.MaxBackups) || (w.MaxAge > 0 && time.Since(b.modTime) > w.MaxAge) {
+			os.Remove(b.name)
+		}
+	}
+}
+
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(name + ".gz")
+
+		return err
+	}
+
+	if err := gz.Close(); err != nil {
+		dst.Close()
+
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RotateSuite struct {
+	suite.Suite
+
+	dir string
+	w   *RotatingFileWriter
+	now time.Time
+}
+
+func (s *RotateSuite) SetupTest() {
+	s.dir = s.T().TempDir()
+	s.now = time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+	s.w = &RotatingFileWriter{
+		Filename: filepath.Join(s.dir, "logs", "access.log"),
+		MaxSize:  10,
+		now: func() time.Time {
+			s.now = s.now.Add(time.Second)
+
+			return s.now
+		},
+	}
+}
+
+func (s *RotateSuite) files() []string {
+	matches, _ := filepath.Glob(filepath.Join(s.dir, "logs", "*"))
+	sort.Strings(matches)
+
+	for i, m := range matches {
+		matches[i] = filepath.Base(m)
+	}
+
+	return matches
+}
+
+func (s *RotateSuite) read(name string) string {
+	b, _ := ioutil.ReadFile(filepath.Join(s.dir, "logs", name))
+
+	return string(b)
+}
+
+func (s *RotateSuite) TestRotateBySize() {
+	s.w.Write([]byte("12345\n"))
+	s.w.Write([]byte("6789\n"))
+	s.w.Write([]byte("abcdef\n"))
+	s.Nil(s.w.Close())
+
+	s.Equal([]string{"access-2017-01-02T15-04-06.000.log", "access-2017-01-02T15-04-07.000.log", "access.log"}, s.files())
+	s.Equal("12345\n", s.read("access-2017-01-02T15-04-06.000.log"))
+	s.Equal("6789\n", s.read("access-2017-01-02T15-04-07.000.log"))
+	s.Equal("abcdef\n", s.read("access.log"))
+}
+
+func (s *RotateSuite) TestRotateByTime() {
+	s.w.MaxSize, s.w.Interval = 0, time.Hour
+	s.w.now = func() time.Time { return s.now }
+
+	s.w.Write([]byte("a\n"))
+	s.now = s.now.Add(time.Minute)
+	s.w.Write([]byte("b\n"))
+	s.now = time.Date(2017, time.January, 2, 16, 0, 1, 0, time.UTC)
+	s.w.Write([]byte("c\n"))
+	s.Nil(s.w.Close())
+
+	s.Equal([]string{"access-2017-01-02T16-00-01.000.log", "access.log"}, s.files())
+	s.Equal("a\nb\n", s.read("access-2017-01-02T16-00-01.000.log"))
+	s.Equal("c\n", s.read("access.log"))
+
+	// written the previous hour, the file is rotated once reopened
+	s.Nil(os.Chtimes(s.w.Filename, s.now, s.now))
+	s.now = s.now.Add(time.Hour)
+	s.w.Write([]byte("d\n"))
+	s.Nil(s.w.Close())
+
+	s.Equal("c\n", s.read("access-2017-01-02T17-00-01.000.log"))
+	s.Equal("d\n", s.read("access.log"))
+}
+
+func (s *RotateSuite) TestSameMillisecond() {
+	s.w.MaxBackups = 2
+	s.w.now = func() time.Time { return s.now }
+
+	for _, entry := range []string{"0123456789", "a", "b", "c"} {
+		s.w.Write([]byte(entry))
+		s.w.Rotate()
+	}
+	s.Nil(s.w.Close())
+
+	s.Equal([]string{"access-2017-01-02T15-04-05.000-2.log", "access-2017-01-02T15-04-05.000-3.log", "access.log"}, s.files())
+	s.Equal("b", s.read("access-2017-01-02T15-04-05.000-2.log"))
+	s.Equal("c", s.read("access-2017-01-02T15-04-05.000-3.log"))
+}
+
+func (s *RotateSuite) TestAppend() {
+	s.w.MaxSize = 0
+	s.w.Write([]byte("a\n"))
+	s.w.Close()
+
+	s.w.Write([]byte("b\n"))
+	s.w.Close()
+
+	s.Equal("a\nb\n", s.read("access.log"))
+}
+
+func (s *RotateSuite) TestMaxBackups() {
+	s.w.MaxBackups = 1
+
+	for i := 0; i < 4; i++ {
+		s.w.Write([]byte("0123456789"))
+	}
+	s.Nil(s.w.Close())
+
+	s.Equal([]string{"access-2017-01-02T15-04-08.000.log", "access.log"}, s.files())
+}
+
+func (s *RotateSuite) TestCompress() {
+	s.w.Compress = true
+	s.w.Write([]byte("0123456789"))
+	s.w.Write([]byte("new"))
+	s.Nil(s.w.Close())
+
+	s.Equal([]string{"access-2017-01-02T15-04-06.000.log.gz", "access.log"}, s.files())
+
+	f, err := os.Open(filepath.Join(s.dir, "logs", "access-2017-01-02T15-04-06.000.log.gz"))
+	s.Nil(err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	s.Nil(err)
+	b, _ := ioutil.ReadAll(gz)
+	s.Equal("0123456789", string(b))
+}
+
+func (s *RotateSuite) TestReopen() {
+	s.w.Write([]byte("before\n"))
+	os.Rename(s.w.Filename, s.w.Filename+".1")
+
+	s.Nil(s.w.Reopen())
+	s.w.Write([]byte("after\n"))
+	s.w.Close()
+
+	s.Equal("before\n", s.read("access.log.1"))
+	s.Equal("after\n", s.read("access.log"))
+}
+
+func TestRotate(t *testing.T) {
+	suite.Run(t, new(RotateSuite))
+}
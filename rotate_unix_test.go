@@ -0,0 +1,30 @@
+//go:build unix
+
+package logger
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func (s *RotateSuite) TestReopenOnSignal() {
+	s.w.MaxSize = 0
+	stop := s.w.ReopenOnSignal()
+	defer stop()
+
+	s.w.Write([]byte("before\n"))
+	os.Rename(s.w.Filename, s.w.Filename+".1")
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	s.Eventually(func() bool {
+		_, err := os.Stat(s.w.Filename)
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	s.w.Write([]byte("after\n"))
+	s.w.Close()
+
+	s.Equal("after\n", s.read("access.log"))
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RouteFunc returns the pattern of the route a router matched req against,
+// e.g. "/users/{id}", empty when it didn't, see WithRouteFunc
+type RouteFunc func(req *http.Request) string
+
+// route returns the pattern of the route req was matched against, e.g.
+// "/users/{id}", as returned by funcs or recorded by http.ServeMux. It's
+// empty for requests no router matched.
+func route(req *http.Request, funcs []RouteFunc) string {
+	for _, f := range funcs {
+		if pattern := f(req); pattern != "" {
+			return pattern
+		}
+	}
+
+	// http.ServeMux patterns may start with a method, e.g. "GET /users/{id}"
+	if i := strings.IndexByte(req.Pattern, ' '); i >= 0 {
+		return strings.TrimLeft(req.Pattern[i:], " \t")
+	}
+
+	return req.Pattern
+}
+
+// route returns the pattern of the route req was matched against, see
+// WithRouteFunc
+func (rh loggerHanlder) route(req *http.Request) string {
+	return route(req, rh.routeFuncs)
+}
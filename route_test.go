@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RouteSuite struct {
+	suite.Suite
+
+	tw testWriter
+}
+
+func (s *RouteSuite) SetupTest() {
+	s.tw = testWriter{}
+}
+
+func (s *RouteSuite) serve(h http.Handler, url string) string {
+	s.tw.Bytes = nil
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+
+	return string(s.tw.Bytes)
+}
+
+func (s *RouteSuite) TestServeMux() {
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}", noopHandler{})
+	mux.Handle("/static/", noopHandler{})
+
+	h := New(mux, WithWriter(&s.tw), WithCustomFormat(":route"))
+
+	s.Equal("/users/{id}\n", s.serve(h, "/users/42"))
+	s.Equal("/static/\n", s.serve(h, "/static/app.js"))
+	s.Equal("-\n", s.serve(h, "/missing"))
+}
+
+func (s *RouteSuite) TestRouteFunc() {
+	mux := http.NewServeMux()
+	mux.Handle("/users/", noopHandler{})
+
+	h := New(mux, WithWriter(&s.tw), WithCustomFormat(":route"),
+		WithRouteFunc(func(req *http.Request) string { return "" }),
+		WithRouteFunc(func(req *http.Request) string {
+			if strings.HasPrefix(req.URL.Path, "/users/") {
+				return "/users/:id"
+			}
+
+			return ""
+		}))
+
+	s.Equal("/users/:id\n", s.serve(h, "/users/42"))
+	s.Equal("-\n", s.serve(h, "/missing"))
+}
+
+func TestRoute(t *testing.T) {
+	suite.Run(t, new(RouteSuite))
+}
@@ -0,0 +1,313 @@
+// Package s3 ships the log output of the logger package to Amazon S3, or
+// any S3-compatible object storage, as gzipped batches, see Writer.
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-http-utils/logger"
+)
+
+// Client is the part of *s3.Client used by Writer
+type Client interface {
+	PutObject(ctx context.Context, params *awss3.PutObjectInput,
+		optFns ...func(*awss3.Options)) (*awss3.PutObjectOutput, error)
+}
+
+// Option configures the writer returned by Writer
+type Option func(*writer)
+
+// WithFlushInterval sets how often the pending batch is uploaded, default
+// to 1 minute, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(w *writer) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithBatchSize sets the size of the log output, before compression, at
+// which a batch is uploaded without waiting for the flush interval,
+// default to 16MB, kept for n <= 0
+func WithBatchSize(n int) Option {
+	return func(w *writer) {
+		if n > 0 {
+			w.batchSize = n
+		}
+	}
+}
+
+// WithRetries sets how many times the upload of a batch is retried, with
+// an exponential backoff, before it's dropped, default to 3, kept for
+// n < 0
+func WithRetries(n int) Option {
+	return func(w *writer) {
+		if n >= 0 {
+			w.retries = n
+		}
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches uploaded in the background, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(w *writer) {
+		w.errors = f
+	}
+}
+
+// WithClock sets the clock giving the hour partition of the entries,
+// default to the system clock
+func WithClock(clock logger.Clock) Option {
+	return func(w *writer) {
+		w.now = clock.Now
+	}
+}
+
+// batch is the gzipped log output of one hour, uploaded as one object
+type batch struct {
+	hour time.Time
+	buf  bytes.Buffer
+	gz   *gzip.Writer
+	size int
+}
+
+// writer compresses the log output into batches uploaded as objects
+type writer struct {
+	client    Client
+	bucket    string
+	prefix    string
+	interval  time.Duration
+	batchSize int
+	retries   int
+	errors    func(error)
+	now       func() time.Time
+	// id tells apart the objects of the writers of the instances sharing
+	// the bucket
+	id string
+
+	mu      sync.Mutex
+	current *batch
+	pending []*batch
+	seq     int
+
+	// sending serializes the uploads
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// Writer returns an io.WriteCloser uploading the log output to bucket as
+// gzipped batches, with keys partitioned by the hour the entries were
+// written, in UTC, under prefix, e.g.
+// access/year=2017/month=01/day=02/hour=15/20170102T150405Z-1a2b3c4d-1.log.gz
+// for the prefix "access/", so it can be queried by Athena or loaded by
+// warehouse tools. Batches are uploaded in the background every flush
+// interval, as soon as they reach the batch size and when the hour
+// changes. Close uploads the pending batches, call it on shutdown, e.g.:
+//
+//	cfg, _ := config.LoadDefaultConfig(ctx)
+//	w, err := s3.Writer(awss3.NewFromConfig(cfg), "logs", "access/")
+//	defer w.Close()
+//
+// S3-compatible storages, such as MinIO or Cloudflare R2, are used through
+// the BaseEndpoint of the options of the client.
+func Writer(client Client, bucket, prefix string, opts ...Option) (io.WriteCloser, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	w := &writer{
+		client:    client,
+		bucket:    bucket,
+		prefix:    prefix,
+		interval:  time.Minute,
+		batchSize: 16 << 20,
+		retries:   3,
+		errors:    func(error) {},
+		now:       time.Now,
+		id:        hex.EncodeToString(id),
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+func (w *writer) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ticker.C:
+			err = w.Flush()
+		case <-w.wake:
+			err = w.upload(false)
+		case <-w.done:
+			return
+		}
+
+		if err != nil {
+			w.errors(err)
+		}
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	hour := w.now().UTC().Truncate(time.Hour)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.current != nil && !w.current.hour.Equal(hour) {
+		w.seal()
+	}
+
+	if w.current == nil {
+		w.current = &batch{hour: hour}
+		w.current.gz = gzip.NewWriter(&w.current.buf)
+	}
+
+	if _, err := w.current.gz.Write(p); err != nil {
+		return 0, err
+	}
+	w.current.size += len(p)
+
+	if w.current.size >= w.batchSize {
+		w.seal()
+	}
+
+	return len(p), nil
+}
+
+// seal moves the current batch to the pending ones and wakes the uploads
+func (w *writer) seal() {
+	w.pending = append(w.pending, w.current)
+	w.current = nil
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Flush uploads the pending batches and the current one, the first error
+// is returned once they were all tried
+func (w *writer) Flush() error {
+	return w.upload(true)
+}
+
+// upload uploads the pending batches, and the current one when current is
+// set
+func (w *writer) upload(current bool) error {
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	if current && w.current != nil {
+		w.pending = append(w.pending, w.current)
+		w.current = nil
+	}
+
+	batches := w.pending
+	w.pending = nil
+
+	keys := make([]string, len(batches))
+	for i, b := range batches {
+		w.seq++
+		keys[i] = w.key(b, w.seq)
+	}
+	w.mu.Unlock()
+
+	var first error
+	for i, b := range batches {
+		// the next batches are uploaded whatever happens to this one
+		err := b.gz.Close()
+		if err == nil {
+			err = w.put(context.Background(), keys[i], b.buf.Bytes())
+		}
+
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// key returns the key of the object of b, the seq-th batch of the writer
+func (w *writer) key(b *batch, seq int) string {
+	return fmt.Sprintf("%syear=%04d/month=%02d/day=%02d/hour=%02d/%s-%s-%d.log.gz",
+		w.prefix, b.hour.Year(), b.hour.Month(), b.hour.Day(), b.hour.Hour(),
+		w.now().UTC().Format("20060102T150405Z"), w.id, seq)
+}
+
+// put uploads body as the object key, retried with an exponential backoff
+func (w *writer) put(ctx context.Context, key string, body []byte) error {
+	backoff := 100 * time.Millisecond
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		_, err = w.client.PutObject(ctx, &awss3.PutObjectInput{
+			Bucket:        aws.String(w.bucket),
+			Key:           aws.String(key),
+			Body:          bytes.NewReader(body),
+			ContentLength: aws.Int64(int64(len(body))),
+			ContentType:   aws.String("application/gzip"),
+		})
+		if err == nil || attempt >= w.retries {
+			break
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	if err != nil {
+		return fmt.Errorf("s3: upload of %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// Close stops the background uploads and uploads the pending batches
+func (w *writer) Close() error {
+	var err error
+
+	w.once.Do(func() {
+		close(w.done)
+		w.wg.Wait()
+
+		err = w.Flush()
+	})
+
+	return err
+}
@@ -0,0 +1,185 @@
+package s3
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/go-http-utils/logger"
+	"github.com/go-http-utils/logger/fakeclock"
+	"github.com/stretchr/testify/suite"
+)
+
+type object struct {
+	key  string
+	body string
+}
+
+type testClient struct {
+	mu      sync.Mutex
+	objects []object
+	errs    []error
+}
+
+func (tc *testClient) PutObject(_ context.Context, params *awss3.PutObjectInput,
+	_ ...func(*awss3.Options)) (*awss3.PutObjectOutput, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if len(tc.errs) > 0 {
+		err := tc.errs[0]
+		tc.errs = tc.errs[1:]
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := gzip.NewReader(params.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	tc.objects = append(tc.objects, object{key: *params.Key, body: string(body)})
+
+	return &awss3.PutObjectOutput{}, nil
+}
+
+type S3Suite struct {
+	suite.Suite
+}
+
+func (s *S3Suite) TestWriter() {
+	tc := &testClient{}
+	clock := fakeclock.New(time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC))
+	w, err := Writer(tc, "logs", "access/", WithClock(clock))
+	s.Require().Nil(err)
+
+	h := logger.New(http.NotFoundHandler(), logger.WithWriter(w), logger.WithCustomFormat(":method :url"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(w.Close())
+
+	s.Require().Len(tc.objects, 1)
+	s.Regexp(`^access/year=2017/month=01/day=02/hour=15/20170102T150405Z-[0-9a-f]{8}-1\.log\.gz$`, tc.objects[0].key)
+	s.Equal("GET /a\nGET /b\n", tc.objects[0].body)
+}
+
+func (s *S3Suite) TestHourPartitions() {
+	tc := &testClient{}
+	clock := fakeclock.New(time.Date(2017, time.January, 2, 15, 59, 59, 0, time.UTC))
+	w, err := Writer(tc, "logs", "", WithClock(clock))
+	s.Require().Nil(err)
+
+	w.Write([]byte("a\n"))
+	clock.Advance(time.Second)
+	w.Write([]byte("b\n"))
+	s.Nil(w.Close())
+
+	s.Require().Len(tc.objects, 2)
+	s.True(strings.HasPrefix(tc.objects[0].key, "year=2017/month=01/day=02/hour=15/"))
+	s.Equal("a\n", tc.objects[0].body)
+	s.True(strings.HasPrefix(tc.objects[1].key, "year=2017/month=01/day=02/hour=16/"))
+	s.Equal("b\n", tc.objects[1].body)
+}
+
+func (s *S3Suite) TestBatchSize() {
+	tc := &testClient{}
+	w, err := Writer(tc, "logs", "", WithBatchSize(4), WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+	w.Write([]byte("c\n"))
+
+	s.Eventually(func() bool {
+		tc.mu.Lock()
+		defer tc.mu.Unlock()
+
+		return len(tc.objects) == 1
+	}, time.Second, 10*time.Millisecond)
+	s.Equal("a\nb\n", tc.objects[0].body)
+}
+
+func (s *S3Suite) TestRetries() {
+	tc := &testClient{errs: []error{errors.New("slow down"), errors.New("slow down")}}
+	w, err := Writer(tc, "logs", "", WithRetries(2))
+	s.Require().Nil(err)
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+	s.Len(tc.objects, 1)
+}
+
+func (s *S3Suite) TestError() {
+	tc := &testClient{errs: []error{errors.New("forbidden"), errors.New("forbidden")}}
+	w, err := Writer(tc, "logs", "", WithRetries(1))
+	s.Require().Nil(err)
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	err = w.(*writer).Flush()
+	s.ErrorContains(err, "forbidden")
+	s.Empty(tc.objects)
+
+	// the batch was dropped
+	s.Nil(w.(*writer).Flush())
+}
+
+func (s *S3Suite) TestErrorNextBatches() {
+	tc := &testClient{errs: []error{errors.New("forbidden")}}
+	clock := fakeclock.New(time.Date(2017, time.January, 2, 15, 59, 59, 0, time.UTC))
+	w, err := Writer(tc, "logs", "", WithClock(clock), WithRetries(0))
+	s.Require().Nil(err)
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	clock.Advance(time.Second)
+	w.Write([]byte("b\n"))
+
+	s.ErrorContains(w.(*writer).Flush(), "forbidden")
+	s.Require().Len(tc.objects, 1)
+	s.Equal("b\n", tc.objects[0].body)
+}
+
+func (s *S3Suite) TestInvalidOptions() {
+	tc := &testClient{}
+	w, err := Writer(tc, "logs", "", WithFlushInterval(0), WithBatchSize(-1), WithRetries(-1))
+	s.Require().Nil(err)
+
+	ww := w.(*writer)
+	s.Equal(time.Minute, ww.interval)
+	s.Equal(16<<20, ww.batchSize)
+	s.Equal(3, ww.retries)
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+	s.Len(tc.objects, 1)
+}
+
+func (s *S3Suite) TestEmpty() {
+	tc := &testClient{}
+	w, err := Writer(tc, "logs", "")
+	s.Require().Nil(err)
+
+	s.Nil(w.Close())
+	s.Empty(tc.objects)
+}
+
+func TestS3(t *testing.T) {
+	suite.Run(t, new(S3Suite))
+}
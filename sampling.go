@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Sampler reports whether a processed request is logged, see WithSampler
+type Sampler func(req *http.Request, status int) bool
+
+// SampleRate returns a Sampler logging the given fraction of requests,
+// from 0 (none) to 1 (all). It counts requests so that exactly one out of
+// every 1/rate requests is logged.
+func SampleRate(rate float64) Sampler {
+	if rate >= 1 {
+		return func(*http.Request, int) bool { return true }
+	}
+
+	if rate <= 0 {
+		return func(*http.Request, int) bool { return false }
+	}
+
+	var count uint64
+
+	return func(*http.Request, int) bool {
+		n := atomic.AddUint64(&count, 1)
+
+		return uint64(float64(n)*rate) != uint64(float64(n-1)*rate)
+	}
+}
+
+// SampleErrors returns a Sampler logging every request with a status of
+// 400 or above, others are passed to sampler, e.g.
+// SampleErrors(SampleRate(0.01)) logs all errors and 1% of the rest
+func SampleErrors(sampler Sampler) Sampler {
+	return func(req *http.Request, status int) bool {
+		return status >= http.StatusBadRequest || sampler(req, status)
+	}
+}
+
+// SamplePaths returns a Sampler using the rate of the longest path prefix
+// of rates matching the request path, requests matching none are passed
+// to fallback, e.g. SamplePaths(map[string]float64{"/api/": 0.1}, SampleRate(1))
+func SamplePaths(rates map[string]float64, fallback Sampler) Sampler {
+	type rule struct {
+		prefix  string
+		sampler Sampler
+	}
+
+	rules := make([]rule, 0, len(rates))
+	for prefix, rate := range rates {
+		rules = append(rules, rule{prefix, SampleRate(rate)})
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return len(rules[i].prefix) > len(rules[j].prefix)
+	})
+
+	return func(req *http.Request, status int) bool {
+		for _, r := range rules {
+			if strings.HasPrefix(req.URL.Path, r.prefix) {
+				return r.sampler(req, status)
+			}
+		}
+
+		return fallback(req, status)
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SamplingSuite struct {
+	suite.Suite
+
+	req *http.Request
+}
+
+func (s *SamplingSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/", nil)
+}
+
+func (s *SamplingSuite) count(sampler Sampler, req *http.Request, status, n int) int {
+	sampled := 0
+
+	for i := 0; i < n; i++ {
+		if sampler(req, status) {
+			sampled++
+		}
+	}
+
+	return sampled
+}
+
+func (s *SamplingSuite) TestSampleRate() {
+	s.Equal(10, s.count(SampleRate(0.01), s.req, http.StatusOK, 1000))
+	s.Equal(250, s.count(SampleRate(0.25), s.req, http.StatusOK, 1000))
+	s.Equal(1000, s.count(SampleRate(1), s.req, http.StatusOK, 1000))
+	s.Equal(0, s.count(SampleRate(0), s.req, http.StatusOK, 1000))
+}
+
+func (s *SamplingSuite) TestSampleErrors() {
+	sampler := SampleErrors(SampleRate(0.01))
+
+	s.Equal(100, s.count(sampler, s.req, http.StatusInternalServerError, 100))
+	s.Equal(100, s.count(sampler, s.req, http.StatusNotFound, 100))
+	s.Equal(1, s.count(sampler, s.req, http.StatusOK, 100))
+}
+
+func (s *SamplingSuite) TestSamplePaths() {
+	sampler := SamplePaths(map[string]float64{
+		"/api/":        0.5,
+		"/api/health/": 0,
+	}, SampleRate(1))
+
+	s.Equal(50, s.count(sampler, httptest.NewRequest(http.MethodGet, "/api/users", nil), http.StatusOK, 100))
+	s.Equal(0, s.count(sampler, httptest.NewRequest(http.MethodGet, "/api/health/live", nil), http.StatusOK, 100))
+	s.Equal(100, s.count(sampler, httptest.NewRequest(http.MethodGet, "/home", nil), http.StatusOK, 100))
+}
+
+func (s *SamplingSuite) TestHandler() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":status"), WithSampling(0.5))
+
+	for i := 0; i < 4; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), s.req)
+	}
+
+	s.Equal(2, strings.Count(string(tw.Bytes), "404\n"))
+}
+
+func TestSampling(t *testing.T) {
+	suite.Run(t, new(SamplingSuite))
+}
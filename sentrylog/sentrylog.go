@@ -0,0 +1,167 @@
+// Package sentrylog reports the server errors logged by the logger package
+// to Sentry, or a Sentry-compatible error tracker such as GlitchTip, see
+// Hook.
+package sentrylog
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-http-utils/logger"
+)
+
+// Option configures the hook returned by Hook
+type Option func(*hook)
+
+// WithMinStatus sets the status from which the entries are reported,
+// default to 500
+func WithMinStatus(status int) Option {
+	return func(h *hook) {
+		h.minStatus = status
+	}
+}
+
+// WithBodySnippet sets the number of bytes of the captured request body,
+// see logger.WithBodyCapture, sent with the events, default to 1024, 0
+// leaves the body out
+func WithBodySnippet(n int) Option {
+	return func(h *hook) {
+		h.bodySnippet = n
+	}
+}
+
+type hook struct {
+	hub         *sentry.Hub
+	minStatus   int
+	bodySnippet int
+}
+
+// Hook returns a hook, for logger.WithHook, capturing the 5xx entries and
+// the panics recovered by logger.WithRecovery as events of hub, default to
+// sentry.CurrentHub(), e.g.:
+//
+//	sentry.Init(sentry.ClientOptions{Dsn: dsn})
+//	defer sentry.Flush(2 * time.Second)
+//
+//	logger.New(mux, logger.WithRecovery(true), logger.WithHook(sentrylog.Hook(nil)))
+//
+// Events hold the request as it's logged, i.e. redacted and masked, with
+// its logged headers and the start of its captured body. Server errors are
+// grouped by method, route and status, panics by their value.
+func Hook(hub *sentry.Hub, opts ...Option) func(e *logger.Entry) {
+	h := &hook{hub: hub, minStatus: http.StatusInternalServerError, bodySnippet: 1024}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h.capture
+}
+
+func (h *hook) capture(e *logger.Entry) {
+	if !e.Panicked && e.Status < h.minStatus {
+		return
+	}
+
+	hub := h.hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	hub.CaptureEvent(h.event(e))
+}
+
+// event returns the event of e
+func (h *hook) event(e *logger.Entry) *sentry.Event {
+	path, query, _ := strings.Cut(e.URL, "?")
+	route := e.Route
+	if route == "" {
+		route = path
+	}
+
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	event.Timestamp = e.Start.Add(e.Duration)
+	event.Transaction = e.Method + " " + route
+	event.Request = h.request(e, path, query)
+
+	event.Tags["http.method"] = e.Method
+	event.Tags["http.route"] = route
+	event.Tags["http.status_code"] = strconv.Itoa(e.Status)
+
+	if e.RequestID != "" {
+		event.Tags["request_id"] = e.RequestID
+	}
+
+	if e.RemoteUser != "" {
+		event.User = sentry.User{Username: e.RemoteUser}
+	}
+
+	if e.TraceID != "" {
+		event.Contexts["trace"] = sentry.Context{"trace_id": e.TraceID, "span_id": e.SpanID}
+	}
+
+	// the duration, size and fields of the entry
+	details := sentry.Context{
+		"duration_ms":   float64(e.Duration.Microseconds()) / 1000,
+		"response_size": e.Size,
+	}
+	for k, v := range e.Fields {
+		details[k] = v
+	}
+	event.Contexts["http_request"] = details
+
+	if e.Panicked {
+		event.Message = "panic: " + e.PanicValue
+		event.Exception = []sentry.Exception{{Type: "panic", Value: e.PanicValue}}
+		details["stack"] = string(e.Stack)
+
+		return event
+	}
+
+	event.Message = fmt.Sprintf("%s %s: %d %s", e.Method, route, e.Status, http.StatusText(e.Status))
+	event.Fingerprint = []string{e.Method, route, strconv.Itoa(e.Status)}
+
+	return event
+}
+
+// request returns the request of the event of e
+func (h *hook) request(e *logger.Entry, path, query string) *sentry.Request {
+	scheme := "http"
+	if e.TLS != nil {
+		scheme = "https"
+	}
+
+	req := &sentry.Request{
+		URL:         scheme + "://" + e.Host + path,
+		Method:      e.Method,
+		QueryString: query,
+		Headers:     map[string]string{},
+	}
+
+	for name, values := range e.Header {
+		req.Headers[name] = strings.Join(values, ", ")
+	}
+
+	if e.UserAgent != "" {
+		req.Headers["User-Agent"] = e.UserAgent
+	}
+
+	if e.Referer != "" {
+		req.Headers["Referer"] = e.Referer
+	}
+
+	if host, _, err := net.SplitHostPort(e.RemoteAddr); err == nil {
+		req.Env = map[string]string{"REMOTE_ADDR": host}
+	}
+
+	if h.bodySnippet > 0 && len(e.Body) > 0 {
+		req.Data = string(e.Body[:min(len(e.Body), h.bodySnippet)])
+	}
+
+	return req
+}
@@ -0,0 +1,135 @@
+package sentrylog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+// testTransport records the events instead of sending them
+type testTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *testTransport) Configure(sentry.ClientOptions) {}
+
+func (t *testTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+func (t *testTransport) Flush(time.Duration) bool {
+	return true
+}
+
+func (t *testTransport) FlushWithContext(context.Context) bool {
+	return true
+}
+
+func (t *testTransport) Close() {}
+
+type SentrySuite struct {
+	suite.Suite
+
+	transport *testTransport
+	hub       *sentry.Hub
+}
+
+func (s *SentrySuite) SetupTest() {
+	s.transport = &testTransport{}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:       "https://key@sentry.example.com/1",
+		Transport: s.transport,
+	})
+	s.Require().Nil(err)
+
+	s.hub = sentry.NewHub(client, sentry.NewScope())
+}
+
+func (s *SentrySuite) TestServerError() {
+	h := logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		io.ReadAll(req.Body)
+		logger.AddField(req.Context(), "tenant", "acme")
+		http.Error(res, "unavailable", http.StatusServiceUnavailable)
+	}), logger.WithHook(Hook(s.hub)), logger.WithBodyCapture(1<<20), logger.WithRequestID(),
+		logger.WithLoggedRequestHeaders("Accept"))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?page=2", strings.NewReader(strings.Repeat("x", 2000)))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-agent")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Require().Len(s.transport.events, 1)
+	event := s.transport.events[0]
+
+	s.Equal(sentry.LevelError, event.Level)
+	s.Equal("POST /orders: 503 Service Unavailable", event.Message)
+	s.Equal("POST /orders", event.Transaction)
+	s.Equal([]string{"POST", "/orders", "503"}, event.Fingerprint)
+	s.Equal("503", event.Tags["http.status_code"])
+	s.NotEmpty(event.Tags["request_id"])
+	s.Equal("acme", event.Contexts["http_request"]["tenant"])
+
+	s.Equal("http://example.com/orders", event.Request.URL)
+	s.Equal("page=2", event.Request.QueryString)
+	s.Equal("application/json", event.Request.Headers["Accept"])
+	s.Equal("test-agent", event.Request.Headers["User-Agent"])
+	s.Equal("192.0.2.1", event.Request.Env["REMOTE_ADDR"])
+	s.Equal(strings.Repeat("x", 1024), event.Request.Data)
+}
+
+func (s *SentrySuite) TestPanic() {
+	h := logger.New(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("nil map")
+	}), logger.WithHook(Hook(s.hub)), logger.WithRecovery(true))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Require().Len(s.transport.events, 1)
+	event := s.transport.events[0]
+
+	s.Equal("panic: nil map", event.Message)
+	s.Equal([]sentry.Exception{{Type: "panic", Value: "nil map"}}, event.Exception)
+	s.Contains(event.Contexts["http_request"]["stack"], "goroutine")
+	s.Nil(event.Fingerprint)
+}
+
+func (s *SentrySuite) TestIgnored() {
+	h := logger.New(http.NotFoundHandler(), logger.WithHook(Hook(s.hub)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Empty(s.transport.events)
+}
+
+func (s *SentrySuite) TestOptions() {
+	h := logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		io.ReadAll(req.Body)
+		http.NotFound(res, req)
+	}), logger.WithHook(Hook(s.hub, WithMinStatus(http.StatusNotFound), WithBodySnippet(0))),
+		logger.WithBodyCapture(1<<20))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("secret"))
+	req.Header.Set("Content-Type", "text/plain")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s.Require().Len(s.transport.events, 1)
+	s.Empty(s.transport.events[0].Request.Data)
+}
+
+func TestSentry(t *testing.T) {
+	suite.Run(t, new(SentrySuite))
+}
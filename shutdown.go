@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+)
+
+// Logger is a http.Handler logging requests which can be shut down
+// without losing entries, see NewLogger
+type Logger struct {
+	rh loggerHanlder
+}
+
+// NewLogger is like New but returns a *Logger, whose Close should be
+// called on shutdown
+func NewLogger(h http.Handler, opts ...Option) *Logger {
+	return &Logger{rh: New(h, opts...).(loggerHanlder)}
+}
+
+func (l *Logger) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	l.rh.ServeHTTP(res, req)
+}
+
+// Flush blocks until the pending entries are written, see WithAsync
+func (l *Logger) Flush() error {
+	return l.rh.Flush()
+}
+
+// Dropped returns the number of entries which could not be written, see
+// WithErrorHandler
+func (l *Logger) Dropped() uint64 {
+	return l.rh.Dropped()
+}
+
+// Counters returns the internal counters of the Logger, see WithExpvar
+func (l *Logger) Counters() Counters {
+	return l.rh.Counters()
+}
+
+// Close writes the pending entries and closes the writers the Logger
+// owns, e.g. the connection of WithSyslog, giving up when ctx is done.
+// Call it once the server stopped serving requests, e.g. after
+// http.Server.Shutdown.
+func (l *Logger) Close(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- l.rh.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ShutdownSuite struct {
+	suite.Suite
+}
+
+func (s *ShutdownSuite) TestBuffered() {
+	tw := testWriter{}
+	bw := bufio.NewWriter(&tw)
+	l := NewLogger(http.NotFoundHandler(), WithWriter(bw), WithClock(testClock{}), WithFormat(TinyLoggerType))
+
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Empty(tw.Bytes)
+
+	s.Nil(l.Close(context.Background()))
+	s.Equal("GET / 404 19 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func (s *ShutdownSuite) TestAsync() {
+	sw := &syncWriter{}
+	l := NewLogger(http.NotFoundHandler(), WithWriter(sw), WithClock(testClock{}), WithFormat(TinyLoggerType), WithAsync(8))
+
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Nil(l.Close(context.Background()))
+	s.Equal("GET / 404 19 - 0.000 ms\n", sw.String())
+}
+
+func (s *ShutdownSuite) TestDeadline() {
+	bw := &blockingWriter{release: make(chan struct{})}
+	defer close(bw.release)
+
+	l := NewLogger(http.NotFoundHandler(), WithWriter(bw), WithAsync(8))
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	s.Equal(context.DeadlineExceeded, l.Close(ctx))
+}
+
+func (s *ShutdownSuite) TestOwnedWriter() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	l := NewLogger(http.NotFoundHandler(), WithSyslog("tcp", ln.Addr().String(), "test", SyslogUser|SyslogNotice))
+	l.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	conn, err := ln.Accept()
+	s.Nil(err)
+	defer conn.Close()
+
+	s.Nil(l.Close(context.Background()))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	b := make([]byte, 4096)
+	for err == nil {
+		_, err = conn.Read(b)
+	}
+	s.NotErrorIs(err, os.ErrDeadlineExceeded)
+}
+
+func TestShutdown(t *testing.T) {
+	suite.Run(t, new(ShutdownSuite))
+}
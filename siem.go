@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	siemVendor  = "go-http-utils"
+	siemProduct = "logger"
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefHeaderEscaper   = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	leefValueEscaper    = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+// siemSeverity returns the CEF and LEEF severity of l, from 0 to 10
+func (l Level) siemSeverity() int {
+	switch l {
+	case LevelDebug:
+		return 1
+	case LevelWarn:
+		return 6
+	case LevelError:
+		return 8
+	}
+
+	return 3
+}
+
+// siemAttr is an extension key with its value
+type siemAttr struct {
+	key, value string
+}
+
+// siemAttrs returns the extension of e named by keys, skipping empty
+// values
+func siemAttrs(e *Entry, keys map[string]string, time string) []siemAttr {
+	host, port, err := net.SplitHostPort(e.RemoteAddr)
+	if err != nil {
+		host, port = e.RemoteAddr, ""
+	}
+
+	values := []siemAttr{
+		{"time", time},
+		{"src", host},
+		{"srcPort", port},
+		{"user", e.RemoteUser},
+		{"method", e.Method},
+		{"url", e.URL},
+		{"host", e.Host},
+		{"proto", e.Proto},
+		{"status", strconv.Itoa(e.Status)},
+		{"size", strconv.Itoa(e.Size)},
+		{"duration", strconv.FormatInt(e.Duration.Milliseconds(), 10)},
+		{"userAgent", e.UserAgent},
+		{"referer", e.Referer},
+		{"requestID", e.RequestID},
+	}
+
+	attrs := make([]siemAttr, 0, len(values))
+	for _, v := range values {
+		if key := keys[v.key]; key != "" && v.value != "" {
+			attrs = append(attrs, siemAttr{key, v.value})
+		}
+	}
+
+	return attrs
+}
+
+// cefKeys are the CEF extension keys of the siemAttrs values
+var cefKeys = map[string]string{
+	"time":      "rt",
+	"src":       "src",
+	"srcPort":   "spt",
+	"user":      "suser",
+	"method":    "requestMethod",
+	"url":       "request",
+	"host":      "dhost",
+	"proto":     "app",
+	"status":    "cn1",
+	"size":      "out",
+	"duration":  "cn2",
+	"userAgent": "requestClientApplication",
+	"referer":   "requestContext",
+	"requestID": "externalId",
+}
+
+// cefFormatter prints entries in the ArcSight Common Event Format
+type cefFormatter struct{}
+
+func (cefFormatter) Format(w io.Writer, e *Entry) error {
+	var b strings.Builder
+
+	b.WriteString("CEF:0|")
+	for _, field := range []string{siemVendor, siemProduct, Version, strconv.Itoa(e.Status),
+		e.Method + " " + e.URL, strconv.Itoa(e.Level.siemSeverity())} {
+		b.WriteString(cefHeaderEscaper.Replace(field))
+		b.WriteByte('|')
+	}
+
+	for i, attr := range siemAttrs(e, cefKeys, strconv.FormatInt(e.Start.UnixMilli(), 10)) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString(attr.key + "=" + cefExtensionEscaper.Replace(attr.value))
+	}
+
+	b.WriteString(" cn1Label=status cn2Label=durationMs\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// leefKeys are the LEEF attribute keys of the siemAttrs values
+var leefKeys = map[string]string{
+	"time":      "devTime",
+	"src":       "src",
+	"srcPort":   "srcPort",
+	"user":      "usrName",
+	"method":    "method",
+	"url":       "url",
+	"host":      "dstHost",
+	"proto":     "httpVersion",
+	"status":    "status",
+	"size":      "dstBytes",
+	"duration":  "durationMs",
+	"userAgent": "userAgent",
+	"referer":   "referer",
+	"requestID": "requestId",
+}
+
+// leefFormatter prints entries in the IBM QRadar Log Event Extended
+// Format 2.0, tab delimited
+type leefFormatter struct{}
+
+func (leefFormatter) Format(w io.Writer, e *Entry) error {
+	var b strings.Builder
+
+	b.WriteString("LEEF:2.0|")
+	for _, field := range []string{siemVendor, siemProduct, Version, strconv.Itoa(e.Status)} {
+		b.WriteString(leefHeaderEscaper.Replace(field))
+		b.WriteByte('|')
+	}
+
+	// the delimiter field, a tab
+	b.WriteString("x09|")
+	b.WriteString("sev=" + strconv.Itoa(e.Level.siemSeverity()))
+
+	// devTime in the default MMM dd yyyy HH:mm:ss.SSS zzz format
+	for _, attr := range siemAttrs(e, leefKeys, e.Start.Format("Jan 02 2006 15:04:05.000 MST")) {
+		b.WriteString("\t" + attr.key + "=" + leefValueEscaper.Replace(attr.value))
+	}
+
+	b.WriteByte('\n')
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
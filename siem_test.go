@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SIEMSuite struct {
+	suite.Suite
+
+	req *http.Request
+	now time.Time
+}
+
+func (s *SIEMSuite) SetupTest() {
+	s.now = time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)
+
+	s.req = httptest.NewRequest(http.MethodGet, "/search?q=a|b=c", nil)
+	s.req.RemoteAddr = "192.0.2.1:1234"
+	s.req.Header.Set("User-Agent", "curl/8.0\tbeta")
+}
+
+func (s *SIEMSuite) serve(t Type) string {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(t), WithClock(testClock{s.now}))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	return string(tw.Bytes)
+}
+
+func (s *SIEMSuite) TestCEF() {
+	s.Equal(`CEF:0|go-http-utils|logger|`+Version+`|404|GET /search?q=a\|b=c|6|`+
+		`rt=1483369445000 src=192.0.2.1 spt=1234 requestMethod=GET request=/search?q\=a|b\=c dhost=example.com app=HTTP/1.1 `+
+		"cn1=404 out=19 cn2=0 requestClientApplication=curl/8.0\tbeta cn1Label=status cn2Label=durationMs\n", s.serve(CEFLoggerType))
+}
+
+func (s *SIEMSuite) TestLEEF() {
+	s.Equal(`LEEF:2.0|go-http-utils|logger|`+Version+`|404|x09|sev=6`+
+		"\tdevTime=Jan 02 2017 15:04:05.000 UTC\tsrc=192.0.2.1\tsrcPort=1234\tmethod=GET\turl=/search?q=a|b=c"+
+		"\tdstHost=example.com\thttpVersion=HTTP/1.1\tstatus=404\tdstBytes=19\tdurationMs=0\tuserAgent=curl/8.0 beta\n", s.serve(LEEFLoggerType))
+}
+
+func (s *SIEMSuite) TestEscaping() {
+	s.Equal(`a\\b\|c`, cefHeaderEscaper.Replace(`a\b|c`))
+	s.Equal(`a\\b\=c\nd`, cefExtensionEscaper.Replace("a\\b=c\nd"))
+	s.Equal("a b c", leefValueEscaper.Replace("a\tb\nc"))
+}
+
+func (s *SIEMSuite) TestSeverity() {
+	s.Equal(1, LevelDebug.siemSeverity())
+	s.Equal(3, LevelInfo.siemSeverity())
+	s.Equal(8, LevelError.siemSeverity())
+}
+
+func TestSIEM(t *testing.T) {
+	suite.Run(t, new(SIEMSuite))
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// SkipPaths returns a skipper for WithSkipper that matches requests whose
+// URL path is exactly one of paths, e.g. SkipPaths("/healthz", "/metrics")
+func SkipPaths(paths ...string) func(*http.Request) bool {
+	set := make(map[string]struct{}, len(paths))
+
+	for _, path := range paths {
+		set[path] = struct{}{}
+	}
+
+	return func(req *http.Request) bool {
+		_, ok := set[req.URL.Path]
+
+		return ok
+	}
+}
+
+// SkipPathPrefix returns a skipper for WithSkipper that matches requests
+// whose URL path starts with one of prefixes, e.g. SkipPathPrefix("/static/")
+func SkipPathPrefix(prefixes ...string) func(*http.Request) bool {
+	return func(req *http.Request) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(req.URL.Path, prefix) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func (rh loggerHanlder) skip(req *http.Request) bool {
+	for _, skipper := range rh.skippers {
+		if skipper(req) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SkipSuite struct {
+	suite.Suite
+}
+
+func (s *SkipSuite) TestSkipPaths() {
+	skip := SkipPaths("/healthz", "/metrics")
+
+	s.True(skip(httptest.NewRequest(http.MethodGet, "/healthz", nil)))
+	s.True(skip(httptest.NewRequest(http.MethodGet, "/metrics?x=1", nil)))
+	s.False(skip(httptest.NewRequest(http.MethodGet, "/healthz/deep", nil)))
+	s.False(skip(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func (s *SkipSuite) TestSkipPathPrefix() {
+	skip := SkipPathPrefix("/static/", "/assets/")
+
+	s.True(skip(httptest.NewRequest(http.MethodGet, "/static/app.js", nil)))
+	s.True(skip(httptest.NewRequest(http.MethodGet, "/assets/logo.png", nil)))
+	s.False(skip(httptest.NewRequest(http.MethodGet, "/static", nil)))
+}
+
+func (s *SkipSuite) TestMultipleSkippers() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{}), WithFormat(TinyLoggerType),
+		WithSkipper(SkipPaths("/healthz")), WithSkipper(SkipPathPrefix("/static/")))
+
+	for _, path := range []string{"/healthz", "/static/app.js", "/users"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	s.Equal("GET /users 404 19 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func (s *SkipSuite) TestSkipZeroAlloc() {
+	h := New(noopHandler{}, WithWriter(&testWriter{}), WithSkipper(SkipPaths("/healthz")))
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	res := httptest.NewRecorder()
+
+	s.Equal(float64(0), testing.AllocsPerRun(100, func() {
+		h.ServeHTTP(res, req)
+	}))
+}
+
+func TestSkip(t *testing.T) {
+	suite.Run(t, new(SkipSuite))
+}
+
+type noopHandler struct{}
+
+func (noopHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"sort"
+)
+
+// slogFormatter logs entries to a log/slog logger, with request and
+// response groups
+type slogFormatter struct {
+	l *slog.Logger
+}
+
+func (sf slogFormatter) Format(_ io.Writer, e *Entry) error {
+	request := []any{
+		slog.String("host", e.Host),
+		slog.String("method", e.Method),
+		slog.String("proto", e.Proto),
+		slog.String("url", e.URL),
+		slog.String("referer", e.Referer),
+		slog.String("user_agent", e.UserAgent),
+		slog.String("remote_addr", e.RemoteAddr),
+		slog.Int64("size", e.RequestSize),
+	}
+
+	if e.RequestID != "" {
+		request = append(request, slog.String("id", e.RequestID))
+	}
+
+	if e.CorrelationID != "" {
+		request = append(request, slog.String("correlation_id", e.CorrelationID))
+	}
+
+	if e.AmznTraceID != "" {
+		request = append(request, slog.String("amzn_trace_id", e.AmznTraceID))
+	}
+
+	if e.RemoteUser != "" {
+		request = append(request, slog.String("user", e.RemoteUser))
+	}
+
+	if e.Route != "" {
+		request = append(request, slog.String("route", e.Route))
+	}
+
+	if e.ConnID != "" {
+		request = append(request, slog.String("connection_id", e.ConnID))
+	}
+
+	if e.Range != "" {
+		request = append(request, slog.String("range", e.Range))
+	}
+
+	if e.Aborted != nil {
+		request = append(request, slog.Bool("aborted", true), slog.String("abort_error", e.Aborted.Error()))
+	}
+
+	response := []any{
+		slog.Int("status", e.Status),
+		slog.Int64("size", int64(e.Size)),
+		slog.Duration("ttfb", e.TTFB),
+		slog.Duration("duration", e.Duration),
+	}
+
+	if e.ContentLength >= 0 {
+		response = append(response, slog.Int64("content_length", e.ContentLength))
+	}
+
+	if e.Empty {
+		response = append(response, slog.Bool("empty", true))
+	}
+
+	if e.ContentEncoding != "" {
+		response = append(response, slog.String("content_encoding", e.ContentEncoding))
+
+		if e.BodySize >= 0 {
+			response = append(response, slog.Int64("body_size", e.BodySize))
+		}
+	}
+
+	if e.ContentRange != "" {
+		response = append(response, slog.String("content_range", e.ContentRange))
+	}
+
+	if e.CacheState != "" {
+		response = append(response, slog.String("cache_state", e.CacheState))
+	}
+
+	if e.Streamed {
+		response = append(response, slog.Bool("streamed", true), slog.Int("flushes", e.Flushes))
+	}
+
+	if e.Preliminary {
+		response = append(response, slog.Bool("preliminary", true))
+	}
+
+	if e.Trailer != nil {
+		response = append(response, slog.Any("trailer", e.Trailer))
+	}
+
+	if e.ResponseBody != nil {
+		response = append(response, slog.String("body", string(e.ResponseBody)))
+	}
+
+	attrs := []slog.Attr{
+		slog.Group("request", request...),
+		slog.Group("response", response...),
+		slog.Time("start_time", e.Start),
+	}
+
+	if e.TraceID != "" {
+		attrs = append(attrs, slog.String("trace_id", e.TraceID), slog.String("span_id", e.SpanID))
+	}
+
+	if e.TLS != nil {
+		tls := []any{
+			slog.String("version", e.TLS.Version),
+			slog.String("cipher", e.TLS.CipherSuite),
+			slog.String("sni", e.TLS.ServerName),
+		}
+
+		if e.TLS.ClientSubject != "" {
+			tls = append(tls, slog.String("client_subject", e.TLS.ClientSubject))
+		}
+
+		attrs = append(attrs, slog.Group("tls", tls...))
+	}
+
+	if e.Panicked {
+		attrs = append(attrs, slog.Bool("panic", true),
+			slog.String("panic_value", e.PanicValue), slog.String("panic_stack", string(e.Stack)))
+	}
+
+	if e.Hijacked {
+		attrs = append(attrs, slog.Bool("hijacked", true))
+	}
+
+	if ws := e.WebSocket; ws != nil {
+		websocket := []any{
+			slog.String("state", ws.state()),
+			slog.Duration("duration", ws.Duration),
+			slog.Int64("frames_in", ws.FramesIn),
+			slog.Int64("frames_out", ws.FramesOut),
+			slog.Int64("bytes_in", ws.BytesIn),
+			slog.Int64("bytes_out", ws.BytesOut),
+		}
+
+		if ws.CloseCode != 0 {
+			websocket = append(websocket, slog.Int("close_code", ws.CloseCode))
+		}
+
+		attrs = append(attrs, slog.Group("websocket", websocket...))
+	}
+
+	if e.Body != nil {
+		attrs = append(attrs, slog.String("body", string(e.Body)))
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, e.Fields[k]))
+	}
+
+	sf.l.LogAttrs(e.Request.Context(), e.Level.slog(), "request processed", attrs...)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SlogSuite struct {
+	suite.Suite
+}
+
+func (s *SlogSuite) TestTypedAttrs() {
+	th := &testSlogHandler{}
+	h := New(http.NotFoundHandler(), WithFormat(SlogLoggerType), WithSlogLogger(slog.New(th)),
+		WithFields(map[string]interface{}{"service": "api"}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	s.Len(th.records, 1)
+	r := th.records[0]
+	s.Equal("request processed", r.Message)
+	s.Equal(slog.LevelWarn, r.Level)
+
+	attrs := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Value.Kind() == slog.KindGroup {
+			for _, ga := range a.Value.Group() {
+				attrs[a.Key+"."+ga.Key] = ga.Value
+			}
+		} else {
+			attrs[a.Key] = a.Value
+		}
+
+		return true
+	})
+
+	s.Equal("GET", attrs["request.method"].String())
+	s.Equal("/users", attrs["request.url"].String())
+	s.Equal(int64(404), attrs["response.status"].Int64())
+	s.Equal(int64(19), attrs["response.size"].Int64())
+	s.Equal(slog.KindDuration, attrs["response.duration"].Kind())
+	s.Equal("api", attrs["service"].Any())
+}
+
+func (s *SlogSuite) TestDefaultWriter() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&buf), WithFormat(SlogLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal(float64(404), entry["response"].(map[string]interface{})["status"])
+	s.Equal("2017-01-02T15:04:05Z", entry["start_time"])
+}
+
+func TestSlog(t *testing.T) {
+	suite.Run(t, new(SlogSuite))
+}
+
+type testSlogHandler struct {
+	records []slog.Record
+}
+
+func (th *testSlogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (th *testSlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	th.records = append(th.records, r)
+
+	return nil
+}
+
+func (th *testSlogHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return th
+}
+
+func (th *testSlogHandler) WithGroup(string) slog.Handler {
+	return th
+}
@@ -0,0 +1,455 @@
+// Package sqlsink inserts the entries of the logger package into a
+// PostgreSQL or ClickHouse table, so access logs can be queried with SQL
+// without a log pipeline, see New.
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-http-utils/logger"
+)
+
+// Dialect is the SQL dialect of the database of a Sink
+type Dialect int
+
+const (
+	// Postgres is PostgreSQL, through a database/sql driver such as
+	// github.com/jackc/pgx/v5/stdlib or github.com/lib/pq
+	Postgres Dialect = iota
+	// ClickHouse is ClickHouse, through the database/sql driver of
+	// github.com/ClickHouse/clickhouse-go/v2
+	ClickHouse
+)
+
+// columns are the columns of the table, in the order of the values of row
+var columns = []string{
+	"time", "level", "method", "host", "url", "route", "proto", "status",
+	"size", "duration_ms", "ttfb_ms", "remote_addr", "remote_user",
+	"referer", "user_agent", "request_id", "trace_id", "request_size",
+}
+
+// postgresMaxParams is the number of parameters PostgreSQL accepts in one
+// statement
+const postgresMaxParams = 65535
+
+// table matches the table names accepted by New, optionally qualified by
+// their schema or database
+var table = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Schema returns the statements creating the table name for dialect, and
+// its index for Postgres, unless they already exist. New runs them unless
+// WithMigration(false) is given.
+func Schema(dialect Dialect, name string) []string {
+	if dialect == ClickHouse {
+		return []string{`CREATE TABLE IF NOT EXISTS ` + name + ` (
+	time DateTime64(3),
+	level LowCardinality(String),
+	method LowCardinality(String),
+	host LowCardinality(String),
+	url String,
+	route LowCardinality(String),
+	proto LowCardinality(String),
+	status UInt16,
+	size UInt64,
+	duration_ms Float64,
+	ttfb_ms Float64,
+	remote_addr String,
+	remote_user String,
+	referer String,
+	user_agent String,
+	request_id String,
+	trace_id String,
+	request_size Int64
+) ENGINE = MergeTree PARTITION BY toYYYYMM(time) ORDER BY time`}
+	}
+
+	index := strings.ReplaceAll(name, ".", "_") + "_time_idx"
+
+	return []string{`CREATE TABLE IF NOT EXISTS ` + name + ` (
+	time timestamptz NOT NULL,
+	level text NOT NULL,
+	method text NOT NULL,
+	host text NOT NULL,
+	url text NOT NULL,
+	route text NOT NULL,
+	proto text NOT NULL,
+	status integer NOT NULL,
+	size bigint NOT NULL,
+	duration_ms double precision NOT NULL,
+	ttfb_ms double precision NOT NULL,
+	remote_addr text NOT NULL,
+	remote_user text NOT NULL,
+	referer text NOT NULL,
+	user_agent text NOT NULL,
+	request_id text NOT NULL,
+	trace_id text NOT NULL,
+	request_size bigint NOT NULL
+)`, `CREATE INDEX IF NOT EXISTS ` + index + ` ON ` + name + ` (time)`}
+}
+
+// Option configures the Sink returned by New
+type Option func(*Sink)
+
+// WithFlushInterval sets how often the pending entries are inserted,
+// default to 5 seconds, kept for d <= 0
+func WithFlushInterval(d time.Duration) Option {
+	return func(s *Sink) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// WithBatchSize sets the number of rows inserted by one statement, and
+// past which they're inserted before the flush interval, default to 1000,
+// kept for n <= 0
+func WithBatchSize(n int) Option {
+	return func(s *Sink) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithMaxPending sets the number of entries waiting to be inserted past
+// which the overflow policy applies, default to 10000, kept for n <= 0
+func WithMaxPending(n int) Option {
+	return func(s *Sink) {
+		if n > 0 {
+			s.maxPending = n
+		}
+	}
+}
+
+// WithOverflowPolicy sets what happens to the entries logged while
+// max pending ones wait to be inserted, default to logger.OverflowDrop:
+// they're dropped and counted by the Dropped method of the handler. With
+// logger.OverflowBlock the requests wait for the inserts instead.
+func WithOverflowPolicy(policy logger.OverflowPolicy) Option {
+	return func(s *Sink) {
+		s.policy = policy
+	}
+}
+
+// WithRetries sets how many times a batch is inserted before it's dropped,
+// with an exponential backoff, default to 3, kept for n <= 0
+func WithRetries(n int) Option {
+	return func(s *Sink) {
+		if n > 0 {
+			s.attempts = n
+		}
+	}
+}
+
+// WithMigration sets whether New creates the table when it's missing, see
+// Schema, default to true
+func WithMigration(enabled bool) Option {
+	return func(s *Sink) {
+		s.migrate = enabled
+	}
+}
+
+// WithErrorHandler sets the function called with the errors of the
+// batches inserted in the background, by default they are dropped silently
+func WithErrorHandler(f func(error)) Option {
+	return func(s *Sink) {
+		s.errors = f
+	}
+}
+
+// Sink is a logger.Formatter inserting every entry as a row of a table,
+// e.g. logger.WithFormatter(sink). Rows are inserted in batches in the
+// background every flush interval, or as soon as a batch is full. Close
+// inserts the pending rows, call it on shutdown.
+type Sink struct {
+	db         *sql.DB
+	dialect    Dialect
+	table      string
+	interval   time.Duration
+	batchSize  int
+	maxPending int
+	policy     logger.OverflowPolicy
+	attempts   int
+	backoff    time.Duration
+	migrate    bool
+	errors     func(error)
+
+	mu      sync.Mutex
+	pending [][]interface{}
+	// room is signaled when the pending rows are taken, for the entries
+	// waiting under logger.OverflowBlock
+	room   *sync.Cond
+	closed bool
+
+	// inserting serializes the batches
+	inserting sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// New returns a Sink inserting into the table name of db, e.g.:
+//
+//	db, err := sql.Open("pgx", "postgres://localhost/logs")
+//	sink, err := sqlsink.New(db, sqlsink.Postgres, "access_logs")
+//	defer sink.Close()
+//
+//	logger.New(mux, logger.WithFormatter(sink))
+//
+// The table is created when it's missing unless WithMigration(false) is
+// given, it then needs the columns of Schema.
+func New(db *sql.DB, dialect Dialect, name string, opts ...Option) (*Sink, error) {
+	if !table.MatchString(name) {
+		return nil, fmt.Errorf("sqlsink: invalid table name %q", name)
+	}
+
+	s := &Sink{
+		db:         db,
+		dialect:    dialect,
+		table:      name,
+		interval:   5 * time.Second,
+		batchSize:  1000,
+		maxPending: 10000,
+		policy:     logger.OverflowDrop,
+		attempts:   3,
+		backoff:    time.Second,
+		migrate:    true,
+		errors:     func(error) {},
+
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	s.room = sync.NewCond(&s.mu)
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if dialect == Postgres {
+		s.batchSize = min(s.batchSize, postgresMaxParams/len(columns))
+	}
+
+	if s.migrate {
+		for _, stmt := range Schema(dialect, name) {
+			if _, err := db.Exec(stmt); err != nil {
+				return nil, fmt.Errorf("sqlsink: create table %s: %w", name, err)
+			}
+		}
+	}
+
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+func (s *Sink) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		if err := s.Flush(); err != nil {
+			s.errors(err)
+		}
+	}
+}
+
+// Format queues e as a row, the writer of the handler is unused. It
+// returns logger.ErrQueueFull when the row is dropped under
+// logger.OverflowDrop, and logger.ErrClosed once the Sink is closed.
+func (s *Sink) Format(_ io.Writer, e *logger.Entry) error {
+	row := values(e)
+
+	s.mu.Lock()
+	for !s.closed && len(s.pending) >= s.maxPending && s.policy == logger.OverflowBlock {
+		s.room.Wait()
+	}
+
+	if s.closed {
+		s.mu.Unlock()
+
+		return logger.ErrClosed
+	}
+
+	if len(s.pending) >= s.maxPending {
+		s.mu.Unlock()
+
+		return logger.ErrQueueFull
+	}
+
+	s.pending = append(s.pending, row)
+	full := len(s.pending) >= s.batchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// values returns the row of e, in the order of columns
+func values(e *logger.Entry) []interface{} {
+	return []interface{}{
+		e.Start.UTC(), level(e.Level), e.Method, e.Host, e.URL, e.Route,
+		e.Proto, int64(e.Status), int64(e.Size), milliseconds(e.Duration),
+		milliseconds(e.TTFB), e.RemoteAddr, e.RemoteUser, e.Referer,
+		e.UserAgent, e.RequestID, e.TraceID, e.RequestSize,
+	}
+}
+
+func level(l logger.Level) string {
+	switch l {
+	case logger.LevelDebug:
+		return "debug"
+	case logger.LevelWarn:
+		return "warn"
+	case logger.LevelError:
+		return "error"
+	}
+
+	return "info"
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Flush inserts the pending rows
+func (s *Sink) Flush() error {
+	s.inserting.Lock()
+	defer s.inserting.Unlock()
+
+	s.mu.Lock()
+	rows := s.pending
+	s.pending = nil
+	s.room.Broadcast()
+	s.mu.Unlock()
+
+	for len(rows) > 0 {
+		n := min(len(rows), s.batchSize)
+		if err := s.insert(context.Background(), rows[:n]); err != nil {
+			return err
+		}
+		rows = rows[n:]
+	}
+
+	return nil
+}
+
+// insert inserts batch, retried with an exponential backoff
+func (s *Sink) insert(ctx context.Context, batch [][]interface{}) error {
+	var err error
+
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.backoff << (attempt - 1))
+		}
+
+		if s.dialect == ClickHouse {
+			err = s.insertClickHouse(ctx, batch)
+		} else {
+			err = s.insertPostgres(ctx, batch)
+		}
+
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("sqlsink: insert of %d rows: %w", len(batch), err)
+}
+
+// insertPostgres inserts batch with one multi-row INSERT
+func (s *Sink) insertPostgres(ctx context.Context, batch [][]interface{}) error {
+	var query strings.Builder
+	query.WriteString("INSERT INTO " + s.table + " (" + strings.Join(columns, ", ") + ") VALUES ")
+
+	args := make([]interface{}, 0, len(batch)*len(columns))
+	for i, row := range batch {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+
+		query.WriteByte('(')
+		for j := range row {
+			if j > 0 {
+				query.WriteString(", ")
+			}
+
+			fmt.Fprintf(&query, "$%d", len(args)+j+1)
+		}
+		query.WriteByte(')')
+
+		args = append(args, row...)
+	}
+
+	_, err := s.db.ExecContext(ctx, query.String(), args...)
+
+	return err
+}
+
+// insertClickHouse inserts batch as one block, the rows being appended to
+// the statement prepared in a transaction and sent on commit
+func (s *Sink) insertClickHouse(ctx context.Context, batch [][]interface{}) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO "+s.table+" ("+strings.Join(columns, ", ")+")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range batch {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Close stops the background inserts and inserts the pending rows, the
+// entries logged afterwards are dropped with logger.ErrClosed
+func (s *Sink) Close() error {
+	var err error
+
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.room.Broadcast()
+		s.mu.Unlock()
+
+		close(s.done)
+		s.wg.Wait()
+
+		err = s.Flush()
+	})
+
+	return err
+}
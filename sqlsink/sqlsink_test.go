@@ -0,0 +1,329 @@
+package sqlsink
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+// statement is a statement run by the test driver
+type statement struct {
+	query string
+	args  []driver.Value
+}
+
+// testDriver records the statements run through it, failing the inserts
+// with errs
+type testDriver struct {
+	mu         sync.Mutex
+	statements []statement
+	commits    int
+	errs       []error
+}
+
+func (d *testDriver) Open(string) (driver.Conn, error) {
+	return &testConn{d: d}, nil
+}
+
+func (d *testDriver) exec(query string, args []driver.Value) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if strings.HasPrefix(query, "INSERT") && len(d.errs) > 0 {
+		err := d.errs[0]
+		d.errs = d.errs[1:]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	d.statements = append(d.statements, statement{query: query, args: args})
+
+	return nil
+}
+
+func (d *testDriver) inserts() []statement {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var inserts []statement
+	for _, st := range d.statements {
+		if strings.HasPrefix(st.query, "INSERT") {
+			inserts = append(inserts, st)
+		}
+	}
+
+	return inserts
+}
+
+type testConn struct {
+	d *testDriver
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{d: c.d, query: query}, nil
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return &testTx{d: c.d}, nil
+}
+
+type testTx struct {
+	d *testDriver
+}
+
+func (tx *testTx) Commit() error {
+	tx.d.mu.Lock()
+	tx.d.commits++
+	tx.d.mu.Unlock()
+
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	return nil
+}
+
+type testStmt struct {
+	d     *testDriver
+	query string
+}
+
+func (st *testStmt) Close() error {
+	return nil
+}
+
+func (st *testStmt) NumInput() int {
+	return -1
+}
+
+func (st *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := st.d.exec(st.query, args); err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (st *testStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+type SQLSinkSuite struct {
+	suite.Suite
+
+	d  *testDriver
+	db *sql.DB
+}
+
+func (s *SQLSinkSuite) SetupTest() {
+	s.d = &testDriver{}
+	s.db = sql.OpenDB(connector{s.d})
+}
+
+func (s *SQLSinkSuite) TearDownTest() {
+	s.db.Close()
+}
+
+// connector opens the connections of a testDriver
+type connector struct {
+	d *testDriver
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return c.d.Open("")
+}
+
+func (c connector) Driver() driver.Driver {
+	return c.d
+}
+
+func (s *SQLSinkSuite) serve(sink *Sink, paths ...string) {
+	h := logger.New(http.NotFoundHandler(), logger.WithFormatter(sink))
+	for _, path := range paths {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("User-Agent", "test-agent")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
+
+func (s *SQLSinkSuite) TestPostgres() {
+	sink, err := New(s.db, Postgres, "access_logs", WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+
+	s.Require().Len(s.d.statements, 2)
+	s.True(strings.HasPrefix(s.d.statements[0].query, "CREATE TABLE IF NOT EXISTS access_logs ("))
+	s.Equal("CREATE INDEX IF NOT EXISTS access_logs_time_idx ON access_logs (time)", s.d.statements[1].query)
+
+	s.serve(sink, "/a", "/b")
+	s.Nil(sink.Close())
+
+	inserts := s.d.inserts()
+	s.Require().Len(inserts, 1)
+	s.True(strings.HasPrefix(inserts[0].query, "INSERT INTO access_logs (time, level, method, host, url, "))
+	s.True(strings.HasSuffix(inserts[0].query, ", $17, $18), ($19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36)"))
+
+	args := inserts[0].args
+	s.Require().Len(args, 2*len(columns))
+	s.Equal("warn", args[1])
+	s.Equal("GET", args[2])
+	s.Equal("/a", args[4])
+	s.Equal(int64(404), args[7])
+	s.Equal("test-agent", args[14])
+	s.Equal("/b", args[len(columns)+4])
+}
+
+func (s *SQLSinkSuite) TestClickHouse() {
+	sink, err := New(s.db, ClickHouse, "logs.access", WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+
+	s.Require().Len(s.d.statements, 1)
+	s.True(strings.HasPrefix(s.d.statements[0].query, "CREATE TABLE IF NOT EXISTS logs.access ("))
+	s.True(strings.HasSuffix(s.d.statements[0].query, "ENGINE = MergeTree PARTITION BY toYYYYMM(time) ORDER BY time"))
+
+	s.serve(sink, "/a", "/b")
+	s.Nil(sink.Close())
+
+	inserts := s.d.inserts()
+	s.Require().Len(inserts, 2)
+	s.Equal("INSERT INTO logs.access ("+strings.Join(columns, ", ")+")", inserts[0].query)
+	s.Equal("/a", inserts[0].args[4])
+	s.Equal("/b", inserts[1].args[4])
+	s.Equal(1, s.d.commits)
+}
+
+func (s *SQLSinkSuite) TestWithoutMigration() {
+	sink, err := New(s.db, Postgres, "access_logs", WithMigration(false))
+	s.Require().Nil(err)
+	s.Nil(sink.Close())
+
+	s.Empty(s.d.statements)
+}
+
+func (s *SQLSinkSuite) TestInvalidTable() {
+	_, err := New(s.db, Postgres, "logs; DROP TABLE users")
+	s.EqualError(err, `sqlsink: invalid table name "logs; DROP TABLE users"`)
+}
+
+func (s *SQLSinkSuite) TestBatchSize() {
+	sink, err := New(s.db, Postgres, "access_logs", WithBatchSize(2), WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+	defer sink.Close()
+
+	s.serve(sink, "/a", "/b")
+
+	s.Eventually(func() bool {
+		return len(s.d.inserts()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// as many rows per statement as PostgreSQL accepts parameters
+	sink, err = New(s.db, Postgres, "access_logs", WithBatchSize(100000))
+	s.Require().Nil(err)
+	defer sink.Close()
+
+	s.Equal(postgresMaxParams/len(columns), sink.batchSize)
+}
+
+func (s *SQLSinkSuite) TestInvalidOptions() {
+	sink, err := New(s.db, Postgres, "access_logs", WithBatchSize(0), WithFlushInterval(-time.Second),
+		WithMaxPending(-1), WithRetries(0))
+	s.Require().Nil(err)
+
+	s.Equal(1000, sink.batchSize)
+	s.Equal(5*time.Second, sink.interval)
+	s.Equal(10000, sink.maxPending)
+	s.Equal(3, sink.attempts)
+
+	s.serve(sink, "/a")
+	s.Nil(sink.Close())
+	s.Len(s.d.inserts(), 1)
+}
+
+func (s *SQLSinkSuite) TestOverflowDrop() {
+	var errs []error
+	sink, err := New(s.db, Postgres, "access_logs", WithMaxPending(1), WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+
+	h := logger.New(http.NotFoundHandler(), logger.WithFormatter(sink),
+		logger.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Equal([]error{logger.ErrQueueFull}, errs)
+	s.Equal(uint64(1), h.(interface{ Dropped() uint64 }).Dropped())
+
+	s.Nil(sink.Close())
+	s.Len(s.d.inserts()[0].args, len(columns))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/c", nil))
+	s.Equal(logger.ErrClosed, errs[1])
+}
+
+func (s *SQLSinkSuite) TestOverflowBlock() {
+	sink, err := New(s.db, Postgres, "access_logs", WithMaxPending(1),
+		WithOverflowPolicy(logger.OverflowBlock), WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+	defer sink.Close()
+
+	s.serve(sink, "/a")
+
+	served := make(chan struct{})
+	go func() {
+		s.serve(sink, "/b")
+		close(served)
+	}()
+
+	select {
+	case <-served:
+		s.Fail("served while the rows were pending")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.Nil(sink.Flush())
+	<-served
+
+	s.Nil(sink.Flush())
+	s.Len(s.d.inserts(), 2)
+}
+
+func (s *SQLSinkSuite) TestRetries() {
+	s.d.errs = []error{errors.New("connection reset")}
+	sink, err := New(s.db, Postgres, "access_logs", WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+	sink.backoff = time.Millisecond
+
+	s.serve(sink, "/a")
+	s.Nil(sink.Close())
+	s.Len(s.d.inserts(), 1)
+}
+
+func (s *SQLSinkSuite) TestError() {
+	s.d.errs = []error{errors.New("permission denied"), errors.New("permission denied")}
+	sink, err := New(s.db, Postgres, "access_logs", WithRetries(2), WithFlushInterval(time.Hour))
+	s.Require().Nil(err)
+	sink.backoff = time.Millisecond
+	defer sink.Close()
+
+	s.serve(sink, "/a")
+	s.EqualError(sink.Flush(), "sqlsink: insert of 1 rows: permission denied")
+	s.Empty(s.d.inserts())
+}
+
+func TestSQLSink(t *testing.T) {
+	suite.Run(t, new(SQLSinkSuite))
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StatsAggregator limits
+const (
+	// statsWindow is the number of latest requests the percentiles are
+	// computed from
+	statsWindow = 1024
+	// statsMaxPaths bounds the paths tracked for the slow paths
+	statsMaxPaths = 1000
+	// statsTopPaths is the number of slow paths of a Summary
+	statsTopPaths = 10
+)
+
+// StatsAggregator aggregates the requests of the handlers it's given to
+// with WithStats in memory, for quick diagnostics without a metrics stack.
+// It's an http.Handler rendering its Summary as JSON, e.g.
+//
+//	stats := logger.NewStats()
+//	mux.Handle("/debug/requests", stats)
+//	http.ListenAndServe(":8080", logger.New(mux, logger.WithStats(stats)))
+type StatsAggregator struct {
+	mu sync.Mutex
+
+	total    uint64
+	statuses [6]uint64
+
+	// latencies is a ring of the durations of the latest requests
+	latencies []time.Duration
+	next      int
+
+	paths map[string]*PathSummary
+}
+
+// NewStats returns an empty StatsAggregator
+func NewStats() *StatsAggregator {
+	return &StatsAggregator{
+		latencies: make([]time.Duration, 0, statsWindow),
+		paths:     map[string]*PathSummary{},
+	}
+}
+
+// Summary is a snapshot of a StatsAggregator
+type Summary struct {
+	// Total is the number of requests
+	Total uint64 `json:"total"`
+	// Statuses counts the requests by status class, e.g. "2xx"
+	Statuses map[string]uint64 `json:"statuses"`
+	// P50, P95 and P99 are the latency percentiles of the latest 1024
+	// requests
+	P50 time.Duration `json:"-"`
+	P95 time.Duration `json:"-"`
+	P99 time.Duration `json:"-"`
+	// SlowPaths are the 10 paths with the highest mean latency
+	SlowPaths []PathSummary `json:"slow_paths"`
+}
+
+// PathSummary aggregates the requests to a route pattern, or to a path
+// when the router has no pattern
+type PathSummary struct {
+	Path  string        `json:"path"`
+	Count uint64        `json:"count"`
+	Mean  time.Duration `json:"-"`
+	Max   time.Duration `json:"-"`
+
+	sum time.Duration
+}
+
+// MarshalJSON renders the latencies in milliseconds
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type summary Summary
+
+	return json.Marshal(struct {
+		summary
+		Latency map[string]float64 `json:"latency_ms"`
+	}{summary(s), map[string]float64{
+		"p50": milliseconds(s.P50),
+		"p95": milliseconds(s.P95),
+		"p99": milliseconds(s.P99),
+	}})
+}
+
+// MarshalJSON renders the latencies in milliseconds
+func (ps PathSummary) MarshalJSON() ([]byte, error) {
+	type pathSummary PathSummary
+
+	return json.Marshal(struct {
+		pathSummary
+		Mean float64 `json:"mean_ms"`
+		Max  float64 `json:"max_ms"`
+	}{pathSummary(ps), milliseconds(ps.Mean), milliseconds(ps.Max)})
+}
+
+// observe adds the request of rl
+func (sa *StatsAggregator) observe(rl *responseLogger, req *http.Request) {
+	path := rl.route
+	if path == "" {
+		path = req.URL.Path
+	}
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	sa.total++
+
+	if class := rl.status / 100; class >= 1 && class <= 5 {
+		sa.statuses[class]++
+	}
+
+	if len(sa.latencies) < statsWindow {
+		sa.latencies = append(sa.latencies, rl.duration)
+	} else {
+		sa.latencies[sa.next] = rl.duration
+		sa.next = (sa.next + 1) % statsWindow
+	}
+
+	ps, ok := sa.paths[path]
+	if !ok {
+		if len(sa.paths) >= statsMaxPaths {
+			return
+		}
+
+		ps = &PathSummary{Path: path}
+		sa.paths[path] = ps
+	}
+
+	ps.Count++
+	ps.sum += rl.duration
+	ps.Max = max(ps.Max, rl.duration)
+}
+
+// Summary returns the totals, status classes, latency percentiles and
+// slow paths of the requests aggregated so far
+func (sa *StatsAggregator) Summary() Summary {
+	sa.mu.Lock()
+
+	s := Summary{Total: sa.total, Statuses: map[string]uint64{}}
+
+	for class, n := range sa.statuses {
+		if n > 0 {
+			s.Statuses[strconv.Itoa(class)+"xx"] = n
+		}
+	}
+
+	latencies := append([]time.Duration{}, sa.latencies...)
+
+	s.SlowPaths = make([]PathSummary, 0, len(sa.paths))
+	for _, ps := range sa.paths {
+		p := *ps
+		p.Mean = p.sum / time.Duration(p.Count)
+		s.SlowPaths = append(s.SlowPaths, p)
+	}
+
+	sa.mu.Unlock()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s.P50 = percentile(latencies, 50)
+	s.P95 = percentile(latencies, 95)
+	s.P99 = percentile(latencies, 99)
+
+	sort.Slice(s.SlowPaths, func(i, j int) bool {
+		if s.SlowPaths[i].Mean != s.SlowPaths[j].Mean {
+			return s.SlowPaths[i].Mean > s.SlowPaths[j].Mean
+		}
+
+		return s.SlowPaths[i].Path < s.SlowPaths[j].Path
+	})
+	if len(s.SlowPaths) > statsTopPaths {
+		s.SlowPaths = s.SlowPaths[:statsTopPaths]
+	}
+
+	return s
+}
+
+// percentile returns the nearest-rank p-th percentile of the sorted
+// durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+
+	return sorted[max(rank, 1)-1]
+}
+
+// ServeHTTP renders the Summary as JSON
+func (sa *StatsAggregator) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+
+	json.NewEncoder(res).Encode(sa.Summary())
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatsSuite struct {
+	suite.Suite
+}
+
+func (s *StatsSuite) observe(sa *StatsAggregator, path string, status int, d time.Duration) {
+	sa.observe(&responseLogger{status: status, duration: d}, httptest.NewRequest(http.MethodGet, path, nil))
+}
+
+func (s *StatsSuite) TestSummary() {
+	sa := NewStats()
+
+	for i := 1; i <= 100; i++ {
+		s.observe(sa, "/fast", http.StatusOK, time.Duration(i)*time.Millisecond)
+	}
+	s.observe(sa, "/slow", http.StatusInternalServerError, time.Second)
+	s.observe(sa, "/missing", http.StatusNotFound, 0)
+
+	summary := sa.Summary()
+	s.Equal(uint64(102), summary.Total)
+	s.Equal(map[string]uint64{"2xx": 100, "4xx": 1, "5xx": 1}, summary.Statuses)
+	s.Equal(50*time.Millisecond, summary.P50)
+	s.Equal(96*time.Millisecond, summary.P95)
+	s.Equal(100*time.Millisecond, summary.P99)
+
+	s.Len(summary.SlowPaths, 3)
+	s.Equal(PathSummary{Path: "/slow", Count: 1, Mean: time.Second, Max: time.Second, sum: time.Second}, summary.SlowPaths[0])
+	s.Equal("/fast", summary.SlowPaths[1].Path)
+	s.Equal(50500*time.Microsecond, summary.SlowPaths[1].Mean)
+	s.Equal(100*time.Millisecond, summary.SlowPaths[1].Max)
+}
+
+func (s *StatsSuite) TestEmpty() {
+	summary := NewStats().Summary()
+
+	s.Equal(uint64(0), summary.Total)
+	s.Equal(time.Duration(0), summary.P99)
+	s.Empty(summary.SlowPaths)
+}
+
+func (s *StatsSuite) TestWindow() {
+	sa := NewStats()
+
+	for i := 0; i < statsWindow; i++ {
+		s.observe(sa, "/", http.StatusOK, time.Second)
+	}
+	for i := 0; i < statsWindow; i++ {
+		s.observe(sa, "/", http.StatusOK, time.Millisecond)
+	}
+
+	summary := sa.Summary()
+	s.Equal(uint64(2*statsWindow), summary.Total)
+	s.Equal(time.Millisecond, summary.P99)
+}
+
+func (s *StatsSuite) TestPaths() {
+	sa := NewStats()
+
+	for i := 0; i < statsMaxPaths+10; i++ {
+		s.observe(sa, "/"+strconv.Itoa(i), http.StatusOK, time.Duration(i))
+	}
+
+	summary := sa.Summary()
+	s.Len(sa.paths, statsMaxPaths)
+	s.Len(summary.SlowPaths, statsTopPaths)
+	s.Equal("/999", summary.SlowPaths[0].Path)
+}
+
+func (s *StatsSuite) TestHandler() {
+	stats := NewStats()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(res http.ResponseWriter, req *http.Request) {
+		res.Write([]byte("user"))
+	})
+
+	clock := &stepClock{now: time.Unix(0, 0), step: 10 * time.Millisecond}
+	h := New(mux, WithWriter(&testWriter{}), WithClock(clock), WithStats(stats))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+
+	res := httptest.NewRecorder()
+	stats.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+
+	s.Equal("application/json", res.Header().Get("Content-Type"))
+	s.JSONEq(`{
+		"total": 2,
+		"statuses": {"2xx": 2},
+		"latency_ms": {"p50": 20, "p95": 20, "p99": 20},
+		"slow_paths": [{"path": "/users/{id}", "count": 2, "mean_ms": 20, "max_ms": 20}]
+	}`, res.Body.String())
+}
+
+func TestStats(t *testing.T) {
+	suite.Run(t, new(StatsSuite))
+}
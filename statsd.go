@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// statsd emits statsd metrics from the same data as the log output, over
+// UDP
+type statsd struct {
+	addr   string
+	prefix string
+	// dogstatsd tags the metrics, tags being the global tags
+	dogstatsd bool
+	tags      string
+
+	inFlight atomic.Int64
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newStatsd(addr, prefix string, dogstatsd bool, tags []string) *statsd {
+	s := &statsd{addr: addr, prefix: prefix, dogstatsd: dogstatsd}
+
+	sanitized := make([]string, len(tags))
+	for i, tag := range tags {
+		sanitized[i] = statsdTag(tag)
+	}
+	s.tags = strings.Join(sanitized, ",")
+
+	return s
+}
+
+// statsdTagReplacer replaces the characters of the DogStatsD format in the
+// tags
+var statsdTagReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+
+func statsdTag(tag string) string {
+	return statsdTagReplacer.Replace(tag)
+}
+
+func (s *statsd) observe(rl *responseLogger, req *http.Request) {
+	status := rl.status
+	if status == 0 && !rl.hijacked {
+		// the server answers 200 once the handler returns
+		status = http.StatusOK
+	}
+	class := strconv.Itoa(status/100) + "xx"
+
+	var tags, gaugeTags string
+	if s.dogstatsd {
+		request := []string{
+			"method:" + metricMethod(req.Method),
+			"status:" + strconv.Itoa(status),
+			"status_class:" + class,
+		}
+		if rl.route != "" {
+			request = append(request, "route:"+statsdTag(rl.route))
+		}
+
+		if s.tags != "" {
+			request = append([]string{s.tags}, request...)
+			gaugeTags = "|#" + s.tags
+		}
+		tags = "|#" + strings.Join(request, ",")
+	}
+
+	var b []byte
+	b = append(b, s.prefix+"http.requests."+class+":1|c"+tags+"\n"...)
+	b = append(b, s.prefix+"http.request.duration:"...)
+	b = strconv.AppendFloat(b, milliseconds(rl.duration), 'f', 3, 64)
+	b = append(b, "|ms"+tags+"\n"...)
+	// the request itself is over
+	b = append(b, s.prefix+"http.requests.in_flight:"...)
+	b = strconv.AppendInt(b, s.inFlight.Load()-1, 10)
+	b = append(b, "|g"+gaugeTags...)
+
+	s.send(b)
+}
+
+// send sends the packet b, dialing the agent first. Errors are ignored,
+// as statsd clients do, a missing agent must not fail the requests.
+func (s *statsd) send(b []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.Dial("udp", s.addr)
+		if err != nil {
+			return
+		}
+		s.conn = conn
+	}
+
+	s.conn.Write(b)
+}
+
+// Close closes the connection to the agent
+func (s *statsd) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatsdSuite struct {
+	suite.Suite
+
+	conn net.PacketConn
+}
+
+func (s *StatsdSuite) SetupTest() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Require().Nil(err)
+
+	s.conn = conn
+}
+
+func (s *StatsdSuite) TearDownTest() {
+	s.conn.Close()
+}
+
+// packet returns the next packet received by the agent
+func (s *StatsdSuite) packet() string {
+	s.conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	b := make([]byte, 1024)
+	n, _, err := s.conn.ReadFrom(b)
+	s.Require().Nil(err)
+
+	return string(b[:n])
+}
+
+func (s *StatsdSuite) TestStatsd() {
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusServiceUnavailable)
+	}), WithWriter(&testWriter{}), WithStatsd(s.conn.LocalAddr().String(), "app."))
+	defer h.(interface{ Close() error }).Close()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines := strings.Split(s.packet(), "\n")
+	s.Require().Len(lines, 3)
+	s.Equal("app.http.requests.5xx:1|c", lines[0])
+	s.Regexp(regexp.MustCompile(`^app\.http\.request\.duration:\d+\.\d{3}\|ms$`), lines[1])
+	s.Equal("app.http.requests.in_flight:0|g", lines[2])
+}
+
+func (s *StatsdSuite) TestDogStatsd() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/{id}", func(res http.ResponseWriter, req *http.Request) {})
+
+	h := New(mux, WithWriter(&testWriter{}),
+		WithDogStatsd(s.conn.LocalAddr().String(), "", "env:prod", "team:a|b"))
+	defer h.(interface{ Close() error }).Close()
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users/1", nil))
+
+	lines := strings.Split(s.packet(), "\n")
+	s.Require().Len(lines, 3)
+
+	tags := "|#env:prod,team:a_b,method:POST,status:200,status_class:2xx,route:/users/{id}"
+	s.Equal("http.requests.2xx:1|c"+tags, lines[0])
+	s.True(strings.HasSuffix(lines[1], "|ms"+tags))
+	s.Equal("http.requests.in_flight:0|g|#env:prod,team:a_b", lines[2])
+}
+
+func (s *StatsdSuite) TestInFlight() {
+	release := make(chan struct{})
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			<-release
+		}
+	}), WithWriter(&syncWriter{}), WithStatsd(s.conn.LocalAddr().String(), ""))
+	defer h.(interface{ Close() error }).Close()
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+
+	s.Eventually(func() bool {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		return strings.HasSuffix(s.packet(), "in_flight:1|g")
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	<-done
+}
+
+func TestStatsd(t *testing.T) {
+	suite.Run(t, new(StatsdSuite))
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sync"
+)
+
+// statusWriter is the writer of the entries of some status classes
+type statusWriter struct {
+	writer  io.Writer
+	classes []int
+	handler loggerHanlder
+}
+
+// WithStatusWriter writes the entries of the requests answered with a
+// status of classes to w instead of the writer of the handler, the class
+// being the first digit of the status, e.g. WithStatusWriter(w, 5) for the
+// 5xx responses. Several classes given together share w, with their
+// lines serialized. The entries are formatted the same way, with their own
+// buffering and async queue when WithBuffering or WithAsync are set. The
+// first writer given a class wins.
+func WithStatusWriter(w io.Writer, classes ...int) Option {
+	return func(rh *loggerHanlder) {
+		rh.statusWriters = append(rh.statusWriters, statusWriter{writer: w, classes: classes})
+	}
+}
+
+// WithErrorWriter writes the entries of the 4xx and 5xx responses to w,
+// e.g. a separate error log file beside the access log, see
+// WithStatusWriter
+func WithErrorWriter(w io.Writer) Option {
+	return WithStatusWriter(w, 4, 5)
+}
+
+// prepareStatusWriters prepares the handlers of the status writers of rh,
+// rh being configured but not yet prepared. The first len(parent) writers
+// were inherited from the handler of a route override: they write through
+// the writers of parent, which already chain, buffer and write
+// asynchronously.
+func (rh loggerHanlder) prepareStatusWriters(parent []statusWriter) []statusWriter {
+	writers := make([]statusWriter, len(rh.statusWriters))
+
+	for i, sw := range rh.statusWriters {
+		h := rh
+		h.writer = sw.writer
+		h.routes, h.owned, h.statusWriters = nil, nil, nil
+		h.targets, h.outputs = nil, nil
+		h.directives = &sync.Once{}
+
+		if i < len(parent) {
+			h.writer = parent[i].handler.writer
+			h.audit, h.bufferSize, h.asyncSize = false, 0, 0
+		}
+
+		sw.handler = h.prepare()
+		writers[i] = sw
+	}
+
+	return writers
+}
+
+// statusWriter returns the handler writing the entries of status, nil
+// when they go to the writer of rh
+func (rh loggerHanlder) statusWriter(status int) *loggerHanlder {
+	for i, sw := range rh.statusWriters {
+		if slices.Contains(sw.classes, status/100) {
+			return &rh.statusWriters[i].handler
+		}
+	}
+
+	return nil
+}
+
+func (rh loggerHanlder) flushStatusWriters() error {
+	errs := []error{}
+
+	for _, sw := range rh.statusWriters {
+		errs = append(errs, sw.handler.Flush())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (rh loggerHanlder) closeStatusWriters() error {
+	errs := []error{}
+
+	for _, sw := range rh.statusWriters {
+		errs = append(errs, sw.handler.Close())
+	}
+
+	return errors.Join(errs...)
+}
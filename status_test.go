@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusWriterSuite struct {
+	suite.Suite
+}
+
+func (s *StatusWriterSuite) handler(res http.ResponseWriter, req *http.Request) {
+	status := http.StatusOK
+	switch req.URL.Path {
+	case "/missing":
+		status = http.StatusNotFound
+	case "/fail":
+		status = http.StatusInternalServerError
+	case "/moved":
+		status = http.StatusMovedPermanently
+	}
+
+	res.WriteHeader(status)
+}
+
+func (s *StatusWriterSuite) serve(h http.Handler, paths ...string) {
+	for _, path := range paths {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+}
+
+func (s *StatusWriterSuite) TestErrorWriter() {
+	var access, errs bytes.Buffer
+	h := New(http.HandlerFunc(s.handler), WithWriter(&access), WithErrorWriter(&errs),
+		WithFormat(TinyLoggerType), WithClock(testClock{}))
+
+	s.serve(h, "/", "/missing", "/fail", "/moved")
+
+	s.Equal("GET / 200 0 - 0.000 ms\nGET /moved 301 0 - 0.000 ms\n", access.String())
+	s.Equal("GET /missing 404 0 - 0.000 ms\nGET /fail 500 0 - 0.000 ms\n", errs.String())
+}
+
+func (s *StatusWriterSuite) TestClasses() {
+	var access, client, server bytes.Buffer
+	h := New(http.HandlerFunc(s.handler), WithWriter(&access), WithFormat(TinyLoggerType),
+		WithStatusWriter(&server, 5), WithStatusWriter(&client, 4, 5))
+
+	s.serve(h, "/", "/missing", "/fail")
+
+	s.Equal(1, strings.Count(access.String(), "\n"))
+	s.Contains(client.String(), "GET /missing 404")
+	s.NotContains(client.String(), "/fail")
+	s.Contains(server.String(), "GET /fail 500")
+}
+
+func (s *StatusWriterSuite) TestBuffered() {
+	var access, errs bytes.Buffer
+	h := New(http.HandlerFunc(s.handler), WithWriter(&access), WithErrorWriter(&errs),
+		WithFormat(TinyLoggerType), WithBuffering(4096, 0))
+
+	s.serve(h, "/", "/fail")
+	s.Empty(errs.String())
+
+	s.Nil(h.(loggerHanlder).Flush())
+	s.Contains(errs.String(), "GET /fail 500")
+	s.Contains(access.String(), "GET / 200")
+	s.Nil(h.(loggerHanlder).Close())
+}
+
+func (s *StatusWriterSuite) TestRouteOverride() {
+	var access, errs bytes.Buffer
+	h := New(http.HandlerFunc(s.handler), WithWriter(&access), WithErrorWriter(&errs),
+		WithFormat(TinyLoggerType), WithRouteOverride("/missing", WithFormat(ShortLoggerType)))
+
+	s.serve(h, "/missing")
+
+	s.Empty(access.String())
+	s.True(strings.HasPrefix(errs.String(), "192.0.2.1:1234 - GET /missing HTTP/1.1 404"))
+}
+
+func TestStatusWriter(t *testing.T) {
+	suite.Run(t, new(StatusWriterSuite))
+}
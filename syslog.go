@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyslogPriority is a syslog facility combined with a severity, e.g.
+// SyslogLocal0 | SyslogInfo
+type SyslogPriority int
+
+// syslog severities
+const (
+	SyslogEmerg SyslogPriority = iota
+	SyslogAlert
+	SyslogCrit
+	SyslogErr
+	SyslogWarning
+	SyslogNotice
+	SyslogInfo
+	SyslogDebug
+)
+
+// syslog facilities
+const (
+	SyslogKern SyslogPriority = iota << 3
+	SyslogUser
+	SyslogMail
+	SyslogDaemon
+	SyslogAuth
+	SyslogSyslog
+
+	SyslogLocal0 SyslogPriority = (iota + 10) << 3
+	SyslogLocal1
+	SyslogLocal2
+	SyslogLocal3
+	SyslogLocal4
+	SyslogLocal5
+	SyslogLocal6
+	SyslogLocal7
+)
+
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogWriter sends every Write as one RFC 5424 message, reconnecting
+// when the connection fails
+type syslogWriter struct {
+	network  string
+	addr     string
+	tag      string
+	priority SyslogPriority
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// SyslogWriter returns an io.WriteCloser sending each write as a RFC 5424
+// syslog message over network ("udp", "tcp", "unix" or "unixgram") to
+// addr, or to the local syslog daemon when network is empty. Messages are
+// tagged with tag as the APP-NAME. A failed write reconnects and retries
+// once.
+func SyslogWriter(network, addr, tag string, priority SyslogPriority) (io.WriteCloser, error) {
+	sw := newSyslogWriter(network, addr, tag, priority)
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if err := sw.connect(); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+func newSyslogWriter(network, addr, tag string, priority SyslogPriority) *syslogWriter {
+	hostname, _ := os.Hostname()
+
+	if tag == "" {
+		tag = os.Args[0]
+	}
+
+	return &syslogWriter{
+		network:  network,
+		addr:     addr,
+		tag:      syslogHeaderValue(tag, 48),
+		priority: priority,
+		hostname: syslogHeaderValue(hostname, 255),
+	}
+}
+
+func (sw *syslogWriter) connect() error {
+	if sw.conn != nil {
+		sw.conn.Close()
+		sw.conn = nil
+	}
+
+	if sw.network != "" {
+		conn, err := net.Dial(sw.network, sw.addr)
+		if err != nil {
+			return err
+		}
+
+		sw.conn = conn
+
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSyslogSockets {
+			if conn, err := net.Dial(network, path); err == nil {
+				sw.conn = conn
+
+				return nil
+			}
+		}
+	}
+
+	return errors.New("logger: no local syslog daemon found")
+}
+
+func (sw *syslogWriter) Write(p []byte) (int, error) {
+	msg := sw.format(time.Now(), strings.TrimRight(string(p), "\n"))
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.conn != nil {
+		if _, err := sw.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+	}
+
+	if err := sw.connect(); err != nil {
+		return 0, err
+	}
+
+	if _, err := sw.conn.Write(msg); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (sw *syslogWriter) Close() error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.conn == nil {
+		return nil
+	}
+
+	err := sw.conn.Close()
+	sw.conn = nil
+
+	return err
+}
+
+// format returns msg as a RFC 5424 message framed for the network: octet
+// counting for TCP, a trailing newline for other streams and none for
+// datagrams
+func (sw *syslogWriter) format(t time.Time, msg string) []byte {
+	line := fmt.Sprintf("<%d>1 %s %s %s %d - - %s", sw.priority, t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		sw.hostname, sw.tag, os.Getpid(), msg)
+
+	switch sw.network {
+	case "tcp", "tcp4", "tcp6":
+		return []byte(strconv.Itoa(len(line)) + " " + line)
+	case "unix":
+		return []byte(line + "\n")
+	}
+
+	return []byte(line)
+}
+
+// syslogHeaderValue makes s a valid RFC 5424 header field: printable ASCII
+// without spaces, at most max long, "-" if empty
+func syslogHeaderValue(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+
+		return r
+	}, s)
+
+	if len(s) > max {
+		s = s[:max]
+	}
+
+	return orDash(s)
+}
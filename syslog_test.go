@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SyslogSuite struct {
+	suite.Suite
+}
+
+func (s *SyslogSuite) TestPriority() {
+	s.Equal(SyslogPriority(134), SyslogLocal0|SyslogInfo)
+	s.Equal(SyslogPriority(191), SyslogLocal7|SyslogDebug)
+	s.Equal(SyslogPriority(30), SyslogDaemon|SyslogInfo)
+}
+
+func (s *SyslogSuite) TestFormat() {
+	sw := newSyslogWriter("udp", "", "my app", SyslogLocal0|SyslogInfo)
+	sw.hostname = "host"
+
+	msg := sw.format(time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), "GET / 200")
+
+	s.Equal("<134>1 2017-01-02T15:04:05.000000Z host myapp "+strconv.Itoa(os.Getpid())+" - - GET / 200", string(msg))
+
+	sw.network = "tcp"
+	msg = sw.format(time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC), "x")
+	s.Regexp(`^\d+ <134>1 `, string(msg))
+}
+
+func (s *SyslogSuite) TestUDP() {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	s.Nil(err)
+	defer conn.Close()
+
+	w, err := SyslogWriter("udp", conn.LocalAddr().String(), "test", SyslogUser|SyslogNotice)
+	s.Nil(err)
+	defer w.Close()
+
+	h := New(http.NotFoundHandler(), WithWriter(w), WithClock(testClock{}), WithFormat(TinyLoggerType))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	b := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(b)
+	s.Nil(err)
+
+	s.Regexp(regexp.MustCompile(`^<13>1 \S+ \S+ test \d+ - - GET / 404 19 - 0.000 ms$`), string(b[:n]))
+}
+
+func (s *SyslogSuite) TestReconnect() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Nil(err)
+	defer ln.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			b := make([]byte, 1024)
+			n, _ := conn.Read(b)
+			lines <- string(b[:n])
+			conn.Close()
+		}
+	}()
+
+	sw := newSyslogWriter("tcp", ln.Addr().String(), "test", SyslogUser|SyslogInfo)
+	defer sw.Close()
+
+	_, err = sw.Write([]byte("first\n"))
+	s.Nil(err)
+	s.Regexp(`first$`, <-lines)
+
+	// the server closed the connection, the next writes reconnect
+	timeout := time.After(time.Second)
+	for {
+		sw.Write([]byte("second\n"))
+
+		select {
+		case line := <-lines:
+			s.Regexp(`second$`, line)
+			return
+		case <-timeout:
+			s.Fail("no reconnection")
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (s *SyslogSuite) TestDialError() {
+	_, err := SyslogWriter("tcp", "127.0.0.1:1", "test", SyslogUser|SyslogInfo)
+
+	s.NotNil(err)
+}
+
+func (s *SyslogSuite) TestHeaderValue() {
+	s.Equal("-", syslogHeaderValue("", 10))
+	s.Equal("abc", syslogHeaderValue("a b\tc", 10))
+	s.Equal("ab", syslogHeaderValue("abc", 2))
+}
+
+func TestSyslog(t *testing.T) {
+	suite.Run(t, new(SyslogSuite))
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"text/template"
+)
+
+// templateFormatter prints entries with a text/template
+type templateFormatter struct {
+	t *template.Template
+}
+
+// TemplateFormatter returns a Formatter rendering the text/template tmpl
+// against each Entry, e.g. "{{.Method}} {{.URL}} {{.Status}} {{.Duration}}",
+// followed by a newline unless it ends with one. tmpl is parsed once, the
+// error is that of the parse.
+func TemplateFormatter(tmpl string) (Formatter, error) {
+	t, err := template.New("entry").Parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateFormatter{t}, nil
+}
+
+func (tf templateFormatter) Format(w io.Writer, e *Entry) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	b := bytes.NewBuffer(*buf)
+	if err := tf.t.Execute(b, e); err != nil {
+		return err
+	}
+
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+
+	_, err := w.Write(b.Bytes())
+	*buf = b.Bytes()
+
+	return err
+}
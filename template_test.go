@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TemplateSuite struct {
+	suite.Suite
+}
+
+func (s *TemplateSuite) TestFormat() {
+	f, err := TemplateFormatter("{{.Method}} {{.URL}} {{.Status}} {{.Duration}}")
+	s.Nil(err)
+
+	tw := testWriter{}
+	e := &Entry{Method: http.MethodGet, URL: "/a", Status: http.StatusOK, Duration: 2 * time.Millisecond}
+	s.Nil(f.Format(&tw, e))
+	s.Equal("GET /a 200 2ms\n", string(tw.Bytes))
+
+	// no newline is added to a template ending with one
+	f, err = TemplateFormatter("{{.Method}}\n")
+	s.Nil(err)
+
+	tw.Bytes = nil
+	s.Nil(f.Format(&tw, e))
+	s.Equal("GET\n", string(tw.Bytes))
+}
+
+func (s *TemplateSuite) TestHandler() {
+	f, err := TemplateFormatter(`{{.Method}} {{.URL}} {{.Status}} {{index .Fields "user"}}`)
+	s.Nil(err)
+
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormatter(f),
+		WithFields(map[string]interface{}{"user": "bob"}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("GET / 404 bob\n", string(tw.Bytes))
+}
+
+func (s *TemplateSuite) TestErrors() {
+	_, err := TemplateFormatter("{{.Method")
+	s.NotNil(err)
+
+	f, err := TemplateFormatter("{{.Missing}}")
+	s.Nil(err)
+	s.NotNil(f.Format(&testWriter{}, &Entry{}))
+}
+
+func TestTemplate(t *testing.T) {
+	suite.Run(t, new(TemplateSuite))
+}
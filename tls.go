@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"crypto/tls"
+)
+
+// TLSInfo describes the TLS connection of a request
+type TLSInfo struct {
+	Version     string
+	CipherSuite string
+	ServerName  string
+	// ClientSubject is the subject of the client certificate, empty without
+	// mutual TLS
+	ClientSubject string
+}
+
+// tlsInfo returns the details of state, nil for plain HTTP requests
+func tlsInfo(state *tls.ConnectionState) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+
+	if len(state.PeerCertificates) > 0 {
+		info.ClientSubject = state.PeerCertificates[0].Subject.String()
+	}
+
+	return info
+}
+
+// tlsToken returns the token printing the field of the TLS details of the
+// request, "-" for plain HTTP
+func tlsToken(field func(info *TLSInfo) string) token {
+	return stringToken(func(e *Entry) string {
+		if e.TLS == nil {
+			return "-"
+		}
+
+		return orDash(field(e.TLS))
+	})
+}
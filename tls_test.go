@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TLSSuite struct {
+	suite.Suite
+
+	tw  testWriter
+	req *http.Request
+}
+
+func (s *TLSSuite) SetupTest() {
+	s.tw = testWriter{}
+
+	s.req = httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	s.req.TLS = &tls.ConnectionState{
+		Version:     tls.VersionTLS13,
+		CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+		ServerName:  "example.com",
+		PeerCertificates: []*x509.Certificate{
+			{Subject: pkix.Name{CommonName: "client", Organization: []string{"Acme"}}},
+		},
+	}
+}
+
+func (s *TLSSuite) TestTokens() {
+	h := New(noopHandler{}, WithWriter(&s.tw),
+		WithCustomFormat(":tls-version :tls-cipher :tls-sni :tls-client-subject"))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Equal("TLS 1.3 TLS_AES_128_GCM_SHA256 example.com CN=client,O=Acme\n", string(s.tw.Bytes))
+}
+
+func (s *TLSSuite) TestPlainHTTP() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithCustomFormat(":tls-version :tls-cipher :tls-sni"))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.Equal("- - -\n", string(s.tw.Bytes))
+}
+
+func (s *TLSSuite) TestJSON() {
+	h := New(noopHandler{}, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&s.tw)))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.tw.Bytes, &entry))
+	s.Equal("TLS 1.3", entry["tls.version"])
+	s.Equal("TLS_AES_128_GCM_SHA256", entry["tls.cipher"])
+	s.Equal("example.com", entry["tls.sni"])
+	s.Equal("CN=client,O=Acme", entry["tls.client_subject"])
+}
+
+func (s *TLSSuite) TestJSONWithoutClientCert() {
+	s.req.TLS.PeerCertificates = nil
+	h := New(noopHandler{}, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&s.tw)))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(s.tw.Bytes, &entry))
+	s.Equal("TLS 1.3", entry["tls.version"])
+	s.NotContains(entry, "tls.client_subject")
+}
+
+func TestTLS(t *testing.T) {
+	suite.Run(t, new(TLSSuite))
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceContext returns the trace and span IDs of req, read from the active
+// OpenTelemetry span of its context, or the W3C traceparent or B3 headers
+func traceContext(req *http.Request) (traceID, spanID string) {
+	if sc := trace.SpanContextFromContext(req.Context()); sc.IsValid() {
+		return sc.TraceID().String(), sc.SpanID().String()
+	}
+
+	if tp := req.Header.Get("Traceparent"); tp != "" {
+		if traceID, spanID, ok := parseTraceparent(tp); ok {
+			return traceID, spanID
+		}
+	}
+
+	if b3 := req.Header.Get("B3"); b3 != "" {
+		parts := strings.Split(b3, "-")
+		if len(parts) >= 2 && validTraceID(parts[0]) && validSpanID(parts[1]) {
+			return parts[0], parts[1]
+		}
+	}
+
+	// canonical keys, so the lookups don't allocate
+	traceID, spanID = req.Header.Get("X-B3-Traceid"), req.Header.Get("X-B3-Spanid")
+	if validTraceID(traceID) && validSpanID(spanID) {
+		return traceID, spanID
+	}
+
+	return "", ""
+}
+
+// parseTraceparent parses a W3C Trace Context traceparent header:
+// version-traceid-parentid-flags
+func parseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isHex(parts[0]) {
+		return "", "", false
+	}
+
+	if len(parts[1]) != 32 || !validTraceID(parts[1]) || !validSpanID(parts[2]) {
+		return "", "", false
+	}
+
+	return parts[1], parts[2], true
+}
+
+// validTraceID reports if id is a non-zero trace ID of 64 or 128 bits
+func validTraceID(id string) bool {
+	return (len(id) == 16 || len(id) == 32) && isHex(id) && strings.Trim(id, "0") != ""
+}
+
+// validSpanID reports if id is a non-zero 64 bits span ID
+func validSpanID(id string) bool {
+	return len(id) == 16 && isHex(id) && strings.Trim(id, "0") != ""
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TraceSuite struct {
+	suite.Suite
+}
+
+func (s *TraceSuite) request(header ...string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	for i := 0; i < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	return req
+}
+
+func (s *TraceSuite) TestTraceparent() {
+	traceID, spanID := traceContext(s.request("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+	s.Equal("00f067aa0ba902b7", spanID)
+}
+
+func (s *TraceSuite) TestInvalidTraceparent() {
+	for _, header := range []string{
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+		"garbage",
+	} {
+		traceID, _ := traceContext(s.request("Traceparent", header))
+		s.Equal("", traceID, header)
+	}
+}
+
+func (s *TraceSuite) TestB3() {
+	traceID, spanID := traceContext(s.request("B3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"))
+	s.Equal("80f198ee56343ba864fe8b2a57d3eff7", traceID)
+	s.Equal("e457b5a2e4d86bd1", spanID)
+
+	traceID, spanID = traceContext(s.request("X-B3-TraceId", "463ac35c9f6413ad", "X-B3-SpanId", "a2fb4a1d1a96d312"))
+	s.Equal("463ac35c9f6413ad", traceID)
+	s.Equal("a2fb4a1d1a96d312", spanID)
+}
+
+func (s *TraceSuite) TestSpanContext() {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x02},
+	})
+	req := s.request("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req = req.WithContext(trace.ContextWithSpanContext(req.Context(), sc))
+
+	traceID, spanID := traceContext(req)
+
+	s.Equal("01000000000000000000000000000000", traceID)
+	s.Equal("0200000000000000", spanID)
+}
+
+func (s *TraceSuite) TestTokens() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithCustomFormat(":trace-id :span-id"))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.request("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	h.ServeHTTP(httptest.NewRecorder(), s.request())
+
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736 00f067aa0ba902b7\n- -\n", string(tw.Bytes))
+}
+
+func (s *TraceSuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(http.NotFoundHandler(), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.request("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+	s.Equal("00f067aa0ba902b7", entry["span_id"])
+}
+
+func TestTrace(t *testing.T) {
+	suite.Run(t, new(TraceSuite))
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// TrafficClass tells who sent a request, see ClassifyTraffic
+type TrafficClass string
+
+// traffic classes
+const (
+	TrafficHuman   TrafficClass = "human"
+	TrafficBot     TrafficClass = "bot"
+	TrafficScanner TrafficClass = "scanner"
+)
+
+// scannerPaths are the path prefixes probed by vulnerability scanners,
+// matched case insensitively
+var scannerPaths = []string{
+	"/wp-admin",
+	"/wp-login.php",
+	"/xmlrpc.php",
+	"/.env",
+	"/.git/",
+	"/.aws/",
+	"/.ssh/",
+	"/.htaccess",
+	"/phpmyadmin",
+	"/cgi-bin/",
+	"/vendor/phpunit/",
+	"/boaform/",
+}
+
+var scannerRegexp = regexp.MustCompile(`(?i)sqlmap|nikto|nmap|masscan|zgrab|nuclei|wpscan|dirbuster|gobuster|acunetix|nessus`)
+
+// ClassifyTraffic returns TrafficScanner for the requests to the paths
+// probed by vulnerability scanners, such as /wp-admin or /.env, or sent by
+// scanners such as sqlmap or nuclei, TrafficBot for the crawlers and tools
+// recognized by BasicUserAgentParser and TrafficHuman for the rest
+func ClassifyTraffic(req *http.Request) TrafficClass {
+	userAgent := req.UserAgent()
+	path := strings.ToLower(req.URL.Path)
+
+	for _, prefix := range scannerPaths {
+		if strings.HasPrefix(path, prefix) {
+			return TrafficScanner
+		}
+	}
+
+	if scannerRegexp.MatchString(userAgent) {
+		return TrafficScanner
+	}
+
+	if botRegexp.MatchString(userAgent) {
+		return TrafficBot
+	}
+
+	return TrafficHuman
+}
+
+// SampleTraffic returns a Sampler using the rate of the TrafficClass of
+// the request, classes missing from rates are passed to fallback, e.g.
+// SampleTraffic(map[TrafficClass]float64{TrafficBot: 0.1, TrafficScanner: 0}, SampleRate(1))
+func SampleTraffic(rates map[TrafficClass]float64, fallback Sampler) Sampler {
+	samplers := make(map[TrafficClass]Sampler, len(rates))
+	for class, rate := range rates {
+		samplers[class] = SampleRate(rate)
+	}
+
+	return func(req *http.Request, status int) bool {
+		if sampler, ok := samplers[ClassifyTraffic(req)]; ok {
+			return sampler(req, status)
+		}
+
+		return fallback(req, status)
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TrafficSuite struct {
+	suite.Suite
+}
+
+func (s *TrafficSuite) request(path, userAgent string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("User-Agent", userAgent)
+
+	return req
+}
+
+func (s *TrafficSuite) TestClassify() {
+	browser := "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+
+	s.Equal(TrafficHuman, ClassifyTraffic(s.request("/users", browser)))
+	s.Equal(TrafficBot, ClassifyTraffic(s.request("/users", "Mozilla/5.0 (compatible; bingbot/2.0)")))
+	s.Equal(TrafficBot, ClassifyTraffic(s.request("/", "curl/8.4.0")))
+	s.Equal(TrafficScanner, ClassifyTraffic(s.request("/wp-admin/setup.php", browser)))
+	s.Equal(TrafficScanner, ClassifyTraffic(s.request("/.ENV", "Mozilla/5.0 (compatible; bingbot/2.0)")))
+	s.Equal(TrafficScanner, ClassifyTraffic(s.request("/", "sqlmap/1.7")))
+	s.Equal(TrafficHuman, ClassifyTraffic(s.request("/environment", browser)))
+}
+
+func (s *TrafficSuite) TestField() {
+	var b bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&b), WithFormat(GELFLoggerType), WithTrafficClassification())
+
+	h.ServeHTTP(httptest.NewRecorder(), s.request("/.git/config", "Go-http-client/1.1"))
+
+	fields := map[string]interface{}{}
+	s.Nil(json.Unmarshal(b.Bytes(), &fields))
+	s.Equal("scanner", fields["_traffic.class"])
+}
+
+func (s *TrafficSuite) TestSample() {
+	sampler := SampleTraffic(map[TrafficClass]float64{TrafficBot: 0.5, TrafficScanner: 0}, SampleRate(1))
+
+	bots := 0
+	for i := 0; i < 10; i++ {
+		if sampler(s.request("/", "Googlebot/2.1"), http.StatusOK) {
+			bots++
+		}
+
+		s.False(sampler(s.request("/wp-login.php", ""), http.StatusNotFound))
+		s.True(sampler(s.request("/", "Mozilla/5.0"), http.StatusOK))
+	}
+
+	s.Equal(5, bots)
+}
+
+func TestTraffic(t *testing.T) {
+	suite.Run(t, new(TrafficSuite))
+}
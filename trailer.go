@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// contentLength returns the Content-Length declared in the response header
+// h, -1 when there's none
+func contentLength(h http.Header) int64 {
+	declared := h.Get("Content-Length")
+	if declared == "" {
+		return -1
+	}
+
+	n, err := strconv.ParseInt(declared, 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+
+	return n
+}
+
+// trailers returns the trailers set in the response header h, i.e. the
+// ones announced by the Trailer header and those set with
+// http.TrailerPrefix, nil when there are none
+func trailers(h http.Header) http.Header {
+	var t http.Header
+
+	for _, declared := range h.Values("Trailer") {
+		for _, name := range strings.Split(declared, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if values, ok := h[name]; ok {
+				if t == nil {
+					t = http.Header{}
+				}
+				t[name] = values
+			}
+		}
+	}
+
+	for k, values := range h {
+		if name, ok := strings.CutPrefix(k, http.TrailerPrefix); ok {
+			if t == nil {
+				t = http.Header{}
+			}
+			t[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+
+	return t
+}
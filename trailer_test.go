@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TrailerSuite struct {
+	suite.Suite
+}
+
+func (s *TrailerSuite) TestContentLength() {
+	s.Equal(int64(-1), contentLength(http.Header{}))
+	s.Equal(int64(-1), contentLength(http.Header{"Content-Length": {"abc"}}))
+	s.Equal(int64(42), contentLength(http.Header{"Content-Length": {"42"}}))
+}
+
+func (s *TrailerSuite) TestTrailers() {
+	s.Nil(trailers(http.Header{"Content-Type": {"text/plain"}}))
+
+	h := http.Header{
+		"Trailer":                       {"grpc-status, X-Missing"},
+		"Grpc-Status":                   {"0"},
+		http.TrailerPrefix + "checksum": {"abc"},
+	}
+	s.Equal(http.Header{"Grpc-Status": {"0"}, "Checksum": {"abc"}}, trailers(h))
+}
+
+func (s *TrailerSuite) TestHead() {
+	tw := testWriter{}
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Content-Length", "1024")
+		res.WriteHeader(http.StatusOK)
+	}), WithWriter(&tw), WithClock(testClock{}), WithFormat(TinyLoggerType))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodHead, "/", nil))
+
+	s.Equal("HEAD / 200 1024 - 0.000 ms\n", string(tw.Bytes))
+}
+
+func (s *TrailerSuite) TestJSON() {
+	var buf bytes.Buffer
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.Header().Set("Trailer", "X-Checksum")
+		res.Header().Set("Content-Length", "5")
+		res.Write([]byte("hello"))
+		res.Header().Set("X-Checksum", "abc")
+	}), WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&buf)))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+
+	s.Equal("5", entry["response.size"])
+	s.Equal("5", entry["response.content_length"])
+	s.Equal(map[string]interface{}{"X-Checksum": []interface{}{"abc"}}, entry["response.trailer"])
+}
+
+func TestTrailer(t *testing.T) {
+	suite.Run(t, new(TrailerSuite))
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// transport is the http.RoundTripper returned by NewTransport
+type transport struct {
+	base http.RoundTripper
+	rh   loggerHanlder
+}
+
+// Transport returns a http.RoundTripper that sends requests through base,
+// or http.DefaultTransport when nil, and logs them by using t type log
+// output printed to writer
+func Transport(base http.RoundTripper, writer io.Writer, t Type) http.RoundTripper {
+	return NewTransport(base, WithWriter(writer), WithFormat(t))
+}
+
+// NewTransport returns a http.RoundTripper that sends requests through
+// base, or http.DefaultTransport when nil, and logs them like the
+// http.Handler returned by New. The entry is written once the response
+// body is read or closed so its size and duration are known; requests
+// which fail are logged with a 0 status. :remote-addr is the host the
+// request is sent to and :url the full URL.
+func NewTransport(base http.RoundTripper, opts ...Option) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &transport{base: base, rh: New(nil, opts...).(loggerHanlder)}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.rh.skip(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	rl := &responseLogger{clock: t.rh.clock, start: t.rh.clock.Now(), durations: t.rh.durations}
+	if t.rh.correlation {
+		req = propagateCorrelationIDs(req)
+	}
+
+	rl.requestID = req.Header.Get(RequestIDHeader)
+	rl.correlationID = req.Header.Get(CorrelationIDHeader)
+	rl.amznTraceID = req.Header.Get(AmznTraceIDHeader)
+	rl.traceID, rl.spanID = traceContext(req)
+
+	if t.rh.resBodyLimit > 0 {
+		rl.resBody = newResponseCapture(t.rh.resBodyLimit, t.rh.resBodyTypes)
+	}
+
+	logged := req.WithContext(req.Context())
+	logged.RequestURI = req.URL.String()
+	logged.RemoteAddr = req.URL.Host
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		rl.duration = elapsed(t.rh.clock, rl.start)
+		rl.ttfb = rl.duration
+		t.rh.log(rl, logged)
+
+		return res, err
+	}
+
+	rl.status = res.StatusCode
+	rl.header = res.Header
+	rl.firstByte()
+	logged.Proto = res.Proto
+
+	res.Body = &loggedBody{ReadCloser: res.Body, t: t, rl: rl, req: logged, header: res.Header}
+
+	return res, nil
+}
+
+// loggedBody counts the bytes of a response body and logs the request at
+// its end
+type loggedBody struct {
+	io.ReadCloser
+
+	t      *transport
+	rl     *responseLogger
+	req    *http.Request
+	header http.Header
+	once   sync.Once
+}
+
+func (lb *loggedBody) Read(b []byte) (int, error) {
+	n, err := lb.ReadCloser.Read(b)
+
+	lb.rl.size += n
+	if lb.rl.resBody != nil {
+		lb.rl.resBody.capture(lb.header, b[:n])
+	}
+
+	if err == io.EOF {
+		lb.log()
+	}
+
+	return n, err
+}
+
+func (lb *loggedBody) Close() error {
+	err := lb.ReadCloser.Close()
+
+	lb.log()
+
+	return err
+}
+
+func (lb *loggedBody) log() {
+	lb.once.Do(func() {
+		lb.rl.duration = elapsed(lb.t.rh.clock, lb.rl.start)
+		lb.t.rh.log(lb.rl, lb.req)
+	})
+}
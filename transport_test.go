@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TransportSuite struct {
+	suite.Suite
+
+	ts *httptest.Server
+	tw testWriter
+}
+
+func (s *TransportSuite) SetupTest() {
+	s.ts = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		res.WriteHeader(http.StatusCreated)
+		res.Write([]byte("created"))
+	}))
+	s.tw = testWriter{}
+}
+
+func (s *TransportSuite) TearDownTest() {
+	s.ts.Close()
+}
+
+func (s *TransportSuite) TestTransport() {
+	client := &http.Client{Transport: NewTransport(nil, WithWriter(&s.tw), WithClock(testClock{}),
+		WithCustomFormat(":remote-addr :method :url HTTP/:http-version :status :res[content-length] :response-time"))}
+
+	res, err := client.Get(s.ts.URL + "/users?id=1")
+	s.Nil(err)
+	s.Empty(s.tw.Bytes)
+
+	b, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	host := strings.TrimPrefix(s.ts.URL, "http://")
+	s.Equal("created", string(b))
+	s.Equal(host+" GET "+s.ts.URL+"/users?id=1 HTTP/1.1 201 7 0.000\n", string(s.tw.Bytes))
+}
+
+func (s *TransportSuite) TestTypes() {
+	var buf bytes.Buffer
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport, WithFormat(JsonLoggerType),
+		WithLogrusLogger(newTestLogrus(&buf)), WithResponseBodyCapture(64))}
+
+	res, err := client.Get(s.ts.URL)
+	s.Nil(err)
+	io.ReadAll(res.Body)
+	res.Body.Close()
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("201", entry["response.status"])
+	s.Equal("created", entry["response.body"])
+}
+
+func (s *TransportSuite) TestError() {
+	client := &http.Client{Transport: Transport(failingTransport{}, &s.tw, TinyLoggerType)}
+
+	_, err := client.Get("http://example.com/")
+	s.NotNil(err)
+	s.True(strings.HasPrefix(string(s.tw.Bytes), "GET http://example.com/ 0 0 - "))
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTransport(t *testing.T) {
+	suite.Run(t, new(TransportSuite))
+}
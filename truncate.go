@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncatedMarker ends the values cut by WithMaxFieldLength
+const truncatedMarker = "..."
+
+// truncatedFields are the fields WithMaxFieldLength can limit
+var truncatedFields = map[string]bool{"user-agent": true, "referer": true, "query": true, "url": true}
+
+// fieldLimits are the maximum lengths of the logged fields by name
+type fieldLimits map[string]int
+
+// request returns a shallow copy of req in which the limited fields are
+// truncated
+func (fl fieldLimits) request(req *http.Request) *http.Request {
+	truncated := *req
+
+	truncated.Header = req.Header.Clone()
+	for name, field := range map[string]string{"User-Agent": "user-agent", "Referer": "referer"} {
+		if n, ok := fl[field]; ok {
+			if value := truncated.Header.Get(name); value != "" {
+				truncated.Header.Set(name, truncate(value, n))
+			}
+		}
+	}
+
+	if n, ok := fl["query"]; ok {
+		if path, query, found := strings.Cut(truncated.RequestURI, "?"); found {
+			truncated.RequestURI = path + "?" + truncate(query, n)
+		}
+
+		if req.URL != nil {
+			u := *req.URL
+			u.RawQuery = truncate(u.RawQuery, n)
+			truncated.URL = &u
+		}
+	}
+
+	if n, ok := fl["url"]; ok {
+		truncated.RequestURI = truncate(truncated.RequestURI, n)
+	}
+
+	return &truncated
+}
+
+// truncate cuts s to at most n bytes, without splitting a UTF-8 sequence,
+// followed by truncatedMarker
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + truncatedMarker
+}
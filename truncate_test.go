@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TruncateSuite struct {
+	suite.Suite
+
+	req *http.Request
+}
+
+func (s *TruncateSuite) SetupTest() {
+	s.req = httptest.NewRequest(http.MethodGet, "/search?q="+strings.Repeat("a", 20), nil)
+	s.req.Header.Set("User-Agent", strings.Repeat("b", 20))
+	s.req.Header.Set("Referer", "http://example.com/")
+}
+
+func (s *TruncateSuite) TestTruncate() {
+	s.Equal("abc", truncate("abc", 3))
+	s.Equal("ab...", truncate("abc", 2))
+	s.Equal("...", truncate("abc", 0))
+	// multi-byte runes aren't split
+	s.Equal("h...", truncate("hé", 2))
+}
+
+func (s *TruncateSuite) TestRequest() {
+	req := fieldLimits{"user-agent": 5, "referer": 100, "query": 4}.request(s.req)
+
+	s.Equal("/search?q=aa...", req.RequestURI)
+	s.Equal("q=aa...", req.URL.RawQuery)
+	s.Equal("bbbbb...", req.UserAgent())
+	s.Equal("http://example.com/", req.Referer())
+
+	// the request seen by the handler is left untouched
+	s.Equal(strings.Repeat("b", 20), s.req.UserAgent())
+	s.Equal("q="+strings.Repeat("a", 20), s.req.URL.RawQuery)
+}
+
+func (s *TruncateSuite) TestURL() {
+	req := fieldLimits{"url": 8}.request(s.req)
+
+	s.Equal("/search?...", req.RequestURI)
+}
+
+func (s *TruncateSuite) TestText() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithClock(testClock{}), WithFormat(CombineLoggerType),
+		WithMaxFieldLength("User-Agent", 4), WithMaxFieldLength("query", 2))
+
+	h.ServeHTTP(httptest.NewRecorder(), s.req)
+
+	s.Contains(string(tw.Bytes), `"GET /search?q=... HTTP/1.1" 404 19 "http://example.com/" "bbbb..."`)
+}
+
+func (s *TruncateSuite) TestUnknownField() {
+	s.Panics(func() { WithMaxFieldLength("host", 10) })
+}
+
+func (s *TruncateSuite) TestNegativeLength() {
+	s.Panics(func() { WithMaxFieldLength("url", -1) })
+	s.NotPanics(func() { WithMaxFieldLength("url", 0) })
+}
+
+func TestTruncate(t *testing.T) {
+	suite.Run(t, new(TruncateSuite))
+}
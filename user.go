@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// remoteUser returns the user of req: the user info of the URL, the
+// username of the Basic authorization or, with WithJWTSubject, the sub
+// claim of the Bearer token. They're set by the client, so they're
+// escaped, see escapeUser.
+func (rh loggerHanlder) remoteUser(req *http.Request) string {
+	if req.URL.User != nil {
+		return escapeUser(req.URL.User.Username())
+	}
+
+	if user, _, ok := req.BasicAuth(); ok {
+		return escapeUser(user)
+	}
+
+	if rh.jwtSubject {
+		return escapeUser(jwtSubject(req.Header.Get("Authorization")))
+	}
+
+	return ""
+}
+
+// escapeUser escapes the control characters, spaces, quotes and
+// backslashes of user as \xHH, so it can't forge log lines or fields
+func escapeUser(user string) string {
+	i := 0
+	for i < len(user) && !userEscaped(user[i]) {
+		i++
+	}
+	if i == len(user) {
+		return user
+	}
+
+	const hex = "0123456789abcdef"
+
+	b := make([]byte, i, len(user)+8)
+	copy(b, user)
+
+	for ; i < len(user); i++ {
+		c := user[i]
+		if !userEscaped(c) {
+			b = append(b, c)
+
+			continue
+		}
+
+		b = append(b, '\\', 'x', hex[c>>4], hex[c&0xf])
+	}
+
+	return string(b)
+}
+
+// userEscaped reports whether c is escaped by escapeUser
+func userEscaped(c byte) bool {
+	return c < 0x20 || c == 0x7f || c == ' ' || c == '"' || c == '\\'
+}
+
+// jwtSubject returns the sub claim of the JWT of a Bearer authorization,
+// the signature is NOT verified so it's only fit for logging
+func jwtSubject(authorization string) string {
+	const prefix = "Bearer "
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return ""
+	}
+
+	parts := strings.Split(authorization[len(prefix):], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+
+	claims := struct {
+		Sub string `json:"sub"`
+	}{}
+
+	if json.Unmarshal(payload, &claims) != nil {
+		return ""
+	}
+
+	return claims.Sub
+}
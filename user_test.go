@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UserSuite struct {
+	suite.Suite
+
+	tw testWriter
+}
+
+func (s *UserSuite) SetupTest() {
+	s.tw = testWriter{}
+}
+
+func (s *UserSuite) serve(h http.Handler, authorization string) string {
+	s.tw.Bytes = nil
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	return string(s.tw.Bytes)
+}
+
+func jwt(payload string) string {
+	return "Bearer eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".c2ln"
+}
+
+func (s *UserSuite) TestBasicAuth() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithCustomFormat(":remote-user"))
+
+	s.Equal("alice\n", s.serve(h, "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:secret"))))
+	s.Equal("-\n", s.serve(h, ""))
+	s.Equal("-\n", s.serve(h, jwt(`{"sub":"bob"}`)))
+}
+
+func (s *UserSuite) TestBasicAuthRedacted() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithCustomFormat(":remote-user"), WithRedactedHeaders("Authorization"))
+
+	s.Equal("alice\n", s.serve(h, "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:secret"))))
+}
+
+func (s *UserSuite) TestJWTSubject() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithCustomFormat(":remote-user"), WithJWTSubject(true))
+
+	s.Equal("bob\n", s.serve(h, jwt(`{"sub":"bob","exp":1}`)))
+	s.Equal("bob\n", s.serve(h, "bearer"+jwt(`{"sub":"bob"}`)[6:]))
+	s.Equal("-\n", s.serve(h, jwt(`{"exp":1}`)))
+	s.Equal("-\n", s.serve(h, jwt(`not json`)))
+	s.Equal("-\n", s.serve(h, "Bearer opaque-token"))
+	s.Equal("alice\n", s.serve(h, "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:secret"))))
+}
+
+func (s *UserSuite) TestEscaped() {
+	h := New(noopHandler{}, WithWriter(&s.tw), WithJWTSubject(true))
+
+	forged := "bob\n10.0.0.1 - admin [02/Jan/2017:15:04:05 +0000] \"GET /admin HTTP/1.1\" 200 0 \"\" \"\""
+	line := s.serve(h, "Basic "+base64.StdEncoding.EncodeToString([]byte(forged+":secret")))
+
+	s.Equal(1, strings.Count(line, "\n"))
+	s.Contains(line, ` - bob\x0a10.0.0.1\x20-\x20admin\x20[`)
+
+	h = New(noopHandler{}, WithWriter(&s.tw), WithCustomFormat(":remote-user"), WithJWTSubject(true))
+	s.Equal(`a\x22b\x5cc\x0d`+"\n", s.serve(h, jwt(`{"sub":"a\"b\\c\r"}`)))
+}
+
+func (s *UserSuite) TestJSON() {
+	h := New(noopHandler{}, WithFormat(JsonLoggerType), WithLogrusLogger(newTestLogrus(&s.tw)), WithJWTSubject(true))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(s.serve(h, jwt(`{"sub":"bob"}`))), &entry))
+	s.Equal("bob", entry["request.user"])
+
+	entry = map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(s.serve(h, "")), &entry))
+	s.NotContains(entry, "request.user")
+}
+
+func TestUser(t *testing.T) {
+	suite.Run(t, new(UserSuite))
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+// UserAgent is a User-Agent header split into fields, those unknown to the
+// parser are left empty
+type UserAgent struct {
+	// Browser is the browser, or the name of the bot or tool, e.g. "Chrome",
+	// "Googlebot" or "curl"
+	Browser string
+	Version string
+	OS      string
+	// Bot is set for crawlers, monitoring and command line tools
+	Bot bool
+}
+
+// UserAgentParser parses User-Agent headers, see WithUserAgentParser
+type UserAgentParser interface {
+	Parse(userAgent string) UserAgent
+}
+
+// UserAgentParserFunc is a function used as a UserAgentParser
+type UserAgentParserFunc func(userAgent string) UserAgent
+
+// Parse calls f(userAgent)
+func (f UserAgentParserFunc) Parse(userAgent string) UserAgent {
+	return f(userAgent)
+}
+
+// BasicUserAgentParser recognizes the major browsers, operating systems,
+// crawlers and command line tools by the tokens of their User-Agent. Use
+// a dedicated library behind a UserAgentParser for more accuracy.
+var BasicUserAgentParser UserAgentParser = UserAgentParserFunc(parseUserAgent)
+
+var (
+	botRegexp = regexp.MustCompile(`(?i)([\w.-]*(?:bot|crawler|spider|slurp|curl|wget|python-requests|go-http-client|httpclient|kube-probe))(?:/([\w.]+))?`)
+
+	// browsers are matched in order, the tokens of the browsers built on
+	// Chrome or Safari come first
+	browsers = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/([\d.]+)`)},
+		{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+		{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+		{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+		{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+		{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+		{"Internet Explorer", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)([\d.]+)`)},
+	}
+
+	systems = []struct {
+		name  string
+		token string
+	}{
+		{"Windows", "Windows"},
+		{"iOS", "iPhone"},
+		{"iOS", "iPad"},
+		{"Android", "Android"},
+		{"ChromeOS", "CrOS"},
+		{"macOS", "Mac OS X"},
+		{"Linux", "Linux"},
+	}
+)
+
+func parseUserAgent(userAgent string) UserAgent {
+	ua := UserAgent{}
+
+	for _, system := range systems {
+		if strings.Contains(userAgent, system.token) {
+			ua.OS = system.name
+
+			break
+		}
+	}
+
+	if m := botRegexp.FindStringSubmatch(userAgent); m != nil {
+		ua.Browser, ua.Version, ua.Bot = m[1], m[2], true
+
+		return ua
+	}
+
+	for _, browser := range browsers {
+		if m := browser.re.FindStringSubmatch(userAgent); m != nil {
+			ua.Browser, ua.Version = browser.name, m[1]
+
+			break
+		}
+	}
+
+	return ua
+}
+
+// userAgentHook returns the hook adding the user_agent.browser,
+// user_agent.version, user_agent.os and user_agent.bot fields
+func userAgentHook(parser UserAgentParser) func(*Entry) {
+	return func(e *Entry) {
+		if e.UserAgent == "" {
+			return
+		}
+
+		ua := parser.Parse(e.UserAgent)
+
+		if ua.Browser != "" {
+			e.Fields["user_agent.browser"] = ua.Browser
+		}
+
+		if ua.Version != "" {
+			e.Fields["user_agent.version"] = ua.Version
+		}
+
+		if ua.OS != "" {
+			e.Fields["user_agent.os"] = ua.OS
+		}
+
+		e.Fields["user_agent.bot"] = ua.Bot
+	}
+}
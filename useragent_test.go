@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type UserAgentSuite struct {
+	suite.Suite
+}
+
+func (s *UserAgentSuite) TestBasic() {
+	for userAgent, expected := range map[string]UserAgent{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36": {
+			Browser: "Chrome", Version: "120.0.0.0", OS: "Windows"},
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91": {
+			Browser: "Edge", Version: "120.0.2210.91", OS: "Windows"},
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0": {
+			Browser: "Firefox", Version: "121.0", OS: "macOS"},
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1": {
+			Browser: "Safari", Version: "17.2", OS: "iOS"},
+		"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36": {
+			Browser: "Samsung Internet", Version: "23.0", OS: "Android"},
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0": {
+			Browser: "Opera", Version: "106.0.0.0", OS: "Linux"},
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)": {
+			Browser: "Googlebot", Version: "2.1", Bot: true},
+		"curl/8.4.0":         {Browser: "curl", Version: "8.4.0", Bot: true},
+		"kube-probe/1.29":    {Browser: "kube-probe", Version: "1.29", Bot: true},
+		"Go-http-client/2.0": {Browser: "Go-http-client", Version: "2.0", Bot: true},
+		"something else":     {},
+	} {
+		s.Equal(expected, BasicUserAgentParser.Parse(userAgent), userAgent)
+	}
+}
+
+func (s *UserAgentSuite) log(parser UserAgentParser, userAgent string) map[string]interface{} {
+	var b bytes.Buffer
+	h := New(http.NotFoundHandler(), WithWriter(&b), WithFormat(GELFLoggerType), WithUserAgentParser(parser))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	fields := map[string]interface{}{}
+	s.Nil(json.Unmarshal(b.Bytes(), &fields))
+
+	return fields
+}
+
+func (s *UserAgentSuite) TestFields() {
+	fields := s.log(BasicUserAgentParser, "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0")
+
+	s.Equal("Firefox", fields["_user_agent.browser"])
+	s.Equal("121.0", fields["_user_agent.version"])
+	s.Equal("macOS", fields["_user_agent.os"])
+	s.Equal(false, fields["_user_agent.bot"])
+
+	fields = s.log(BasicUserAgentParser, "")
+	s.NotContains(fields, "_user_agent.bot")
+}
+
+func (s *UserAgentSuite) TestCustomParser() {
+	fields := s.log(UserAgentParserFunc(func(userAgent string) UserAgent {
+		return UserAgent{Browser: "custom:" + userAgent, Bot: true}
+	}), "agent")
+
+	s.Equal("custom:agent", fields["_user_agent.browser"])
+	s.Equal(true, fields["_user_agent.bot"])
+	s.NotContains(fields, "_user_agent.os")
+}
+
+func TestUserAgent(t *testing.T) {
+	suite.Run(t, new(UserAgentSuite))
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const w3cFields = "date time c-ip cs-username cs-method cs-uri-stem cs-uri-query sc-status sc-bytes time-taken cs-version cs(User-Agent) cs(Referer)"
+
+// w3cFormatter prints entries in the W3C extended log file format, with
+// the directives written before the first one
+type w3cFormatter struct {
+	directives *sync.Once
+	clock      Clock
+}
+
+func (wf w3cFormatter) Format(w io.Writer, e *Entry) error {
+	var b strings.Builder
+
+	wf.writeDirectives(&b)
+
+	start := e.Start.UTC()
+	clientIP, _, err := net.SplitHostPort(e.RemoteAddr)
+	if err != nil {
+		clientIP = e.RemoteAddr
+	}
+
+	path, query, _ := strings.Cut(e.URL, "?")
+
+	b.WriteString(strings.Join([]string{
+		start.Format("2006-01-02"),
+		start.Format("15:04:05"),
+		w3cValue(clientIP),
+		w3cValue(e.RemoteUser),
+		w3cValue(e.Method),
+		w3cValue(path),
+		w3cValue(query),
+		strconv.Itoa(e.Status),
+		strconv.Itoa(e.Size),
+		strconv.FormatInt(e.Duration.Milliseconds(), 10),
+		w3cValue(e.Proto),
+		w3cValue(e.UserAgent),
+		w3cValue(e.Referer),
+	}, " "))
+	b.WriteByte('\n')
+
+	_, err = io.WriteString(w, b.String())
+
+	return err
+}
+
+// writeDirectives writes the directives to b unless they were already
+func (wf w3cFormatter) writeDirectives(b *strings.Builder) {
+	wf.directives.Do(func() {
+		fmt.Fprintf(b, "#Version: 1.0\n#Date: %s\n#Fields: %s\n",
+			wf.clock.Now().UTC().Format("2006-01-02 15:04:05"), w3cFields)
+	})
+}
+
+// remark writes msg as a #Remark directive, after the directives of the
+// first entry when none was logged yet
+func (wf w3cFormatter) remark(w io.Writer, msg string) error {
+	var b strings.Builder
+
+	wf.writeDirectives(&b)
+
+	b.WriteString("#Remark: " + strings.NewReplacer("\n", " ", "\r", " ").Replace(msg) + "\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// w3cValue makes s a single W3C field: spaces are replaced with "+" as IIS
+// does and empty values with "-"
+func w3cValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ':
+			return '+'
+		case '\n', '\r', '\t':
+			return -1
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type W3CSuite struct {
+	suite.Suite
+}
+
+func (s *W3CSuite) TestW3C() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(W3CLoggerType),
+		WithClock(testClock{time.Date(2017, time.January, 2, 15, 4, 5, 0, time.UTC)}))
+
+	req := httptest.NewRequest(http.MethodGet, "/search?q=go+logger", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux)")
+
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodHead, "/", nil))
+
+	s.Equal("#Version: 1.0\n"+
+		"#Date: 2017-01-02 15:04:05\n"+
+		"#Fields: "+w3cFields+"\n"+
+		"2017-01-02 15:04:05 192.0.2.1 - GET /search q=go+logger 404 19 0 HTTP/1.1 Mozilla/5.0+(X11;+Linux) -\n"+
+		"2017-01-02 15:04:05 192.0.2.1 - HEAD / - 404 19 0 HTTP/1.1 - -\n", string(tw.Bytes))
+}
+
+func (s *W3CSuite) TestValue() {
+	s.Equal("-", w3cValue(""))
+	s.Equal("a+b", w3cValue("a b"))
+	s.Equal("ab", w3cValue("a\nb"))
+}
+
+func TestW3C(t *testing.T) {
+	suite.Run(t, new(W3CSuite))
+}
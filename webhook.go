@@ -0,0 +1,299 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Webhook batching and retry defaults
+const (
+	webhookBatchSize     = 500
+	webhookMaxPending    = 10000
+	webhookFlushInterval = 5 * time.Second
+	webhookRetries       = 5
+	webhookMinBackoff    = 500 * time.Millisecond
+	webhookMaxBackoff    = 30 * time.Second
+)
+
+// WebhookWriter is an io.WriteCloser POSTing the entries written to URL
+// as NDJSON batches, one entry per line, usable as the writer of any
+// handler, e.g. with NDJSONLoggerType. Batches are sent in the background
+// every FlushInterval or BatchSize entries, and retried with an
+// exponential backoff on network errors, 429 and 5xx responses. Close
+// sends the last batch, call it on shutdown.
+type WebhookWriter struct {
+	// URL is the endpoint the batches are POSTed to
+	URL string
+	// Header is sent with every batch, e.g. an Authorization header
+	Header http.Header
+	// Gzip compresses the batches, sent with Content-Encoding: gzip
+	Gzip bool
+	// BatchSize is the maximum number of entries sent at once, and the
+	// number of pending entries sending them right away, default to 500
+	BatchSize int
+	// MaxPending is the number of entries waiting to be sent past which
+	// the entries written are dropped with ErrQueueFull, and counted by the
+	// Dropped method of the handler, default to 10000
+	MaxPending int
+	// FlushInterval is how often the pending entries are sent, default to
+	// 5 seconds
+	FlushInterval time.Duration
+	// Retries is the number of times a batch is sent again before it's
+	// given up on, default to 5, -1 disables them
+	Retries int
+	// DeadLetter is the file the batches which could not be delivered are
+	// appended to, uncompressed, so they can be replayed. They're dropped
+	// when it's empty.
+	DeadLetter string
+	// Client sends the batches, default to a client with a 10 seconds
+	// timeout
+	Client *http.Client
+	// ErrorHandler is called with the errors of the batches sent in the
+	// background, by default they are dropped silently
+	ErrorHandler func(error)
+
+	start   sync.Once
+	backoff time.Duration
+
+	mu      sync.Mutex
+	pending []byte
+	// ends are the offsets of the end of the pending entries
+	ends   []int
+	closed bool
+
+	// sending serializes the batches
+	sending sync.Mutex
+
+	wake chan struct{}
+	done chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+// init applies the defaults and starts the background sends
+func (w *WebhookWriter) init() {
+	if w.BatchSize <= 0 {
+		w.BatchSize = webhookBatchSize
+	}
+
+	if w.MaxPending <= 0 {
+		w.MaxPending = webhookMaxPending
+	}
+
+	if w.FlushInterval <= 0 {
+		w.FlushInterval = webhookFlushInterval
+	}
+
+	if w.Retries == 0 {
+		w.Retries = webhookRetries
+	}
+
+	if w.Client == nil {
+		w.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if w.backoff == 0 {
+		w.backoff = webhookMinBackoff
+	}
+
+	w.wake = make(chan struct{}, 1)
+	w.done = make(chan struct{})
+
+	w.wg.Add(1)
+	go w.run()
+}
+
+func (w *WebhookWriter) run() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+
+		if err := w.Flush(); err != nil && w.ErrorHandler != nil {
+			w.ErrorHandler(err)
+		}
+	}
+}
+
+func (w *WebhookWriter) Write(p []byte) (int, error) {
+	w.start.Do(w.init)
+
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+
+		return 0, ErrClosed
+	}
+
+	if len(w.ends) >= w.MaxPending {
+		w.mu.Unlock()
+
+		return 0, ErrQueueFull
+	}
+
+	w.pending = append(w.pending, p...)
+	if !bytes.HasSuffix(p, []byte("\n")) {
+		w.pending = append(w.pending, '\n')
+	}
+	w.ends = append(w.ends, len(w.pending))
+	full := len(w.ends) >= w.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the pending entries in batches of BatchSize, appending those
+// which can't be delivered to DeadLetter
+func (w *WebhookWriter) Flush() error {
+	w.start.Do(w.init)
+
+	w.sending.Lock()
+	defer w.sending.Unlock()
+
+	w.mu.Lock()
+	pending, ends := w.pending, w.ends
+	w.pending, w.ends = nil, nil
+	w.mu.Unlock()
+
+	errs := []error{}
+
+	for start := 0; len(ends) > 0; {
+		n := min(w.BatchSize, len(ends))
+		batch := pending[start:ends[n-1]]
+		start, ends = ends[n-1], ends[n:]
+
+		err := w.send(batch)
+		if err != nil && w.DeadLetter != "" {
+			err = errors.Join(err, w.deadLetter(batch))
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send posts batch, retrying with an exponential backoff when the
+// endpoint is unreachable, rate limits or fails
+func (w *WebhookWriter) send(batch []byte) error {
+	body := batch
+	if w.Gzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(batch)
+		gz.Close()
+
+		body = buf.Bytes()
+	}
+
+	backoff := w.backoff
+
+	var err error
+	for attempt := 0; attempt <= max(w.Retries, 0); attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff = min(2*backoff, webhookMaxBackoff)
+		}
+
+		var retry bool
+		retry, err = w.post(body)
+		if !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// post posts body once and reports whether it's worth sending again
+func (w *WebhookWriter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	for name, values := range w.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	if w.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	res, err := w.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	if res.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("logger: webhook failed with status %d", res.StatusCode)
+
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500, err
+}
+
+// deadLetter appends batch to DeadLetter
+func (w *WebhookWriter) deadLetter(batch []byte) error {
+	f, err := os.OpenFile(w.DeadLetter, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(batch); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// Close stops the background sends and sends the pending entries, later
+// writes fail with ErrClosed
+func (w *WebhookWriter) Close() error {
+	w.start.Do(w.init)
+
+	var err error
+
+	w.once.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+
+		close(w.done)
+		w.wg.Wait()
+
+		err = w.Flush()
+	})
+
+	return err
+}
@@ -0,0 +1,203 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookSuite struct {
+	suite.Suite
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	statuses []int
+	server   *httptest.Server
+}
+
+func (s *WebhookSuite) SetupTest() {
+	s.requests, s.bodies, s.statuses = nil, nil, nil
+	s.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var body io.Reader = req.Body
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(req.Body)
+			if !s.Nil(err) {
+				return
+			}
+			body = gz
+		}
+
+		b, _ := io.ReadAll(body)
+		s.requests = append(s.requests, req)
+		s.bodies = append(s.bodies, string(b))
+
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			res.WriteHeader(status)
+
+			return
+		}
+
+		res.WriteHeader(http.StatusAccepted)
+	}))
+}
+
+func (s *WebhookSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *WebhookSuite) TestBatch() {
+	w := &WebhookWriter{
+		URL:    s.server.URL,
+		Header: http.Header{"Authorization": {"Bearer token"}},
+	}
+
+	h := NewLogger(http.NotFoundHandler(), WithWriter(w), WithCustomFormat(":method :url"))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+	s.Nil(h.Flush())
+
+	s.Require().Len(s.requests, 1)
+	s.Equal(http.MethodPost, s.requests[0].Method)
+	s.Equal("Bearer token", s.requests[0].Header.Get("Authorization"))
+	s.Equal("application/x-ndjson", s.requests[0].Header.Get("Content-Type"))
+	s.Empty(s.requests[0].Header.Get("Content-Encoding"))
+	s.Equal("GET /a\nGET /b\n", s.bodies[0])
+
+	s.Nil(w.Close())
+	_, err := w.Write([]byte("GET /c\n"))
+	s.Equal(ErrClosed, err)
+}
+
+func (s *WebhookSuite) TestGzip() {
+	w := &WebhookWriter{URL: s.server.URL, Gzip: true}
+	w.Write([]byte(`{"request.url":"/"}`))
+	s.Nil(w.Close())
+
+	s.Require().Len(s.requests, 1)
+	s.Equal("gzip", s.requests[0].Header.Get("Content-Encoding"))
+	s.Equal("{\"request.url\":\"/\"}\n", s.bodies[0])
+}
+
+func (s *WebhookSuite) TestBatchSize() {
+	w := &WebhookWriter{URL: s.server.URL, BatchSize: 2, FlushInterval: time.Hour}
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+
+	s.Eventually(func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return len(s.bodies) == 1
+	}, time.Second, 10*time.Millisecond)
+	s.Equal("a\nb\n", s.bodies[0])
+}
+
+func (s *WebhookSuite) TestBatches() {
+	w := &WebhookWriter{URL: s.server.URL, BatchSize: 2, FlushInterval: time.Hour}
+
+	for _, entry := range []string{"a\n", "b\n", "c\n", "d\n", "e\n"} {
+		w.Write([]byte(entry))
+	}
+	s.Nil(w.Close())
+
+	// some batches may be sent in the background once full
+	for _, body := range s.bodies {
+		s.LessOrEqual(strings.Count(body, "\n"), 2)
+	}
+	s.Equal("a\nb\nc\nd\ne\n", strings.Join(s.bodies, ""))
+}
+
+func (s *WebhookSuite) TestErrorHandler() {
+	s.statuses = []int{http.StatusBadRequest}
+	errs := make(chan error, 1)
+	w := &WebhookWriter{URL: s.server.URL, FlushInterval: 10 * time.Millisecond,
+		ErrorHandler: func(err error) { errs <- err }}
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+
+	select {
+	case err := <-errs:
+		s.EqualError(err, "logger: webhook failed with status 400")
+	case <-time.After(time.Second):
+		s.Fail("no error")
+	}
+}
+
+func (s *WebhookSuite) TestMaxPending() {
+	w := &WebhookWriter{URL: s.server.URL, MaxPending: 1, FlushInterval: time.Hour}
+
+	var errs []error
+	h := New(http.NotFoundHandler(), WithWriter(w), WithCustomFormat(":url"),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/b", nil))
+
+	s.Equal([]error{ErrQueueFull}, errs)
+	s.Equal(uint64(1), h.(interface{ Dropped() uint64 }).Dropped())
+
+	s.Nil(w.Close())
+	s.Equal([]string{"/a\n"}, s.bodies)
+}
+
+func (s *WebhookSuite) TestRetries() {
+	s.statuses = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	w := &WebhookWriter{URL: s.server.URL, backoff: time.Millisecond}
+
+	w.Write([]byte("a\n"))
+	s.Nil(w.Close())
+
+	s.Len(s.bodies, 3)
+	s.Equal("a\n", s.bodies[2])
+}
+
+func (s *WebhookSuite) TestDeadLetter() {
+	dead := filepath.Join(s.T().TempDir(), "webhook.ndjson")
+	s.statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadRequest}
+	w := &WebhookWriter{URL: s.server.URL, Retries: 1, DeadLetter: dead, backoff: time.Millisecond}
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	s.EqualError(w.Flush(), "logger: webhook failed with status 502")
+	s.Len(s.bodies, 2)
+
+	// client errors aren't retried
+	w.Write([]byte("b\n"))
+	s.EqualError(w.Flush(), "logger: webhook failed with status 400")
+	s.Len(s.bodies, 3)
+
+	b, err := os.ReadFile(dead)
+	s.Nil(err)
+	s.Equal("a\nb\n", string(b))
+}
+
+func (s *WebhookSuite) TestNoRetries() {
+	s.statuses = []int{http.StatusServiceUnavailable}
+	w := &WebhookWriter{URL: s.server.URL, Retries: -1}
+	defer w.Close()
+
+	w.Write([]byte("a\n"))
+	s.NotNil(w.Flush())
+	s.Len(s.bodies, 1)
+}
+
+func TestWebhook(t *testing.T) {
+	suite.Run(t, new(WebhookSuite))
+}
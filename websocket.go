@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebSocketSession is the WebSocket session of an upgraded request, see
+// WithWebSocketSessions
+type WebSocketSession struct {
+	// Closed is false on the entry logged at the upgrade, true on the one
+	// logged once the connection is closed
+	Closed bool
+	// Duration is the time from the upgrade to the close of the connection
+	Duration time.Duration
+	// FramesIn and BytesIn are read from the client, FramesOut and
+	// BytesOut written to it, control frames included and the handshake
+	// left out
+	FramesIn  int64
+	FramesOut int64
+	BytesIn   int64
+	BytesOut  int64
+	// CloseCode is the status code of the first close frame sent by either
+	// side, 0 when there was none or it had no code
+	CloseCode int
+}
+
+// state returns the state of the session when it was logged, open or
+// closed
+func (ws *WebSocketSession) state() string {
+	if ws.Closed {
+		return "closed"
+	}
+
+	return "open"
+}
+
+// wsSession tracks the connection of an upgraded request, logged once it's
+// closed and the handler returned, whichever comes last
+type wsSession struct {
+	rh  loggerHanlder
+	rl  *responseLogger
+	req *http.Request
+
+	start time.Time
+	// logged is set when the entry of the upgrade was logged, the one of
+	// the close then always is
+	logged bool
+
+	in, out   wsFrames
+	closeCode atomic.Int64
+
+	mu       sync.Mutex
+	returned bool
+	closed   bool
+	end      time.Time
+}
+
+// isWebSocketUpgrade reports whether req asks for a WebSocket upgrade
+func isWebSocketUpgrade(req *http.Request) bool {
+	return strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		hasToken(req.Header.Values("Connection"), "upgrade")
+}
+
+// hasToken reports whether the comma-separated values contain token
+func hasToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// startWebSocket logs the upgrade of the request of rl, whose connection
+// was just hijacked, and returns the connection counting its frames
+func (rh loggerHanlder) startWebSocket(rl *responseLogger, req *http.Request, conn net.Conn, brw *bufio.ReadWriter) (net.Conn, *bufio.ReadWriter) {
+	s := &wsSession{rh: rh, rl: rl, req: req, start: rh.clock.Now()}
+	s.in.closeCode = &s.closeCode
+	s.out.closeCode = &s.closeCode
+	// unless the 101 was sent through the ResponseWriter, the handler
+	// writes it on the connection
+	s.out.handshake = rl.status != http.StatusSwitchingProtocols
+
+	rl.session = s
+	rl.status = http.StatusSwitchingProtocols
+	rl.duration = elapsed(rh.clock, rl.start)
+	rl.route = rh.route(req)
+	s.logged = rh.log(rl, req)
+
+	wc := &wsConn{Conn: conn, session: s}
+
+	// what the server read ahead isn't read from the connection again
+	var r io.Reader = wc
+	if n := brw.Reader.Buffered(); n > 0 {
+		buffered, _ := brw.Reader.Peek(n)
+		buffered = bytes.Clone(buffered)
+		s.in.count(buffered)
+
+		r = io.MultiReader(bytes.NewReader(buffered), wc)
+	}
+
+	return wc, bufio.NewReadWriter(bufio.NewReader(r), bufio.NewWriter(wc))
+}
+
+// handlerReturned is called once the handler of the session returned
+func (s *wsSession) handlerReturned() {
+	s.mu.Lock()
+	s.returned = true
+	closed := s.closed
+	s.mu.Unlock()
+
+	if closed {
+		s.log()
+	}
+}
+
+// close is called once the connection of the session is closed
+func (s *wsSession) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.closed = true
+	s.end = s.rh.clock.Now()
+	returned := s.returned
+	s.mu.Unlock()
+
+	if returned {
+		s.log()
+	}
+}
+
+// log writes the entry of the end of the session and releases its
+// responseLogger
+func (s *wsSession) log() {
+	rl := s.rl
+	rl.duration = elapsed(s.rh.clock, rl.start)
+
+	if s.logged {
+		s.rh.write(rl, s.req)
+	} else {
+		s.rh.log(rl, s.req)
+	}
+
+	responseLoggers.Put(rl)
+}
+
+// info returns the session as it's logged
+func (s *wsSession) info() *WebSocketSession {
+	info := &WebSocketSession{
+		FramesIn:  s.in.frames.Load(),
+		FramesOut: s.out.frames.Load(),
+		BytesIn:   s.in.bytes.Load(),
+		BytesOut:  s.out.bytes.Load(),
+		CloseCode: int(s.closeCode.Load()),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		info.Closed = true
+		info.Duration = s.end.Sub(s.start)
+	}
+
+	return info
+}
+
+// wsConn is the hijacked connection of a WebSocket session, counting the
+// frames read and written
+type wsConn struct {
+	net.Conn
+
+	session *wsSession
+}
+
+func (c *wsConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.session.in.count(b[:n])
+
+	return n, err
+}
+
+func (c *wsConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.session.out.count(b[:n])
+
+	return n, err
+}
+
+func (c *wsConn) Close() error {
+	err := c.Conn.Close()
+	c.session.close()
+
+	return err
+}
+
+// wsOpClose is the opcode of close frames
+const wsOpClose = 0x8
+
+// wsFrames parses the frames of one direction of a WebSocket connection
+// as they go through, only their headers and the code of close frames
+type wsFrames struct {
+	frames atomic.Int64
+	bytes  atomic.Int64
+
+	closeCode *atomic.Int64
+
+	// handshake is set while the HTTP response is written, crlf counting
+	// the bytes of the "\r\n\r\n" ending it matched so far
+	handshake bool
+	crlf      int
+
+	header    []byte
+	remaining uint64
+	// closing is set while the payload of a close frame is read, its code
+	// being collected in code
+	closing bool
+	masked  bool
+	mask    [4]byte
+	offset  uint64
+	code    []byte
+}
+
+func (f *wsFrames) count(b []byte) {
+	for f.handshake && len(b) > 0 {
+		switch {
+		case b[0] == "\r\n\r\n"[f.crlf]:
+			f.crlf++
+		case b[0] == '\r':
+			f.crlf = 1
+		default:
+			f.crlf = 0
+		}
+
+		b = b[1:]
+		f.handshake = f.crlf < 4
+	}
+
+	f.bytes.Add(int64(len(b)))
+
+	for len(b) > 0 {
+		if f.remaining > 0 {
+			n := min(uint64(len(b)), f.remaining)
+			if f.closing {
+				f.closePayload(b[:n])
+			}
+
+			f.remaining -= n
+			f.offset += n
+			b = b[n:]
+
+			continue
+		}
+
+		f.header = append(f.header, b[0])
+		b = b[1:]
+
+		if len(f.header) < wsHeaderLen(f.header) {
+			continue
+		}
+
+		f.frame()
+	}
+}
+
+// wsHeaderLen returns the length of the frame header starting with h
+func wsHeaderLen(h []byte) int {
+	if len(h) < 2 {
+		return 2
+	}
+
+	n := 2
+	switch h[1] & 0x7f {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+
+	if h[1]&0x80 != 0 {
+		n += 4
+	}
+
+	return n
+}
+
+// frame starts the frame whose header was read
+func (f *wsFrames) frame() {
+	h := f.header
+	f.frames.Add(1)
+
+	length := uint64(h[1] & 0x7f)
+	rest := h[2:]
+	switch length {
+	case 126:
+		length, rest = uint64(binary.BigEndian.Uint16(rest)), rest[2:]
+	case 127:
+		length, rest = binary.BigEndian.Uint64(rest), rest[8:]
+	}
+
+	f.masked = h[1]&0x80 != 0
+	if f.masked {
+		copy(f.mask[:], rest)
+	}
+
+	f.closing = h[0]&0x0f == wsOpClose && length >= 2
+	f.remaining = length
+	f.offset = 0
+	f.code = f.code[:0]
+	f.header = f.header[:0]
+}
+
+// closePayload collects the code of a close frame from its payload p
+func (f *wsFrames) closePayload(p []byte) {
+	for i := 0; i < len(p) && len(f.code) < 2; i++ {
+		c := p[i]
+		if f.masked {
+			c ^= f.mask[(f.offset+uint64(i))%4]
+		}
+
+		f.code = append(f.code, c)
+	}
+
+	if len(f.code) == 2 {
+		f.closeCode.CompareAndSwap(0, int64(binary.BigEndian.Uint16(f.code)))
+		f.closing = false
+	}
+}
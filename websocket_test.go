@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WebSocketSuite struct {
+	suite.Suite
+}
+
+// maskedFrame returns a client frame of opcode with payload, masked with
+// the key 1, 2, 3, 4
+func maskedFrame(opcode byte, payload []byte) []byte {
+	mask := []byte{1, 2, 3, 4}
+	b := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask...)
+
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+
+	return b
+}
+
+func (s *WebSocketSuite) TestSession() {
+	w := &syncWriter{}
+
+	h := New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		conn, brw, err := res.(http.Hijacker).Hijack()
+		if !s.Nil(err) {
+			return
+		}
+		defer conn.Close()
+
+		brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		brw.Write([]byte{0x81, 0x02, 'h', 'i'})
+		brw.Flush()
+
+		// the text and close frames of the client
+		io.ReadFull(brw, make([]byte, 11+8))
+
+		brw.Write([]byte{0x88, 0x02, 0x03, 0xe8})
+		brw.Flush()
+	}), WithWriter(w), WithFormat(NDJSONLoggerType), WithWebSocketSessions(true))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	s.Require().Nil(err)
+	defer conn.Close()
+
+	conn.Write([]byte("GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	conn.Write(maskedFrame(0x1, []byte("hello")))
+	conn.Write(maskedFrame(0x8, []byte{0x03, 0xe8}))
+	io.Copy(io.Discard, conn)
+
+	s.Eventually(func() bool {
+		return strings.Count(w.String(), "\n") == 2
+	}, time.Second, 10*time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	open, closed := map[string]interface{}{}, map[string]interface{}{}
+	s.Nil(json.Unmarshal([]byte(lines[0]), &open))
+	s.Nil(json.Unmarshal([]byte(lines[1]), &closed))
+
+	s.Equal(float64(101), open["response.status"])
+	s.Equal("open", open["websocket.state"])
+	s.NotContains(open, "websocket.frames_in")
+
+	s.Equal(float64(101), closed["response.status"])
+	s.Equal("closed", closed["websocket.state"])
+	s.Equal(float64(2), closed["websocket.frames_in"])
+	s.Equal(float64(2), closed["websocket.frames_out"])
+	s.Equal(float64(19), closed["websocket.bytes_in"])
+	s.Equal(float64(8), closed["websocket.bytes_out"])
+	s.Equal(float64(1000), closed["websocket.close_code"])
+}
+
+func (s *WebSocketSuite) TestNotUpgraded() {
+	tw := testWriter{}
+	h := New(http.NotFoundHandler(), WithWriter(&tw), WithFormat(NDJSONLoggerType), WithWebSocketSessions(true))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	s.NotContains(string(tw.Bytes), "websocket")
+}
+
+func (s *WebSocketSuite) TestFrames() {
+	var stream []byte
+	stream = append(stream, 0x82, 126, 0x01, 0x00)
+	stream = append(stream, make([]byte, 256)...)
+	stream = append(stream, 0x89, 0x00)
+	stream = append(stream, maskedFrame(0x8, []byte{0x03, 0xe9, 'b', 'y', 'e'})...)
+
+	var code atomic.Int64
+	f := wsFrames{closeCode: &code}
+
+	// a byte at a time, headers and payloads split across writes
+	for i := range stream {
+		f.count(stream[i : i+1])
+	}
+
+	s.Equal(int64(3), f.frames.Load())
+	s.Equal(int64(len(stream)), f.bytes.Load())
+	s.Equal(int64(1001), code.Load())
+}
+
+func (s *WebSocketSuite) TestHandshake() {
+	var code atomic.Int64
+	f := wsFrames{closeCode: &code, handshake: true}
+
+	f.count([]byte("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\n\r"))
+	f.count([]byte("\n\x81\x02hi"))
+
+	s.Equal(int64(1), f.frames.Load())
+	s.Equal(int64(4), f.bytes.Load())
+}
+
+func (s *WebSocketSuite) TestIsUpgrade() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	s.False(isWebSocketUpgrade(req))
+
+	req.Header.Set("Upgrade", "WebSocket")
+	req.Header.Set("Connection", "keep-alive, upgrade")
+	s.True(isWebSocketUpgrade(req))
+}
+
+func TestWebSocket(t *testing.T) {
+	suite.Run(t, new(WebSocketSuite))
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+)
+
+// responseWriter is the part of responseLogger every wrapped
+// http.ResponseWriter exposes
+type responseWriter interface {
+	http.ResponseWriter
+
+	Unwrap() http.ResponseWriter
+}
+
+const (
+	flusher = 1 << iota
+	hijacker
+	pusher
+	readerFrom
+)
+
+// wrap returns rl as a http.ResponseWriter that only advertises the
+// optional interfaces the underlying writer supports, so type assertions
+// done by downstream handlers keep working as without the middleware
+func wrap(rl *responseLogger) http.ResponseWriter {
+	return wrapAs(rl, supports(rl.rw))
+}
+
+// wrapped returns wrap(rl), reusing the writer wrapped for the previous
+// request of the pooled rl when the underlying writer supports the same
+// interfaces
+func (rl *responseLogger) wrapped() http.ResponseWriter {
+	supported := supports(rl.rw)
+	if rl.wrapper == nil || rl.wrapperKind != supported {
+		rl.wrapper, rl.wrapperKind = wrapAs(rl, supported), supported
+	}
+
+	return rl.wrapper
+}
+
+// supports returns the optional interfaces rw implements
+func supports(rw http.ResponseWriter) int {
+	var supported int
+
+	if _, ok := rw.(http.Flusher); ok {
+		supported |= flusher
+	}
+	if _, ok := rw.(http.Hijacker); ok {
+		supported |= hijacker
+	}
+	if _, ok := rw.(http.Pusher); ok {
+		supported |= pusher
+	}
+	if _, ok := rw.(io.ReaderFrom); ok {
+		supported |= readerFrom
+	}
+
+	return supported
+}
+
+// wrapAs returns rl as a http.ResponseWriter advertising the supported
+// optional interfaces
+func wrapAs(rl *responseLogger, supported int) http.ResponseWriter {
+	switch supported {
+	case flusher | hijacker | pusher | readerFrom:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rl, rl, rl, rl, rl}
+	case hijacker | pusher | readerFrom:
+		return struct {
+			responseWriter
+			http.Hijacker
+			http.Pusher
+			io.ReaderFrom
+		}{rl, rl, rl, rl}
+	case flusher | pusher | readerFrom:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{rl, rl, rl, rl}
+	case pusher | readerFrom:
+		return struct {
+			responseWriter
+			http.Pusher
+			io.ReaderFrom
+		}{rl, rl, rl}
+	case flusher | hijacker | readerFrom:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rl, rl, rl, rl}
+	case hijacker | readerFrom:
+		return struct {
+			responseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rl, rl, rl}
+	case flusher | readerFrom:
+		return struct {
+			responseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rl, rl, rl}
+	case readerFrom:
+		return struct {
+			responseWriter
+			io.ReaderFrom
+		}{rl, rl}
+	case flusher | hijacker | pusher:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{rl, rl, rl, rl}
+	case hijacker | pusher:
+		return struct {
+			responseWriter
+			http.Hijacker
+			http.Pusher
+		}{rl, rl, rl}
+	case flusher | pusher:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Pusher
+		}{rl, rl, rl}
+	case pusher:
+		return struct {
+			responseWriter
+			http.Pusher
+		}{rl, rl}
+	case flusher | hijacker:
+		return struct {
+			responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rl, rl, rl}
+	case hijacker:
+		return struct {
+			responseWriter
+			http.Hijacker
+		}{rl, rl}
+	case flusher:
+		return struct {
+			responseWriter
+			http.Flusher
+		}{rl, rl}
+	}
+
+	return struct {
+		responseWriter
+	}{rl}
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type WrapSuite struct {
+	suite.Suite
+}
+
+func (s *WrapSuite) TestPlain() {
+	w := wrap(&responseLogger{rw: testResponseWriter{}})
+
+	_, flusher := w.(http.Flusher)
+	_, hijacker := w.(http.Hijacker)
+
+	s.False(flusher)
+	s.False(hijacker)
+}
+
+func (s *WrapSuite) TestFlusher() {
+	rec := httptest.NewRecorder()
+	w := wrap(&responseLogger{rw: rec})
+
+	_, hijacker := w.(http.Hijacker)
+	s.False(hijacker)
+
+	w.(http.Flusher).Flush()
+	s.True(rec.Flushed)
+}
+
+func (s *WrapSuite) TestHijacker() {
+	rl := &responseLogger{rw: testHijacker{}}
+	w := wrap(rl)
+
+	_, flusher := w.(http.Flusher)
+	s.False(flusher)
+
+	conn, _, err := w.(http.Hijacker).Hijack()
+	s.Nil(err)
+	s.NotNil(conn)
+	s.True(rl.hijacked)
+
+	conn.Close()
+}
+
+func (s *WrapSuite) TestUnwrap() {
+	rec := httptest.NewRecorder()
+	w := wrap(&responseLogger{rw: rec})
+
+	s.Equal(rec, w.(interface{ Unwrap() http.ResponseWriter }).Unwrap())
+}
+
+func (s *WrapSuite) TestHijackUnsupported() {
+	_, _, err := (&responseLogger{rw: testResponseWriter{}}).Hijack()
+
+	s.NotNil(err)
+}
+
+func (s *WrapSuite) TestHijackedLog() {
+	tw := testWriter{}
+	h := Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		conn, _, _ := res.(http.Hijacker).Hijack()
+		conn.Close()
+	}), &tw, TinyLoggerType)
+
+	h.ServeHTTP(testHijacker{}, httptest.NewRequest(http.MethodGet, "/ws", nil))
+
+	s.Regexp(`^GET /ws hijacked 0 - \d+\.\d{3} ms\n$`, string(tw.Bytes))
+}
+
+func (s *WrapSuite) TestPusher() {
+	tp := &testPusher{}
+	w := wrap(&responseLogger{rw: tp})
+
+	_, readerFrom := w.(io.ReaderFrom)
+	s.False(readerFrom)
+
+	s.Nil(w.(http.Pusher).Push("/app.js", nil))
+	s.Equal("/app.js", tp.target)
+}
+
+func (s *WrapSuite) TestPushUnsupported() {
+	s.Equal(http.ErrNotSupported, (&responseLogger{rw: testResponseWriter{}}).Push("/app.js", nil))
+}
+
+func (s *WrapSuite) TestReadFrom() {
+	rl := &responseLogger{rw: testResponseWriter{}}
+
+	size, err := rl.ReadFrom(strings.NewReader("test-logger"))
+
+	s.Nil(err)
+	s.Equal(int64(11), size)
+	s.Equal(11, rl.size)
+	s.Equal(http.StatusOK, rl.status)
+}
+
+func (s *WrapSuite) TestServer() {
+	tw := testWriter{}
+	var flusher, hijacker, pusher, readerFrom bool
+
+	ts := httptest.NewServer(Handler(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, flusher = res.(http.Flusher)
+		_, hijacker = res.(http.Hijacker)
+		_, pusher = res.(http.Pusher)
+		_, readerFrom = res.(io.ReaderFrom)
+
+		io.Copy(res, strings.NewReader("test-logger"))
+	}), &tw, TinyLoggerType))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	s.Nil(err)
+	b, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	s.Equal("test-logger", string(b))
+	s.True(flusher)
+	s.True(hijacker)
+	s.False(pusher)
+	s.True(readerFrom)
+	s.Regexp(`^GET / 200 11 - \d+\.\d{3} ms\n$`, string(tw.Bytes))
+}
+
+func TestWrap(t *testing.T) {
+	suite.Run(t, new(WrapSuite))
+}
+
+type testHijacker struct {
+	testResponseWriter
+}
+
+func (th testHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, peer := net.Pipe()
+	peer.Close()
+
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+type testPusher struct {
+	testResponseWriter
+
+	target string
+}
+
+func (tp *testPusher) Push(target string, opts *http.PushOptions) error {
+	tp.target = target
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package zap writes the JsonLoggerType entries of the logger package to
+// a zap logger, see logger.WithBackend.
+package zap
+
+import (
+	"sort"
+
+	"github.com/go-http-utils/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Backend returns a logger.Backend writing to l
+func Backend(l *zap.Logger) logger.Backend {
+	return backend{l}
+}
+
+type backend struct {
+	l *zap.Logger
+}
+
+func (b backend) Log(level logger.Level, msg string, fields map[string]interface{}) {
+	ce := b.l.Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	zf := make([]zap.Field, 0, len(keys))
+	for _, k := range keys {
+		zf = append(zf, zap.Any(k, fields[k]))
+	}
+
+	ce.Write(zf...)
+}
+
+func zapLevel(level logger.Level) zapcore.Level {
+	switch level {
+	case logger.LevelDebug:
+		return zapcore.DebugLevel
+	case logger.LevelWarn:
+		return zapcore.WarnLevel
+	case logger.LevelError:
+		return zapcore.ErrorLevel
+	}
+
+	return zapcore.InfoLevel
+}
@@ -0,0 +1,45 @@
+package zap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type ZapSuite struct {
+	suite.Suite
+}
+
+func (s *ZapSuite) TestBackend() {
+	core, logs := observer.New(zapcore.InfoLevel)
+	h := logger.New(http.NotFoundHandler(), logger.WithFormat(logger.JsonLoggerType),
+		logger.WithBackend(Backend(zap.New(core))))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	s.Equal(1, logs.Len())
+
+	entry := logs.All()[0]
+	s.Equal("request processed", entry.Message)
+	s.Equal(zapcore.WarnLevel, entry.Level)
+	s.Equal("404", entry.ContextMap()["response.status"])
+	s.Equal("GET", entry.ContextMap()["request.method"])
+}
+
+func (s *ZapSuite) TestDisabledLevel() {
+	core, logs := observer.New(zapcore.ErrorLevel)
+
+	Backend(zap.New(core)).Log(logger.LevelInfo, "request processed", map[string]interface{}{"a": 1})
+
+	s.Equal(0, logs.Len())
+}
+
+func TestZap(t *testing.T) {
+	suite.Run(t, new(ZapSuite))
+}
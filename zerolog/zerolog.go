@@ -0,0 +1,34 @@
+// Package zerolog writes the JsonLoggerType entries of the logger package
+// to a zerolog logger, see logger.WithBackend.
+package zerolog
+
+import (
+	"github.com/go-http-utils/logger"
+	"github.com/rs/zerolog"
+)
+
+// Backend returns a logger.Backend writing to l
+func Backend(l zerolog.Logger) logger.Backend {
+	return backend{l}
+}
+
+type backend struct {
+	l zerolog.Logger
+}
+
+func (b backend) Log(level logger.Level, msg string, fields map[string]interface{}) {
+	b.l.WithLevel(zerologLevel(level)).Fields(fields).Msg(msg)
+}
+
+func zerologLevel(level logger.Level) zerolog.Level {
+	switch level {
+	case logger.LevelDebug:
+		return zerolog.DebugLevel
+	case logger.LevelWarn:
+		return zerolog.WarnLevel
+	case logger.LevelError:
+		return zerolog.ErrorLevel
+	}
+
+	return zerolog.InfoLevel
+}
@@ -0,0 +1,37 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-http-utils/logger"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+)
+
+type ZerologSuite struct {
+	suite.Suite
+}
+
+func (s *ZerologSuite) TestBackend() {
+	var buf bytes.Buffer
+	h := logger.New(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusBadGateway)
+	}), logger.WithFormat(logger.JsonLoggerType), logger.WithBackend(Backend(zerolog.New(&buf))))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	entry := map[string]interface{}{}
+	s.Nil(json.Unmarshal(buf.Bytes(), &entry))
+	s.Equal("request processed", entry["message"])
+	s.Equal("error", entry["level"])
+	s.Equal("502", entry["response.status"])
+	s.Equal("GET", entry["request.method"])
+}
+
+func TestZerolog(t *testing.T) {
+	suite.Run(t, new(ZerologSuite))
+}